@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/fluxcd/pkg/apis/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	AlertKind string = "Alert"
+
+	// DeletionReason is the reason of the events emitted by the Flux
+	// controllers when finalizing the deletion of an object.
+	DeletionReason string = "Deleted"
+)
+
+// AlertSpec defines an alerting rule for events involving a list of objects.
+type AlertSpec struct {
+	// ProviderRefs specifies the Providers this Alert dispatches events to.
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	ProviderRefs []meta.LocalObjectReference `json:"providerRefs"`
+
+	// EventSeverities specifies the severities of the events dispatched by
+	// this Alert. If not set, events of all severities are dispatched.
+	// +kubebuilder:validation:items:Enum=info;error
+	// +optional
+	EventSeverities []string `json:"eventSeverities,omitempty"`
+
+	// EventReasons specifies the reasons of the events dispatched by this
+	// Alert, e.g. Deleted for the deletion of the event sources.
+	// If not set, events of all reasons are dispatched.
+	// +optional
+	EventReasons []string `json:"eventReasons,omitempty"`
+
+	// EventSources specifies how to filter events based
+	// on the involved object kind, name and namespace.
+	// +required
+	EventSources []CrossNamespaceObjectReference `json:"eventSources"`
+
+	// InclusionList specifies a list of Golang regular expressions
+	// to be used for including messages.
+	// +optional
+	InclusionList []string `json:"inclusionList,omitempty"`
+
+	// EventMetadata is an optional field for adding metadata to events dispatched by the
+	// controller. This can be used for enhancing the context of the event. If a field
+	// would override one already present on the original event as generated by the emitter,
+	// then the override doesn't happen, i.e. the original value is preserved, and an info
+	// log is printed.
+	// +optional
+	EventMetadata map[string]string `json:"eventMetadata,omitempty"`
+
+	// EventMetadataFormat maps the keys of the event metadata to a function
+	// formatting their values before dispatching the event. The supported
+	// functions are shortSHA, shortening the digest of revisions, trimRef,
+	// trimming the refs/heads/ and refs/tags/ prefixes of revisions, and
+	// humanizeDuration, rounding durations.
+	// +optional
+	EventMetadataFormat map[string]string `json:"eventMetadataFormat,omitempty"`
+
+	// ExclusionList specifies a list of Golang regular expressions
+	// to be used for excluding messages.
+	// +optional
+	ExclusionList []string `json:"exclusionList,omitempty"`
+
+	// SampleRate is the fraction of the info events dispatched by this Alert,
+	// between 0 and 1, e.g. 0.1 for one event out of ten. Error events are
+	// always dispatched. If not set, all the info events are dispatched.
+	// +kubebuilder:validation:Pattern="^(0(\\.[0-9]+)?|1(\\.0+)?)$"
+	// +optional
+	SampleRate string `json:"sampleRate,omitempty"`
+
+	// RateLimitInterval overrides, for this Alert, the interval of the rate
+	// limiting of the duplicate events set with the --rate-limit-interval
+	// controller flag, e.g. a longer interval for chat Providers or 0s to
+	// dispatch all the events to audit sinks.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	RateLimitInterval *metav1.Duration `json:"rateLimitInterval,omitempty"`
+
+	// TemplateRef specifies the NotificationTemplate, in the namespace of the
+	// Alert, rendering the messages of the notifications.
+	// +optional
+	TemplateRef *meta.LocalObjectReference `json:"templateRef,omitempty"`
+
+	// ServiceAccountName is the name of the Kubernetes ServiceAccount, in the
+	// namespace of the Alert, impersonated when reading the event source objects
+	// to match their labels. When not specified, the controller's own
+	// ServiceAccount is used.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Summary holds a short description of the impact and affected cluster.
+	// Deprecated: Use EventMetadata instead.
+	//
+	// +kubebuilder:validation:MaxLength:=255
+	// +optional
+	// +deprecated
+	Summary string `json:"summary,omitempty"`
+
+	// Suspend tells the controller to suspend subsequent
+	// events handling for this Alert.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// AlertStatus defines the observed state of the Alert.
+type AlertStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions holds the conditions for the Alert.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +kubebuilder:storageversion
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].message",description=""
+
+// Alert is the Schema for the alerts API
+type Alert struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AlertSpec `json:"spec,omitempty"`
+
+	// +kubebuilder:default:={"observedGeneration":-1}
+	Status AlertStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the status conditions of the object.
+func (in *Alert) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the status conditions on the object.
+func (in *Alert) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// MatchesSeverity returns true if events of the given severity are
+// dispatched by this Alert.
+func (in *Alert) MatchesSeverity(severity string) bool {
+	if len(in.Spec.EventSeverities) == 0 {
+		return true
+	}
+	for _, s := range in.Spec.EventSeverities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesReason returns true if events of the given reason are
+// dispatched by this Alert.
+func (in *Alert) MatchesReason(reason string) bool {
+	if len(in.Spec.EventReasons) == 0 {
+		return true
+	}
+	for _, r := range in.Spec.EventReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+//+kubebuilder:object:root=true
+
+// AlertList contains a list of Alert
+type AlertList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Alert `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Alert{}, &AlertList{})
+}
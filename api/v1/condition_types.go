@@ -28,4 +28,44 @@ const (
 
 	// TokenNotFoundReason represents the fact that receiver token can't be found.
 	TokenNotFoundReason string = "TokenNotFound"
+
+	// ResumedReason represents the fact that a given resource has been
+	// resumed after being suspended.
+	ResumedReason string = "Resumed"
+)
+
+const (
+	// ProviderResolvedCondition indicates whether the Providers referenced
+	// by the Alert exist.
+	ProviderResolvedCondition string = "ProviderResolved"
+
+	// MessageFiltersValidCondition indicates whether the inclusion and
+	// exclusion lists of the Alert are valid regular expressions.
+	MessageFiltersValidCondition string = "MessageFiltersValid"
+
+	// EventSourcesValidCondition indicates whether the kinds of the Alert
+	// event sources are served by the cluster.
+	EventSourcesValidCondition string = "EventSourcesValid"
+
+	// CredentialsValidCondition indicates whether the credentials of the
+	// Provider are allowed to post notifications to the target.
+	CredentialsValidCondition string = "CredentialsValid"
+)
+
+const (
+	// ProviderNotFoundReason represents the fact that a Provider
+	// referenced by the Alert can't be found.
+	ProviderNotFoundReason string = "ProviderNotFound"
+
+	// InvalidRegexReason represents the fact that a regular expression of
+	// the Alert can't be compiled.
+	InvalidRegexReason string = "InvalidRegex"
+
+	// UnknownKindReason represents the fact that the kind of an event
+	// source isn't served by the cluster.
+	UnknownKindReason string = "UnknownKind"
+
+	// InsufficientPermissionsReason represents the fact that the credentials
+	// of the Provider are rejected or lack the required permissions.
+	InsufficientPermissionsReason string = "InsufficientPermissions"
 )
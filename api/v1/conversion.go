@@ -0,0 +1,33 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// ConversionDataAnnotation is the annotation holding the spec of a v1 object
+// when it can't be represented in the API version it was converted to. It is
+// used to restore the v1 fields when the object is converted back to v1.
+const ConversionDataAnnotation = "notification.toolkit.fluxcd.io/conversion-data"
+
+// ProxyAnnotation is the annotation holding the deprecated .spec.proxy of a
+// beta Provider converted to v1. The proxy isn't used by the v1 Provider, it
+// is restored when the object is converted back to the beta API version.
+const ProxyAnnotation = "notification.toolkit.fluxcd.io/proxy"
+
+// Hub marks Alert as a conversion hub.
+func (*Alert) Hub() {}
+
+// Hub marks Provider as a conversion hub.
+func (*Provider) Hub() {}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	NotificationPolicyKind string = "NotificationPolicy"
+)
+
+// NotificationPolicySpec defines the defaults merged into all the Alerts
+// of the cluster.
+type NotificationPolicySpec struct {
+	// ExclusionList specifies a list of Golang regular expressions to be
+	// used for excluding messages, in addition to the exclusion list of
+	// the Alerts.
+	// +optional
+	ExclusionList []string `json:"exclusionList,omitempty"`
+
+	// SuppressionWindows specifies the recurring time windows during which
+	// the events are not dispatched by the Alerts, e.g. the maintenance
+	// windows of the cluster.
+	// +optional
+	SuppressionWindows []SuppressionWindow `json:"suppressionWindows,omitempty"`
+
+	// EventMetadata specifies the metadata added to the events dispatched by
+	// the Alerts, overriding the eventMetadata of the Alerts with the same
+	// keys. As for the Alerts, the metadata of the original event is preserved.
+	// +optional
+	EventMetadata map[string]string `json:"eventMetadata,omitempty"`
+
+	// ExcludedNamespaces specifies the namespaces whose Alerts are not
+	// subject to this NotificationPolicy.
+	// +optional
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+
+	// Suspend tells the controller to stop applying this NotificationPolicy
+	// to the Alerts.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// SuppressionWindow defines a recurring time window, in UTC.
+type SuppressionWindow struct {
+	// Start is the start time of the window, in the HH:MM format.
+	// +kubebuilder:validation:Pattern="^([01][0-9]|2[0-3]):[0-5][0-9]$"
+	// +required
+	Start string `json:"start"`
+
+	// End is the end time of the window, in the HH:MM format. The window
+	// spans midnight when the end time is before the start time.
+	// +kubebuilder:validation:Pattern="^([01][0-9]|2[0-3]):[0-5][0-9]$"
+	// +required
+	End string `json:"end"`
+
+	// Days specifies the days of the week the window starts on.
+	// If not set, the window recurs every day.
+	// +kubebuilder:validation:items:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+	// +optional
+	Days []string `json:"days,omitempty"`
+}
+
+// Contains returns true if the given time is within the window. It returns
+// false when the start or end time can't be parsed.
+func (in SuppressionWindow) Contains(t time.Time) bool {
+	start, err := time.Parse("15:04", in.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", in.End)
+	if err != nil {
+		return false
+	}
+
+	t = t.UTC()
+	startsOn := func(day time.Weekday) bool {
+		return len(in.Days) == 0 || slices.Contains(in.Days, day.String())
+	}
+	minutes := t.Hour()*60 + t.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+
+	if startMinutes <= endMinutes {
+		return startsOn(t.Weekday()) && minutes >= startMinutes && minutes < endMinutes
+	}
+	// The window spans midnight, it started either today or yesterday.
+	if minutes >= startMinutes {
+		return startsOn(t.Weekday())
+	}
+	return minutes < endMinutes && startsOn(t.AddDate(0, 0, -1).Weekday())
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// NotificationPolicy is the Schema for the notificationpolicies API
+type NotificationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NotificationPolicySpec `json:"spec,omitempty"`
+}
+
+// AppliesTo returns true if the NotificationPolicy applies to the Alerts
+// of the given namespace.
+func (in *NotificationPolicy) AppliesTo(namespace string) bool {
+	return !in.Spec.Suspend && !slices.Contains(in.Spec.ExcludedNamespaces, namespace)
+}
+
+// Suppresses returns true if the given time is within one of the
+// suppression windows of the NotificationPolicy.
+func (in *NotificationPolicy) Suppresses(t time.Time) bool {
+	for _, w := range in.Spec.SuppressionWindows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+//+kubebuilder:object:root=true
+
+// NotificationPolicyList contains a list of NotificationPolicy
+type NotificationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NotificationPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NotificationPolicy{}, &NotificationPolicyList{})
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	NotificationTemplateKind string = "NotificationTemplate"
+)
+
+// NotificationTemplateSpec defines the templates of the notification
+// messages of the Alerts referencing the NotificationTemplate.
+type NotificationTemplateSpec struct {
+	// Message is the Go template rendering the message of the notifications,
+	// e.g. '{{ .InvolvedObject.Kind }}/{{ .InvolvedObject.Name }}: {{ .Message }}'.
+	// The template is rendered with the event. When not set, the message of
+	// the event is sent as is.
+	// +kubebuilder:validation:MaxLength:=4096
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ProviderMessages overrides the Message template for the Providers of
+	// the given types, e.g. to use the markup of a chat Provider.
+	// +kubebuilder:validation:MaxProperties:=50
+	// +optional
+	ProviderMessages map[string]string `json:"providerMessages,omitempty"`
+}
+
+// +genclient
+// +kubebuilder:storageversion
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// NotificationTemplate is the Schema for the notificationtemplates API
+type NotificationTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NotificationTemplateSpec `json:"spec,omitempty"`
+}
+
+// MessageTemplate returns the message template for the Provider of the
+// given type, or an empty string if the message is not templated.
+func (in *NotificationTemplate) MessageTemplate(providerType string) string {
+	if tmpl, ok := in.Spec.ProviderMessages[providerType]; ok {
+		return tmpl
+	}
+	return in.Spec.Message
+}
+
+//+kubebuilder:object:root=true
+
+// NotificationTemplateList contains a list of NotificationTemplate
+type NotificationTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NotificationTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NotificationTemplate{}, &NotificationTemplateList{})
+}
@@ -0,0 +1,412 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluxcd/pkg/apis/meta"
+)
+
+const (
+	ProviderKind            string = "Provider"
+	GenericProvider         string = "generic"
+	GenericHMACProvider     string = "generic-hmac"
+	SlackProvider           string = "slack"
+	GrafanaProvider         string = "grafana"
+	DiscordProvider         string = "discord"
+	MSTeamsProvider         string = "msteams"
+	RocketProvider          string = "rocket"
+	GitHubDispatchProvider  string = "githubdispatch"
+	GitHubProvider          string = "github"
+	GitLabProvider          string = "gitlab"
+	GiteaProvider           string = "gitea"
+	BitbucketServerProvider string = "bitbucketserver"
+	BitbucketProvider       string = "bitbucket"
+	AzureDevOpsProvider     string = "azuredevops"
+	GoogleChatProvider      string = "googlechat"
+	GooglePubSubProvider    string = "googlepubsub"
+	WebexProvider           string = "webex"
+	SentryProvider          string = "sentry"
+	AzureEventHubProvider   string = "azureeventhub"
+	TelegramProvider        string = "telegram"
+	LarkProvider            string = "lark"
+	MatrixProvider          string = "matrix"
+	OpsgenieProvider        string = "opsgenie"
+	AlertManagerProvider    string = "alertmanager"
+	PagerDutyProvider       string = "pagerduty"
+	DataDogProvider         string = "datadog"
+	NATSProvider            string = "nats"
+	ExecProvider            string = "exec"
+	GerritProvider          string = "gerrit"
+	ForgejoProvider         string = "forgejo"
+	FluxRelayProvider       string = "fluxrelay"
+	CloudEventsProvider     string = "cloudevents"
+	BackstageProvider       string = "backstage"
+	PortProvider            string = "port"
+	GitLabTriggerProvider   string = "gitlabtrigger"
+	JenkinsProvider         string = "jenkins"
+	StatusBoardProvider     string = "statusboard"
+)
+
+// ProviderSpec defines the desired state of the Provider.
+type ProviderSpec struct {
+	// Type specifies which Provider implementation to use.
+	// +kubebuilder:validation:Enum=slack;discord;msteams;rocket;generic;generic-hmac;github;gitlab;gitea;bitbucketserver;bitbucket;azuredevops;googlechat;googlepubsub;webex;sentry;azureeventhub;telegram;lark;matrix;opsgenie;alertmanager;grafana;githubdispatch;pagerduty;datadog;nats;exec;gerrit;forgejo;fluxrelay;cloudevents;backstage;port;gitlabtrigger;jenkins;statusboard
+	// +required
+	Type string `json:"type"`
+
+	// Channel specifies the destination channel where events should be posted.
+	// +kubebuilder:validation:MaxLength:=2048
+	// +optional
+	Channel string `json:"channel,omitempty"`
+
+	// Username specifies the name under which events are posted.
+	// +kubebuilder:validation:MaxLength:=2048
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// MetadataFields configures how the event metadata is rendered as
+	// structured fields by the slack, discord and msteams Providers.
+	// +optional
+	MetadataFields *MetadataFields `json:"metadataFields,omitempty"`
+
+	// Appearance overrides the colors and emojis of the messages posted by
+	// the slack, discord, rocket, lark, telegram, matrix and webex Providers,
+	// by event severity.
+	// +optional
+	Appearance *Appearance `json:"appearance,omitempty"`
+
+	// MessageTruncation configures the truncation of the event messages
+	// exceeding a maximum length, before they are sent to the Provider.
+	// +optional
+	MessageTruncation *MessageTruncation `json:"messageTruncation,omitempty"`
+
+	// HMAC configures the signature of the requests sent by the
+	// generic-hmac Provider.
+	// +optional
+	HMAC *HMACSignature `json:"hmac,omitempty"`
+
+	// GitHubDispatch configures the events sent by the githubdispatch
+	// Provider.
+	// +optional
+	GitHubDispatch *GitHubDispatch `json:"githubDispatch,omitempty"`
+
+	// Address specifies the endpoint, in a generic sense, to where alerts are sent.
+	// What kind of endpoint depends on the specific Provider type being used.
+	// For the generic Provider, for example, this is an HTTP/S address.
+	// For other Provider types this could be a project ID or a namespace.
+	// The ${key} placeholders are substituted with the values of the matching
+	// keys of the Secret referenced by SecretRef.
+	// +kubebuilder:validation:MaxLength:=2048
+	// +kubebuilder:validation:Optional
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// AddressFrom specifies a source to read the address from, for addresses
+	// that are managed centrally and don't need to be stored in a Secret.
+	// It takes precedence over .spec.address, while an address read from
+	// the Secret referenced in .spec.secretRef takes precedence over it.
+	// +optional
+	AddressFrom *AddressSource `json:"addressFrom,omitempty"`
+
+	// APIBaseURL specifies the base URL of the Git provider API, e.g.
+	// https://github.example.com/api/v3 for a GitHub Enterprise Server.
+	// When not set, it's derived from the address of the repository.
+	// Only used by the github and githubdispatch Providers.
+	// +kubebuilder:validation:Pattern="^(http|https)://.*$"
+	// +kubebuilder:validation:MaxLength:=2048
+	// +optional
+	APIBaseURL string `json:"apiBaseURL,omitempty"`
+
+	// CommitStatusKey overrides the key of the build statuses posted by the
+	// bitbucketserver Provider, which defaults to a hash of the involved
+	// object and Provider UID. The {kind}, {name} and {namespace}
+	// placeholders are replaced with the fields of the involved object.
+	// Keys longer than 40 characters are replaced with their SHA-1 hash.
+	// +kubebuilder:validation:MaxLength:=256
+	// +optional
+	CommitStatusKey string `json:"commitStatusKey,omitempty"`
+
+	// Timeout for sending alerts to the Provider.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m))+$"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// ProxySecretRef specifies the Secret containing the address of the
+	// HTTP/S proxy server (in the `address` key), and optionally the
+	// credentials to authenticate with it (in the `username` and `password`
+	// keys).
+	// +optional
+	ProxySecretRef *meta.LocalObjectReference `json:"proxySecretRef,omitempty"`
+
+	// SecretRef specifies the Secret containing the authentication
+	// credentials for this Provider.
+	// +optional
+	SecretRef *meta.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// ServiceAccountName is the name of the Kubernetes ServiceAccount used
+	// to authenticate with Entra workload identity federation, instead of
+	// the token in the Secret referenced in .spec.secretRef.
+	// Only supported by the azuredevops Provider.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// CertSecretRef specifies the Secret containing
+	// a PEM-encoded CA certificate (in the `ca.crt` key).
+	// +optional
+	//
+	// Note: Support for the `caFile` key has
+	// been deprecated.
+	CertSecretRef *meta.LocalObjectReference `json:"certSecretRef,omitempty"`
+
+	// DNS configures the resolution of the hostnames of the Provider
+	// endpoints, e.g. in split-horizon DNS environments.
+	// +optional
+	DNS *ProviderDNS `json:"dns,omitempty"`
+
+	// Suspend tells the controller to suspend subsequent
+	// events handling for this Provider.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// MetadataFields configures the rendering of the event metadata as
+// structured fields.
+type MetadataFields struct {
+	// Order specifies the metadata keys rendered first, in the given order.
+	// The other keys are rendered after them, sorted alphabetically.
+	// +optional
+	Order []string `json:"order,omitempty"`
+
+	// Exclude specifies the metadata keys that are not rendered.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Appearance configures the messages of each event severity.
+type Appearance struct {
+	// Info configures the messages of the info events.
+	// +optional
+	Info *SeverityAppearance `json:"info,omitempty"`
+
+	// Error configures the messages of the error events.
+	// +optional
+	Error *SeverityAppearance `json:"error,omitempty"`
+}
+
+// SeverityAppearance configures the color and emoji of the messages.
+type SeverityAppearance struct {
+	// Color of the messages, e.g. '#ff0000'. It's passed as is to the
+	// Provider, which may also accept named colors such as 'danger' for slack
+	// and discord, or the card template colors such as 'red' for lark.
+	// +kubebuilder:validation:MaxLength:=32
+	// +optional
+	Color string `json:"color,omitempty"`
+
+	// Emoji prefixing the title of the messages, e.g. ':fire:' or '🔥'.
+	// +kubebuilder:validation:MaxLength:=64
+	// +optional
+	Emoji string `json:"emoji,omitempty"`
+}
+
+// Truncation strategies of the event messages.
+const (
+	// TruncateTail keeps the beginning of the message.
+	TruncateTail string = "Tail"
+	// TruncateHead keeps the end of the message.
+	TruncateHead string = "Head"
+)
+
+// MessageTruncation configures the truncation of the event messages.
+type MessageTruncation struct {
+	// MaxLength is the maximum length of the message in characters,
+	// including the truncation marker and the details link.
+	// +kubebuilder:validation:Minimum=1
+	// +required
+	MaxLength int `json:"maxLength"`
+
+	// Strategy specifies which part of the message is kept: 'Tail' cuts
+	// the end of the message, 'Head' cuts its beginning.
+	// +kubebuilder:validation:Enum=Head;Tail
+	// +kubebuilder:default:=Tail
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+
+	// DetailsURL is appended to the truncated messages to link to the full
+	// message. The `{namespace}`, `{kind}` and `{name}` placeholders are
+	// replaced with the ones of the involved object.
+	// +kubebuilder:validation:Pattern="^(http|https)://.*$"
+	// +optional
+	DetailsURL string `json:"detailsURL,omitempty"`
+}
+
+// HMACSignature configures the HMAC signature of the requests.
+type HMACSignature struct {
+	// Header is the name of the HTTP header carrying the signature.
+	// +kubebuilder:validation:Pattern="^[A-Za-z0-9-]+$"
+	// +kubebuilder:validation:MaxLength:=256
+	// +kubebuilder:default:=X-Signature
+	// +optional
+	Header string `json:"header,omitempty"`
+
+	// Algorithm is the hash function of the HMAC.
+	// +kubebuilder:validation:Enum=sha1;sha256;sha512
+	// +kubebuilder:default:=sha256
+	// +optional
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+// GitHubDispatch configures the events sent by the githubdispatch Provider.
+type GitHubDispatch struct {
+	// Workflow is the file name or the ID of a GitHub Actions workflow.
+	// When set, a workflow_dispatch event is sent to trigger the workflow
+	// instead of a repository_dispatch event.
+	// +optional
+	Workflow string `json:"workflow,omitempty"`
+
+	// Ref is the Git branch or tag the workflow is run for.
+	// +kubebuilder:default:=main
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// Inputs maps the names of the workflow inputs to Go templates
+	// rendered with the event, e.g. '{{ .Metadata.revision }}'.
+	// For repository_dispatch events, the rendered inputs are sent as the
+	// client payload instead of the event.
+	// +kubebuilder:validation:MaxProperties:=10
+	// +optional
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+// AddressSource specifies a source for the address of a Provider.
+type AddressSource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap in the same namespace
+	// as the Provider.
+	// +required
+	ConfigMapKeyRef ConfigMapKeyReference `json:"configMapKeyRef"`
+}
+
+// ConfigMapKeyReference contains enough information to locate a key
+// of a ConfigMap in the same namespace as the referring object.
+type ConfigMapKeyReference struct {
+	// Name of the ConfigMap.
+	// +required
+	Name string `json:"name"`
+
+	// Key in the ConfigMap.
+	// +required
+	Key string `json:"key"`
+}
+
+// ProviderDNS configures the resolution of the hostnames of the Provider
+// endpoints by the notifier HTTP clients.
+type ProviderDNS struct {
+	// Nameserver is the address of the DNS server resolving the hostnames,
+	// in the form host:port. The port defaults to 53 when omitted.
+	// +kubebuilder:validation:MaxLength:=256
+	// +optional
+	Nameserver string `json:"nameserver,omitempty"`
+
+	// HostAliases maps hostnames to IP addresses, taking precedence
+	// over the DNS resolution.
+	// +optional
+	HostAliases []HostAlias `json:"hostAliases,omitempty"`
+}
+
+// HostAlias holds the mapping between an IP address and hostnames.
+type HostAlias struct {
+	// IP address of the hostnames.
+	// +required
+	IP string `json:"ip"`
+
+	// Hostnames resolving to the IP address.
+	// +kubebuilder:validation:MinItems:=1
+	// +required
+	Hostnames []string `json:"hostnames"`
+}
+
+// ProviderStatus defines the observed state of the Provider.
+type ProviderStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions holds the conditions for the Provider.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Capabilities lists the optional features supported by the Provider
+	// type, e.g. TLS, Proxy, CommitStatus and WorkloadIdentity.
+	// +optional
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// +genclient
+// +kubebuilder:storageversion
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].message",description=""
+
+// Provider is the Schema for the providers API
+type Provider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ProviderSpec `json:"spec,omitempty"`
+
+	// +kubebuilder:default:={"observedGeneration":-1}
+	Status ProviderStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the status conditions of the object.
+func (in *Provider) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the status conditions on the object.
+func (in *Provider) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+//+kubebuilder:object:root=true
+
+// ProviderList contains a list of Provider
+type ProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Provider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Provider{}, &ProviderList{})
+}
+
+// GetTimeout returns the timeout value with a default of 15s for this Provider.
+func (in *Provider) GetTimeout() time.Duration {
+	duration := 15 * time.Second
+	if in.Spec.Timeout != nil {
+		duration = in.Spec.Timeout.Duration
+	}
+
+	return duration
+}
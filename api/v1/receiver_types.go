@@ -27,27 +27,66 @@ import (
 )
 
 const (
-	ReceiverKind        string = "Receiver"
-	ReceiverWebhookPath string = "/hook/"
-	GenericReceiver     string = "generic"
-	GenericHMACReceiver string = "generic-hmac"
-	GitHubReceiver      string = "github"
-	GitLabReceiver      string = "gitlab"
-	BitbucketReceiver   string = "bitbucket"
-	HarborReceiver      string = "harbor"
-	DockerHubReceiver   string = "dockerhub"
-	QuayReceiver        string = "quay"
-	GCRReceiver         string = "gcr"
-	NexusReceiver       string = "nexus"
-	ACRReceiver         string = "acr"
-	CDEventsReceiver    string = "cdevents"
+	ReceiverKind         string = "Receiver"
+	ReceiverWebhookPath  string = "/hook/"
+	GenericReceiver      string = "generic"
+	GenericHMACReceiver  string = "generic-hmac"
+	GitHubReceiver       string = "github"
+	GitLabReceiver       string = "gitlab"
+	BitbucketReceiver    string = "bitbucket"
+	HarborReceiver       string = "harbor"
+	DockerHubReceiver    string = "dockerhub"
+	QuayReceiver         string = "quay"
+	GCRReceiver          string = "gcr"
+	NexusReceiver        string = "nexus"
+	ACRReceiver          string = "acr"
+	CDEventsReceiver     string = "cdevents"
+	CIReceiver           string = "ci"
+	DistributionReceiver string = "distribution"
+	CloudBuildReceiver   string = "cloudbuild"
+	RenovateReceiver     string = "renovate"
+
+	// DefaultTokenSecretKey is the Secret data key holding the
+	// Receiver token when .spec.secretKey is not set.
+	DefaultTokenSecretKey string = "token"
+)
+
+const (
+	// TriggerAccepted means the resource was annotated to request its
+	// reconciliation, or notified.
+	TriggerAccepted string = "Accepted"
+
+	// TriggerFiltered means no object matched the resource selector.
+	TriggerFiltered string = "Filtered"
+
+	// TriggerFailed means the reconciliation of the resource couldn't be
+	// requested.
+	TriggerFailed string = "Failed"
+
+	// MaxReceiverTriggers is the number of trigger results kept in the
+	// Receiver status.
+	MaxReceiverTriggers int = 10
+)
+
+const (
+	// AnnotateAction requests the reconciliation of the objects matching a
+	// Receiver resource by annotating them.
+	AnnotateAction string = "annotate"
+
+	// NotifyAction dispatches an event for the objects matching a Receiver
+	// resource without modifying them.
+	NotifyAction string = "notify"
+
+	// WebhookReceivedReason is the reason of the events dispatched for the
+	// objects matching a Receiver resource with the notify action.
+	WebhookReceivedReason string = "WebhookReceived"
 )
 
 // ReceiverSpec defines the desired state of the Receiver.
 type ReceiverSpec struct {
 	// Type of webhook sender, used to determine
 	// the validation procedure and payload deserialization.
-	// +kubebuilder:validation:Enum=generic;generic-hmac;github;gitlab;bitbucket;harbor;dockerhub;quay;gcr;nexus;acr;cdevents
+	// +kubebuilder:validation:Enum=generic;generic-hmac;github;gitlab;bitbucket;harbor;dockerhub;quay;gcr;nexus;acr;cdevents;ci;distribution;cloudbuild;renovate
 	// +required
 	Type string `json:"type"`
 
@@ -65,13 +104,32 @@ type ReceiverSpec struct {
 
 	// A list of resources to be notified about changes.
 	// +required
-	Resources []CrossNamespaceObjectReference `json:"resources"`
+	Resources []ReceiverObjectReference `json:"resources"`
 
 	// SecretRef specifies the Secret containing the token used
 	// to validate the payload authenticity.
 	// +required
 	SecretRef meta.LocalObjectReference `json:"secretRef"`
 
+	// SecretKey is the key of the Secret data holding the token,
+	// defaults to 'token'.
+	// +optional
+	SecretKey string `json:"secretKey,omitempty"`
+
+	// Timeout for the outbound requests made to validate the webhook
+	// requests, e.g. the verification of the Google tokens of the gcr and
+	// cloudbuild Receivers. Defaults to 15s.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m))+$"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// PayloadSchemaRef specifies the key of a ConfigMap containing a JSON
+	// Schema the payloads must satisfy before the resources are reconciled.
+	// Only used by the generic and generic-hmac Receivers.
+	// +optional
+	PayloadSchemaRef *ConfigMapKeyReference `json:"payloadSchemaRef,omitempty"`
+
 	// Suspend tells the controller to suspend subsequent
 	// events handling for this receiver.
 	// +optional
@@ -94,6 +152,35 @@ type ReceiverStatus struct {
 	// ObservedGeneration is the last observed generation of the Receiver object.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastTriggers holds the results of the most recent reconciliation
+	// requests of the resources, newest first.
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	LastTriggers []ReceiverTrigger `json:"lastTriggers,omitempty"`
+}
+
+// ReceiverTrigger is the result of a reconciliation request of a resource
+// targeted by the Receiver.
+type ReceiverTrigger struct {
+	// Resource is the reference of the resource in the format
+	// 'Kind/namespace/name'.
+	// +required
+	Resource string `json:"resource"`
+
+	// Time of the reconciliation request.
+	// +required
+	Time metav1.Time `json:"time"`
+
+	// Result of the reconciliation request, one of Accepted, Filtered
+	// or Failed.
+	// +kubebuilder:validation:Enum=Accepted;Filtered;Failed
+	// +required
+	Result string `json:"result"`
+
+	// Message holds the error of the failed requests.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // GetConditions returns the status conditions of the object.
@@ -112,6 +199,24 @@ func (in *Receiver) GetWebhookPath(token string) string {
 	return fmt.Sprintf("%s%x", ReceiverWebhookPath, digest)
 }
 
+// GetNamespacedWebhookPath returns the incoming webhook path for the given
+// token, prefixed with the namespace of the Receiver in the format of
+// '/hook/<namespace>/sha256sum(token+name+namespace)', so that the ingress
+// controllers can apply per-tenant policies by path prefix.
+func (in *Receiver) GetNamespacedWebhookPath(token string) string {
+	digest := sha256.Sum256([]byte(token + in.GetName() + in.GetNamespace()))
+	return fmt.Sprintf("%s%s/%x", ReceiverWebhookPath, in.GetNamespace(), digest)
+}
+
+// GetTokenSecretKey returns the Secret data key holding the token
+// with a default of 'token' for this Receiver.
+func (in *Receiver) GetTokenSecretKey() string {
+	if in.Spec.SecretKey != "" {
+		return in.Spec.SecretKey
+	}
+	return DefaultTokenSecretKey
+}
+
 // GetInterval returns the interval value with a default of 10m for this Receiver.
 func (in *Receiver) GetInterval() time.Duration {
 	duration := 10 * time.Minute
@@ -122,6 +227,16 @@ func (in *Receiver) GetInterval() time.Duration {
 	return duration
 }
 
+// GetTimeout returns the timeout value with a default of 15s for this Receiver.
+func (in *Receiver) GetTimeout() time.Duration {
+	duration := 15 * time.Second
+	if in.Spec.Timeout != nil {
+		duration = in.Spec.Timeout.Duration
+	}
+
+	return duration
+}
+
 // +genclient
 // +kubebuilder:storageversion
 // +kubebuilder:object:root=true
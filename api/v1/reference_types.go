@@ -48,4 +48,89 @@ type CrossNamespaceObjectReference struct {
 	// MatchLabels requires the name to be set to `*`.
 	// +optional
 	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+
+	// InclusionList specifies a list of Golang regular expressions
+	// to be used for including the messages of the events matching
+	// this reference. Only used by the Alert event sources.
+	// +optional
+	InclusionList []string `json:"inclusionList,omitempty"`
+
+	// ExclusionList specifies a list of Golang regular expressions
+	// to be used for excluding the messages of the events matching
+	// this reference. Only used by the Alert event sources.
+	// +optional
+	ExclusionList []string `json:"exclusionList,omitempty"`
+}
+
+// ReceiverObjectReference contains enough information to let a Receiver
+// locate the referenced object at cluster level. The kind isn't restricted
+// to the Flux kinds, as the controller can allow Receivers to target
+// resources of any kind.
+type ReceiverObjectReference struct {
+	// API version of the referent
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of the referent
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Kind string `json:"kind"`
+
+	// Name of the referent
+	// If multiple resources are targeted `*` may be set.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=53
+	// +required
+	Name string `json:"name"`
+
+	// Namespace of the referent
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=53
+	// +kubebuilder:validation:Optional
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// MatchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+	// map is equivalent to an element of matchExpressions, whose key field is "key", the
+	// operator is "In", and the values array contains only "value". The requirements are ANDed.
+	// MatchLabels requires the name to be set to `*`.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+
+	// Action specifies what the Receiver does with the objects matching this
+	// reference, either 'annotate' to request their reconciliation, or 'notify'
+	// to dispatch an event for them to the Alerts without modifying them.
+	// Defaults to 'annotate'.
+	// +kubebuilder:validation:Enum=annotate;notify
+	// +optional
+	Action string `json:"action,omitempty"`
+
+	// Events scopes this reference to the given event types, e.g. 'push'
+	// for GitHub or 'Tag Push Hook' for GitLab, so that the Receiver handles
+	// its matching objects only for the webhook requests of these types.
+	// Defaults to all the event types.
+	// +optional
+	Events []string `json:"events,omitempty"`
+}
+
+// fluxKinds is the set of the Flux kinds the Receivers can target when
+// the controller doesn't allow arbitrary resources.
+var fluxKinds = map[string]bool{
+	"Bucket":                true,
+	"GitRepository":         true,
+	"Kustomization":         true,
+	"HelmRelease":           true,
+	"HelmChart":             true,
+	"HelmRepository":        true,
+	"ImageRepository":       true,
+	"ImagePolicy":           true,
+	"ImageUpdateAutomation": true,
+	"OCIRepository":         true,
+}
+
+// IsFluxKind returns true if the given kind is one of the Flux kinds
+// the Receivers can target when the controller doesn't allow arbitrary
+// resources.
+func IsFluxKind(kind string) bool {
+	return fluxKinds[kind]
 }
@@ -0,0 +1,315 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// ConvertTo converts this Alert to the Hub version (v1).
+func (src *Alert) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1.Alert)
+
+	var restored v1.AlertSpec
+	ok, err := popConversionData(&src.ObjectMeta, &dst.ObjectMeta, &restored)
+	if err != nil {
+		return err
+	}
+
+	dst.Spec = v1.AlertSpec{
+		ProviderRefs:  []meta.LocalObjectReference{src.Spec.ProviderRef},
+		EventSources:  convertEventSourcesTo(src.Spec.EventSources),
+		ExclusionList: src.Spec.ExclusionList,
+		Summary:       src.Spec.Summary,
+		Suspend:       src.Spec.Suspend,
+	}
+	if src.Spec.EventSeverity == "error" {
+		dst.Spec.EventSeverities = []string{"error"}
+	}
+
+	// Restore the fields that can't be represented in v1beta1, unless they
+	// have been changed since the conversion.
+	if ok {
+		if len(restored.ProviderRefs) > 0 && restored.ProviderRefs[0] == src.Spec.ProviderRef {
+			dst.Spec.ProviderRefs = restored.ProviderRefs
+		}
+		if alertEventSeverity(restored.EventSeverities) == src.Spec.EventSeverity {
+			dst.Spec.EventSeverities = restored.EventSeverities
+		}
+		if reflect.DeepEqual(convertEventSourcesFrom(restored.EventSources), src.Spec.EventSources) {
+			dst.Spec.EventSources = restored.EventSources
+		}
+		dst.Spec.EventReasons = restored.EventReasons
+		dst.Spec.InclusionList = restored.InclusionList
+		dst.Spec.EventMetadata = restored.EventMetadata
+		dst.Spec.ServiceAccountName = restored.ServiceAccountName
+		dst.Spec.SampleRate = restored.SampleRate
+		dst.Spec.EventMetadataFormat = restored.EventMetadataFormat
+		dst.Spec.TemplateRef = restored.TemplateRef
+		dst.Spec.RateLimitInterval = restored.RateLimitInterval
+	}
+
+	dst.Status = v1.AlertStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+func (dst *Alert) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1.Alert)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = AlertSpec{
+		EventSeverity: alertEventSeverity(src.Spec.EventSeverities),
+		EventSources:  convertEventSourcesFrom(src.Spec.EventSources),
+		ExclusionList: src.Spec.ExclusionList,
+		Summary:       src.Spec.Summary,
+		Suspend:       src.Spec.Suspend,
+	}
+	if len(src.Spec.ProviderRefs) > 0 {
+		dst.Spec.ProviderRef = src.Spec.ProviderRefs[0]
+	}
+
+	lossy := len(src.Spec.ProviderRefs) > 1 ||
+		len(src.Spec.EventReasons) > 0 ||
+		len(src.Spec.InclusionList) > 0 ||
+		len(src.Spec.EventMetadata) > 0 ||
+		src.Spec.ServiceAccountName != "" ||
+		src.Spec.SampleRate != "" ||
+		len(src.Spec.EventMetadataFormat) > 0 ||
+		src.Spec.TemplateRef != nil ||
+		src.Spec.RateLimitInterval != nil ||
+		!reflect.DeepEqual(src.Spec.EventSeverities, severitiesForEventSeverity(dst.Spec.EventSeverity)) ||
+		!reflect.DeepEqual(convertEventSourcesTo(dst.Spec.EventSources), src.Spec.EventSources)
+	if lossy {
+		if err := pushConversionData(&dst.ObjectMeta, src.Spec); err != nil {
+			return err
+		}
+	}
+
+	dst.Status = AlertStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertTo converts this Provider to the Hub version (v1).
+// The deprecated .spec.proxy field is preserved in the proxy annotation.
+func (src *Provider) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1.Provider)
+
+	var restored v1.ProviderSpec
+	ok, err := popConversionData(&src.ObjectMeta, &dst.ObjectMeta, &restored)
+	if err != nil {
+		return err
+	}
+	if src.Spec.Proxy != "" {
+		setAnnotation(&dst.ObjectMeta, v1.ProxyAnnotation, src.Spec.Proxy)
+	}
+
+	dst.Spec = v1.ProviderSpec{
+		Type:          src.Spec.Type,
+		Channel:       src.Spec.Channel,
+		Username:      src.Spec.Username,
+		Address:       src.Spec.Address,
+		Timeout:       src.Spec.Timeout,
+		SecretRef:     src.Spec.SecretRef,
+		CertSecretRef: src.Spec.CertSecretRef,
+		Suspend:       src.Spec.Suspend,
+	}
+
+	// Restore the fields that can't be represented in v1beta1.
+	if ok {
+		dst.Spec.AddressFrom = restored.AddressFrom
+		dst.Spec.ProxySecretRef = restored.ProxySecretRef
+		dst.Spec.MetadataFields = restored.MetadataFields
+		dst.Spec.Appearance = restored.Appearance
+		dst.Spec.MessageTruncation = restored.MessageTruncation
+		dst.Spec.APIBaseURL = restored.APIBaseURL
+		dst.Spec.CommitStatusKey = restored.CommitStatusKey
+		dst.Spec.ServiceAccountName = restored.ServiceAccountName
+		dst.Spec.DNS = restored.DNS
+		dst.Spec.HMAC = restored.HMAC
+		dst.Spec.GitHubDispatch = restored.GitHubDispatch
+	}
+
+	dst.Status = v1.ProviderStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+func (dst *Provider) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1.Provider)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = ProviderSpec{
+		Type:          src.Spec.Type,
+		Channel:       src.Spec.Channel,
+		Username:      src.Spec.Username,
+		Address:       src.Spec.Address,
+		Timeout:       src.Spec.Timeout,
+		Proxy:         popAnnotation(&dst.ObjectMeta, v1.ProxyAnnotation),
+		SecretRef:     src.Spec.SecretRef,
+		CertSecretRef: src.Spec.CertSecretRef,
+		Suspend:       src.Spec.Suspend,
+	}
+	if src.Spec.ProxySecretRef != nil || src.Spec.AddressFrom != nil ||
+		src.Spec.MetadataFields != nil || src.Spec.Appearance != nil ||
+		src.Spec.MessageTruncation != nil ||
+		src.Spec.APIBaseURL != "" ||
+		src.Spec.CommitStatusKey != "" || src.Spec.ServiceAccountName != "" ||
+		src.Spec.DNS != nil || src.Spec.HMAC != nil ||
+		src.Spec.GitHubDispatch != nil {
+		if err := pushConversionData(&dst.ObjectMeta, src.Spec); err != nil {
+			return err
+		}
+	}
+
+	dst.Status = ProviderStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// convertEventSourcesTo returns the v1 event sources matching the given
+// v1beta1 event sources.
+func convertEventSourcesTo(sources []CrossNamespaceObjectReference) []v1.CrossNamespaceObjectReference {
+	if sources == nil {
+		return nil
+	}
+	results := make([]v1.CrossNamespaceObjectReference, len(sources))
+	for i, source := range sources {
+		results[i] = v1.CrossNamespaceObjectReference{
+			APIVersion:  source.APIVersion,
+			Kind:        source.Kind,
+			Name:        source.Name,
+			Namespace:   source.Namespace,
+			MatchLabels: source.MatchLabels,
+		}
+	}
+	return results
+}
+
+// convertEventSourcesFrom returns the v1beta1 event sources matching the
+// given v1 event sources, without their inclusion and exclusion lists.
+func convertEventSourcesFrom(sources []v1.CrossNamespaceObjectReference) []CrossNamespaceObjectReference {
+	if sources == nil {
+		return nil
+	}
+	results := make([]CrossNamespaceObjectReference, len(sources))
+	for i, source := range sources {
+		results[i] = CrossNamespaceObjectReference{
+			APIVersion:  source.APIVersion,
+			Kind:        source.Kind,
+			Name:        source.Name,
+			Namespace:   source.Namespace,
+			MatchLabels: source.MatchLabels,
+		}
+	}
+	return results
+}
+
+// alertEventSeverity returns the v1beta1 event severity matching the given
+// v1 event severities.
+func alertEventSeverity(severities []string) string {
+	if len(severities) == 1 && severities[0] == "error" {
+		return "error"
+	}
+	return "info"
+}
+
+// severitiesForEventSeverity returns the v1 event severities matching the
+// given v1beta1 event severity.
+func severitiesForEventSeverity(severity string) []string {
+	if severity == "error" {
+		return []string{"error"}
+	}
+	return nil
+}
+
+// pushConversionData stores the given v1 spec in the conversion data
+// annotation of the object.
+func pushConversionData(obj *metav1.ObjectMeta, spec any) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversion data: %w", err)
+	}
+	setAnnotation(obj, v1.ConversionDataAnnotation, string(data))
+	return nil
+}
+
+// popConversionData copies the src object metadata to dst without the
+// conversion data annotation, and decodes the annotation into spec.
+// It returns false if the annotation isn't set.
+func popConversionData(src, dst *metav1.ObjectMeta, spec any) (bool, error) {
+	*dst = *src
+	data, ok := src.Annotations[v1.ConversionDataAnnotation]
+	if !ok {
+		return false, nil
+	}
+	popAnnotation(dst, v1.ConversionDataAnnotation)
+	if err := json.Unmarshal([]byte(data), spec); err != nil {
+		return false, fmt.Errorf("failed to unmarshal conversion data: %w", err)
+	}
+	return true, nil
+}
+
+// setAnnotation sets the given annotation on a copy of the annotations of
+// the object, which may be shared with the object it was converted from.
+func setAnnotation(obj *metav1.ObjectMeta, key, value string) {
+	annotations := make(map[string]string, len(obj.Annotations)+1)
+	for k, v := range obj.Annotations {
+		annotations[k] = v
+	}
+	annotations[key] = value
+	obj.Annotations = annotations
+}
+
+// popAnnotation removes the given annotation from a copy of the annotations
+// of the object, and returns its value.
+func popAnnotation(obj *metav1.ObjectMeta, key string) string {
+	value, ok := obj.Annotations[key]
+	if !ok {
+		return ""
+	}
+	annotations := make(map[string]string, len(obj.Annotations))
+	for k, v := range obj.Annotations {
+		if k != key {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.Annotations = annotations
+	return value
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+const (
+	// ProviderResolvedCondition indicates whether the Provider referenced
+	// by the Alert exists.
+	ProviderResolvedCondition string = "ProviderResolved"
+
+	// MessageFiltersValidCondition indicates whether the inclusion and
+	// exclusion lists of the Alert are valid regular expressions.
+	MessageFiltersValidCondition string = "MessageFiltersValid"
+
+	// EventSourcesValidCondition indicates whether the kinds of the Alert
+	// event sources are served by the cluster.
+	EventSourcesValidCondition string = "EventSourcesValid"
+)
+
+const (
+	// ProviderNotFoundReason represents the fact that the Provider
+	// referenced by the Alert can't be found.
+	ProviderNotFoundReason string = "ProviderNotFound"
+
+	// InvalidRegexReason represents the fact that a regular expression of
+	// the Alert can't be compiled.
+	InvalidRegexReason string = "InvalidRegex"
+
+	// UnknownKindReason represents the fact that the kind of an event
+	// source isn't served by the cluster.
+	UnknownKindReason string = "UnknownKind"
+)
@@ -89,6 +89,13 @@ type ProviderSpec struct {
 	// +optional
 	Address string `json:"address,omitempty"`
 
+	// AddressFrom specifies a source to read the address from, for addresses
+	// that are managed centrally and don't need to be stored in a Secret.
+	// It takes precedence over .spec.address, while an address read from
+	// the Secret referenced in .spec.secretRef takes precedence over it.
+	// +optional
+	AddressFrom *AddressSource `json:"addressFrom,omitempty"`
+
 	// Timeout for sending alerts to the Provider.
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m))+$"
@@ -121,8 +128,27 @@ type ProviderSpec struct {
 	Suspend bool `json:"suspend,omitempty"`
 }
 
+// AddressSource specifies a source for the address of a Provider.
+type AddressSource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap in the same namespace
+	// as the Provider.
+	// +required
+	ConfigMapKeyRef ConfigMapKeyReference `json:"configMapKeyRef"`
+}
+
+// ConfigMapKeyReference contains enough information to locate a key
+// of a ConfigMap in the same namespace as the referring object.
+type ConfigMapKeyReference struct {
+	// Name of the ConfigMap.
+	// +required
+	Name string `json:"name"`
+
+	// Key in the ConfigMap.
+	// +required
+	Key string `json:"key"`
+}
+
 // +genclient
-// +kubebuilder:storageversion
 // +kubebuilder:object:root=true
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package alertmetrics records the per-Alert metrics, shared by the event
+// server counting the matched events and the sent notifications, and the
+// Alert reconciler managing the series of the Alerts.
+package alertmetrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// alertMetricsMaxSeries is the maximum number of Alerts with their own
+// series in the per-Alert metrics. The activity of the other Alerts is
+// counted under the alertMetricsOverflow labels.
+const alertMetricsMaxSeries = 1000
+
+// alertMetricsOverflow is the namespace and name label value of the series
+// counting the Alerts exceeding alertMetricsMaxSeries.
+const alertMetricsOverflow = "_other"
+
+var alertEventsMatched = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gotk_alert_events_matched_total",
+		Help: "The number of events matched by an Alert.",
+	},
+	[]string{"namespace", "name"},
+)
+
+var alertNotificationsSent = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gotk_alert_notifications_sent_total",
+		Help: "The number of notifications successfully sent to the Providers of an Alert.",
+	},
+	[]string{"namespace", "name"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(alertEventsMatched, alertNotificationsSent)
+}
+
+// alertSeries tracks the Alerts with their own series in the per-Alert
+// metrics, to bound their cardinality.
+var alertSeries = &alertMetricsSeries{alerts: make(map[types.NamespacedName]struct{})}
+
+type alertMetricsSeries struct {
+	mu     sync.Mutex
+	alerts map[types.NamespacedName]struct{}
+}
+
+// labels returns the label values of the series of the given Alert,
+// which are the overflow ones once the maximum number of series is reached.
+func (s *alertMetricsSeries) labels(namespace, name string) []string {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.alerts[key]; !ok {
+		if len(s.alerts) >= alertMetricsMaxSeries {
+			return []string{alertMetricsOverflow, alertMetricsOverflow}
+		}
+		s.alerts[key] = struct{}{}
+	}
+	return []string{namespace, name}
+}
+
+// forget removes the given Alert, returning if it had its own series.
+func (s *alertMetricsSeries) forget(namespace, name string) bool {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.alerts[key]
+	delete(s.alerts, key)
+	return ok
+}
+
+// RecordMatched counts an event matched by the given Alert.
+func RecordMatched(namespace, name string) {
+	alertEventsMatched.WithLabelValues(alertSeries.labels(namespace, name)...).Inc()
+}
+
+// RecordNotificationSent counts a notification sent for the given Alert.
+func RecordNotificationSent(namespace, name string) {
+	alertNotificationsSent.WithLabelValues(alertSeries.labels(namespace, name)...).Inc()
+}
+
+// Init creates the series of the given Alert with a zero value,
+// so that the Alerts that never match any event can be told apart.
+func Init(namespace, name string) {
+	labels := alertSeries.labels(namespace, name)
+	alertEventsMatched.WithLabelValues(labels...)
+	alertNotificationsSent.WithLabelValues(labels...)
+}
+
+// Delete deletes the series of the given Alert from the
+// per-Alert metrics, it's called when the Alert is deleted.
+func Delete(namespace, name string) {
+	if !alertSeries.forget(namespace, name) {
+		return
+	}
+	alertEventsMatched.DeleteLabelValues(namespace, name)
+	alertNotificationsSent.DeleteLabelValues(namespace, name)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertmetrics
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestAlertMetrics(t *testing.T) {
+	g := NewWithT(t)
+
+	Init("metrics", "alert")
+	g.Expect(testutil.ToFloat64(alertEventsMatched.WithLabelValues("metrics", "alert"))).To(Equal(float64(0)))
+
+	RecordMatched("metrics", "alert")
+	RecordMatched("metrics", "alert")
+	RecordNotificationSent("metrics", "alert")
+	g.Expect(testutil.ToFloat64(alertEventsMatched.WithLabelValues("metrics", "alert"))).To(Equal(float64(2)))
+	g.Expect(testutil.ToFloat64(alertNotificationsSent.WithLabelValues("metrics", "alert"))).To(Equal(float64(1)))
+
+	Delete("metrics", "alert")
+	g.Expect(testutil.ToFloat64(alertEventsMatched.WithLabelValues("metrics", "alert"))).To(Equal(float64(0)))
+}
+
+func TestAlertMetricsSeries_Overflow(t *testing.T) {
+	g := NewWithT(t)
+
+	s := &alertMetricsSeries{alerts: make(map[types.NamespacedName]struct{})}
+	for i := range alertMetricsMaxSeries {
+		g.Expect(s.labels("default", fmt.Sprintf("alert-%d", i))).To(Equal([]string{"default", fmt.Sprintf("alert-%d", i)}))
+	}
+	g.Expect(s.labels("default", "extra")).To(Equal([]string{alertMetricsOverflow, alertMetricsOverflow}))
+	// The Alerts already tracked keep their series.
+	g.Expect(s.labels("default", "alert-0")).To(Equal([]string{"default", "alert-0"}))
+
+	// Forgetting an Alert makes room for another one.
+	g.Expect(s.forget("default", "alert-0")).To(BeTrue())
+	g.Expect(s.forget("default", "extra")).To(BeFalse())
+	g.Expect(s.labels("default", "extra")).To(Equal([]string{"default", "extra"}))
+}
@@ -18,57 +18,156 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	kuberecorder "k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	apiv1 "github.com/fluxcd/notification-controller/api/v1"
-	apiv1beta3 "github.com/fluxcd/notification-controller/api/v1beta3"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	helper "github.com/fluxcd/pkg/runtime/controller"
 	"github.com/fluxcd/pkg/runtime/patch"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+	"github.com/fluxcd/notification-controller/internal/alertmetrics"
 )
 
 // +kubebuilder:rbac:groups=notification.toolkit.fluxcd.io,resources=alerts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=notification.toolkit.fluxcd.io,resources=alerts/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-// AlertReconciler reconciles an Alert object to migrate it to static Alert.
+// alertProviderRefIndexKey is the index of the Alerts by the name of their
+// Provider.
+const alertProviderRefIndexKey = ".spec.providerRef.name"
+
+// eventSourceGroups maps the kinds of the event sources to their API group,
+// used when the event source doesn't specify an API version.
+var eventSourceGroups = map[string]string{
+	"Bucket":                "source.toolkit.fluxcd.io",
+	"GitRepository":         "source.toolkit.fluxcd.io",
+	"HelmChart":             "source.toolkit.fluxcd.io",
+	"HelmRepository":        "source.toolkit.fluxcd.io",
+	"OCIRepository":         "source.toolkit.fluxcd.io",
+	"Kustomization":         "kustomize.toolkit.fluxcd.io",
+	"HelmRelease":           "helm.toolkit.fluxcd.io",
+	"ImageRepository":       "image.toolkit.fluxcd.io",
+	"ImagePolicy":           "image.toolkit.fluxcd.io",
+	"ImageUpdateAutomation": "image.toolkit.fluxcd.io",
+}
+
+// AlertReconciler reconciles an Alert object to migrate it to static Alert
+// and to validate its spec.
 type AlertReconciler struct {
 	client.Client
+	helper.Metrics
 	kuberecorder.EventRecorder
 
 	ControllerName string
+
+	suspends suspendTracker
 }
 
 func (r *AlertReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// This index is used to list the Alerts referencing a Provider when the
+	// Provider is created or deleted.
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1.Alert{},
+		alertProviderRefIndexKey, func(o client.Object) []string {
+			alert := o.(*apiv1.Alert)
+			names := make([]string, 0, len(alert.Spec.ProviderRefs))
+			for _, ref := range alert.Spec.ProviderRefs {
+				names = append(names, ref.Name)
+			}
+			return names
+		}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&apiv1beta3.Alert{}, builder.WithPredicates(finalizerPredicate{})).
+		For(&apiv1.Alert{}, builder.WithPredicates(
+			predicate.Or(finalizerPredicate{}, predicate.GenerationChangedPredicate{}),
+		)).
+		Watches(
+			&apiv1.Provider{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProviderChange),
+			builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(event.UpdateEvent) bool { return false },
+			}),
+		).
 		Complete(r)
 }
 
 func (r *AlertReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, retErr error) {
 	log := ctrl.LoggerFrom(ctx)
 
-	obj := &apiv1beta3.Alert{}
+	obj := &apiv1.Alert{}
 	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		// Alerts have no finalizer, forget the suspend status and the
+		// metrics of the deleted Alerts here.
+		if apierrors.IsNotFound(err) {
+			r.suspends.forget(req.NamespacedName)
+			alertmetrics.Delete(req.Namespace, req.Name)
+			if r.MetricsRecorder != nil {
+				r.MetricsRecorder.DeleteSuspend(corev1.ObjectReference{
+					Kind:      apiv1.AlertKind,
+					Name:      req.Name,
+					Namespace: req.Namespace,
+				})
+			}
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// Early return if no migration is needed.
-	if !controllerutil.ContainsFinalizer(obj, apiv1.NotificationFinalizer) {
-		return ctrl.Result{}, nil
+	r.RecordSuspend(ctx, obj, obj.Spec.Suspend)
+	alertmetrics.Init(obj.Namespace, obj.Name)
+	r.suspends.observe(r.EventRecorder, obj, apiv1.AlertKind, obj.Spec.Suspend)
+
+	if controllerutil.ContainsFinalizer(obj, apiv1.NotificationFinalizer) {
+		return r.migrate(ctx, obj)
 	}
 
-	// Examine if the object is under deletion.
-	var delete bool
+	// Skip if it's being deleted or suspended.
 	if !obj.ObjectMeta.DeletionTimestamp.IsZero() {
-		delete = true
+		return ctrl.Result{}, nil
+	}
+	if obj.Spec.Suspend {
+		log.Info("reconciliation is suspended for this object")
+		return ctrl.Result{}, nil
 	}
 
+	// Initialize the runtime patcher with the current version of the object.
+	patcher := patch.NewSerialPatcher(obj, r.Client)
+
+	defer func() {
+		if err := r.patch(ctx, obj, patcher); err != nil {
+			retErr = kerrors.NewAggregate([]error{retErr, err})
+		}
+	}()
+
+	return ctrl.Result{}, r.validate(ctx, obj)
+}
+
+// migrate removes the notification-controller finalizer from the object.
+func (r *AlertReconciler) migrate(ctx context.Context, obj *apiv1.Alert) (result ctrl.Result, retErr error) {
+	log := ctrl.LoggerFrom(ctx)
+
 	// Skip if it's suspend and not being deleted.
-	if obj.Spec.Suspend && !delete {
+	if obj.Spec.Suspend && obj.ObjectMeta.DeletionTimestamp.IsZero() {
 		log.Info("reconciliation is suspended for this object")
 		return ctrl.Result{}, nil
 	}
@@ -92,3 +191,157 @@ func (r *AlertReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resu
 
 	return
 }
+
+// validate verifies that the Providers referenced by the Alert exist, that
+// the inclusion and exclusion lists compile, and that the kinds of the event
+// sources are served by the cluster. The outcome of each check is recorded in
+// a discrete condition, and summarized in the Ready condition.
+func (r *AlertReconciler) validate(ctx context.Context, obj *apiv1.Alert) error {
+	var failed []string
+
+	missing, err := r.missingProviders(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		conditions.MarkFalse(obj, apiv1.ProviderResolvedCondition, apiv1.ProviderNotFoundReason,
+			"providers not found: %s", strings.Join(missing, ", "))
+		failed = append(failed, apiv1.ProviderResolvedCondition)
+	} else {
+		conditions.MarkTrue(obj, apiv1.ProviderResolvedCondition, meta.SucceededReason,
+			"providers found")
+	}
+
+	if errs := validateMessageFilters(obj); len(errs) > 0 {
+		conditions.MarkFalse(obj, apiv1.MessageFiltersValidCondition, apiv1.InvalidRegexReason,
+			"%s", errors.Join(errs...))
+		failed = append(failed, apiv1.MessageFiltersValidCondition)
+	} else {
+		conditions.MarkTrue(obj, apiv1.MessageFiltersValidCondition, meta.SucceededReason,
+			"inclusion and exclusion lists are valid")
+	}
+
+	unknown, err := r.unknownEventSourceKinds(obj)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		conditions.MarkFalse(obj, apiv1.EventSourcesValidCondition, apiv1.UnknownKindReason,
+			"kinds not served by the cluster: %s", strings.Join(unknown, ", "))
+		failed = append(failed, apiv1.EventSourcesValidCondition)
+	} else {
+		conditions.MarkTrue(obj, apiv1.EventSourcesValidCondition, meta.SucceededReason,
+			"event sources are valid")
+	}
+
+	if len(failed) > 0 {
+		msg := fmt.Sprintf("validation failed: %s", strings.Join(failed, ", "))
+		conditions.MarkFalse(obj, meta.ReadyCondition, apiv1.ValidationFailedReason, "%s", msg)
+		r.Event(obj, corev1.EventTypeWarning, apiv1.ValidationFailedReason, msg)
+		return nil
+	}
+
+	conditions.MarkTrue(obj, meta.ReadyCondition, meta.SucceededReason, "Alert is valid")
+	return nil
+}
+
+// missingProviders returns the names of the Providers referenced by the Alert
+// that can't be found.
+func (r *AlertReconciler) missingProviders(ctx context.Context, obj *apiv1.Alert) ([]string, error) {
+	var missing []string
+	for _, ref := range obj.Spec.ProviderRefs {
+		provider := &apiv1.Provider{}
+		providerName := types.NamespacedName{Namespace: obj.GetNamespace(), Name: ref.Name}
+		if err := r.Get(ctx, providerName, provider); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get provider '%s': %w", providerName, err)
+			}
+			missing = append(missing, ref.Name)
+		}
+	}
+	return missing, nil
+}
+
+// validateMessageFilters returns the compile errors of the inclusion and
+// exclusion regular expressions of the Alert.
+func validateMessageFilters(obj *apiv1.Alert) []error {
+	var errs []error
+	for _, exp := range obj.Spec.InclusionList {
+		if _, err := regexp.Compile(exp); err != nil {
+			errs = append(errs, fmt.Errorf("invalid inclusion regex '%s': %w", exp, err))
+		}
+	}
+	for _, exp := range obj.Spec.ExclusionList {
+		if _, err := regexp.Compile(exp); err != nil {
+			errs = append(errs, fmt.Errorf("invalid exclusion regex '%s': %w", exp, err))
+		}
+	}
+	return errs
+}
+
+// unknownEventSourceKinds returns the kinds of the event sources that are not
+// served by the cluster.
+func (r *AlertReconciler) unknownEventSourceKinds(obj *apiv1.Alert) ([]string, error) {
+	var unknown []string
+	seen := make(map[schema.GroupKind]bool)
+	for _, source := range obj.Spec.EventSources {
+		gk := schema.GroupKind{Group: eventSourceGroups[source.Kind], Kind: source.Kind}
+		if source.APIVersion != "" {
+			gv, err := schema.ParseGroupVersion(source.APIVersion)
+			if err != nil {
+				unknown = append(unknown, fmt.Sprintf("%s/%s", source.APIVersion, source.Kind))
+				continue
+			}
+			gk.Group = gv.Group
+		}
+		if seen[gk] {
+			continue
+		}
+		seen[gk] = true
+
+		if _, err := r.RESTMapper().RESTMapping(gk); err != nil {
+			if !apimeta.IsNoMatchError(err) {
+				return nil, fmt.Errorf("failed to look up kind '%s': %w", gk, err)
+			}
+			unknown = append(unknown, gk.String())
+		}
+	}
+	return unknown, nil
+}
+
+// patch updates the object status and conditions.
+func (r *AlertReconciler) patch(ctx context.Context, obj *apiv1.Alert, patcher *patch.SerialPatcher) error {
+	ownedConditions := []string{
+		meta.ReadyCondition,
+		apiv1.ProviderResolvedCondition,
+		apiv1.MessageFiltersValidCondition,
+		apiv1.EventSourcesValidCondition,
+	}
+	patchOpts := []patch.Option{
+		patch.WithOwnedConditions{Conditions: ownedConditions},
+		patch.WithForceOverwriteConditions{},
+		patch.WithFieldOwner(r.ControllerName),
+	}
+
+	if conditions.Has(obj, meta.ReadyCondition) {
+		obj.Status.ObservedGeneration = obj.Generation
+	}
+
+	return patcher.Patch(ctx, obj, patchOpts...)
+}
+
+// requestsForProviderChange enqueues the Alerts referencing the Provider.
+func (r *AlertReconciler) requestsForProviderChange(ctx context.Context, o client.Object) []reconcile.Request {
+	var list apiv1.AlertList
+	if err := r.List(ctx, &list, client.InNamespace(o.GetNamespace()),
+		client.MatchingFields{alertProviderRefIndexKey: o.GetName()}); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list Alerts for Provider change")
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+	}
+	return reqs
+}
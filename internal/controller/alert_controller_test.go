@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
 	"github.com/fluxcd/pkg/runtime/patch"
 	. "github.com/onsi/gomega"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -30,7 +31,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	apiv1 "github.com/fluxcd/notification-controller/api/v1"
-	apiv1beta3 "github.com/fluxcd/notification-controller/api/v1beta3"
 )
 
 func TestAlertReconciler(t *testing.T) {
@@ -45,7 +45,7 @@ func TestAlertReconciler(t *testing.T) {
 		g.Expect(testEnv.Cleanup(ctx, testns)).ToNot(HaveOccurred())
 	})
 
-	alert := &apiv1beta3.Alert{
+	alert := &apiv1.Alert{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("alert-%s", randStringRunes(5)),
 			Namespace: testns.Name,
@@ -56,8 +56,8 @@ func TestAlertReconciler(t *testing.T) {
 	// Remove finalizer at create.
 
 	alert.ObjectMeta.Finalizers = append(alert.ObjectMeta.Finalizers, "foo.bar", apiv1.NotificationFinalizer)
-	alert.Spec = apiv1beta3.AlertSpec{
-		ProviderRef:  meta.LocalObjectReference{Name: "foo-provider"},
+	alert.Spec = apiv1.AlertSpec{
+		ProviderRefs: []meta.LocalObjectReference{{Name: "foo-provider"}},
 		EventSources: []apiv1.CrossNamespaceObjectReference{},
 	}
 	g.Expect(testEnv.Create(ctx, alert)).ToNot(HaveOccurred())
@@ -119,3 +119,77 @@ func TestAlertReconciler(t *testing.T) {
 		return false
 	}, timeout).Should(BeTrue())
 }
+
+func TestAlertReconciler_validation(t *testing.T) {
+	g := NewWithT(t)
+
+	timeout := 10 * time.Second
+
+	testns, err := testEnv.CreateNamespace(ctx, "alert-test")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	t.Cleanup(func() {
+		g.Expect(testEnv.Cleanup(ctx, testns)).ToNot(HaveOccurred())
+	})
+
+	alert := &apiv1.Alert{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("alert-%s", randStringRunes(5)),
+			Namespace: testns.Name,
+		},
+		Spec: apiv1.AlertSpec{
+			ProviderRefs:  []meta.LocalObjectReference{{Name: "validation-provider"}},
+			InclusionList: []string{".*succeeded.*", "(invalid"},
+			EventSources: []apiv1.CrossNamespaceObjectReference{
+				// The Flux source CRDs are not installed in the test environment.
+				{Kind: "GitRepository", Name: "*"},
+			},
+		},
+	}
+	alertKey := client.ObjectKeyFromObject(alert)
+	g.Expect(testEnv.Create(ctx, alert)).ToNot(HaveOccurred())
+
+	g.Eventually(func() bool {
+		_ = testEnv.Get(ctx, alertKey, alert)
+		return conditions.IsFalse(alert, meta.ReadyCondition) &&
+			alert.Status.ObservedGeneration == alert.Generation
+	}, timeout, time.Second).Should(BeTrue())
+	g.Expect(conditions.GetReason(alert, meta.ReadyCondition)).To(Equal(apiv1.ValidationFailedReason))
+	g.Expect(conditions.GetReason(alert, apiv1.ProviderResolvedCondition)).To(Equal(apiv1.ProviderNotFoundReason))
+	g.Expect(conditions.GetReason(alert, apiv1.MessageFiltersValidCondition)).To(Equal(apiv1.InvalidRegexReason))
+	g.Expect(conditions.GetMessage(alert, apiv1.MessageFiltersValidCondition)).To(ContainSubstring("(invalid"))
+	g.Expect(conditions.GetReason(alert, apiv1.EventSourcesValidCondition)).To(Equal(apiv1.UnknownKindReason))
+	g.Expect(conditions.GetMessage(alert, apiv1.EventSourcesValidCondition)).To(ContainSubstring("GitRepository.source.toolkit.fluxcd.io"))
+
+	// Resolve the Provider at creation.
+
+	provider := &apiv1.Provider{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "validation-provider",
+			Namespace: testns.Name,
+		},
+		Spec: apiv1.ProviderSpec{
+			Type: "generic",
+		},
+	}
+	g.Expect(testEnv.Create(ctx, provider)).ToNot(HaveOccurred())
+
+	g.Eventually(func() bool {
+		_ = testEnv.Get(ctx, alertKey, alert)
+		return conditions.IsTrue(alert, apiv1.ProviderResolvedCondition)
+	}, timeout, time.Second).Should(BeTrue())
+
+	// Validate the fixed inclusion list.
+
+	patchHelper, err := patch.NewHelper(alert, testEnv.Client)
+	g.Expect(err).ToNot(HaveOccurred())
+	alert.Spec.InclusionList = []string{".*succeeded.*"}
+	g.Expect(patchHelper.Patch(ctx, alert)).ToNot(HaveOccurred())
+
+	g.Eventually(func() bool {
+		_ = testEnv.Get(ctx, alertKey, alert)
+		return conditions.IsTrue(alert, apiv1.MessageFiltersValidCondition) &&
+			alert.Status.ObservedGeneration == alert.Generation
+	}, timeout, time.Second).Should(BeTrue())
+	g.Expect(conditions.IsFalse(alert, apiv1.EventSourcesValidCondition)).To(BeTrue())
+}
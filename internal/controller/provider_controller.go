@@ -18,59 +18,140 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	kuberecorder "k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
-	apiv1 "github.com/fluxcd/notification-controller/api/v1"
-	apiv1beta3 "github.com/fluxcd/notification-controller/api/v1beta3"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	helper "github.com/fluxcd/pkg/runtime/controller"
 	"github.com/fluxcd/pkg/runtime/patch"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+	"github.com/fluxcd/notification-controller/internal/notifier"
 )
 
 // +kubebuilder:rbac:groups=notification.toolkit.fluxcd.io,resources=providers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=notification.toolkit.fluxcd.io,resources=providers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
+// providerPreflightRetryInterval is the interval at which the credentials of
+// a Provider are verified again after a failed preflight, so that a fixed
+// Secret is picked up without changing the Provider.
+const providerPreflightRetryInterval = 5 * time.Minute
+
+// providerWarmUpFailedReason is the reason of the events emitted when the
+// endpoint of a Provider can't be warmed up.
+const providerWarmUpFailedReason = "WarmUpFailed"
+
+// preflightProviderTypes lists the types of the Providers whose credentials
+// are verified at reconcile time.
+var preflightProviderTypes = map[string]bool{
+	apiv1.GitHubProvider: true,
+}
+
 // ProviderReconciler reconciles a Provider object to migrate it to static
-// Provider.
+// Provider, to validate the features it configures against the capabilities
+// of its type and to verify its credentials.
 type ProviderReconciler struct {
 	client.Client
+	helper.Metrics
 	kuberecorder.EventRecorder
 
 	ControllerName string
+	NoProxy        []string
+
+	// WarmUp enables the resolution of the Provider endpoints, and the
+	// connection to them, when the Providers become ready.
+	WarmUp bool
+
+	suspends suspendTracker
 }
 
 func (r *ProviderReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&apiv1beta3.Provider{}, builder.WithPredicates(finalizerPredicate{})).
+		For(&apiv1.Provider{}, builder.WithPredicates(
+			predicate.Or(finalizerPredicate{}, predicate.GenerationChangedPredicate{}),
+		)).
 		Complete(r)
 }
 
 func (r *ProviderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, retErr error) {
 	log := ctrl.LoggerFrom(ctx)
 
-	obj := &apiv1beta3.Provider{}
+	obj := &apiv1.Provider{}
 	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		// Providers have no finalizer, forget the suspend status of the
+		// deleted Providers here.
+		if apierrors.IsNotFound(err) {
+			r.suspends.forget(req.NamespacedName)
+			if r.MetricsRecorder != nil {
+				r.MetricsRecorder.DeleteSuspend(corev1.ObjectReference{
+					Kind:      apiv1.ProviderKind,
+					Name:      req.Name,
+					Namespace: req.Namespace,
+				})
+			}
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// Early return if no migration is needed.
-	if !controllerutil.ContainsFinalizer(obj, apiv1.NotificationFinalizer) {
-		return ctrl.Result{}, nil
+	r.RecordSuspend(ctx, obj, obj.Spec.Suspend)
+	r.suspends.observe(r.EventRecorder, obj, apiv1.ProviderKind, obj.Spec.Suspend)
+
+	if controllerutil.ContainsFinalizer(obj, apiv1.NotificationFinalizer) {
+		return r.migrate(ctx, obj)
 	}
 
-	// Examine if the object is under deletion.
-	var delete bool
+	// Skip if it's being deleted or suspended.
 	if !obj.ObjectMeta.DeletionTimestamp.IsZero() {
-		delete = true
+		return ctrl.Result{}, nil
 	}
+	if obj.Spec.Suspend {
+		log.Info("reconciliation is suspended for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the runtime patcher with the current version of the object.
+	patcher := patch.NewSerialPatcher(obj, r.Client)
+
+	defer func() {
+		if err := r.patch(ctx, obj, patcher); err != nil {
+			retErr = kerrors.NewAggregate([]error{retErr, err})
+		}
+	}()
+
+	obj.Status.Capabilities = notifier.Capabilities(obj.Spec.Type).List()
+	if err := notifier.ValidateCapabilities(obj.Spec); err != nil {
+		conditions.Delete(obj, apiv1.CredentialsValidCondition)
+		conditions.MarkFalse(obj, meta.ReadyCondition, apiv1.ValidationFailedReason, "%s", err)
+		r.Event(obj, corev1.EventTypeWarning, apiv1.ValidationFailedReason, err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	result, err := r.preflight(ctx, obj)
+	if err == nil && r.WarmUp && conditions.IsTrue(obj, meta.ReadyCondition) {
+		r.warmUp(ctx, obj.DeepCopy())
+	}
+	return result, err
+}
+
+// migrate removes the notification-controller finalizer from the object.
+func (r *ProviderReconciler) migrate(ctx context.Context, obj *apiv1.Provider) (result ctrl.Result, retErr error) {
+	log := ctrl.LoggerFrom(ctx)
 
 	// Skip if it's suspend and not being deleted.
-	if obj.Spec.Suspend && !delete {
+	if obj.Spec.Suspend && obj.ObjectMeta.DeletionTimestamp.IsZero() {
 		log.Info("reconciliation is suspended for this object")
 		return ctrl.Result{}, nil
 	}
@@ -94,3 +175,89 @@ func (r *ProviderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 
 	return
 }
+
+// preflight verifies that the credentials of the Provider can be used to post
+// notifications to the target, for the Provider types supporting it.
+// Errors other than insufficient permissions, e.g. network errors, are
+// returned to retry the reconciliation with backoff.
+func (r *ProviderReconciler) preflight(ctx context.Context, obj *apiv1.Provider) (ctrl.Result, error) {
+	if !preflightProviderTypes[obj.Spec.Type] {
+		conditions.Delete(obj, apiv1.CredentialsValidCondition)
+		conditions.MarkTrue(obj, meta.ReadyCondition, meta.SucceededReason, "Provider is valid")
+		return ctrl.Result{}, nil
+	}
+
+	sender, _, err := notifier.NewForProvider(ctx, r.Client, r.Client, *obj, r.NoProxy)
+	if err != nil {
+		r.markCredentialsInvalid(obj, apiv1.ValidationFailedReason, err)
+		return ctrl.Result{RequeueAfter: providerPreflightRetryInterval}, nil
+	}
+
+	checker, ok := sender.(notifier.Preflighter)
+	if !ok {
+		conditions.Delete(obj, apiv1.CredentialsValidCondition)
+		conditions.MarkTrue(obj, meta.ReadyCondition, meta.SucceededReason, "Provider is valid")
+		return ctrl.Result{}, nil
+	}
+
+	preflightCtx := notifier.WithResolver(notifier.WithProvider(ctx, obj.Spec.Type), notifier.ProviderResolver(obj))
+	preflightCtx, cancel := context.WithTimeout(preflightCtx, obj.GetTimeout())
+	defer cancel()
+	if err := checker.Preflight(preflightCtx); err != nil {
+		if !errors.Is(err, notifier.ErrInsufficientPermissions) {
+			return ctrl.Result{}, err
+		}
+		r.markCredentialsInvalid(obj, apiv1.InsufficientPermissionsReason, err)
+		return ctrl.Result{RequeueAfter: providerPreflightRetryInterval}, nil
+	}
+
+	conditions.MarkTrue(obj, apiv1.CredentialsValidCondition, meta.SucceededReason,
+		"credentials have the required permissions")
+	conditions.MarkTrue(obj, meta.ReadyCondition, meta.SucceededReason, "Provider is valid")
+	return ctrl.Result{}, nil
+}
+
+// warmUp resolves the hostname of the Provider endpoint and connects to it
+// in the background, without sending a notification. The failures are
+// reported with a warning event, so that the misconfigured endpoints are
+// discovered before the first alert.
+func (r *ProviderReconciler) warmUp(ctx context.Context, obj *apiv1.Provider) {
+	log := ctrl.LoggerFrom(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), obj.GetTimeout())
+		defer cancel()
+		if err := notifier.WarmUpProvider(ctx, r.Client, *obj, r.NoProxy); err != nil {
+			log.Error(err, "failed to warm up the Provider endpoint")
+			r.Event(obj, corev1.EventTypeWarning, providerWarmUpFailedReason, err.Error())
+			return
+		}
+		log.V(1).Info("warmed up the Provider endpoint")
+	}()
+}
+
+// markCredentialsInvalid records the preflight error in the CredentialsValid
+// and Ready conditions, and emits a warning event.
+func (r *ProviderReconciler) markCredentialsInvalid(obj *apiv1.Provider, reason string, err error) {
+	conditions.MarkFalse(obj, apiv1.CredentialsValidCondition, reason, "%s", err)
+	conditions.MarkFalse(obj, meta.ReadyCondition, reason, "%s", err)
+	r.Event(obj, corev1.EventTypeWarning, reason, err.Error())
+}
+
+// patch updates the object status and conditions.
+func (r *ProviderReconciler) patch(ctx context.Context, obj *apiv1.Provider, patcher *patch.SerialPatcher) error {
+	ownedConditions := []string{
+		meta.ReadyCondition,
+		apiv1.CredentialsValidCondition,
+	}
+	patchOpts := []patch.Option{
+		patch.WithOwnedConditions{Conditions: ownedConditions},
+		patch.WithForceOverwriteConditions{},
+		patch.WithFieldOwner(r.ControllerName),
+	}
+
+	if conditions.Has(obj, meta.ReadyCondition) {
+		obj.Status.ObservedGeneration = obj.Generation
+	}
+
+	return patcher.Patch(ctx, obj, patchOpts...)
+}
@@ -18,18 +18,22 @@ package controller
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
 	"github.com/fluxcd/pkg/runtime/patch"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	apiv1 "github.com/fluxcd/notification-controller/api/v1"
-	apiv1beta3 "github.com/fluxcd/notification-controller/api/v1beta3"
 )
 
 func TestProviderReconciler(t *testing.T) {
@@ -44,7 +48,7 @@ func TestProviderReconciler(t *testing.T) {
 		g.Expect(testEnv.Cleanup(ctx, testns)).ToNot(HaveOccurred())
 	})
 
-	provider := &apiv1beta3.Provider{
+	provider := &apiv1.Provider{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("provider-%s", randStringRunes(5)),
 			Namespace: testns.Name,
@@ -55,7 +59,7 @@ func TestProviderReconciler(t *testing.T) {
 	// Remove finalizer at create.
 
 	provider.ObjectMeta.Finalizers = append(provider.ObjectMeta.Finalizers, "foo.bar", apiv1.NotificationFinalizer)
-	provider.Spec = apiv1beta3.ProviderSpec{
+	provider.Spec = apiv1.ProviderSpec{
 		Type: "slack",
 	}
 	g.Expect(testEnv.Create(ctx, provider)).ToNot(HaveOccurred())
@@ -117,3 +121,121 @@ func TestProviderReconciler(t *testing.T) {
 		return false
 	}, timeout).Should(BeTrue())
 }
+
+func TestProviderReconciler_preflight(t *testing.T) {
+	g := NewWithT(t)
+
+	timeout := 10 * time.Second
+
+	testns, err := testEnv.CreateNamespace(ctx, "provider-test")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	t.Cleanup(func() {
+		g.Expect(testEnv.Cleanup(ctx, testns)).ToNot(HaveOccurred())
+	})
+
+	// The GitHub Enterprise API reports a classic token without the repo scope.
+	ghes := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "read:org")
+		_, _ = w.Write([]byte(`{"permissions":{"push":true}}`))
+	}))
+	t.Cleanup(ghes.Close)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "github-token",
+			Namespace: testns.Name,
+		},
+		StringData: map[string]string{
+			"token": "test-token",
+		},
+	}
+	g.Expect(testEnv.Create(ctx, secret)).ToNot(HaveOccurred())
+
+	provider := &apiv1.Provider{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("provider-%s", randStringRunes(5)),
+			Namespace: testns.Name,
+		},
+		Spec: apiv1.ProviderSpec{
+			Type:      apiv1.GitHubProvider,
+			Address:   ghes.URL + "/foo/bar",
+			SecretRef: &meta.LocalObjectReference{Name: secret.Name},
+		},
+	}
+	providerKey := client.ObjectKeyFromObject(provider)
+	g.Expect(testEnv.Create(ctx, provider)).ToNot(HaveOccurred())
+
+	g.Eventually(func() bool {
+		_ = testEnv.Get(ctx, providerKey, provider)
+		return conditions.IsFalse(provider, meta.ReadyCondition) &&
+			provider.Status.ObservedGeneration == provider.Generation
+	}, timeout, time.Second).Should(BeTrue())
+	g.Expect(conditions.GetReason(provider, apiv1.CredentialsValidCondition)).To(Equal(apiv1.InsufficientPermissionsReason))
+	g.Expect(conditions.GetMessage(provider, apiv1.CredentialsValidCondition)).To(ContainSubstring("repo:status"))
+
+	// Providers without preflight are always ready.
+
+	patchHelper, err := patch.NewHelper(provider, testEnv.Client)
+	g.Expect(err).ToNot(HaveOccurred())
+	provider.Spec.Type = apiv1.SlackProvider
+	g.Expect(patchHelper.Patch(ctx, provider)).ToNot(HaveOccurred())
+
+	g.Eventually(func() bool {
+		_ = testEnv.Get(ctx, providerKey, provider)
+		return conditions.IsTrue(provider, meta.ReadyCondition) &&
+			provider.Status.ObservedGeneration == provider.Generation
+	}, timeout, time.Second).Should(BeTrue())
+	g.Expect(conditions.Has(provider, apiv1.CredentialsValidCondition)).To(BeFalse())
+}
+
+func TestProviderReconciler_capabilities(t *testing.T) {
+	g := NewWithT(t)
+
+	timeout := 10 * time.Second
+
+	testns, err := testEnv.CreateNamespace(ctx, "provider-test")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	t.Cleanup(func() {
+		g.Expect(testEnv.Cleanup(ctx, testns)).ToNot(HaveOccurred())
+	})
+
+	provider := &apiv1.Provider{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("provider-%s", randStringRunes(5)),
+			Namespace: testns.Name,
+		},
+		Spec: apiv1.ProviderSpec{
+			Type:          apiv1.TelegramProvider,
+			Channel:       "flux",
+			CertSecretRef: &meta.LocalObjectReference{Name: "ca"},
+		},
+	}
+	providerKey := client.ObjectKeyFromObject(provider)
+	g.Expect(testEnv.Create(ctx, provider)).ToNot(HaveOccurred())
+
+	g.Eventually(func() bool {
+		_ = testEnv.Get(ctx, providerKey, provider)
+		return conditions.IsFalse(provider, meta.ReadyCondition) &&
+			provider.Status.ObservedGeneration == provider.Generation
+	}, timeout, time.Second).Should(BeTrue())
+	g.Expect(conditions.GetReason(provider, meta.ReadyCondition)).To(Equal(apiv1.ValidationFailedReason))
+	g.Expect(conditions.GetMessage(provider, meta.ReadyCondition)).To(
+		Equal("certSecretRef is not supported by the 'telegram' Provider"))
+	g.Expect(provider.Status.Capabilities).To(BeEmpty())
+
+	// The capabilities of the type are reported in the status.
+
+	patchHelper, err := patch.NewHelper(provider, testEnv.Client)
+	g.Expect(err).ToNot(HaveOccurred())
+	provider.Spec.Type = apiv1.SlackProvider
+	g.Expect(patchHelper.Patch(ctx, provider)).ToNot(HaveOccurred())
+
+	g.Eventually(func() bool {
+		_ = testEnv.Get(ctx, providerKey, provider)
+		return conditions.IsTrue(provider, meta.ReadyCondition) &&
+			provider.Status.ObservedGeneration == provider.Generation
+	}, timeout, time.Second).Should(BeTrue())
+	g.Expect(provider.Status.Capabilities).To(Equal([]string{"TLS", "Proxy"}))
+}
@@ -19,6 +19,8 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -42,7 +44,8 @@ import (
 	"github.com/fluxcd/pkg/runtime/predicates"
 
 	apiv1 "github.com/fluxcd/notification-controller/api/v1"
-	"github.com/fluxcd/notification-controller/internal/server"
+	"github.com/fluxcd/notification-controller/internal/features"
+	"github.com/fluxcd/notification-controller/pkg/server"
 )
 
 // ReceiverReconciler reconciles a Receiver object
@@ -52,6 +55,16 @@ type ReceiverReconciler struct {
 	kuberecorder.EventRecorder
 
 	ControllerName string
+
+	// NamespacedWebhookPaths generates the webhook paths under
+	// '/hook/<namespace>/' instead of '/hook/'.
+	NamespacedWebhookPaths bool
+
+	// ArbitraryResources allows the Receivers to target resources of any
+	// kind, and not only Flux kinds.
+	ArbitraryResources bool
+
+	suspends suspendTracker
 }
 
 type ReceiverReconcilerOptions struct {
@@ -91,6 +104,10 @@ func (r *ReceiverReconciler) SetupWithManagerAndOptions(mgr ctrl.Manager, opts R
 // +kubebuilder:rbac:groups=source.fluxcd.io,resources=helmrepositories/status,verbs=get
 // +kubebuilder:rbac:groups=image.fluxcd.io,resources=imagerepositories,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=image.fluxcd.io,resources=imagerepositories/status,verbs=get
+// +kubebuilder:rbac:groups=image.fluxcd.io,resources=imagepolicies,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=image.fluxcd.io,resources=imagepolicies/status,verbs=get
+// +kubebuilder:rbac:groups=image.fluxcd.io,resources=imageupdateautomations,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=image.fluxcd.io,resources=imageupdateautomations/status,verbs=get
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 
@@ -100,9 +117,14 @@ func (r *ReceiverReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 
 	obj := &apiv1.Receiver{}
 	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.suspends.forget(req.NamespacedName)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	r.suspends.observe(r.EventRecorder, obj, apiv1.ReceiverKind, obj.Spec.Suspend)
+
 	// Initialize the runtime patcher with the current version of the object.
 	patcher := patch.NewSerialPatcher(obj, r.Client)
 
@@ -114,6 +136,7 @@ func (r *ReceiverReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 
 		// Record Prometheus metrics.
 		r.Metrics.RecordDuration(ctx, obj, reconcileStart)
+		r.Metrics.RecordSuspend(ctx, obj, obj.Spec.Suspend)
 
 		// Emit warning event if the reconciliation failed.
 		if retErr != nil {
@@ -159,6 +182,13 @@ func (r *ReceiverReconciler) reconcile(ctx context.Context, obj *apiv1.Receiver)
 	// Mark the resource as under reconciliation.
 	conditions.MarkReconciling(obj, meta.ProgressingReason, "Reconciliation in progress")
 
+	if err := r.validateResources(obj); err != nil {
+		conditions.MarkFalse(obj, meta.ReadyCondition, apiv1.ValidationFailedReason, "%s", err)
+		r.Event(obj, corev1.EventTypeWarning, apiv1.ValidationFailedReason, err.Error())
+		obj.Status.WebhookPath = ""
+		return ctrl.Result{}, nil
+	}
+
 	token, err := r.token(ctx, obj)
 	if err != nil {
 		conditions.MarkFalse(obj, meta.ReadyCondition, apiv1.TokenNotFoundReason, "%s", err)
@@ -167,6 +197,9 @@ func (r *ReceiverReconciler) reconcile(ctx context.Context, obj *apiv1.Receiver)
 	}
 
 	webhookPath := obj.GetWebhookPath(token)
+	if r.NamespacedWebhookPaths {
+		webhookPath = obj.GetNamespacedWebhookPath(token)
+	}
 	msg := fmt.Sprintf("Receiver initialized for path: %s", webhookPath)
 
 	// Mark the resource as ready and set the webhook path in status.
@@ -230,6 +263,25 @@ func (r *ReceiverReconciler) patch(ctx context.Context, obj *apiv1.Receiver, pat
 	return nil
 }
 
+// validateResources returns an error if the Receiver targets resources of
+// kinds that are not Flux kinds, unless arbitrary resources are allowed.
+func (r *ReceiverReconciler) validateResources(obj *apiv1.Receiver) error {
+	if r.ArbitraryResources {
+		return nil
+	}
+	var kinds []string
+	for _, resource := range obj.Spec.Resources {
+		if !apiv1.IsFluxKind(resource.Kind) && !slices.Contains(kinds, resource.Kind) {
+			kinds = append(kinds, resource.Kind)
+		}
+	}
+	if len(kinds) > 0 {
+		return fmt.Errorf("kinds not supported without the %s feature gate: %s",
+			features.ReceiverArbitraryResources, strings.Join(kinds, ", "))
+	}
+	return nil
+}
+
 // token extract the token value from the secret object
 func (r *ReceiverReconciler) token(ctx context.Context, receiver *apiv1.Receiver) (string, error) {
 	token := ""
@@ -244,10 +296,11 @@ func (r *ReceiverReconciler) token(ctx context.Context, receiver *apiv1.Receiver
 		return "", fmt.Errorf("unable to read token from secret '%s' error: %w", secretName, err)
 	}
 
-	if val, ok := secret.Data["token"]; ok {
+	key := receiver.GetTokenSecretKey()
+	if val, ok := secret.Data[key]; ok {
 		token = string(val)
 	} else {
-		return "", fmt.Errorf("invalid '%s' secret data: required fields 'token'", secretName)
+		return "", fmt.Errorf("invalid '%s' secret data: required field '%s'", secretName, key)
 	}
 
 	return token, nil
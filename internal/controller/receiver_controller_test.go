@@ -42,7 +42,7 @@ import (
 	"github.com/fluxcd/pkg/ssa"
 
 	apiv1 "github.com/fluxcd/notification-controller/api/v1"
-	"github.com/fluxcd/notification-controller/internal/server"
+	"github.com/fluxcd/notification-controller/pkg/server"
 )
 
 func TestReceiverReconciler_deleteBeforeFinalizer(t *testing.T) {
@@ -62,7 +62,7 @@ func TestReceiverReconciler_deleteBeforeFinalizer(t *testing.T) {
 	receiver.Namespace = namespaceName
 	receiver.Spec = apiv1.ReceiverSpec{
 		Type: "github",
-		Resources: []apiv1.CrossNamespaceObjectReference{
+		Resources: []apiv1.ReceiverObjectReference{
 			{Kind: "Bucket", Name: "Foo"},
 		},
 		SecretRef: meta.LocalObjectReference{Name: "foo-secret"},
@@ -115,7 +115,7 @@ func TestReceiverReconciler_Reconcile(t *testing.T) {
 		Spec: apiv1.ReceiverSpec{
 			Type:   "generic",
 			Events: []string{"push"},
-			Resources: []apiv1.CrossNamespaceObjectReference{
+			Resources: []apiv1.ReceiverObjectReference{
 				{
 					Name: "podinfo",
 					Kind: "GitRepository",
@@ -239,7 +239,7 @@ func TestReceiverReconciler_EventHandler(t *testing.T) {
 
 	// Use the client from the manager as the server handler needs to list objects from the cache
 	// which the "live" k8s client does not have access to.
-	receiverServer := server.NewReceiverServer("127.0.0.1:56788", logf.Log, testEnv.GetClient(), true)
+	receiverServer := server.NewReceiverServer("localhost:56788", logf.Log, testEnv.GetClient(), server.ReceiverServerOptions{ExportHTTPPathMetrics: true})
 	receiverMdlw := middleware.New(middleware.Config{
 		Recorder: prommetrics.NewRecorder(prommetrics.Config{
 			Prefix: "gotk_receiver",
@@ -295,7 +295,7 @@ func TestReceiverReconciler_EventHandler(t *testing.T) {
 		Spec: apiv1.ReceiverSpec{
 			Type:   "generic",
 			Events: []string{"pull"},
-			Resources: []apiv1.CrossNamespaceObjectReference{
+			Resources: []apiv1.ReceiverObjectReference{
 				{
 					Name: "podinfo",
 					Kind: "GitRepository",
@@ -354,3 +354,28 @@ func TestReceiverReconciler_EventHandler(t *testing.T) {
 		}, timeout, time.Second).Should(BeTrue())
 	})
 }
+
+func TestReceiverReconciler_validateResources(t *testing.T) {
+	receiver := &apiv1.Receiver{
+		Spec: apiv1.ReceiverSpec{
+			Resources: []apiv1.ReceiverObjectReference{
+				{Kind: "GitRepository", Name: "app"},
+				{APIVersion: "example.com/v1", Kind: "Pipeline", Name: "build"},
+				{APIVersion: "example.com/v1", Kind: "Pipeline", Name: "test"},
+			},
+		},
+	}
+
+	t.Run("rejects non-Flux kinds when disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		r := &ReceiverReconciler{}
+		g.Expect(r.validateResources(receiver)).To(MatchError(
+			"kinds not supported without the ReceiverArbitraryResources feature gate: Pipeline"))
+	})
+
+	t.Run("accepts non-Flux kinds when enabled", func(t *testing.T) {
+		g := NewWithT(t)
+		r := &ReceiverReconciler{ArbitraryResources: true}
+		g.Expect(r.validateResources(receiver)).To(Succeed())
+	})
+}
@@ -75,6 +75,7 @@ func TestMain(m *testing.M) {
 
 	if err := (&AlertReconciler{
 		Client:         testEnv,
+		Metrics:        testMetricsH,
 		ControllerName: controllerName,
 		EventRecorder:  testEnv.GetEventRecorderFor(controllerName),
 	}).SetupWithManager(testEnv); err != nil {
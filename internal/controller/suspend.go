@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kuberecorder "k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// suspendTracker remembers the last observed suspend state of the objects
+// of a kind, to emit an event when they are suspended or resumed.
+// The zero value is ready to use.
+type suspendTracker struct {
+	mu        sync.Mutex
+	suspended map[types.NamespacedName]bool
+}
+
+// observe records the suspend state of the given object and emits an event
+// if it differs from the last observed one. The first observation of an
+// object doesn't emit an event, as its previous state is unknown.
+func (t *suspendTracker) observe(recorder kuberecorder.EventRecorder, obj client.Object, kind string, suspend bool) {
+	key := client.ObjectKeyFromObject(obj)
+
+	t.mu.Lock()
+	if t.suspended == nil {
+		t.suspended = make(map[types.NamespacedName]bool)
+	}
+	last, found := t.suspended[key]
+	t.suspended[key] = suspend
+	t.mu.Unlock()
+
+	if !found || last == suspend {
+		return
+	}
+	if suspend {
+		recorder.Event(obj, corev1.EventTypeNormal, meta.SuspendedReason, fmt.Sprintf("%s suspended", kind))
+	} else {
+		recorder.Event(obj, corev1.EventTypeNormal, apiv1.ResumedReason, fmt.Sprintf("%s resumed", kind))
+	}
+}
+
+// forget removes the given object from the tracker.
+func (t *suspendTracker) forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.suspended, key)
+}
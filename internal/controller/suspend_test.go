@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestSuspendTracker(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(32)
+	var tracker suspendTracker
+
+	obj := &apiv1.Provider{}
+	obj.Name = "foo"
+	obj.Namespace = "default"
+
+	// The first observation has no previous state to compare with.
+	tracker.observe(recorder, obj, apiv1.ProviderKind, false)
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	tracker.observe(recorder, obj, apiv1.ProviderKind, true)
+	g.Expect(recorder.Events).To(Receive(Equal("Normal Suspended Provider suspended")))
+
+	tracker.observe(recorder, obj, apiv1.ProviderKind, true)
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	tracker.observe(recorder, obj, apiv1.ProviderKind, false)
+	g.Expect(recorder.Events).To(Receive(Equal("Normal Resumed Provider resumed")))
+
+	// A forgotten object is observed again as new.
+	tracker.forget(client.ObjectKeyFromObject(obj))
+	tracker.observe(recorder, obj, apiv1.ProviderKind, true)
+	g.Expect(recorder.Events).To(BeEmpty())
+}
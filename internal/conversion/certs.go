@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	certFile   = "tls.crt"
+	keyFile    = "tls.key"
+	caCertFile = "ca.crt"
+
+	certValidity = 10 * 365 * 24 * time.Hour
+)
+
+// EnsureCertificate writes the serving certificate of the webhook server to
+// the directory, unless the directory already holds one, e.g. mounted from
+// a Secret issued by cert-manager, and returns the PEM-encoded CA
+// certificate.
+//
+// The certificate is read from the given Secret, which is created with a
+// self-signed certificate for the Service when it doesn't exist, for all
+// the replicas to serve the same certificate.
+func EnsureCertificate(ctx context.Context, c client.Client, dir string, secret types.NamespacedName, serviceName string) ([]byte, error) {
+	caPEM, err := os.ReadFile(filepath.Join(dir, caCertFile))
+	if err == nil {
+		for _, name := range []string{certFile, keyFile} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				return nil, fmt.Errorf("invalid certificate directory, '%s' is missing: %w", name, err)
+			}
+		}
+		return caPEM, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the CA certificate: %w", err)
+	}
+
+	data, err := ensureCertificateSecret(ctx, c, secret, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create the certificate directory: %w", err)
+	}
+	for _, name := range []string{certFile, keyFile, caCertFile} {
+		if len(data[name]) == 0 {
+			return nil, fmt.Errorf("invalid certificate Secret '%s', '%s' is missing", secret, name)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data[name], 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write '%s': %w", name, err)
+		}
+	}
+	return data[caCertFile], nil
+}
+
+// ensureCertificateSecret returns the data of the given certificate Secret,
+// creating it with a self-signed certificate for the Service when it
+// doesn't exist. The Secret created first wins when several replicas start
+// at the same time.
+func ensureCertificateSecret(ctx context.Context, c client.Client, key types.NamespacedName, serviceName string) (map[string][]byte, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, key, secret)
+	if err == nil {
+		return secret.Data, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get the certificate Secret '%s': %w", key, err)
+	}
+
+	caPEM, certPEM, keyPEM, err := generateCertificate(serviceName, key.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			certFile:   certPEM,
+			keyFile:    keyPEM,
+			caCertFile: caPEM,
+		},
+	}
+	err = c.Create(ctx, secret)
+	if apierrors.IsAlreadyExists(err) {
+		if err := c.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get the certificate Secret '%s': %w", key, err)
+		}
+		return secret.Data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the certificate Secret '%s': %w", key, err)
+	}
+	return secret.Data, nil
+}
+
+// generateCertificate returns a self-signed CA certificate, and a serving
+// certificate and key signed by the CA for the DNS names of the Service.
+func generateCertificate(serviceName, namespace string) (caPEM, certPEM, keyPEM []byte, err error) {
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := notBefore.Add(certValidity)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate the CA key: %w", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca", serviceName)},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create the CA certificate: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate the serving key: %w", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: fmt.Sprintf("%s.%s.svc", serviceName, namespace)},
+		DNSNames: []string{
+			serviceName,
+			fmt.Sprintf("%s.%s", serviceName, namespace),
+			fmt.Sprintf("%s.%s.svc", serviceName, namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", serviceName, namespace),
+		},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create the serving certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal the serving key: %w", err)
+	}
+
+	caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return caPEM, certPEM, keyPEM, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsureCertificate(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	kclient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	secret := types.NamespacedName{Name: "webhook-cert", Namespace: "flux-system"}
+
+	dir := filepath.Join(t.TempDir(), "certs")
+	caPEM, err := EnsureCertificate(context.TODO(), kclient, dir, secret, "webhook")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	pair, err := tls.LoadX509KeyPair(filepath.Join(dir, certFile), filepath.Join(dir, keyFile))
+	g.Expect(err).ToNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	g.Expect(err).ToNot(HaveOccurred())
+
+	roots := x509.NewCertPool()
+	g.Expect(roots.AppendCertsFromPEM(caPEM)).To(BeTrue())
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName: "webhook.flux-system.svc",
+		Roots:   roots,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Share the certificate of the Secret with the other replicas.
+	created := &corev1.Secret{}
+	g.Expect(kclient.Get(context.TODO(), secret, created)).To(Succeed())
+	g.Expect(created.Data[caCertFile]).To(Equal(caPEM))
+
+	otherDir := filepath.Join(t.TempDir(), "certs")
+	other, err := EnsureCertificate(context.TODO(), kclient, otherDir, secret, "webhook")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(other).To(Equal(caPEM))
+	otherCert, err := os.ReadFile(filepath.Join(otherDir, certFile))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(otherCert).To(Equal(created.Data[certFile]))
+
+	// Reuse the certificate of the directory.
+	g.Expect(kclient.Delete(context.TODO(), created)).To(Succeed())
+	again, err := EnsureCertificate(context.TODO(), kclient, dir, secret, "webhook")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(again).To(Equal(caPEM))
+
+	// Reject an incomplete certificate directory.
+	g.Expect(os.Remove(filepath.Join(dir, keyFile))).To(Succeed())
+	_, err = EnsureCertificate(context.TODO(), kclient, dir, secret, "webhook")
+	g.Expect(err).To(MatchError(ContainSubstring("'tls.key' is missing")))
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlconversion "sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+	apiv1b1 "github.com/fluxcd/notification-controller/api/v1beta1"
+	apiv1b2 "github.com/fluxcd/notification-controller/api/v1beta2"
+	apiv1b3 "github.com/fluxcd/notification-controller/api/v1beta3"
+)
+
+func TestAlertConversion(t *testing.T) {
+	hub := &apiv1.Alert{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "alert",
+			Namespace:   "default",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: apiv1.AlertSpec{
+			ProviderRefs:    []meta.LocalObjectReference{{Name: "slack"}, {Name: "pagerduty"}},
+			EventSeverities: []string{"error", "info"},
+			EventSources: []apiv1.CrossNamespaceObjectReference{
+				{Kind: "Kustomization", Name: "*"},
+			},
+			InclusionList:     []string{".*"},
+			ExclusionList:     []string{"^skip"},
+			EventMetadata:     map[string]string{"env": "prod"},
+			RateLimitInterval: &metav1.Duration{Duration: time.Minute},
+		},
+		Status: apiv1.AlertStatus{ObservedGeneration: 2},
+	}
+
+	t.Run("v1beta3", func(t *testing.T) {
+		g := NewWithT(t)
+
+		spoke := &apiv1b3.Alert{}
+		g.Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		g.Expect(spoke.Spec.ProviderRef.Name).To(Equal("slack"))
+		g.Expect(spoke.Spec.EventSeverity).To(Equal("info"))
+		g.Expect(spoke.Annotations).To(HaveKey(apiv1.ConversionDataAnnotation))
+		g.Expect(hub.Annotations).ToNot(HaveKey(apiv1.ConversionDataAnnotation))
+
+		restored := &apiv1.Alert{}
+		g.Expect(spoke.ConvertTo(restored)).To(Succeed())
+		g.Expect(restored).To(Equal(hub))
+
+		// Drop the restored fields changed in v1beta3.
+		spoke.Spec.ProviderRef.Name = "teams"
+		spoke.Spec.EventSeverity = "error"
+		g.Expect(spoke.ConvertTo(restored)).To(Succeed())
+		g.Expect(restored.Spec.ProviderRefs).To(Equal([]meta.LocalObjectReference{{Name: "teams"}}))
+		g.Expect(restored.Spec.EventSeverities).To(Equal([]string{"error"}))
+	})
+
+	t.Run("v1beta2", func(t *testing.T) {
+		g := NewWithT(t)
+
+		spoke := &apiv1b2.Alert{}
+		g.Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		g.Expect(spoke.Spec.ProviderRef.Name).To(Equal("slack"))
+
+		restored := &apiv1.Alert{}
+		g.Expect(spoke.ConvertTo(restored)).To(Succeed())
+		g.Expect(restored).To(Equal(hub))
+	})
+
+	t.Run("v1beta1", func(t *testing.T) {
+		g := NewWithT(t)
+
+		spoke := &apiv1b1.Alert{}
+		g.Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		g.Expect(spoke.Spec.ProviderRef.Name).To(Equal("slack"))
+		g.Expect(spoke.Spec.EventSources).To(Equal([]apiv1b1.CrossNamespaceObjectReference{
+			{Kind: "Kustomization", Name: "*"},
+		}))
+
+		restored := &apiv1.Alert{}
+		g.Expect(spoke.ConvertTo(restored)).To(Succeed())
+		g.Expect(restored).To(Equal(hub))
+	})
+
+	t.Run("lossless", func(t *testing.T) {
+		g := NewWithT(t)
+
+		spoke := &apiv1b3.Alert{
+			Spec: apiv1b3.AlertSpec{
+				ProviderRef:   meta.LocalObjectReference{Name: "slack"},
+				EventSeverity: "error",
+			},
+		}
+		converted := &apiv1.Alert{}
+		g.Expect(spoke.ConvertTo(converted)).To(Succeed())
+		g.Expect(converted.Spec.EventSeverities).To(Equal([]string{"error"}))
+
+		back := &apiv1b3.Alert{}
+		g.Expect(back.ConvertFrom(converted)).To(Succeed())
+		g.Expect(back.Annotations).ToNot(HaveKey(apiv1.ConversionDataAnnotation))
+		g.Expect(back.Spec).To(Equal(spoke.Spec))
+	})
+}
+
+func TestProviderConversion(t *testing.T) {
+	hub := &apiv1.Provider{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "provider",
+			Namespace: "default",
+		},
+		Spec: apiv1.ProviderSpec{
+			Type:           apiv1.SlackProvider,
+			Channel:        "general",
+			Timeout:        &metav1.Duration{Duration: 10 * time.Second},
+			ProxySecretRef: &meta.LocalObjectReference{Name: "proxy"},
+			SecretRef:      &meta.LocalObjectReference{Name: "token"},
+			MetadataFields: &apiv1.MetadataFields{
+				Order:   []string{"summary"},
+				Exclude: []string{"revision"},
+			},
+			MessageTruncation: &apiv1.MessageTruncation{
+				MaxLength: 4000,
+				Strategy:  apiv1.TruncateHead,
+			},
+			AddressFrom: &apiv1.AddressSource{
+				ConfigMapKeyRef: apiv1.ConfigMapKeyReference{Name: "addresses", Key: "slack"},
+			},
+		},
+	}
+
+	for name, spoke := range map[string]ctrlconversion.Convertible{
+		"v1beta3": &apiv1b3.Provider{},
+		"v1beta2": &apiv1b2.Provider{},
+		"v1beta1": &apiv1b1.Provider{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			restored := &apiv1.Provider{}
+			g.Expect(spoke.ConvertTo(restored)).To(Succeed())
+			g.Expect(restored).To(Equal(hub))
+		})
+	}
+
+	// The v1 fields missing from the spokes are restored after a round-trip.
+	for name, spec := range map[string]apiv1.ProviderSpec{
+		"dns": {
+			Type: apiv1.GenericProvider,
+			DNS: &apiv1.ProviderDNS{
+				Nameserver:  "10.0.0.10",
+				HostAliases: []apiv1.HostAlias{{IP: "10.0.0.5", Hostnames: []string{"hooks.example.com"}}},
+			},
+		},
+		"hmac": {
+			Type: apiv1.GenericHMACProvider,
+			HMAC: &apiv1.HMACSignature{Header: "X-Hub-Signature-256", Algorithm: "sha256"},
+		},
+		"appearance": {
+			Type: apiv1.SlackProvider,
+			Appearance: &apiv1.Appearance{
+				Error: &apiv1.SeverityAppearance{Color: "#ff0000", Emoji: ":fire:"},
+			},
+		},
+		"serviceAccountName": {
+			Type:               apiv1.AzureDevOpsProvider,
+			Address:            "https://dev.azure.com/org/project/_git/repo",
+			ServiceAccountName: "notifier",
+		},
+		"githubDispatch": {
+			Type: apiv1.GitHubDispatchProvider,
+			GitHubDispatch: &apiv1.GitHubDispatch{
+				Workflow: "deploy.yaml",
+				Ref:      "main",
+				Inputs:   map[string]string{"environment": "production"},
+			},
+		},
+	} {
+		for version, spoke := range map[string]ctrlconversion.Convertible{
+			"v1beta3": &apiv1b3.Provider{},
+			"v1beta2": &apiv1b2.Provider{},
+			"v1beta1": &apiv1b1.Provider{},
+		} {
+			t.Run(version+" "+name, func(t *testing.T) {
+				g := NewWithT(t)
+
+				hub := &apiv1.Provider{Spec: spec}
+				g.Expect(spoke.ConvertFrom(hub)).To(Succeed())
+				restored := &apiv1.Provider{}
+				g.Expect(spoke.ConvertTo(restored)).To(Succeed())
+				g.Expect(restored).To(Equal(hub))
+			})
+		}
+	}
+
+	t.Run("deprecated fields", func(t *testing.T) {
+		g := NewWithT(t)
+
+		spoke := &apiv1b3.Provider{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			Spec: apiv1b3.ProviderSpec{
+				Type:     apiv1b3.GenericProvider,
+				Interval: &metav1.Duration{Duration: time.Minute},
+				Proxy:    "https://proxy.example.com",
+			},
+		}
+		converted := &apiv1.Provider{}
+		g.Expect(spoke.ConvertTo(converted)).To(Succeed())
+		g.Expect(converted.Spec).To(Equal(apiv1.ProviderSpec{Type: apiv1.GenericProvider}))
+		g.Expect(converted.Annotations).To(HaveKeyWithValue(apiv1.ProxyAnnotation, "https://proxy.example.com"))
+		g.Expect(spoke.Annotations).ToNot(HaveKey(apiv1.ProxyAnnotation))
+
+		// The proxy is restored in the spokes, the interval is dropped.
+		back := &apiv1b3.Provider{}
+		g.Expect(back.ConvertFrom(converted)).To(Succeed())
+		g.Expect(back.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+		g.Expect(back.Spec).To(Equal(apiv1b3.ProviderSpec{
+			Type:  apiv1b3.GenericProvider,
+			Proxy: "https://proxy.example.com",
+		}))
+
+		backv1b2 := &apiv1b2.Provider{}
+		g.Expect(backv1b2.ConvertFrom(converted)).To(Succeed())
+		g.Expect(backv1b2.Spec.Proxy).To(Equal("https://proxy.example.com"))
+
+		backv1b1 := &apiv1b1.Provider{}
+		g.Expect(backv1b1.ConvertFrom(converted)).To(Succeed())
+		g.Expect(backv1b1.Spec.Proxy).To(Equal("https://proxy.example.com"))
+		g.Expect(converted.Annotations).To(HaveKey(apiv1.ProxyAnnotation))
+	})
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=create
+
+const (
+	flagWebhookPort        = "conversion-webhook-port"
+	flagWebhookCertDir     = "conversion-webhook-cert-dir"
+	flagWebhookServiceName = "conversion-webhook-service-name"
+	flagWebhookCertSecret  = "conversion-webhook-cert-secret"
+)
+
+// CRDs lists the names of the CustomResourceDefinitions served by the
+// conversion webhook.
+var CRDs = []string{
+	"alerts.notification.toolkit.fluxcd.io",
+	"providers.notification.toolkit.fluxcd.io",
+}
+
+// Options holds the configuration of the conversion webhook server.
+type Options struct {
+	// Port the webhook server binds to.
+	Port int
+
+	// CertDir is the directory holding the serving certificate of the
+	// webhook server. The certificate of the CertSecretName Secret is
+	// written to it when the directory doesn't contain one.
+	CertDir string
+
+	// CertSecretName is the name of the Secret, in the namespace of the
+	// controller, holding the serving certificate shared by all the
+	// replicas. A self-signed certificate is generated when the Secret
+	// doesn't exist.
+	CertSecretName string
+
+	// ServiceName is the name of the Service routing the conversion
+	// requests of the API server to the webhook server.
+	ServiceName string
+}
+
+// BindFlags will parse the given pflag.FlagSet for conversion webhook
+// option flags and set the Options accordingly.
+func (o *Options) BindFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.Port, flagWebhookPort, 9443,
+		"The port the conversion webhook server binds to.")
+	fs.StringVar(&o.CertDir, flagWebhookCertDir, "/tmp/k8s-webhook-server/serving-certs",
+		"The directory holding the serving certificate of the conversion webhook server, in the tls.crt, tls.key and ca.crt files. The certificate of the --"+flagWebhookCertSecret+" Secret is used when the directory doesn't contain one.")
+	fs.StringVar(&o.CertSecretName, flagWebhookCertSecret, "notification-controller-webhook-cert",
+		"The name of the Secret holding the serving certificate of the conversion webhook server shared by all the replicas. A self-signed certificate is generated when the Secret doesn't exist.")
+	fs.StringVar(&o.ServiceName, flagWebhookServiceName, "notification-controller-webhook",
+		"The name of the Service routing the conversion requests to the webhook server.")
+}
+
+// SetupWithManager registers the conversion webhook of the Alert and
+// Provider APIs with the webhook server of the manager.
+func SetupWithManager(mgr ctrl.Manager) error {
+	for _, obj := range []runtime.Object{&apiv1.Alert{}, &apiv1.Provider{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CABundleInjector sets the CA bundle of the conversion webhook
+// configuration of the CRDs, for the API server to trust the serving
+// certificate of the webhook server.
+type CABundleInjector struct {
+	// Reader reads the CRDs from the API server.
+	Reader client.Reader
+
+	// Writer patches the CRDs.
+	Writer client.Writer
+
+	// CABundle is the PEM-encoded CA certificate of the webhook server.
+	CABundle []byte
+
+	// CRDs lists the names of the CRDs to inject the CA bundle into.
+	CRDs []string
+
+	Logger logr.Logger
+}
+
+// Start injects the CA bundle into the CRDs, retrying until it succeeds or
+// the context is cancelled.
+func (i *CABundleInjector) Start(ctx context.Context) error {
+	return wait.PollUntilContextCancel(ctx, 10*time.Second, true, func(ctx context.Context) (bool, error) {
+		if err := i.Inject(ctx); err != nil {
+			i.Logger.Error(err, "failed to inject the conversion webhook CA bundle")
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+// NeedLeaderElection returns true, the CA bundle is injected by the leader
+// only, while all the replicas serve the conversion webhook.
+func (i *CABundleInjector) NeedLeaderElection() bool {
+	return true
+}
+
+// Inject sets the CA bundle of the CRDs configured with the webhook
+// conversion strategy. The other CRDs are left untouched.
+func (i *CABundleInjector) Inject(ctx context.Context) error {
+	for _, name := range i.CRDs {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := i.Reader.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+			return fmt.Errorf("failed to get CRD '%s': %w", name, err)
+		}
+
+		conversion := crd.Spec.Conversion
+		if conversion == nil || conversion.Strategy != apiextensionsv1.WebhookConverter ||
+			conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil {
+			i.Logger.Info("skipping CA bundle injection, the CRD doesn't use the webhook conversion strategy", "crd", name)
+			continue
+		}
+		if bytes.Equal(conversion.Webhook.ClientConfig.CABundle, i.CABundle) {
+			continue
+		}
+
+		patch := client.MergeFrom(crd.DeepCopy())
+		conversion.Webhook.ClientConfig.CABundle = i.CABundle
+		if err := i.Writer.Patch(ctx, crd, patch); err != nil {
+			return fmt.Errorf("failed to patch CRD '%s': %w", name, err)
+		}
+		i.Logger.Info("injected the conversion webhook CA bundle", "crd", name)
+	}
+	return nil
+}
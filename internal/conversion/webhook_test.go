@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestCABundleInjector_Inject(t *testing.T) {
+	g := NewWithT(t)
+
+	webhookCRD := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "alerts.notification.toolkit.fluxcd.io"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig: &apiextensionsv1.WebhookClientConfig{
+						Service: &apiextensionsv1.ServiceReference{
+							Name:      "notification-controller-webhook",
+							Namespace: "flux-system",
+						},
+					},
+					ConversionReviewVersions: []string{"v1"},
+				},
+			},
+		},
+	}
+	noneCRD := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "providers.notification.toolkit.fluxcd.io"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.NoneConverter,
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	kclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(webhookCRD, noneCRD).Build()
+
+	injector := &CABundleInjector{
+		Reader:   kclient,
+		Writer:   kclient,
+		CABundle: []byte("ca"),
+		CRDs:     CRDs,
+		Logger:   log.Log,
+	}
+	g.Expect(injector.Inject(context.TODO())).To(Succeed())
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	g.Expect(kclient.Get(context.TODO(), types.NamespacedName{Name: webhookCRD.Name}, crd)).To(Succeed())
+	g.Expect(crd.Spec.Conversion.Webhook.ClientConfig.CABundle).To(Equal([]byte("ca")))
+
+	g.Expect(kclient.Get(context.TODO(), types.NamespacedName{Name: noneCRD.Name}, crd)).To(Succeed())
+	g.Expect(crd.Spec.Conversion.Webhook).To(BeNil())
+
+	// Fail on missing CRDs.
+	injector.CRDs = []string{"receivers.notification.toolkit.fluxcd.io"}
+	g.Expect(injector.Inject(context.TODO())).To(MatchError(ContainSubstring("failed to get CRD")))
+}
@@ -27,12 +27,32 @@ const (
 	// When enabled, it will cache both object types, resulting in increased
 	// memory usage and cluster-wide RBAC permissions (list and watch).
 	CacheSecretsAndConfigMaps = "CacheSecretsAndConfigMaps"
+
+	// ReceiverArbitraryResources controls whether the Receivers can request
+	// the reconciliation of resources of any kind, and not only Flux kinds.
+	//
+	// When enabled, the apiVersion of the non-Flux resources must be set,
+	// and the controller must be granted the RBAC permissions to get, list
+	// and patch them.
+	ReceiverArbitraryResources = "ReceiverArbitraryResources"
+
+	// ExecProvider controls whether the Providers of type exec can be used
+	// to post the events to a sidecar over a Unix domain socket.
+	ExecProvider = "ExecProvider"
 )
 
 var features = map[string]bool{
 	// CacheSecretsAndConfigMaps
 	// opt-in from v0.31
 	CacheSecretsAndConfigMaps: false,
+
+	// ReceiverArbitraryResources
+	// opt-in from v1.5
+	ReceiverArbitraryResources: false,
+
+	// ExecProvider
+	// opt-in from v1.5
+	ExecProvider: false,
 }
 
 // FeatureGates contains a list of all supported feature gates and
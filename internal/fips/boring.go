@@ -0,0 +1,31 @@
+//go:build boringcrypto
+
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"crypto/boring"
+
+	// Restrict the TLS settings to the FIPS-approved ones.
+	_ "crypto/tls/fipsonly"
+)
+
+// boringEnabled returns whether the BoringCrypto module is in use.
+func boringEnabled() bool {
+	return boring.Enabled()
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fips implements the FIPS 140 mode of notification-controller,
+// in which the controller refuses to start when not built with a FIPS 140
+// validated cryptographic module, and refuses the HMAC algorithms not
+// approved for the regulated environments.
+package fips
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// enabled tells whether the FIPS mode is turned on.
+var enabled bool
+
+// Enable turns on the FIPS mode. It fails when the controller isn't built
+// with GOEXPERIMENT=boringcrypto, or when the BoringCrypto module isn't
+// in use at runtime.
+func Enable() error {
+	if !boringEnabled() {
+		return errors.New("FIPS mode requires the controller to be built with GOEXPERIMENT=boringcrypto")
+	}
+	enabled = true
+	return nil
+}
+
+// Enabled returns whether the FIPS mode is turned on.
+func Enabled() bool {
+	return enabled
+}
+
+// CheckHMACAlgorithm returns an error when the FIPS mode is turned on and
+// the given HMAC hash algorithm, e.g. sha1 or sha256, is not allowed.
+func CheckHMACAlgorithm(algorithm string) error {
+	if enabled && strings.EqualFold(algorithm, "sha1") {
+		return fmt.Errorf("the HMAC algorithm '%s' is not allowed in FIPS mode", algorithm)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCheckHMACAlgorithm(t *testing.T) {
+	g := NewWithT(t)
+	defer func() { enabled = false }()
+
+	g.Expect(CheckHMACAlgorithm("sha1")).To(Succeed())
+
+	enabled = true
+	g.Expect(CheckHMACAlgorithm("sha1")).To(MatchError(ContainSubstring("not allowed in FIPS mode")))
+	g.Expect(CheckHMACAlgorithm("SHA1")).ToNot(Succeed())
+	g.Expect(CheckHMACAlgorithm("sha256")).To(Succeed())
+	g.Expect(CheckHMACAlgorithm("sha512")).To(Succeed())
+}
+
+func TestEnable(t *testing.T) {
+	g := NewWithT(t)
+	defer func() { enabled = false }()
+
+	err := Enable()
+	if boringEnabled() {
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(Enabled()).To(BeTrue())
+	} else {
+		g.Expect(err).To(MatchError(ContainSubstring("GOEXPERIMENT=boringcrypto")))
+		g.Expect(Enabled()).To(BeFalse())
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+// Appearance overrides the colors and emojis of the messages posted by
+// the chat notifiers, by event severity.
+type Appearance struct {
+	// Colors maps the event severities to the color of their messages.
+	Colors map[string]string
+	// Emojis maps the event severities to the emoji of their messages.
+	Emojis map[string]string
+}
+
+// color returns the color of the messages of the given severity, or the
+// given default color if it's not overridden.
+func (a Appearance) color(severity, defaultColor string) string {
+	if c, ok := a.Colors[severity]; ok && c != "" {
+		return c
+	}
+	return defaultColor
+}
+
+// emoji returns the emoji of the messages of the given severity, or the
+// given default emoji if it's not overridden.
+func (a Appearance) emoji(severity, defaultEmoji string) string {
+	if e, ok := a.Emojis[severity]; ok && e != "" {
+		return e
+	}
+	return defaultEmoji
+}
+
+// withEmoji prefixes the given title with the emoji of the messages of the
+// given severity, if any.
+func (a Appearance) withEmoji(severity, title string) string {
+	if e := a.emoji(severity, ""); e != "" {
+		return e + " " + title
+	}
+	return title
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+func TestAppearance(t *testing.T) {
+	var defaults Appearance
+	require.Equal(t, "good", defaults.color(eventv1.EventSeverityInfo, "good"))
+	require.Equal(t, "💫", defaults.emoji(eventv1.EventSeverityInfo, "💫"))
+	require.Equal(t, "title", defaults.withEmoji(eventv1.EventSeverityInfo, "title"))
+
+	a := Appearance{
+		Colors: map[string]string{eventv1.EventSeverityError: "#ff0000", eventv1.EventSeverityInfo: ""},
+		Emojis: map[string]string{eventv1.EventSeverityError: ":fire:"},
+	}
+	require.Equal(t, "#ff0000", a.color(eventv1.EventSeverityError, "danger"))
+	require.Equal(t, "good", a.color(eventv1.EventSeverityInfo, "good"))
+	require.Equal(t, ":fire:", a.emoji(eventv1.EventSeverityError, "🚨"))
+	require.Equal(t, ":fire: title", a.withEmoji(eventv1.EventSeverityError, "title"))
+	require.Equal(t, "title", a.withEmoji(eventv1.EventSeverityInfo, "title"))
+}
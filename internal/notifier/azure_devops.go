@@ -18,7 +18,6 @@ package notifier
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
@@ -46,8 +45,13 @@ type AzureDevOps struct {
 	Client      azureDevOpsClient
 }
 
+// azureDevOpsBearerToken is the token type of the Entra access tokens,
+// as opposed to the personal access tokens.
+const azureDevOpsBearerToken = "bearer"
+
 // NewAzureDevOps creates and returns a new AzureDevOps notifier.
-func NewAzureDevOps(providerUID string, addr string, token string, certPool *x509.CertPool) (*AzureDevOps, error) {
+// The token is a personal access token, unless tokenType is 'bearer'.
+func NewAzureDevOps(providerUID string, addr string, token string, certPool *x509.CertPool, tokenType string) (*AzureDevOps, error) {
 	if len(token) == 0 {
 		return nil, errors.New("azure devops token cannot be empty")
 	}
@@ -66,12 +70,17 @@ func NewAzureDevOps(providerUID string, addr string, token string, certPool *x50
 	repo := comp[3]
 
 	orgURL := fmt.Sprintf("%v/%v", host, org)
-	connection := azuredevops.NewPatConnection(orgURL, token)
-	if certPool != nil {
-		connection.TlsConfig = &tls.Config{
-			RootCAs: certPool,
-		}
+	var connection *azuredevops.Connection
+	switch tokenType {
+	case "":
+		connection = azuredevops.NewPatConnection(orgURL, token)
+	case azureDevOpsBearerToken:
+		connection = azuredevops.NewAnonymousConnection(orgURL)
+		connection.AuthorizationString = "Bearer " + token
+	default:
+		return nil, fmt.Errorf("invalid azure devops token type %q", tokenType)
 	}
+	connection.TlsConfig = newTLSConfig(certPool)
 	client := connection.GetClientByUrl(orgURL)
 	gitClient := &git.ClientImpl{
 		Client: *client,
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	authnv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AzureClientIDAnnotation is the ServiceAccount annotation holding the
+	// client ID of the Entra application federated with the ServiceAccount.
+	AzureClientIDAnnotation = "azure.workload.identity/client-id"
+	// AzureTenantIDAnnotation is the ServiceAccount annotation holding the
+	// Entra tenant ID. It defaults to the AZURE_TENANT_ID of the controller.
+	AzureTenantIDAnnotation = "azure.workload.identity/tenant-id"
+
+	// azureTokenExchangeAudience is the audience of the ServiceAccount
+	// tokens exchanged for Entra access tokens.
+	azureTokenExchangeAudience = "api://AzureADTokenExchange"
+	// azureDevOpsScope is the scope of the Azure DevOps REST API.
+	azureDevOpsScope = "499b84ac-1321-427f-aa17-267ca6975798/.default"
+	// azureTokenExpiryMargin is how long before their expiry the cached
+	// access tokens are renewed.
+	azureTokenExpiryMargin = 5 * time.Minute
+)
+
+// AzureDevOpsWorkloadIdentityToken returns an Entra access token for Azure
+// DevOps, obtained by exchanging a token of the given ServiceAccount
+// through workload identity federation. The access tokens are cached
+// per ServiceAccount until shortly before they expire.
+func AzureDevOpsWorkloadIdentityToken(ctx context.Context, kubeClient client.Client, namespace, serviceAccountName string) (string, error) {
+	return azureTokens.get(ctx, kubeClient, namespace, serviceAccountName, azureDevOpsScope)
+}
+
+// azureTokens is the process-wide cache of the Entra access tokens.
+var azureTokens = &azureTokenCache{tokens: make(map[string]azureCachedToken)}
+
+type azureCachedToken struct {
+	token     string
+	expiresOn time.Time
+}
+
+// azureTokenCache caches the Entra access tokens by ServiceAccount and scope.
+type azureTokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]azureCachedToken
+
+	// getToken exchanges a ServiceAccount token, it's overridden in tests.
+	getToken func(ctx context.Context, tenantID, clientID, assertion, scope string) (azureCachedToken, error)
+}
+
+func (c *azureTokenCache) get(ctx context.Context, kubeClient client.Client, namespace, name, scope string) (string, error) {
+	key := fmt.Sprintf("%s/%s/%s", namespace, name, scope)
+
+	c.mu.Lock()
+	cached, ok := c.tokens[key]
+	c.mu.Unlock()
+	if ok && time.Now().Add(azureTokenExpiryMargin).Before(cached.expiresOn) {
+		return cached.token, nil
+	}
+
+	var sa corev1.ServiceAccount
+	if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &sa); err != nil {
+		return "", fmt.Errorf("failed to get ServiceAccount '%s/%s': %w", namespace, name, err)
+	}
+	clientID := sa.Annotations[AzureClientIDAnnotation]
+	if clientID == "" {
+		return "", fmt.Errorf("ServiceAccount '%s/%s' has no '%s' annotation", namespace, name, AzureClientIDAnnotation)
+	}
+	tenantID := sa.Annotations[AzureTenantIDAnnotation]
+	if tenantID == "" {
+		tenantID = os.Getenv("AZURE_TENANT_ID")
+	}
+	if tenantID == "" {
+		return "", fmt.Errorf("ServiceAccount '%s/%s' has no '%s' annotation and AZURE_TENANT_ID is not set",
+			namespace, name, AzureTenantIDAnnotation)
+	}
+
+	tokenRequest := &authnv1.TokenRequest{
+		Spec: authnv1.TokenRequestSpec{
+			Audiences: []string{azureTokenExchangeAudience},
+		},
+	}
+	if err := kubeClient.SubResource("token").Create(ctx, &sa, tokenRequest); err != nil {
+		return "", fmt.Errorf("failed to create token for ServiceAccount '%s/%s': %w", namespace, name, err)
+	}
+
+	getToken := c.getToken
+	if getToken == nil {
+		getToken = exchangeAzureToken
+	}
+	token, err := getToken(ctx, tenantID, clientID, tokenRequest.Status.Token, scope)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange token of ServiceAccount '%s/%s': %w", namespace, name, err)
+	}
+
+	c.mu.Lock()
+	c.tokens[key] = token
+	c.mu.Unlock()
+	return token.token, nil
+}
+
+// exchangeAzureToken exchanges the ServiceAccount token assertion for an
+// Entra access token of the given application.
+func exchangeAzureToken(ctx context.Context, tenantID, clientID, assertion, scope string) (azureCachedToken, error) {
+	cred, err := azidentity.NewClientAssertionCredential(tenantID, clientID,
+		func(context.Context) (string, error) { return assertion, nil }, nil)
+	if err != nil {
+		return azureCachedToken{}, err
+	}
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return azureCachedToken{}, err
+	}
+	return azureCachedToken{token: token.Token, expiresOn: token.ExpiresOn}, nil
+}
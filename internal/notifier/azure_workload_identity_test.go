@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAzureTokenCache(t *testing.T) {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "ado",
+			Annotations: map[string]string{
+				AzureClientIDAnnotation: "client",
+				AzureTenantIDAnnotation: "tenant",
+			},
+		},
+	}
+	kubeClient := fake.NewClientBuilder().WithObjects(sa).Build()
+
+	exchanges := 0
+	expiresOn := time.Now().Add(time.Hour)
+	cache := &azureTokenCache{
+		tokens: make(map[string]azureCachedToken),
+		getToken: func(_ context.Context, tenantID, clientID, _, scope string) (azureCachedToken, error) {
+			exchanges++
+			require.Equal(t, "tenant", tenantID)
+			require.Equal(t, "client", clientID)
+			require.Equal(t, azureDevOpsScope, scope)
+			return azureCachedToken{token: "access-token", expiresOn: expiresOn}, nil
+		},
+	}
+
+	for range 2 {
+		token, err := cache.get(context.TODO(), kubeClient, "default", "ado", azureDevOpsScope)
+		require.NoError(t, err)
+		require.Equal(t, "access-token", token)
+	}
+	require.Equal(t, 1, exchanges)
+
+	// Tokens about to expire are renewed.
+	expiresOn = time.Now().Add(time.Minute)
+	cache.tokens = make(map[string]azureCachedToken)
+	for range 2 {
+		_, err := cache.get(context.TODO(), kubeClient, "default", "ado", azureDevOpsScope)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, exchanges)
+}
+
+func TestAzureTokenCache_MissingClientID(t *testing.T) {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ado"},
+	}
+	kubeClient := fake.NewClientBuilder().WithObjects(sa).Build()
+	cache := &azureTokenCache{tokens: make(map[string]azureCachedToken)}
+
+	_, err := cache.get(context.TODO(), kubeClient, "default", "ado", azureDevOpsScope)
+	require.ErrorContains(t, err, AzureClientIDAnnotation)
+}
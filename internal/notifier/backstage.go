@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// MetaBackstageEntityKey is the event metadata key holding the comma-separated
+// Backstage entity refs of the involved object, set with the
+// event.toolkit.fluxcd.io/backstage-entity annotation on the object.
+const MetaBackstageEntityKey = "backstage-entity"
+
+// Backstage is an implementation of the notification Interface that posts
+// the events to the Backstage notifications API.
+type Backstage struct {
+	URL      string
+	ProxyURL string
+	CertPool *x509.CertPool
+	Token    string
+}
+
+// BackstageNotification is the request body of the Backstage notifications API.
+type BackstageNotification struct {
+	Recipients BackstageRecipients `json:"recipients"`
+	Payload    BackstagePayload    `json:"payload"`
+}
+
+// BackstageRecipients holds the entities notified in Backstage.
+type BackstageRecipients struct {
+	Type      string   `json:"type"`
+	EntityRef []string `json:"entityRef"`
+}
+
+// BackstagePayload holds the content of a Backstage notification.
+type BackstagePayload struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Topic       string `json:"topic"`
+}
+
+func NewBackstage(hookURL string, proxyURL string, certPool *x509.CertPool, token string) (*Backstage, error) {
+	_, err := url.ParseRequestURI(hookURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Backstage hook URL %s: '%w'", hookURL, err)
+	}
+
+	if token == "" {
+		return nil, errors.New("empty Backstage token")
+	}
+
+	return &Backstage{
+		URL:      hookURL,
+		ProxyURL: proxyURL,
+		CertPool: certPool,
+		Token:    token,
+	}, nil
+}
+
+// Post posts the event as a notification of the Backstage entities of the
+// involved object.
+func (b *Backstage) Post(ctx context.Context, event eventv1.Event) error {
+	// Skip Git commit status update event.
+	if event.HasMetadata(eventv1.MetaCommitStatusKey, eventv1.MetaCommitStatusUpdateValue) {
+		return nil
+	}
+
+	payload := BackstageNotification{
+		Recipients: BackstageRecipients{
+			Type:      "entity",
+			EntityRef: backstageEntityRefs(event),
+		},
+		Payload: BackstagePayload{
+			Title: fmt.Sprintf("%s/%s.%s", event.InvolvedObject.Kind, event.InvolvedObject.Name,
+				event.InvolvedObject.Namespace),
+			Description: backstageDescription(event),
+			Severity:    backstageSeverity(event.Severity),
+			Topic:       "flux",
+		},
+	}
+
+	err := postMessage(ctx, b.URL, b.ProxyURL, b.CertPool, payload, func(req *retryablehttp.Request) {
+		req.Header.Set("Authorization", "Bearer "+b.Token)
+	})
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+	return nil
+}
+
+// backstageEntityRefs returns the entity refs from the event metadata, or
+// the component named after the involved object in the default namespace
+// of the Backstage catalog.
+func backstageEntityRefs(event eventv1.Event) []string {
+	var refs []string
+	for _, ref := range strings.Split(event.Metadata[MetaBackstageEntityKey], ",") {
+		if ref = strings.TrimSpace(ref); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		refs = append(refs, "component:default/"+event.InvolvedObject.Name)
+	}
+	return refs
+}
+
+// backstageDescription returns the event message followed by the metadata
+// of the event, sorted by key.
+func backstageDescription(event eventv1.Event) string {
+	keys := make([]string, 0, len(event.Metadata))
+	for k := range event.Metadata {
+		if k != MetaBackstageEntityKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(event.Message)
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("\n%s: %s", k, event.Metadata[k]))
+	}
+	return sb.String()
+}
+
+// backstageSeverity maps the event severity to a Backstage notification
+// severity.
+func backstageSeverity(severity string) string {
+	switch severity {
+	case eventv1.EventSeverityError:
+		return "high"
+	case eventv1.EventSeverityTrace:
+		return "low"
+	default:
+		return "normal"
+	}
+}
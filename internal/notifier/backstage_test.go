@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+func TestBackstage_Post(t *testing.T) {
+	tests := []struct {
+		name         string
+		event        func() eventv1.Event
+		wantRefs     []string
+		wantSeverity string
+	}{
+		{
+			name:         "default entity ref",
+			event:        testEvent,
+			wantRefs:     []string{"component:default/webapp"},
+			wantSeverity: "normal",
+		},
+		{
+			name: "entity refs from metadata",
+			event: func() eventv1.Event {
+				e := testEvent()
+				e.Severity = eventv1.EventSeverityError
+				e.Metadata[MetaBackstageEntityKey] = "component:default/frontend, component:payments/api"
+				return e
+			},
+			wantRefs:     []string{"component:default/frontend", "component:payments/api"},
+			wantSeverity: "high",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload BackstageNotification
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			}))
+			defer ts.Close()
+
+			backstage, err := NewBackstage(ts.URL, "", nil, "token")
+			require.NoError(t, err)
+
+			require.NoError(t, backstage.Post(context.TODO(), tt.event()))
+			require.Equal(t, "entity", payload.Recipients.Type)
+			require.Equal(t, tt.wantRefs, payload.Recipients.EntityRef)
+			require.Equal(t, tt.wantSeverity, payload.Payload.Severity)
+			require.Equal(t, "GitRepository/webapp.gitops-system", payload.Payload.Title)
+			require.Equal(t, "message\ntest: metadata", payload.Payload.Description)
+		})
+	}
+}
+
+func TestBackstage_EmptyToken(t *testing.T) {
+	_, err := NewBackstage("https://backstage.example.com/api/notifications/notifications", "", nil, "")
+	require.Error(t, err)
+}
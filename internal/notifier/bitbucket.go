@@ -18,7 +18,6 @@ package notifier
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"errors"
@@ -66,15 +65,13 @@ func NewBitbucket(providerUID string, addr string, token string, certPool *x509.
 	repo := comp[1]
 
 	client := bitbucket.NewBasicAuth(username, password)
-	if certPool != nil {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: certPool,
-			},
+	var tr http.RoundTripper
+	if tlsConfig := newTLSConfig(certPool); tlsConfig != nil {
+		tr = &http.Transport{
+			TLSClientConfig: tlsConfig,
 		}
-		hc := &http.Client{Transport: tr}
-		client.HttpClient = hc
 	}
+	client.HttpClient = &http.Client{Transport: newUserAgentTransport(tr)}
 
 	return &Bitbucket{
 		Owner:       owner,
@@ -114,6 +111,9 @@ func (b Bitbucket) Post(ctx context.Context, event eventv1.Event) error {
 		RepoSlug: b.Repo,
 		Revision: rev,
 	}
+	// GetCommitStatus ignores the context of the options, bind the context
+	// to the requests of the HTTP client instead.
+	b.Client.HttpClient = withRequestContext(ctx, b.Client.HttpClient)
 	cso := &bitbucket.CommitStatusOptions{
 		State:       state,
 		Key:         key,
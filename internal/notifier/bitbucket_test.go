@@ -17,8 +17,10 @@ limitations under the License.
 package notifier
 
 import (
+	"context"
 	"testing"
 
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,3 +40,19 @@ func TestNewBitbucketInvalidToken(t *testing.T) {
 	_, err := NewBitbucket("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://bitbucket.org/foo/bar", "bar", nil)
 	assert.NotNil(t, err)
 }
+
+func TestBitbucket_PostCanceled(t *testing.T) {
+	b, err := NewBitbucket("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://bitbucket.org/foo/bar", "foo:bar", nil)
+	assert.Nil(t, err)
+
+	event := eventv1.Event{
+		Severity: eventv1.EventSeverityInfo,
+		Metadata: map[string]string{
+			eventv1.MetaRevisionKey: "main@sha1:69b59063470310ebbd88a9156325322a124e55a3",
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = b.Post(ctx, event)
+	assert.ErrorContains(t, err, context.Canceled.Error())
+}
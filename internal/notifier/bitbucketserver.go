@@ -19,7 +19,6 @@ package notifier
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"errors"
@@ -43,6 +42,7 @@ type BitbucketServer struct {
 	Username        string
 	Password        string
 	Token           string
+	StatusKey       string
 	Client          *retryablehttp.Client
 }
 
@@ -51,6 +51,7 @@ const (
 	bbServerEndPointBuildsTmpl        = "%[1]s/builds"
 	bbServerGetBuildStatusQueryString = "key"
 	bbServerSourceCodeMgmtString      = "/scm/"
+	bbServerMaxKeyLength              = 40
 )
 
 type bbServerBuildStatus struct {
@@ -81,20 +82,19 @@ type bbServerBuildStatusSetRequest struct {
 }
 
 // NewBitbucketServer creates and returns a new BitbucketServer notifier.
-func NewBitbucketServer(providerUID string, addr string, token string, certPool *x509.CertPool, username string, password string) (*BitbucketServer, error) {
+func NewBitbucketServer(providerUID string, addr string, token string, certPool *x509.CertPool, username string, password string, statusKey string) (*BitbucketServer, error) {
 	url, err := parseBitbucketServerGitAddress(addr)
 	if err != nil {
 		return nil, err
 	}
 
 	httpClient := retryablehttp.NewClient()
-	if certPool != nil {
+	if tlsConfig := newTLSConfig(certPool); tlsConfig != nil {
 		httpClient.HTTPClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: certPool,
-			},
+			TLSClientConfig: tlsConfig,
 		}
 	}
+	httpClient.HTTPClient.Transport = newUserAgentTransport(httpClient.HTTPClient.Transport)
 
 	httpClient.HTTPClient.Timeout = 15 * time.Second
 	httpClient.RetryWaitMin = 2 * time.Second
@@ -113,6 +113,7 @@ func NewBitbucketServer(providerUID string, addr string, token string, certPool
 		Token:           token,
 		Username:        username,
 		Password:        password,
+		StatusKey:       statusKey,
 		Client:          httpClient,
 	}, nil
 }
@@ -138,9 +139,7 @@ func (b BitbucketServer) Post(ctx context.Context, event eventv1.Event) error {
 
 	name, desc := formatNameAndDescription(event)
 	name = name + " [" + desc + "]" //Bitbucket server displays this data on browser. Thus adding description here.
-	id := generateCommitStatusID(b.ProviderUID, event)
-	// key has a limitation of 40 characters in bitbucket api
-	key := sha1String(id)
+	key := b.buildStatusKey(event)
 
 	u := b.Url.JoinPath(b.createBuildPath(rev)).String()
 	dupe, err := b.duplicateBitbucketServerStatus(ctx, state, name, desc, key, u)
@@ -158,6 +157,24 @@ func (b BitbucketServer) Post(ctx context.Context, event eventv1.Event) error {
 	return nil
 }
 
+// buildStatusKey returns the key of the build status posted for the event.
+// The key has a limitation of 40 characters in bitbucket api, longer keys are
+// replaced with their SHA-1 hash.
+func (b BitbucketServer) buildStatusKey(event eventv1.Event) string {
+	if b.StatusKey == "" {
+		return sha1String(generateCommitStatusID(b.ProviderUID, event))
+	}
+	key := strings.NewReplacer(
+		"{kind}", event.InvolvedObject.Kind,
+		"{name}", event.InvolvedObject.Name,
+		"{namespace}", event.InvolvedObject.Namespace,
+	).Replace(b.StatusKey)
+	if len(key) > bbServerMaxKeyLength {
+		key = sha1String(key)
+	}
+	return key
+}
+
 func (b BitbucketServer) state(severity string) (string, error) {
 	switch severity {
 	case eventv1.EventSeverityInfo:
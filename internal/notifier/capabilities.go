@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"fmt"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+const (
+	// TLSCapability is the support of the custom CA certificates of the
+	// Secrets referenced in .spec.certSecretRef.
+	TLSCapability = "TLS"
+
+	// ProxyCapability is the support of the HTTP/S proxies of the Secrets
+	// referenced in .spec.proxySecretRef.
+	ProxyCapability = "Proxy"
+
+	// CommitStatusCapability is the support of the Git commit status
+	// updates.
+	CommitStatusCapability = "CommitStatus"
+
+	// WorkloadIdentityCapability is the support of the authentication with
+	// the ServiceAccount of .spec.serviceAccountName.
+	WorkloadIdentityCapability = "WorkloadIdentity"
+)
+
+// ProviderCapabilities describes the optional features supported by the
+// notifiers of a Provider type.
+type ProviderCapabilities struct {
+	TLS              bool
+	Proxy            bool
+	CommitStatus     bool
+	WorkloadIdentity bool
+}
+
+// List returns the names of the supported features.
+func (c ProviderCapabilities) List() []string {
+	var names []string
+	if c.TLS {
+		names = append(names, TLSCapability)
+	}
+	if c.Proxy {
+		names = append(names, ProxyCapability)
+	}
+	if c.CommitStatus {
+		names = append(names, CommitStatusCapability)
+	}
+	if c.WorkloadIdentity {
+		names = append(names, WorkloadIdentityCapability)
+	}
+	return names
+}
+
+// capabilities is a map of provider names to the features supported by
+// their notifiers, it must be kept in sync with the factory functions.
+var capabilities = map[string]ProviderCapabilities{
+	apiv1.GenericProvider:         {TLS: true, Proxy: true},
+	apiv1.GenericHMACProvider:     {TLS: true, Proxy: true},
+	apiv1.SlackProvider:           {TLS: true, Proxy: true},
+	apiv1.DiscordProvider:         {Proxy: true},
+	apiv1.RocketProvider:          {TLS: true, Proxy: true},
+	apiv1.MSTeamsProvider:         {TLS: true, Proxy: true},
+	apiv1.GoogleChatProvider:      {Proxy: true},
+	apiv1.GooglePubSubProvider:    {},
+	apiv1.WebexProvider:           {TLS: true, Proxy: true},
+	apiv1.SentryProvider:          {TLS: true},
+	apiv1.AzureEventHubProvider:   {},
+	apiv1.TelegramProvider:        {Proxy: true},
+	apiv1.LarkProvider:            {},
+	apiv1.MatrixProvider:          {TLS: true},
+	apiv1.OpsgenieProvider:        {TLS: true, Proxy: true},
+	apiv1.AlertManagerProvider:    {TLS: true, Proxy: true},
+	apiv1.GrafanaProvider:         {TLS: true, Proxy: true},
+	apiv1.PagerDutyProvider:       {TLS: true, Proxy: true},
+	apiv1.DataDogProvider:         {TLS: true, Proxy: true},
+	apiv1.NATSProvider:            {},
+	apiv1.GitHubProvider:          {TLS: true, CommitStatus: true},
+	apiv1.GitHubDispatchProvider:  {TLS: true},
+	apiv1.GitLabTriggerProvider:   {TLS: true},
+	apiv1.JenkinsProvider:         {TLS: true},
+	apiv1.StatusBoardProvider:     {},
+	apiv1.GitLabProvider:          {TLS: true, CommitStatus: true},
+	apiv1.GiteaProvider:           {TLS: true, CommitStatus: true},
+	apiv1.BitbucketServerProvider: {TLS: true, CommitStatus: true},
+	apiv1.BitbucketProvider:       {TLS: true, CommitStatus: true},
+	apiv1.AzureDevOpsProvider:     {TLS: true, CommitStatus: true, WorkloadIdentity: true},
+	apiv1.ExecProvider:            {},
+	apiv1.GerritProvider:          {TLS: true, CommitStatus: true},
+	apiv1.ForgejoProvider:         {TLS: true, CommitStatus: true},
+	apiv1.FluxRelayProvider:       {TLS: true, Proxy: true},
+	apiv1.CloudEventsProvider:     {TLS: true, Proxy: true},
+	apiv1.BackstageProvider:       {TLS: true, Proxy: true},
+	apiv1.PortProvider:            {TLS: true, Proxy: true},
+}
+
+// Capabilities returns the features supported by the notifiers of the
+// given Provider type. No feature is supported by the unknown types.
+func Capabilities(providerType string) ProviderCapabilities {
+	return capabilities[providerType]
+}
+
+// ValidateCapabilities returns an error if the given Provider spec
+// configures a feature not supported by the notifiers of its type.
+func ValidateCapabilities(spec apiv1.ProviderSpec) error {
+	c := Capabilities(spec.Type)
+	switch {
+	case spec.CertSecretRef != nil && !c.TLS:
+		return fmt.Errorf("certSecretRef is not supported by the '%s' Provider", spec.Type)
+	case spec.ProxySecretRef != nil && !c.Proxy:
+		return fmt.Errorf("proxySecretRef is not supported by the '%s' Provider", spec.Type)
+	case spec.ServiceAccountName != "" && !c.WorkloadIdentity:
+		return fmt.Errorf("serviceAccountName is not supported by the '%s' Provider", spec.Type)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"testing"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestCapabilities(t *testing.T) {
+	for provider := range notifiers {
+		_, ok := capabilities[provider]
+		require.True(t, ok, "missing capabilities of the '%s' Provider", provider)
+	}
+	for provider := range capabilities {
+		_, ok := notifiers[provider]
+		require.True(t, ok, "capabilities of the unknown '%s' Provider", provider)
+	}
+
+	require.Equal(t, []string{TLSCapability, CommitStatusCapability, WorkloadIdentityCapability},
+		Capabilities(apiv1.AzureDevOpsProvider).List())
+	require.Equal(t, []string{TLSCapability, ProxyCapability}, Capabilities(apiv1.SlackProvider).List())
+	require.Equal(t, []string{ProxyCapability}, Capabilities(apiv1.TelegramProvider).List())
+	require.Empty(t, Capabilities("unknown").List())
+}
+
+func TestValidateCapabilities(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    apiv1.ProviderSpec
+		wantErr string
+	}{
+		{
+			name: "no optional feature",
+			spec: apiv1.ProviderSpec{Type: apiv1.LarkProvider},
+		},
+		{
+			name: "supported features",
+			spec: apiv1.ProviderSpec{
+				Type:           apiv1.SlackProvider,
+				CertSecretRef:  &meta.LocalObjectReference{Name: "ca"},
+				ProxySecretRef: &meta.LocalObjectReference{Name: "proxy"},
+			},
+		},
+		{
+			name: "unsupported certSecretRef",
+			spec: apiv1.ProviderSpec{
+				Type:          apiv1.TelegramProvider,
+				CertSecretRef: &meta.LocalObjectReference{Name: "ca"},
+			},
+			wantErr: "certSecretRef is not supported by the 'telegram' Provider",
+		},
+		{
+			name: "unsupported proxySecretRef",
+			spec: apiv1.ProviderSpec{
+				Type:           apiv1.GitHubProvider,
+				ProxySecretRef: &meta.LocalObjectReference{Name: "proxy"},
+			},
+			wantErr: "proxySecretRef is not supported by the 'github' Provider",
+		},
+		{
+			name: "unsupported serviceAccountName",
+			spec: apiv1.ProviderSpec{
+				Type:               apiv1.GitLabProvider,
+				ServiceAccountName: "notifier",
+			},
+			wantErr: "serviceAccountName is not supported by the 'gitlab' Provider",
+		},
+		{
+			name: "supported serviceAccountName",
+			spec: apiv1.ProviderSpec{
+				Type:               apiv1.AzureDevOpsProvider,
+				ServiceAccountName: "notifier",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCapabilities(tt.spec)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
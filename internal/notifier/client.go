@@ -18,11 +18,9 @@ package notifier
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -36,11 +34,10 @@ type requestOptFunc func(*retryablehttp.Request)
 
 func postMessage(ctx context.Context, address, proxy string, certPool *x509.CertPool, payload interface{}, reqOpts ...requestOptFunc) error {
 	httpClient := retryablehttp.NewClient()
-	if certPool != nil {
+	tlsConfig := newTLSConfig(certPool)
+	if tlsConfig != nil {
 		httpClient.HTTPClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: certPool,
-			},
+			TLSClientConfig: tlsConfig,
 		}
 	}
 
@@ -49,12 +46,6 @@ func postMessage(ctx context.Context, address, proxy string, certPool *x509.Cert
 		if err != nil {
 			return fmt.Errorf("unable to parse proxy URL '%s', error: %w", proxy, err)
 		}
-		var tlsConfig *tls.Config
-		if certPool != nil {
-			tlsConfig = &tls.Config{
-				RootCAs: certPool,
-			}
-		}
 		httpClient.HTTPClient.Transport = &http.Transport{
 			Proxy:           http.ProxyURL(proxyURL),
 			TLSClientConfig: tlsConfig,
@@ -70,6 +61,11 @@ func postMessage(ctx context.Context, address, proxy string, certPool *x509.Cert
 		}
 	}
 
+	httpClient.HTTPClient.Transport = withResolver(httpClient.HTTPClient.Transport)
+	// The transport isn't reused across the calls, don't leave its
+	// connections open.
+	defer httpClient.HTTPClient.CloseIdleConnections()
+
 	// Disable the timeout for the HTTP client,
 	// as we set the provider timeout on the context.
 	httpClient.HTTPClient.Timeout = 0
@@ -92,6 +88,7 @@ func postMessage(ctx context.Context, address, proxy string, certPool *x509.Cert
 		req = req.WithContext(ctx)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgentFromContext(ctx))
 	for _, o := range reqOpts {
 		o(req)
 	}
@@ -99,13 +96,10 @@ func postMessage(ctx context.Context, address, proxy string, certPool *x509.Cert
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusCreated {
-		b, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("unable to read response body, %s", err)
-		}
-		return fmt.Errorf("request failed with status code %d, %s", resp.StatusCode, string(b))
+		return responseError(resp)
 	}
 
 	return nil
@@ -60,6 +60,17 @@ func Test_postMessage_timeout(t *testing.T) {
 	require.Error(t, err, "context deadline exceeded")
 }
 
+func Test_postMessage_canceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must not be sent")
+	}))
+	defer ts.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := postMessage(ctx, ts.URL, "", nil, map[string]string{"status": "success"})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
 func Test_postSelfSignedCert(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		b, err := io.ReadAll(r.Body)
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+const (
+	// cloudEventsSpecVersion is the version of the CloudEvents specification
+	// of the events posted by the CloudEvents notifier.
+	cloudEventsSpecVersion = "1.0"
+
+	// cloudEventsContentType is the content type of the CloudEvents in
+	// structured content mode.
+	cloudEventsContentType = "application/cloudevents+json; charset=utf-8"
+
+	// cloudEventsDefaultType is the type of the CloudEvents when the
+	// Provider doesn't specify one.
+	cloudEventsDefaultType = "io.fluxcd.event"
+)
+
+// CloudEvents is an implementation of the notification Interface that posts
+// the events in the CloudEvents structured JSON format, e.g. to an Argo Events
+// webhook event source or a Keptn API.
+type CloudEvents struct {
+	URL      string
+	ProxyURL string
+	Source   string
+	Type     string
+	Headers  map[string]string
+	CertPool *x509.CertPool
+}
+
+// cloudEvent is a CloudEvent holding a Flux event as data.
+type cloudEvent struct {
+	SpecVersion     string        `json:"specversion"`
+	ID              string        `json:"id"`
+	Source          string        `json:"source"`
+	Type            string        `json:"type"`
+	Subject         string        `json:"subject,omitempty"`
+	Time            string        `json:"time,omitempty"`
+	DataContentType string        `json:"datacontenttype"`
+	Severity        string        `json:"severity"`
+	Reason          string        `json:"reason"`
+	Data            eventv1.Event `json:"data"`
+}
+
+// NewCloudEvents returns a CloudEvents notifier posting to the given URL.
+// The source and type attributes of the CloudEvents are set to the given
+// values when not empty, otherwise the source is the reporting controller
+// of the events and the type is io.fluxcd.event.
+func NewCloudEvents(hookURL string, proxyURL string, source string, eventType string, headers map[string]string, certPool *x509.CertPool) (*CloudEvents, error) {
+	if _, err := url.ParseRequestURI(hookURL); err != nil {
+		return nil, fmt.Errorf("invalid hook URL %s: %w", hookURL, err)
+	}
+
+	if eventType == "" {
+		eventType = cloudEventsDefaultType
+	}
+
+	return &CloudEvents{
+		URL:      hookURL,
+		ProxyURL: proxyURL,
+		Source:   source,
+		Type:     eventType,
+		Headers:  headers,
+		CertPool: certPool,
+	}, nil
+}
+
+// Post posts the event as a CloudEvent in structured content mode.
+func (c *CloudEvents) Post(ctx context.Context, event eventv1.Event) error {
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed marshalling event: %w", err)
+	}
+
+	source := c.Source
+	if source == "" {
+		source = event.ReportingController
+	}
+
+	payload := cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              fmt.Sprintf("%x", sha256.Sum256(eventJSON)),
+		Source:          source,
+		Type:            c.Type,
+		Subject:         fmt.Sprintf("%s/%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Namespace, event.InvolvedObject.Name),
+		DataContentType: "application/json",
+		Severity:        event.Severity,
+		Reason:          event.Reason,
+		Data:            event,
+	}
+	if !event.Timestamp.IsZero() {
+		payload.Time = event.Timestamp.UTC().Format(time.RFC3339)
+	}
+
+	err = postMessage(ctx, c.URL, c.ProxyURL, c.CertPool, payload, func(req *retryablehttp.Request) {
+		req.Header.Set("Content-Type", cloudEventsContentType)
+		req.Header.Set(NotificationHeader, event.ReportingController)
+		for key, val := range c.Headers {
+			req.Header.Set(key, val)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+	return nil
+}
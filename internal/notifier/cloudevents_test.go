@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudEvents_Post(t *testing.T) {
+	var received cloudEvent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/cloudevents+json; charset=utf-8", r.Header.Get("Content-Type"))
+		require.Equal(t, "source-controller", r.Header.Get(NotificationHeader))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	ce, err := NewCloudEvents(ts.URL, "", "", "", nil, nil)
+	require.NoError(t, err)
+
+	event := testEvent()
+	require.NoError(t, ce.Post(context.TODO(), event))
+	require.Equal(t, "1.0", received.SpecVersion)
+	require.NotEmpty(t, received.ID)
+	require.Equal(t, "source-controller", received.Source)
+	require.Equal(t, "io.fluxcd.event", received.Type)
+	require.Equal(t, "GitRepository/gitops-system/webapp", received.Subject)
+	require.Equal(t, "info", received.Severity)
+	require.Equal(t, "reason", received.Reason)
+	require.NotEmpty(t, received.Time)
+	require.Equal(t, "application/json", received.DataContentType)
+	require.Equal(t, event.Message, received.Data.Message)
+
+	// The ID is the same for all the delivery attempts of an event.
+	id := received.ID
+	require.NoError(t, ce.Post(context.TODO(), event))
+	require.Equal(t, id, received.ID)
+
+	// The source and type are configurable.
+	ce, err = NewCloudEvents(ts.URL, "", "/clusters/staging", "io.fluxcd.deployment", nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, ce.Post(context.TODO(), event))
+	require.Equal(t, "/clusters/staging", received.Source)
+	require.Equal(t, "io.fluxcd.deployment", received.Type)
+}
+
+func TestCloudEvents_InvalidURL(t *testing.T) {
+	_, err := NewCloudEvents("not a url", "", "", "", nil, nil)
+	require.Error(t, err)
+}
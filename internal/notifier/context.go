@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"net/http"
+)
+
+// withRequestContext returns a copy of the given HTTP client which sends
+// its requests with the given context, for the clients of SDKs which
+// ignore the context of some of their calls.
+func withRequestContext(ctx context.Context, client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	c := *client
+	base := c.Transport
+	if t, ok := base.(*requestContextTransport); ok {
+		base = t.base
+	}
+	c.Transport = &requestContextTransport{ctx: ctx, base: base}
+	return &c
+}
+
+// requestContextTransport is an http.RoundTripper replacing the context
+// of the requests.
+type requestContextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *requestContextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req.WithContext(t.ctx))
+}
@@ -18,7 +18,6 @@ package notifier
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"net/http"
@@ -56,8 +55,10 @@ func NewDataDog(address string, proxyUrl string, certPool *x509.CertPool, token
 	conf.Host = baseUrl.Host
 	conf.Scheme = baseUrl.Scheme
 
-	if proxyUrl != "" || certPool != nil {
-		transport := &http.Transport{}
+	var transport http.RoundTripper
+	tlsConfig := newTLSConfig(certPool)
+	if proxyUrl != "" || tlsConfig != nil {
+		tr := &http.Transport{}
 
 		if proxyUrl != "" {
 			proxy, err := url.Parse(proxyUrl)
@@ -65,18 +66,16 @@ func NewDataDog(address string, proxyUrl string, certPool *x509.CertPool, token
 				return nil, fmt.Errorf("failed to parse proxy URL %q: %w", proxyUrl, err)
 			}
 
-			transport.Proxy = http.ProxyURL(proxy)
+			tr.Proxy = http.ProxyURL(proxy)
 		}
 
-		if certPool != nil {
-			transport.TLSClientConfig = &tls.Config{
-				RootCAs: certPool,
-			}
-		}
+		tr.TLSClientConfig = tlsConfig
 
-		conf.HTTPClient = &http.Client{
-			Transport: transport,
-		}
+		transport = tr
+	}
+
+	conf.HTTPClient = &http.Client{
+		Transport: newUserAgentTransport(transport),
 	}
 
 	apiClient := datadog.NewAPIClient(conf)
@@ -32,6 +32,13 @@ type Discord struct {
 	ProxyURL string
 	Username string
 	Channel  string
+
+	// MetadataFields configures the rendering of the event metadata
+	// as attachment fields.
+	MetadataFields MetadataFields
+
+	// Appearance overrides the colors and emojis of the messages.
+	Appearance Appearance
 }
 
 // NewDiscord validates the URL and returns a Discord object
@@ -74,15 +81,16 @@ func (s *Discord) Post(ctx context.Context, event eventv1.Event) error {
 	if event.Severity == eventv1.EventSeverityError {
 		color = "danger"
 	}
+	color = s.Appearance.color(event.Severity, color)
 
 	sfields := make([]SlackField, 0, len(event.Metadata))
-	for k, v := range event.Metadata {
-		sfields = append(sfields, SlackField{k, v, false})
+	for _, f := range s.MetadataFields.fields(event.Metadata) {
+		sfields = append(sfields, SlackField{f.Key, f.Value, false})
 	}
 
 	a := SlackAttachment{
 		Color:      color,
-		AuthorName: fmt.Sprintf("%s/%s.%s", strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name, event.InvolvedObject.Namespace),
+		AuthorName: s.Appearance.withEmoji(event.Severity, fmt.Sprintf("%s/%s.%s", strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name, event.InvolvedObject.Namespace)),
 		Text:       event.Message,
 		MrkdwnIn:   []string{"text"},
 		Fields:     sfields,
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// execSocketScheme is the optional scheme of the Exec socket address.
+const execSocketScheme = "unix://"
+
+// Exec is an implementation of the notification Interface that posts the
+// event to a sidecar container listening on a Unix domain socket, which
+// runs the custom delivery logic.
+type Exec struct {
+	SocketPath string
+	Headers    map[string]string
+}
+
+// NewExec returns an Exec notifier posting the events to the Unix domain
+// socket at the given absolute path, optionally prefixed with 'unix://'.
+func NewExec(address string, headers map[string]string) (*Exec, error) {
+	socketPath := strings.TrimPrefix(address, execSocketScheme)
+	if !filepath.IsAbs(socketPath) {
+		return nil, fmt.Errorf("invalid socket address %s: must be an absolute path", address)
+	}
+
+	return &Exec{
+		SocketPath: filepath.Clean(socketPath),
+		Headers:    headers,
+	}, nil
+}
+
+// ValidateSocketDir returns an error if the socket of the notifier is not
+// located in the given directory.
+func (e *Exec) ValidateSocketDir(dir string) error {
+	rel, err := filepath.Rel(filepath.Clean(dir), e.SocketPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("invalid socket address %s: must be located in %s", e.SocketPath, dir)
+	}
+	return nil
+}
+
+// Post posts the event as JSON to the sidecar.
+func (e *Exec) Post(ctx context.Context, event eventv1.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshalling notification payload failed: %w", err)
+	}
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.HTTPClient.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{Timeout: 5 * time.Second}).DialContext(ctx, "unix", e.SocketPath)
+		},
+	}
+	// Disable the timeout for the HTTP client,
+	// as we set the provider timeout on the context.
+	httpClient.HTTPClient.Timeout = 0
+	httpClient.RetryWaitMin = 1 * time.Second
+	httpClient.RetryWaitMax = 10 * time.Second
+	httpClient.RetryMax = 4
+	httpClient.Logger = nil
+
+	// The host is ignored by the transport, the request is sent to the socket.
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, "http://localhost/", data)
+	if err != nil {
+		return fmt.Errorf("failed to create a new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgentFromContext(ctx))
+	req.Header.Set(NotificationHeader, event.ReportingController)
+	for key, val := range e.Headers {
+		req.Header.Set(key, val)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return responseError(resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+func TestNewExec(t *testing.T) {
+	e, err := NewExec("unix:///var/run/notifier/../notifier.sock", nil)
+	require.NoError(t, err)
+	require.Equal(t, "/var/run/notifier.sock", e.SocketPath)
+
+	_, err = NewExec("/var/run/notifier.sock", nil)
+	require.NoError(t, err)
+
+	_, err = NewExec("notifier.sock", nil)
+	require.Error(t, err)
+
+	_, err = NewExec("http://localhost:8080", nil)
+	require.Error(t, err)
+}
+
+func TestExec_ValidateSocketDir(t *testing.T) {
+	e, err := NewExec("unix:///var/run/notifier/sidecar.sock", nil)
+	require.NoError(t, err)
+	require.NoError(t, e.ValidateSocketDir("/var/run/notifier"))
+	require.NoError(t, e.ValidateSocketDir("/var/run/notifier/"))
+	require.Error(t, e.ValidateSocketDir("/var/run/notifier/sidecar.sock"))
+	require.Error(t, e.ValidateSocketDir("/var/run/notif"))
+	require.Error(t, e.ValidateSocketDir(""))
+
+	e, err = NewExec("unix:///var/run/notifier/../docker.sock", nil)
+	require.NoError(t, err)
+	require.EqualError(t, e.ValidateSocketDir("/var/run/notifier"),
+		"invalid socket address /var/run/docker.sock: must be located in /var/run/notifier")
+}
+
+func TestExec_Post(t *testing.T) {
+	// Keep the socket path short, Unix domain socket paths are limited
+	// to about 100 characters.
+	dir, err := os.MkdirTemp("", "exec")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "notifier.sock")
+
+	l, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	status := http.StatusOK
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, "source-controller", r.Header.Get(NotificationHeader))
+		require.Equal(t, "token", r.Header.Get("Authorization"))
+
+		var payload eventv1.Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		require.Equal(t, "webapp", payload.InvolvedObject.Name)
+		w.WriteHeader(status)
+	})}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	e, err := NewExec("unix://"+socketPath, map[string]string{"Authorization": "token"})
+	require.NoError(t, err)
+	require.NoError(t, e.Post(context.TODO(), testEvent()))
+
+	status = http.StatusBadRequest
+	err = e.Post(context.TODO(), testEvent())
+	require.ErrorContains(t, err, "request failed with status code 400")
+}
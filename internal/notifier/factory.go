@@ -17,10 +17,15 @@ limitations under the License.
 package notifier
 
 import (
+	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"strings"
 
-	apiv1 "github.com/fluxcd/notification-controller/api/v1beta3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
 )
 
 var (
@@ -40,7 +45,7 @@ var (
 		apiv1.AzureEventHubProvider:   azureEventHubNotifierFunc,
 		apiv1.TelegramProvider:        telegramNotifierFunc,
 		apiv1.LarkProvider:            larkNotifierFunc,
-		apiv1.Matrix:                  matrixNotifierFunc,
+		apiv1.MatrixProvider:          matrixNotifierFunc,
 		apiv1.OpsgenieProvider:        opsgenieNotifierFunc,
 		apiv1.AlertManagerProvider:    alertmanagerNotifierFunc,
 		apiv1.GrafanaProvider:         grafanaNotifierFunc,
@@ -49,11 +54,21 @@ var (
 		apiv1.NATSProvider:            natsNotifierFunc,
 		apiv1.GitHubProvider:          gitHubNotifierFunc,
 		apiv1.GitHubDispatchProvider:  gitHubDispatchNotifierFunc,
+		apiv1.GitLabTriggerProvider:   gitLabTriggerNotifierFunc,
+		apiv1.JenkinsProvider:         jenkinsNotifierFunc,
+		apiv1.StatusBoardProvider:     statusBoardNotifierFunc,
 		apiv1.GitLabProvider:          gitLabNotifierFunc,
 		apiv1.GiteaProvider:           giteaNotifierFunc,
 		apiv1.BitbucketServerProvider: bitbucketServerNotifierFunc,
 		apiv1.BitbucketProvider:       bitbucketNotifierFunc,
 		apiv1.AzureDevOpsProvider:     azureDevOpsNotifierFunc,
+		apiv1.ExecProvider:            execNotifierFunc,
+		apiv1.GerritProvider:          gerritNotifierFunc,
+		apiv1.ForgejoProvider:         forgejoNotifierFunc,
+		apiv1.FluxRelayProvider:       fluxRelayNotifierFunc,
+		apiv1.CloudEventsProvider:     cloudEventsNotifierFunc,
+		apiv1.BackstageProvider:       backstageNotifierFunc,
+		apiv1.PortProvider:            portNotifierFunc,
 	}
 )
 
@@ -61,46 +76,40 @@ var (
 type notifierMap map[string]factoryFunc
 
 // factoryFunc is a factory function that creates a new notifier
-type factoryFunc func(opts notifierOptions) (Interface, error)
-
-type notifierOptions struct {
-	URL         string
-	ProxyURL    string
-	Username    string
-	Channel     string
-	Token       string
-	Headers     map[string]string
-	CertPool    *x509.CertPool
-	Password    string
-	ProviderUID string
+type factoryFunc func(opts FactoryOptions) (Interface, error)
+
+// FactoryOptions holds the settings of a Provider the notifiers are
+// created with.
+type FactoryOptions struct {
+	URL             string
+	ProxyURL        string
+	Username        string
+	Channel         string
+	Token           string
+	Headers         map[string]string
+	CertPool        *x509.CertPool
+	Password        string
+	ProviderUID     string
+	MetadataFields  MetadataFields
+	APIBaseURL      string
+	TokenType       string
+	CommitStatusKey string
+	ClientCert      *tls.Certificate
+	EncryptionKey   *rsa.PublicKey
+	HMAC            HMACOptions
+	GitHubDispatch  GitHubDispatchOptions
+	Appearance      Appearance
+	KubeClient      client.Client
+	Namespace       string
 }
 
 type Factory struct {
-	notifierOptions
-}
-
-func NewFactory(url string,
-	proxy string,
-	username string,
-	channel string,
-	token string,
-	headers map[string]string,
-	certPool *x509.CertPool,
-	password string,
-	providerUID string) *Factory {
-	return &Factory{
-		notifierOptions: notifierOptions{
-			URL:         url,
-			ProxyURL:    proxy,
-			Username:    username,
-			Channel:     channel,
-			Token:       token,
-			Headers:     headers,
-			CertPool:    certPool,
-			Password:    password,
-			ProviderUID: providerUID,
-		},
-	}
+	FactoryOptions
+}
+
+// NewFactory returns a Factory creating the notifiers with the given options.
+func NewFactory(opts FactoryOptions) *Factory {
+	return &Factory{FactoryOptions: opts}
 }
 
 func (f Factory) Notifier(provider string) (Interface, error) {
@@ -113,7 +122,7 @@ func (f Factory) Notifier(provider string) (Interface, error) {
 		err error
 	)
 	if notifier, ok := notifiers[provider]; ok {
-		n, err = notifier(f.notifierOptions)
+		n, err = notifier(f.FactoryOptions)
 	} else {
 		err = fmt.Errorf("provider %s not supported", provider)
 	}
@@ -124,122 +133,256 @@ func (f Factory) Notifier(provider string) (Interface, error) {
 	return n, err
 }
 
-func genericNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewForwarder(opts.URL, opts.ProxyURL, opts.Headers, opts.CertPool, nil)
+func genericNotifierFunc(opts FactoryOptions) (Interface, error) {
+	f, err := newEncryptingForwarder(opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
 }
 
-func genericHMACNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewForwarder(opts.URL, opts.ProxyURL, opts.Headers, opts.CertPool, []byte(opts.Token))
+func genericHMACNotifierFunc(opts FactoryOptions) (Interface, error) {
+	if opts.HMAC.Algorithm != "" {
+		if _, ok := hmacAlgorithms[opts.HMAC.Algorithm]; !ok {
+			return nil, fmt.Errorf("unsupported HMAC algorithm '%s'", opts.HMAC.Algorithm)
+		}
+	}
+	f, err := newEncryptingForwarder(opts, []byte(opts.Token))
+	if err != nil {
+		return nil, err
+	}
+	f.HMACHeader = opts.HMAC.Header
+	f.HMACAlgorithm = opts.HMAC.Algorithm
+	return f, nil
 }
 
-func slackNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewSlack(opts.URL, opts.ProxyURL, opts.Token, opts.CertPool, opts.Username, opts.Channel)
+// newEncryptingForwarder returns a Forwarder encrypting the events with the
+// encryption key of the options, if any.
+func newEncryptingForwarder(opts FactoryOptions, hmacKey []byte) (*Forwarder, error) {
+	f, err := NewForwarder(opts.URL, opts.ProxyURL, opts.Headers, opts.CertPool, hmacKey)
+	if err != nil {
+		return nil, err
+	}
+	f.EncryptionKey = opts.EncryptionKey
+	return f, nil
 }
 
-func discordNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewDiscord(opts.URL, opts.ProxyURL, opts.Username, opts.Channel)
+func execNotifierFunc(opts FactoryOptions) (Interface, error) {
+	return NewExec(opts.URL, opts.Headers)
 }
 
-func rocketNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewRocket(opts.URL, opts.ProxyURL, opts.CertPool, opts.Username, opts.Channel)
+func slackNotifierFunc(opts FactoryOptions) (Interface, error) {
+	n, err := NewSlack(opts.URL, opts.ProxyURL, opts.Token, opts.CertPool, opts.Username, opts.Channel)
+	if err != nil {
+		return nil, err
+	}
+	n.MetadataFields = opts.MetadataFields
+	n.Appearance = opts.Appearance
+	return n, nil
 }
 
-func msteamsNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewMSTeams(opts.URL, opts.ProxyURL, opts.CertPool)
+func discordNotifierFunc(opts FactoryOptions) (Interface, error) {
+	n, err := NewDiscord(opts.URL, opts.ProxyURL, opts.Username, opts.Channel)
+	if err != nil {
+		return nil, err
+	}
+	n.MetadataFields = opts.MetadataFields
+	n.Appearance = opts.Appearance
+	return n, nil
 }
 
-func googleChatNotifierFunc(opts notifierOptions) (Interface, error) {
+func rocketNotifierFunc(opts FactoryOptions) (Interface, error) {
+	n, err := NewRocket(opts.URL, opts.ProxyURL, opts.CertPool, opts.Username, opts.Channel)
+	if err != nil {
+		return nil, err
+	}
+	n.Appearance = opts.Appearance
+	return n, nil
+}
+
+func msteamsNotifierFunc(opts FactoryOptions) (Interface, error) {
+	n, err := NewMSTeams(opts.URL, opts.ProxyURL, opts.CertPool)
+	if err != nil {
+		return nil, err
+	}
+	n.MetadataFields = opts.MetadataFields
+	return n, nil
+}
+
+func googleChatNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewGoogleChat(opts.URL, opts.ProxyURL)
 }
 
-func googlePubSubNotifierFunc(opts notifierOptions) (Interface, error) {
+func googlePubSubNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewGooglePubSub(opts.URL, opts.Channel, opts.Token, opts.Headers)
 }
 
-func webexNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewWebex(opts.URL, opts.ProxyURL, opts.CertPool, opts.Channel, opts.Token)
+func webexNotifierFunc(opts FactoryOptions) (Interface, error) {
+	n, err := NewWebex(opts.URL, opts.ProxyURL, opts.CertPool, opts.Channel, opts.Token)
+	if err != nil {
+		return nil, err
+	}
+	n.Appearance = opts.Appearance
+	return n, nil
 }
 
-func sentryNotifierFunc(opts notifierOptions) (Interface, error) {
+func sentryNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewSentry(opts.CertPool, opts.URL, opts.Channel)
 }
 
-func azureEventHubNotifierFunc(opts notifierOptions) (Interface, error) {
+func azureEventHubNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewAzureEventHub(opts.URL, opts.Token, opts.Channel)
 }
 
-func telegramNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewTelegram(opts.Channel, opts.Token)
+func telegramNotifierFunc(opts FactoryOptions) (Interface, error) {
+	n, err := NewTelegram(opts.ProxyURL, opts.Channel, opts.Token)
+	if err != nil {
+		return nil, err
+	}
+	if opts.URL != "" {
+		n.URL = strings.TrimSuffix(opts.URL, "/")
+	}
+	n.Appearance = opts.Appearance
+	return n, nil
 }
 
-func larkNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewLark(opts.URL)
+func larkNotifierFunc(opts FactoryOptions) (Interface, error) {
+	n, err := NewLark(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	n.Appearance = opts.Appearance
+	return n, nil
 }
 
-func matrixNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewMatrix(opts.URL, opts.Token, opts.Channel, opts.CertPool)
+func matrixNotifierFunc(opts FactoryOptions) (Interface, error) {
+	n, err := NewMatrix(opts.URL, opts.Token, opts.Channel, opts.CertPool)
+	if err != nil {
+		return nil, err
+	}
+	n.Appearance = opts.Appearance
+	return n, nil
 }
 
-func opsgenieNotifierFunc(opts notifierOptions) (Interface, error) {
+func opsgenieNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewOpsgenie(opts.URL, opts.ProxyURL, opts.CertPool, opts.Token)
 }
 
-func alertmanagerNotifierFunc(opts notifierOptions) (Interface, error) {
+func alertmanagerNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewAlertmanager(opts.URL, opts.ProxyURL, opts.CertPool)
 }
 
-func grafanaNotifierFunc(opts notifierOptions) (Interface, error) {
+func grafanaNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewGrafana(opts.URL, opts.ProxyURL, opts.Token, opts.CertPool, opts.Username, opts.Password)
 }
 
-func pagerDutyNotifierFunc(opts notifierOptions) (Interface, error) {
+func pagerDutyNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewPagerDuty(opts.URL, opts.ProxyURL, opts.CertPool, opts.Channel)
 }
 
-func dataDogNotifierFunc(opts notifierOptions) (Interface, error) {
+func dataDogNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewDataDog(opts.URL, opts.ProxyURL, opts.CertPool, opts.Token)
 }
 
-func natsNotifierFunc(opts notifierOptions) (Interface, error) {
+func natsNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewNATS(opts.URL, opts.Channel, opts.Username, opts.Password)
 }
 
-func gitHubNotifierFunc(opts notifierOptions) (Interface, error) {
+func gitHubNotifierFunc(opts FactoryOptions) (Interface, error) {
 	if opts.Token == "" && opts.Password != "" {
 		opts.Token = opts.Password
 	}
-	return NewGitHub(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool)
+	return NewGitHub(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool, opts.APIBaseURL)
 }
 
-func gitHubDispatchNotifierFunc(opts notifierOptions) (Interface, error) {
+func gitHubDispatchNotifierFunc(opts FactoryOptions) (Interface, error) {
 	if opts.Token == "" && opts.Password != "" {
 		opts.Token = opts.Password
 	}
-	return NewGitHubDispatch(opts.URL, opts.Token, opts.CertPool)
+	inputs, err := parseGitHubDispatchInputs(opts.GitHubDispatch.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	n, err := NewGitHubDispatch(opts.URL, opts.Token, opts.CertPool, opts.APIBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	n.Workflow = opts.GitHubDispatch.Workflow
+	n.Ref = opts.GitHubDispatch.Ref
+	n.Inputs = inputs
+	return n, nil
+}
+
+func gitLabNotifierFunc(opts FactoryOptions) (Interface, error) {
+	if opts.Token == "" && opts.Password != "" {
+		opts.Token = opts.Password
+	}
+	return NewGitLab(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool, opts.TokenType)
 }
 
-func gitLabNotifierFunc(opts notifierOptions) (Interface, error) {
+func gitLabTriggerNotifierFunc(opts FactoryOptions) (Interface, error) {
 	if opts.Token == "" && opts.Password != "" {
 		opts.Token = opts.Password
 	}
-	return NewGitLab(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool)
+	return NewGitLabTrigger(opts.URL, opts.Token, opts.CertPool)
 }
 
-func giteaNotifierFunc(opts notifierOptions) (Interface, error) {
+func giteaNotifierFunc(opts FactoryOptions) (Interface, error) {
 	if opts.Token == "" && opts.Password != "" {
 		opts.Token = opts.Password
 	}
 	return NewGitea(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool)
 }
 
-func bitbucketServerNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewBitbucketServer(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool, opts.Username, opts.Password)
+func fluxRelayNotifierFunc(opts FactoryOptions) (Interface, error) {
+	return NewFluxRelay(opts.URL, opts.ProxyURL, opts.Channel, opts.Headers, opts.CertPool, opts.ClientCert)
+}
+
+func cloudEventsNotifierFunc(opts FactoryOptions) (Interface, error) {
+	return NewCloudEvents(opts.URL, opts.ProxyURL, opts.Channel, opts.Username, opts.Headers, opts.CertPool)
+}
+
+func backstageNotifierFunc(opts FactoryOptions) (Interface, error) {
+	return NewBackstage(opts.URL, opts.ProxyURL, opts.CertPool, opts.Token)
+}
+
+func portNotifierFunc(opts FactoryOptions) (Interface, error) {
+	return NewPort(opts.URL, opts.ProxyURL, opts.CertPool, opts.Channel, opts.Token)
+}
+
+func forgejoNotifierFunc(opts FactoryOptions) (Interface, error) {
+	if opts.Token == "" && opts.Password != "" {
+		opts.Token = opts.Password
+	}
+	return NewForgejo(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool)
+}
+
+func bitbucketServerNotifierFunc(opts FactoryOptions) (Interface, error) {
+	return NewBitbucketServer(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool, opts.Username, opts.Password, opts.CommitStatusKey)
 }
 
-func bitbucketNotifierFunc(opts notifierOptions) (Interface, error) {
+func bitbucketNotifierFunc(opts FactoryOptions) (Interface, error) {
 	return NewBitbucket(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool)
 }
 
-func azureDevOpsNotifierFunc(opts notifierOptions) (Interface, error) {
-	return NewAzureDevOps(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool)
+func gerritNotifierFunc(opts FactoryOptions) (Interface, error) {
+	if opts.Password == "" && opts.Token != "" {
+		opts.Password = opts.Token
+	}
+	return NewGerrit(opts.URL, opts.Channel, opts.Username, opts.Password, opts.CertPool)
+}
+
+func jenkinsNotifierFunc(opts FactoryOptions) (Interface, error) {
+	if opts.Token == "" && opts.Password != "" {
+		opts.Token = opts.Password
+	}
+	return NewJenkins(opts.URL, opts.Username, opts.Token, opts.CertPool)
+}
+
+func statusBoardNotifierFunc(opts FactoryOptions) (Interface, error) {
+	return NewStatusBoard(opts.KubeClient, opts.Namespace, opts.URL)
+}
+
+func azureDevOpsNotifierFunc(opts FactoryOptions) (Interface, error) {
+	return NewAzureDevOps(opts.ProviderUID, opts.URL, opts.Token, opts.CertPool, opts.TokenType)
 }
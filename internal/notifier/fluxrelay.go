@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+// fluxRelayClusterKey is the metadata key, prefixed with the Event API
+// group, carrying the name of the cluster the relayed events come from.
+const fluxRelayClusterKey = "cluster"
+
+// fluxRelayMaxBufferedEvents is the maximum number of undelivered events kept
+// on disk per Provider, the oldest events are dropped first.
+const fluxRelayMaxBufferedEvents = 1000
+
+// fluxRelayBufferLocks serializes the access to the buffer directories, as
+// the events of a Provider can be dispatched concurrently.
+var fluxRelayBufferLocks sync.Map
+
+// FluxRelay is an implementation of the notification Interface that forwards
+// the events to the event endpoint of a notification-controller, usually
+// running in a management cluster. The events are sent gzipped, annotated
+// with the name of the source cluster and authenticated with an optional
+// client certificate. When BufferDir is set, the events that can't be
+// delivered are written to disk and sent again, in order, before the next
+// events.
+type FluxRelay struct {
+	URL       string
+	ProxyURL  string
+	Cluster   string
+	Headers   map[string]string
+	TLSConfig *tls.Config
+	BufferDir string
+}
+
+func NewFluxRelay(hookURL string, proxyURL string, cluster string, headers map[string]string, certPool *x509.CertPool, clientCert *tls.Certificate) (*FluxRelay, error) {
+	if _, err := url.ParseRequestURI(hookURL); err != nil {
+		return nil, fmt.Errorf("invalid hook URL %s: %w", hookURL, err)
+	}
+
+	tlsConfig := newTLSConfig(certPool)
+	if clientCert != nil {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.Certificates = []tls.Certificate{*clientCert}
+	}
+
+	return &FluxRelay{
+		URL:       hookURL,
+		ProxyURL:  proxyURL,
+		Cluster:   cluster,
+		Headers:   headers,
+		TLSConfig: tlsConfig,
+	}, nil
+}
+
+// Post forwards the event, after the buffered ones if any. The event is
+// buffered instead of failing when it can't be delivered and a buffer
+// directory is set.
+func (f *FluxRelay) Post(ctx context.Context, event eventv1.Event) error {
+	if f.Cluster != "" {
+		metadata := make(map[string]string, len(event.Metadata)+1)
+		maps.Copy(metadata, event.Metadata)
+		metadata[eventv1.Group+"/"+fluxRelayClusterKey] = f.Cluster
+		event.Metadata = metadata
+	}
+
+	if f.BufferDir == "" {
+		return f.send(ctx, event)
+	}
+
+	lock, _ := fluxRelayBufferLocks.LoadOrStore(f.BufferDir, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	// Keep the events in order by buffering the event behind the pending
+	// ones if they can't all be delivered.
+	err := f.flush(ctx)
+	if err == nil {
+		err = f.send(ctx, event)
+	}
+	if err != nil {
+		if bufErr := f.buffer(event); bufErr != nil {
+			return errors.Join(err, bufErr)
+		}
+	}
+	return nil
+}
+
+// send posts the given event to the relay address.
+func (f *FluxRelay) send(ctx context.Context, event eventv1.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed marshalling event: %w", err)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, f.URL, body)
+	if err != nil {
+		return fmt.Errorf("failed to create a new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(NotificationHeader, event.ReportingController)
+	req.Header.Set(IdempotencyKeyHeader, fmt.Sprintf("%x", sha256.Sum256(body)))
+	req.Header.Set(EventVersionHeader, EventVersion)
+	for key, val := range f.Headers {
+		req.Header.Set(key, val)
+	}
+	if err := gzipBody(req); err != nil {
+		return fmt.Errorf("failed to compress the request body: %w", err)
+	}
+	req.Header.Set("Content-Encoding", "gzip")
+
+	client, err := f.newClient()
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// newClient returns an HTTP client using the proxy and TLS configuration of
+// the relay.
+func (f *FluxRelay) newClient() (*retryablehttp.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = f.TLSConfig
+	if f.ProxyURL != "" {
+		proxyURL, err := url.Parse(f.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse proxy URL '%s', error: %w", f.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	client := retryablehttp.NewClient()
+	client.HTTPClient.Transport = newUserAgentTransport(transport)
+	// Disable the timeout for the HTTP client,
+	// as we set the provider timeout on the context.
+	client.HTTPClient.Timeout = 0
+	client.RetryWaitMin = 2 * time.Second
+	client.RetryWaitMax = 30 * time.Second
+	client.RetryMax = 4
+	client.Logger = nil
+	return client, nil
+}
+
+// bufferedEvents returns the paths of the buffered events, oldest first.
+func (f *FluxRelay) bufferedEvents() ([]string, error) {
+	entries, err := os.ReadDir(f.BufferDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".json") {
+			paths = append(paths, filepath.Join(f.BufferDir, entry.Name()))
+		}
+	}
+	return paths, nil
+}
+
+// flush sends the buffered events, oldest first, and stops at the first
+// one that can't be delivered.
+func (f *FluxRelay) flush(ctx context.Context) error {
+	paths, err := f.bufferedEvents()
+	if err != nil {
+		return fmt.Errorf("failed to read the buffered events: %w", err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read the buffered event: %w", err)
+		}
+		var event eventv1.Event
+		if err := json.Unmarshal(data, &event); err == nil {
+			if err := f.send(ctx, event); err != nil {
+				return err
+			}
+		}
+		// Corrupted events are dropped.
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove the buffered event: %w", err)
+		}
+	}
+	return nil
+}
+
+// buffer writes the given event to the buffer directory, dropping the oldest
+// events beyond fluxRelayMaxBufferedEvents.
+func (f *FluxRelay) buffer(event eventv1.Event) error {
+	if err := os.MkdirAll(f.BufferDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create the buffer directory: %w", err)
+	}
+
+	paths, err := f.bufferedEvents()
+	if err != nil {
+		return fmt.Errorf("failed to read the buffered events: %w", err)
+	}
+	for len(paths) >= fluxRelayMaxBufferedEvents {
+		if err := os.Remove(paths[0]); err != nil {
+			return fmt.Errorf("failed to remove the buffered event: %w", err)
+		}
+		paths = paths[1:]
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed marshalling event: %w", err)
+	}
+	name := fmt.Sprintf("%020d-%x", time.Now().UnixNano(), sha256.Sum256(data))
+	tmp := filepath.Join(f.BufferDir, name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to buffer the event: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(f.BufferDir, name+".json")); err != nil {
+		return fmt.Errorf("failed to buffer the event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+func TestFluxRelay_Post(t *testing.T) {
+	var received []eventv1.Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		require.Equal(t, "source-controller", r.Header.Get(NotificationHeader))
+		require.NotEmpty(t, r.Header.Get(IdempotencyKeyHeader))
+		require.Equal(t, EventVersion, r.Header.Get(EventVersionHeader))
+		require.Equal(t, "token", r.Header.Get("Authorization"))
+
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		var event eventv1.Event
+		require.NoError(t, json.NewDecoder(gz).Decode(&event))
+		received = append(received, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	relay, err := NewFluxRelay(ts.URL, "", "staging", map[string]string{"Authorization": "token"}, nil, nil)
+	require.NoError(t, err)
+
+	event := testEvent()
+	require.NoError(t, relay.Post(context.TODO(), event))
+	require.Len(t, received, 1)
+	require.Equal(t, "staging", received[0].Metadata[eventv1.Group+"/cluster"])
+	require.Equal(t, "metadata", received[0].Metadata["test"])
+
+	// The metadata of the original event is left untouched.
+	require.NotContains(t, event.Metadata, eventv1.Group+"/cluster")
+}
+
+func TestFluxRelay_PostBuffered(t *testing.T) {
+	available := false
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		var event eventv1.Event
+		require.NoError(t, json.NewDecoder(gz).Decode(&event))
+		received = append(received, event.Message)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	relay, err := NewFluxRelay(ts.URL, "", "", nil, nil, nil)
+	require.NoError(t, err)
+	relay.BufferDir = t.TempDir()
+
+	post := func(msg string, timeout time.Duration) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		event := testEvent()
+		event.Message = msg
+		require.NoError(t, relay.Post(ctx, event))
+	}
+
+	// The undelivered events are buffered.
+	post("first", 100*time.Millisecond)
+	post("second", 100*time.Millisecond)
+	entries, err := os.ReadDir(relay.BufferDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Empty(t, received)
+
+	// The buffered events are delivered in order before the next event.
+	available = true
+	post("third", time.Minute)
+	require.Equal(t, []string{"first", "second", "third"}, received)
+	entries, err = os.ReadDir(relay.BufferDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestFluxRelay_PostUnbuffered(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	relay, err := NewFluxRelay(ts.URL, "", "", nil, nil, nil)
+	require.NoError(t, err)
+
+	err = relay.Post(context.TODO(), testEvent())
+	require.ErrorContains(t, err, "status code 403")
+}
+
+func TestNewFluxRelay(t *testing.T) {
+	_, err := NewFluxRelay("not a url", "", "", nil, nil, nil)
+	require.Error(t, err)
+
+	relay, err := NewFluxRelay("https://example.com", "", "", nil, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, relay.TLSConfig)
+}
@@ -17,23 +17,73 @@ limitations under the License.
 package notifier
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"net/url"
+	"strings"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 
 	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/fluxcd/notification-controller/internal/fips"
 )
 
 // NotificationHeader is a header sent to identify requests from the
 // notification controller.
 const NotificationHeader = "gotk-component"
 
+// IdempotencyKeyHeader is a header carrying a key derived from the event, so
+// that a receiving notification-controller can discard the events delivered
+// more than once, e.g. when a request is retried after a timeout.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// EventVersionHeader is a header carrying the version of the event payload,
+// so that a receiving notification-controller can reject the versions it
+// doesn't support.
+const EventVersionHeader = "X-Flux-Event-Version"
+
+// EventVersion is the version of the event payload sent in the
+// EventVersionHeader.
+const EventVersion = eventv1.Group + "/v1beta1"
+
+// DefaultHMACHeader is the header carrying the HMAC signature of the
+// requests of the generic-hmac notifier.
+const DefaultHMACHeader = "X-Signature"
+
+// DefaultHMACAlgorithm is the hash function of the HMAC signature of the
+// requests of the generic-hmac notifier.
+const DefaultHMACAlgorithm = "sha256"
+
+// hmacAlgorithms maps the supported HMAC algorithms to their hash function.
+var hmacAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// HMACOptions configures the signature of the requests of the generic-hmac
+// notifier, for receivers expecting e.g. an X-Hub-Signature header with a
+// SHA-1 HMAC.
+type HMACOptions struct {
+	// Header is the header carrying the signature, DefaultHMACHeader
+	// when empty.
+	Header string
+	// Algorithm is the hash function of the HMAC, one of sha1, sha256 and
+	// sha512, DefaultHMACAlgorithm when empty.
+	Algorithm string
+}
+
 // Forwarder is an implementation of the notification Interface that posts the
 // body as an HTTP request using an optional proxy.
 type Forwarder struct {
@@ -42,6 +92,18 @@ type Forwarder struct {
 	Headers  map[string]string
 	CertPool *x509.CertPool
 	HMACKey  []byte
+
+	// HMACHeader is the header carrying the signature, DefaultHMACHeader
+	// when empty.
+	HMACHeader string
+
+	// HMACAlgorithm is the hash function of the signature,
+	// DefaultHMACAlgorithm when empty.
+	HMACAlgorithm string
+
+	// EncryptionKey is an optional RSA public key, the body is sent
+	// encrypted in the JWE compact serialization when set.
+	EncryptionKey *rsa.PublicKey
 }
 
 func NewForwarder(hookURL string, proxyURL string, headers map[string]string, certPool *x509.CertPool, hmacKey []byte) (*Forwarder, error) {
@@ -62,33 +124,103 @@ func NewForwarder(hookURL string, proxyURL string, headers map[string]string, ce
 	}, nil
 }
 
-func sign(payload, key []byte) string {
-	h := hmac.New(sha256.New, key)
+func sign(newHash func() hash.Hash, payload, key []byte) string {
+	h := hmac.New(newHash, key)
 	h.Write(payload)
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// signature returns the name of the header carrying the HMAC signature of
+// the given payload, and its value in the form <algorithm>=<hex-encoded HMAC>.
+func (f *Forwarder) signature(payload []byte) (string, string, error) {
+	header := f.HMACHeader
+	if header == "" {
+		header = DefaultHMACHeader
+	}
+	algorithm := f.HMACAlgorithm
+	if algorithm == "" {
+		algorithm = DefaultHMACAlgorithm
+	}
+	newHash, ok := hmacAlgorithms[algorithm]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported HMAC algorithm '%s'", algorithm)
+	}
+	if err := fips.CheckHMACAlgorithm(algorithm); err != nil {
+		return "", "", err
+	}
+	return header, fmt.Sprintf("%s=%s", algorithm, sign(newHash, payload, f.HMACKey)), nil
+}
+
 func (f *Forwarder) Post(ctx context.Context, event eventv1.Event) error {
-	var sig string
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed marshalling event: %w", err)
+	}
+	idempotencyKey := fmt.Sprintf("%x", sha256.Sum256(eventJSON))
+
+	body := eventJSON
+	var encrypted string
+	if f.EncryptionKey != nil {
+		encrypted, err = encryptJWE(eventJSON, f.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("failed encrypting event: %w", err)
+		}
+		body = []byte(encrypted)
+	}
+
+	var sigHeader, sig string
 	if len(f.HMACKey) != 0 {
-		eventJSON, err := json.Marshal(event)
+		sigHeader, sig, err = f.signature(body)
 		if err != nil {
-			return fmt.Errorf("failed marshalling event: %w", err)
+			return err
 		}
-		sig = fmt.Sprintf("sha256=%s", sign(eventJSON, f.HMACKey))
 	}
-	err := postMessage(ctx, f.URL, f.ProxyURL, f.CertPool, event, func(req *retryablehttp.Request) {
+	err = postMessage(ctx, f.URL, f.ProxyURL, f.CertPool, event, func(req *retryablehttp.Request) {
+		if encrypted != "" {
+			_ = req.SetBody(body)
+			req.Header.Set("Content-Type", JWEContentType)
+		}
 		req.Header.Set(NotificationHeader, event.ReportingController)
+		req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
 		for key, val := range f.Headers {
 			req.Header.Set(key, val)
 		}
 		if sig != "" {
-			req.Header.Set("X-Signature", sig)
+			req.Header.Set(sigHeader, sig)
 		}
-	})
+	}, f.compressRequest)
 
 	if err != nil {
 		return fmt.Errorf("postMessage failed: %w", err)
 	}
 	return nil
 }
+
+// compressRequest gzips the request body when the Content-Encoding header
+// of the Forwarder is set to gzip. The HMAC signature, if any, is computed
+// on the uncompressed body, after encryption. The body is sent uncompressed,
+// without the Content-Encoding header, if it can't be compressed.
+func (f *Forwarder) compressRequest(req *retryablehttp.Request) {
+	if !strings.EqualFold(req.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+	if err := gzipBody(req); err != nil {
+		req.Header.Del("Content-Encoding")
+	}
+}
+
+func gzipBody(req *retryablehttp.Request) error {
+	body, err := req.BodyBytes()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(body); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return req.SetBody(buf.Bytes())
+}
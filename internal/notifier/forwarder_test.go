@@ -17,8 +17,16 @@ limitations under the License.
 package notifier
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -77,6 +85,7 @@ func TestForwarder_Post(t *testing.T) {
 		hmacKey    []byte
 		hmacHeader string
 		xSigHeader string
+		gzip       bool
 	}{
 		{
 			name: "happy path with nil HMAC key",
@@ -91,16 +100,30 @@ func TestForwarder_Post(t *testing.T) {
 			hmacHeader: "sha256=65b018549b1254e7226d1c08f9567ee45bc9de0fc4e7b1a40253f9a018b08be7",
 			xSigHeader: "should be overwritten with actual signature",
 		},
+		{
+			name:       "gzip content encoding compresses the body",
+			hmacKey:    []byte("7152fed34dd6149a7c75a276c510da27cb6f82b0"),
+			hmacHeader: "sha256=65b018549b1254e7226d1c08f9567ee45bc9de0fc4e7b1a40253f9a018b08be7",
+			gzip:       true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				b, err := io.ReadAll(r.Body)
+				body := r.Body
+				if tt.gzip {
+					require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+					gz, err := gzip.NewReader(r.Body)
+					require.NoError(t, err)
+					body = gz
+				}
+				b, err := io.ReadAll(body)
 				require.NoError(t, err)
 
 				require.Equal(t, "source-controller", r.Header.Get("gotk-component"))
 				require.Equal(t, "token", r.Header.Get("Authorization"))
+				require.Equal(t, fmt.Sprintf("%x", sha256.Sum256(b)), r.Header.Get(IdempotencyKeyHeader))
 				if tt.hmacHeader == "" {
 					sigHdrVal, ok := r.Header["X-Signature"]
 					if tt.xSigHeader == "" {
@@ -124,6 +147,9 @@ func TestForwarder_Post(t *testing.T) {
 			if tt.xSigHeader != "" {
 				headers["X-Signature"] = tt.xSigHeader
 			}
+			if tt.gzip {
+				headers["Content-Encoding"] = "gzip"
+			}
 			forwarder, err := NewForwarder(ts.URL, "", headers, nil, tt.hmacKey)
 			require.NoError(t, err)
 
@@ -134,3 +160,70 @@ func TestForwarder_Post(t *testing.T) {
 		})
 	}
 }
+
+func TestForwarder_HMACOptions(t *testing.T) {
+	key := []byte("7152fed34dd6149a7c75a276c510da27cb6f82b0")
+	tests := []struct {
+		name      string
+		header    string
+		algorithm string
+		wantErr   bool
+		sigHeader string
+		prefix    string
+		newHash   func() hash.Hash
+	}{
+		{
+			name:      "defaults",
+			sigHeader: "X-Signature",
+			prefix:    "sha256=",
+			newHash:   sha256.New,
+		},
+		{
+			name:      "X-Hub-Signature with sha1",
+			header:    "X-Hub-Signature",
+			algorithm: "sha1",
+			sigHeader: "X-Hub-Signature",
+			prefix:    "sha1=",
+			newHash:   sha1.New,
+		},
+		{
+			name:      "sha512",
+			algorithm: "sha512",
+			sigHeader: "X-Signature",
+			prefix:    "sha512=",
+			newHash:   sha512.New,
+		},
+		{
+			name:      "unsupported algorithm",
+			algorithm: "md5",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				mac := hmac.New(tt.newHash, key)
+				mac.Write(b)
+				require.Equal(t, tt.prefix+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(tt.sigHeader))
+			}))
+			defer ts.Close()
+
+			opts := FactoryOptions{
+				URL:   ts.URL,
+				Token: string(key),
+				HMAC:  HMACOptions{Header: tt.header, Algorithm: tt.algorithm},
+			}
+			n, err := genericHMACNotifierFunc(opts)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, n.Post(context.TODO(), testEvent()))
+		})
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+)
+
+// gerritMagicPrefix is prepended by Gerrit to its JSON responses
+// to prevent cross-site script inclusion.
+const gerritMagicPrefix = ")]}'"
+
+// gerritReviewTag marks the reviews posted by the notifier as automated,
+// so that Gerrit can filter them out of the change messages.
+const gerritReviewTag = "autogenerated:flux"
+
+// Gerrit is a notifier posting reviews on the Gerrit change of the revision
+// of the events.
+type Gerrit struct {
+	URL      *url.URL
+	Label    string
+	Username string
+	Password string
+	Client   *retryablehttp.Client
+}
+
+type gerritChange struct {
+	Number int `json:"_number"`
+}
+
+type gerritReviewInput struct {
+	Message string         `json:"message,omitempty"`
+	Labels  map[string]int `json:"labels,omitempty"`
+	Tag     string         `json:"tag,omitempty"`
+}
+
+// NewGerrit creates and returns a new Gerrit notifier. The address is the
+// URL of the Gerrit server. When the label is set, the reviews vote +1 on
+// it for info events and -1 for error events, otherwise they only comment.
+func NewGerrit(addr string, label string, username string, password string, certPool *x509.CertPool) (*Gerrit, error) {
+	if username == "" || password == "" {
+		return nil, errors.New("gerrit username and password cannot be empty")
+	}
+
+	u, err := url.ParseRequestURI(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Gerrit URL %q: %w", addr, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid Gerrit URL %q: scheme must be http or https", addr)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	httpClient := retryablehttp.NewClient()
+	if tlsConfig := newTLSConfig(certPool); tlsConfig != nil {
+		httpClient.HTTPClient.Transport = &http.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	}
+	httpClient.HTTPClient.Transport = newUserAgentTransport(httpClient.HTTPClient.Transport)
+	// Disable the timeout for the HTTP client,
+	// as we set the provider timeout on the context.
+	httpClient.HTTPClient.Timeout = 0
+	httpClient.RetryWaitMin = 2 * time.Second
+	httpClient.RetryWaitMax = 30 * time.Second
+	httpClient.RetryMax = 4
+	httpClient.Logger = nil
+
+	return &Gerrit{
+		URL:      u,
+		Label:    label,
+		Username: username,
+		Password: password,
+		Client:   httpClient,
+	}, nil
+}
+
+// Post posts a review on the Gerrit change of the event revision.
+// Revisions not belonging to a change, e.g. pushed directly to a branch,
+// are ignored.
+func (g *Gerrit) Post(ctx context.Context, event eventv1.Event) error {
+	// Skip progressing events
+	if event.HasReason(meta.ProgressingReason) {
+		return nil
+	}
+
+	revString, ok := event.Metadata[eventv1.MetaRevisionKey]
+	if !ok {
+		return errors.New("missing revision metadata")
+	}
+	rev, err := parseRevision(revString)
+	if err != nil {
+		return err
+	}
+	vote, err := toGerritVote(event.Severity)
+	if err != nil {
+		return err
+	}
+
+	change, err := g.findChange(ctx, rev)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return nil
+	}
+
+	name, desc := formatNameAndDescription(event)
+	review := gerritReviewInput{
+		Message: fmt.Sprintf("%s: %s\n\n%s", name, desc, event.Message),
+		Tag:     gerritReviewTag,
+	}
+	if g.Label != "" {
+		review.Labels = map[string]int{g.Label: vote}
+	}
+
+	body, err := json.Marshal(review)
+	if err != nil {
+		return fmt.Errorf("marshalling review failed: %w", err)
+	}
+	reviewURL := g.URL.JoinPath("a", "changes", strconv.Itoa(change.Number), "revisions", rev, "review")
+	if _, err := g.do(ctx, http.MethodPost, reviewURL.String(), body); err != nil {
+		return fmt.Errorf("could not post review: %w", err)
+	}
+	return nil
+}
+
+// findChange returns the change of the given commit, or nil if the commit
+// doesn't belong to any change.
+func (g *Gerrit) findChange(ctx context.Context, commit string) (*gerritChange, error) {
+	queryURL := g.URL.JoinPath("a", "changes", "/")
+	queryURL.RawQuery = url.Values{"q": {"commit:" + commit}, "n": {"1"}}.Encode()
+
+	body, err := g.do(ctx, http.MethodGet, queryURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not query changes: %w", err)
+	}
+
+	var changes []gerritChange
+	body = bytes.TrimPrefix(body, []byte(gerritMagicPrefix))
+	if err := json.Unmarshal(body, &changes); err != nil {
+		return nil, fmt.Errorf("could not decode changes: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return &changes[0], nil
+}
+
+// do sends an authenticated request to the Gerrit REST API and returns the
+// response body.
+func (g *Gerrit) do(ctx context.Context, method, u string, body []byte) ([]byte, error) {
+	var reqBody interface{}
+	if body != nil {
+		reqBody = body
+	}
+	req, err := retryablehttp.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(g.Username, g.Password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusCodeError(resp.StatusCode, b)
+	}
+	return b, nil
+}
+
+func toGerritVote(severity string) (int, error) {
+	switch severity {
+	case eventv1.EventSeverityInfo:
+		return 1, nil
+	case eventv1.EventSeverityError:
+		return -1, nil
+	default:
+		return 0, errors.New("can't convert to gerrit vote")
+	}
+}
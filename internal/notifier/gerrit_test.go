@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/stretchr/testify/require"
+)
+
+const gerritTestCommit = "5394cb7f48332b2de7c17dd8b8384bbc84b7e738"
+
+func TestNewGerrit(t *testing.T) {
+	g, err := NewGerrit("https://gerrit.example.com/r/", "Verified", "flux", "secret", nil)
+	require.NoError(t, err)
+	require.Equal(t, "/r", g.URL.Path)
+
+	_, err = NewGerrit("https://gerrit.example.com", "Verified", "flux", "", nil)
+	require.ErrorContains(t, err, "cannot be empty")
+
+	_, err = NewGerrit("ssh://gerrit.example.com:29418", "Verified", "flux", "secret", nil)
+	require.ErrorContains(t, err, "scheme must be http or https")
+}
+
+func TestGerrit_Post(t *testing.T) {
+	tests := []struct {
+		name       string
+		label      string
+		severity   string
+		changes    string
+		wantReview *gerritReviewInput
+	}{
+		{
+			name:     "vote on info",
+			label:    "Verified",
+			severity: eventv1.EventSeverityInfo,
+			changes:  `[{"_number":42}]`,
+			wantReview: &gerritReviewInput{
+				Message: "kustomization/hello-world: reason\n\nmessage",
+				Labels:  map[string]int{"Verified": 1},
+				Tag:     gerritReviewTag,
+			},
+		},
+		{
+			name:     "vote on error",
+			label:    "Verified",
+			severity: eventv1.EventSeverityError,
+			changes:  `[{"_number":42}]`,
+			wantReview: &gerritReviewInput{
+				Message: "kustomization/hello-world: reason\n\nmessage",
+				Labels:  map[string]int{"Verified": -1},
+				Tag:     gerritReviewTag,
+			},
+		},
+		{
+			name:     "comment without label",
+			severity: eventv1.EventSeverityInfo,
+			changes:  `[{"_number":42}]`,
+			wantReview: &gerritReviewInput{
+				Message: "kustomization/hello-world: reason\n\nmessage",
+				Tag:     gerritReviewTag,
+			},
+		},
+		{
+			name:     "commit without change",
+			label:    "Verified",
+			severity: eventv1.EventSeverityInfo,
+			changes:  `[]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var review *gerritReviewInput
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, pass, ok := r.BasicAuth()
+				require.True(t, ok)
+				require.Equal(t, "flux", user)
+				require.Equal(t, "secret", pass)
+
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/r/a/changes/":
+					require.Equal(t, "commit:"+gerritTestCommit, r.URL.Query().Get("q"))
+					fmt.Fprintf(w, "%s\n%s", gerritMagicPrefix, tt.changes)
+				case r.Method == http.MethodPost && r.URL.Path == "/r/a/changes/42/revisions/"+gerritTestCommit+"/review":
+					review = &gerritReviewInput{}
+					require.NoError(t, json.NewDecoder(r.Body).Decode(review))
+					fmt.Fprintf(w, "%s\n{}", gerritMagicPrefix)
+				default:
+					t.Errorf("unexpected %s request at %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer ts.Close()
+
+			g, err := NewGerrit(ts.URL+"/r", tt.label, "flux", "secret", nil)
+			require.NoError(t, err)
+
+			err = g.Post(context.TODO(), generateTestEventKustomization(tt.severity, map[string]string{
+				eventv1.MetaRevisionKey: "main@sha1:" + gerritTestCommit,
+			}))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantReview, review)
+		})
+	}
+}
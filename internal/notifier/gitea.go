@@ -18,7 +18,6 @@ package notifier
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
@@ -28,6 +27,8 @@ import (
 	"strings"
 
 	"code.gitea.io/sdk/gitea"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -46,6 +47,17 @@ type Gitea struct {
 var _ Interface = &Gitea{}
 
 func NewGitea(providerUID string, addr string, token string, certPool *x509.CertPool) (*Gitea, error) {
+	return newGitea(providerUID, addr, token, certPool, false)
+}
+
+// NewForgejo returns a Gitea notifier for a Forgejo server, e.g. Codeberg.
+// Forgejo versions diverge from the Gitea ones, the Gitea SDK is configured
+// with the Gitea version the Forgejo API is compatible with instead.
+func NewForgejo(providerUID string, addr string, token string, certPool *x509.CertPool) (*Gitea, error) {
+	return newGitea(providerUID, addr, token, certPool, true)
+}
+
+func newGitea(providerUID string, addr string, token string, certPool *x509.CertPool, forgejo bool) (*Gitea, error) {
 	if len(token) == 0 {
 		return nil, errors.New("gitea token cannot be empty")
 	}
@@ -64,19 +76,21 @@ func NewGitea(providerUID string, addr string, token string, certPool *x509.Cert
 		return nil, fmt.Errorf("invalid repository id %q", id)
 	}
 
-	client, err := gitea.NewClient(host, gitea.SetToken(token))
+	var client *gitea.Client
+	if forgejo {
+		client, err = newForgejoClient(host, token, certPool)
+	} else {
+		client, err = gitea.NewClient(host, gitea.SetToken(token))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed creating Gitea client: %w", err)
 	}
 
-	if certPool != nil {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: certPool,
-			},
-		}
-		client.SetHTTPClient(&http.Client{Transport: tr})
+	provider := apiv1.GiteaProvider
+	if forgejo {
+		provider = apiv1.ForgejoProvider
 	}
+	client.SetHTTPClient(newGiteaHTTPClient(certPool, provider))
 
 	return &Gitea{
 		BaseURL:     host,
@@ -89,12 +103,52 @@ func NewGitea(providerUID string, addr string, token string, certPool *x509.Cert
 	}, nil
 }
 
-func (g *Gitea) Post(ctx context.Context, event eventv1.Event) error {
-	revString, ok := event.Metadata[eventv1.MetaRevisionKey]
-	if !ok {
-		return errors.New("missing revision metadata")
+// newForgejoClient returns a Gitea SDK client for the Forgejo server at the
+// given host, set to the Gitea version of the Forgejo API.
+func newForgejoClient(host string, token string, certPool *x509.CertPool) (*gitea.Client, error) {
+	opts := []gitea.ClientOption{
+		gitea.SetToken(token),
+		gitea.SetHTTPClient(newGiteaHTTPClient(certPool, apiv1.ForgejoProvider)),
+	}
+
+	// Skip the version checks of the SDK to read the Forgejo version.
+	client, err := gitea.NewClient(host, append(opts, gitea.SetGiteaVersion(""))...)
+	if err != nil {
+		return nil, err
 	}
-	rev, err := parseRevision(revString)
+	raw, _, err := client.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading Forgejo version: %w", err)
+	}
+
+	return gitea.NewClient(host, append(opts, gitea.SetGiteaVersion(forgejoGiteaVersion(raw)))...)
+}
+
+// forgejoGiteaVersion returns the Gitea version the given Forgejo version is
+// compatible with. Forgejo 7 and later append it as build metadata, e.g.
+// 7.0.0+gitea-1.22.0, while the versions before follow the Gitea ones.
+func forgejoGiteaVersion(v string) string {
+	if _, compat, ok := strings.Cut(v, "+gitea-"); ok {
+		return compat
+	}
+	return v
+}
+
+// newGiteaHTTPClient returns the HTTP client of the Gitea SDK, which reads
+// the server version without the context given to Post, hence the provider
+// type set on the User-Agent transport.
+func newGiteaHTTPClient(certPool *x509.CertPool, provider string) *http.Client {
+	tr := http.DefaultTransport
+	if tlsConfig := newTLSConfig(certPool); tlsConfig != nil {
+		tr = &http.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	}
+	return &http.Client{Transport: &userAgentTransport{base: tr, provider: provider}}
+}
+
+func (g *Gitea) Post(ctx context.Context, event eventv1.Event) error {
+	rev, err := parseCommitRevision(event)
 	if err != nil {
 		return err
 	}
@@ -113,6 +167,9 @@ func (g *Gitea) Post(ctx context.Context, event eventv1.Event) error {
 		Context:     id,
 	}
 
+	// The SDK sends its requests with the context of the client.
+	g.Client.SetContext(ctx)
+
 	listStatusesOpts := gitea.ListStatusesOption{
 		ListOptions: gitea.ListOptions{
 			Page:     0,
@@ -102,3 +102,46 @@ func TestGitea_Post(t *testing.T) {
 	err = g.Post(context.Background(), event)
 	assert.NoError(t, err)
 }
+
+func TestGitea_PostCanceled(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	g, err := NewGitea("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", srv.URL+"/foo/bar", "foobar", nil)
+	assert.Nil(t, err)
+
+	event := eventv1.Event{
+		Severity: "info",
+		Metadata: map[string]string{
+			eventv1.MetaRevisionKey: "main@sha1:69b59063470310ebbd88a9156325322a124e55a3",
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = g.Post(ctx, event)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewForgejo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/version":
+			fmt.Fprintf(w, `{"version":"7.0.5+gitea-1.21.0"}`)
+		default:
+			t.Logf("unknown %s request at %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	g, err := NewForgejo("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", srv.URL+"/foo/bar", "foobar", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, g.Owner, "foo")
+	assert.Equal(t, g.Repo, "bar")
+	assert.NoError(t, g.Client.CheckServerVersionConstraint("= 1.21.0"))
+}
+
+func TestForgejoGiteaVersion(t *testing.T) {
+	assert.Equal(t, "1.22.0", forgejoGiteaVersion("9.0.0+gitea-1.22.0"))
+	assert.Equal(t, "1.21.0", forgejoGiteaVersion("7.0.0-dev-1234-abcdef+gitea-1.21.0"))
+	assert.Equal(t, "1.20.5-0", forgejoGiteaVersion("1.20.5-0"))
+}
@@ -18,7 +18,6 @@ package notifier
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
@@ -40,53 +39,84 @@ type GitHub struct {
 	Client      *github.Client
 }
 
-func NewGitHub(providerUID string, addr string, token string, certPool *x509.CertPool) (*GitHub, error) {
+func NewGitHub(providerUID string, addr string, token string, certPool *x509.CertPool, apiBaseURL string) (*GitHub, error) {
+	client, owner, repo, err := newGitHubClient(addr, token, certPool, apiBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitHub{
+		Owner:       owner,
+		Repo:        repo,
+		ProviderUID: providerUID,
+		Client:      client,
+	}, nil
+}
+
+// newGitHubClient returns a GitHub API client for the repository at the given
+// address, along with the repository owner and name. For GitHub Enterprise
+// Server, the API base URL is derived from the address, including the path
+// under which the instance is hosted, unless apiBaseURL is set, in which case
+// it's used as-is.
+func newGitHubClient(addr string, token string, certPool *x509.CertPool, apiBaseURL string) (*github.Client, string, string, error) {
 	if len(token) == 0 {
-		return nil, errors.New("github token cannot be empty")
+		return nil, "", "", errors.New("github token cannot be empty")
 	}
 
 	host, id, err := parseGitAddress(addr)
 	if err != nil {
-		return nil, err
+		return nil, "", "", err
 	}
 
 	baseUrl, err := url.Parse(host)
 	if err != nil {
-		return nil, err
+		return nil, "", "", err
 	}
 
 	comp := strings.Split(id, "/")
+	if baseUrl.Host != "github.com" && len(comp) > 2 {
+		// The GitHub Enterprise Server is hosted under a subpath,
+		// e.g. https://example.com/github/org/repo.
+		host = host + "/" + strings.Join(comp[:len(comp)-2], "/")
+		comp = comp[len(comp)-2:]
+	}
 	if len(comp) != 2 {
-		return nil, fmt.Errorf("invalid repository id %q", id)
+		return nil, "", "", fmt.Errorf("invalid repository id %q", id)
+	}
+
+	enterprise := baseUrl.Host != "github.com" || apiBaseURL != ""
+	// The custom CA certificates are only trusted for GitHub Enterprise.
+	if !enterprise {
+		certPool = nil
+	}
+	var tr http.RoundTripper
+	if tlsConfig := newTLSConfig(certPool); tlsConfig != nil {
+		tr = &http.Transport{
+			TLSClientConfig: tlsConfig,
+		}
 	}
 
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(context.Background(), ts)
+	hc := &http.Client{Transport: newUserAgentTransport(tr)}
+	tc := oauth2.NewClient(context.WithValue(context.Background(), oauth2.HTTPClient, hc), ts)
 	client := github.NewClient(tc)
-	if baseUrl.Host != "github.com" {
-		if certPool != nil {
-			tr := &http.Transport{
-				TLSClientConfig: &tls.Config{
-					RootCAs: certPool,
-				},
+	if enterprise {
+		if apiBaseURL != "" {
+			apiURL, err := url.Parse(strings.TrimSuffix(apiBaseURL, "/") + "/")
+			if err != nil {
+				return nil, "", "", fmt.Errorf("invalid GitHub API base URL: %w", err)
+			}
+			client = github.NewClient(tc)
+			client.BaseURL = apiURL
+		} else {
+			client, err = github.NewEnterpriseClient(host, host, tc)
+			if err != nil {
+				return nil, "", "", fmt.Errorf("could not create enterprise GitHub client: %v", err)
 			}
-			hc := &http.Client{Transport: tr}
-			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, hc)
-			tc = oauth2.NewClient(ctx, ts)
-		}
-
-		client, err = github.NewEnterpriseClient(host, host, tc)
-		if err != nil {
-			return nil, fmt.Errorf("could not create enterprise GitHub client: %v", err)
 		}
 	}
 
-	return &GitHub{
-		Owner:       comp[0],
-		Repo:        comp[1],
-		ProviderUID: providerUID,
-		Client:      client,
-	}, nil
+	return client, comp[0], comp[1], nil
 }
 
 // Post Github commit status
@@ -117,23 +147,80 @@ func (g *GitHub) Post(ctx context.Context, event eventv1.Event) error {
 		Description: &desc,
 	}
 
-	opts := &github.ListOptions{PerPage: 50}
-	statuses, _, err := g.Client.Repositories.ListStatuses(ctx, g.Owner, g.Repo, rev, opts)
-	if err != nil {
-		return fmt.Errorf("could not list commit statuses: %v", err)
-	}
-	if duplicateGithubStatus(statuses, status) {
+	return retryCommitNotFound(ctx, isGitHubNotFound, func() error {
+		opts := &github.ListOptions{PerPage: 50}
+		statuses, _, err := g.Client.Repositories.ListStatuses(ctx, g.Owner, g.Repo, rev, opts)
+		if err != nil {
+			return fmt.Errorf("could not list commit statuses: %w", err)
+		}
+		if duplicateGithubStatus(statuses, status) {
+			return nil
+		}
+
+		_, _, err = g.Client.Repositories.CreateStatus(ctx, g.Owner, g.Repo, rev, status)
+		if err != nil {
+			return fmt.Errorf("could not create commit status: %w", err)
+		}
+
 		return nil
-	}
+	})
+}
 
-	_, _, err = g.Client.Repositories.CreateStatus(ctx, g.Owner, g.Repo, rev, status)
+// isGitHubNotFound returns true if the error is a 404 response from the
+// GitHub API, e.g. for a commit that is not yet known to the API.
+func isGitHubNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil &&
+		errResp.Response.StatusCode == http.StatusNotFound
+}
+
+// Preflight verifies that the token has access to the repository. For classic
+// personal access tokens and OAuth tokens, it also verifies that the token has
+// the repo or repo:status scope and that its owner has push access, both being
+// required to create commit statuses. The statuses permission of fine-grained
+// tokens and GitHub Apps can't be verified without writing a commit status.
+func (g *GitHub) Preflight(ctx context.Context) error {
+	repo, resp, err := g.Client.Repositories.Get(ctx, g.Owner, g.Repo)
 	if err != nil {
-		return fmt.Errorf("could not create commit status: %v", err)
+		if resp != nil {
+			switch resp.StatusCode {
+			case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+				return fmt.Errorf("%w: could not get repository '%s/%s': %v",
+					ErrInsufficientPermissions, g.Owner, g.Repo, err)
+			}
+		}
+		return fmt.Errorf("could not get repository '%s/%s': %w", g.Owner, g.Repo, err)
 	}
 
+	scopes, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]
+	if !ok {
+		return nil
+	}
+	if !hasGitHubStatusScope(scopes) {
+		return fmt.Errorf("%w: token must have the 'repo' or 'repo:status' scope, got '%s'",
+			ErrInsufficientPermissions, strings.Join(scopes, ", "))
+	}
+	if perms := repo.GetPermissions(); perms != nil && !perms["push"] {
+		return fmt.Errorf("%w: token owner has no push access to repository '%s/%s'",
+			ErrInsufficientPermissions, g.Owner, g.Repo)
+	}
 	return nil
 }
 
+// hasGitHubStatusScope returns true if the given X-OAuth-Scopes header values
+// grant access to the commit statuses.
+func hasGitHubStatusScope(values []string) bool {
+	for _, v := range values {
+		for _, scope := range strings.Split(v, ",") {
+			switch strings.TrimSpace(scope) {
+			case "repo", "repo:status":
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func toGitHubState(severity string) (string, error) {
 	switch severity {
 	case eventv1.EventSeverityInfo:
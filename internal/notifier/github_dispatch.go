@@ -18,71 +18,54 @@ package notifier
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"net/http"
-	"net/url"
 	"strings"
+	"text/template"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 
 	"github.com/google/go-github/v64/github"
-	"golang.org/x/oauth2"
 )
 
-type GitHubDispatch struct {
-	Owner  string
-	Repo   string
-	Client *github.Client
+// DefaultGitHubDispatchRef is the Git reference the workflows are run for
+// when none is configured.
+const DefaultGitHubDispatchRef = "main"
+
+// GitHubDispatchOptions configures the events sent by the githubdispatch
+// notifier.
+type GitHubDispatchOptions struct {
+	// Workflow is the file name or ID of the workflow triggered with a
+	// workflow_dispatch event. A repository_dispatch event is sent when
+	// empty.
+	Workflow string
+	// Ref is the Git reference the workflow is run for,
+	// DefaultGitHubDispatchRef when empty.
+	Ref string
+	// Inputs maps the workflow inputs to Go templates rendered with the
+	// event.
+	Inputs map[string]string
 }
 
-func NewGitHubDispatch(addr string, token string, certPool *x509.CertPool) (*GitHubDispatch, error) {
-	if len(token) == 0 {
-		return nil, errors.New("github token cannot be empty")
-	}
-
-	host, id, err := parseGitAddress(addr)
-	if err != nil {
-		return nil, err
-	}
+type GitHubDispatch struct {
+	Owner    string
+	Repo     string
+	Client   *github.Client
+	Workflow string
+	Ref      string
+	Inputs   map[string]*template.Template
+}
 
-	baseUrl, err := url.Parse(host)
+func NewGitHubDispatch(addr string, token string, certPool *x509.CertPool, apiBaseURL string) (*GitHubDispatch, error) {
+	client, owner, repo, err := newGitHubClient(addr, token, certPool, apiBaseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	comp := strings.Split(id, "/")
-	if len(comp) != 2 {
-		return nil, fmt.Errorf("invalid repository id %q", id)
-	}
-
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(context.Background(), ts)
-	client := github.NewClient(tc)
-	if baseUrl.Host != "github.com" {
-		if certPool != nil {
-			tr := &http.Transport{
-				TLSClientConfig: &tls.Config{
-					RootCAs: certPool,
-				},
-			}
-			hc := &http.Client{Transport: tr}
-			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, hc)
-			tc = oauth2.NewClient(ctx, ts)
-		}
-
-		client, err = github.NewEnterpriseClient(host, host, tc)
-		if err != nil {
-			return nil, fmt.Errorf("could not create enterprise GitHub client: %v", err)
-		}
-	}
-
 	return &GitHubDispatch{
-		Owner:  comp[0],
-		Repo:   comp[1],
+		Owner:  owner,
+		Repo:   repo,
 		Client: client,
 	}, nil
 }
@@ -97,7 +80,35 @@ func (g *GitHubDispatch) Post(ctx context.Context, event eventv1.Event) error {
 	eventType := fmt.Sprintf("%s/%s.%s",
 		event.InvolvedObject.Kind, event.InvolvedObject.Name, event.InvolvedObject.Namespace)
 
-	eventData, err := json.Marshal(event)
+	inputs, err := g.renderInputs(event)
+	if err != nil {
+		return err
+	}
+
+	if g.Workflow != "" {
+		ref := g.Ref
+		if ref == "" {
+			ref = DefaultGitHubDispatchRef
+		}
+		req := github.CreateWorkflowDispatchEventRequest{
+			Ref:    ref,
+			Inputs: make(map[string]interface{}, len(inputs)),
+		}
+		for k, v := range inputs {
+			req.Inputs[k] = v
+		}
+		_, err = g.Client.Actions.CreateWorkflowDispatchEventByFileName(ctx, g.Owner, g.Repo, g.Workflow, req)
+		if err != nil {
+			return fmt.Errorf("could not send github workflow dispatch event: %v", err)
+		}
+		return nil
+	}
+
+	var payload interface{} = event
+	if len(g.Inputs) > 0 {
+		payload = inputs
+	}
+	eventData, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal object into json: %w", err)
 	}
@@ -115,3 +126,33 @@ func (g *GitHubDispatch) Post(ctx context.Context, event eventv1.Event) error {
 
 	return nil
 }
+
+// renderInputs renders the input templates with the event.
+func (g *GitHubDispatch) renderInputs(event eventv1.Event) (map[string]string, error) {
+	inputs := make(map[string]string, len(g.Inputs))
+	for name, tmpl := range g.Inputs {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, event); err != nil {
+			return nil, fmt.Errorf("failed to render input '%s': %w", name, err)
+		}
+		inputs[name] = sb.String()
+	}
+	return inputs, nil
+}
+
+// parseGitHubDispatchInputs parses the input templates, rendering missing
+// metadata keys as empty strings.
+func parseGitHubDispatchInputs(inputs map[string]string) (map[string]*template.Template, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	tmpls := make(map[string]*template.Template, len(inputs))
+	for name, text := range inputs {
+		tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for input '%s': %w", name, err)
+		}
+		tmpls[name] = tmpl
+	}
+	return tmpls, nil
+}
@@ -18,6 +18,9 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,7 +30,7 @@ import (
 )
 
 func TestNewGitHubDispatchBasic(t *testing.T) {
-	g, err := NewGitHubDispatch("https://github.com/foo/bar", "foobar", nil)
+	g, err := NewGitHubDispatch("https://github.com/foo/bar", "foobar", nil, "")
 	assert.Nil(t, err)
 	assert.Equal(t, g.Owner, "foo")
 	assert.Equal(t, g.Repo, "bar")
@@ -35,7 +38,7 @@ func TestNewGitHubDispatchBasic(t *testing.T) {
 }
 
 func TestNewEnterpriseGitHubDispatchBasic(t *testing.T) {
-	g, err := NewGitHubDispatch("https://foobar.com/foo/bar", "foobar", nil)
+	g, err := NewGitHubDispatch("https://foobar.com/foo/bar", "foobar", nil, "")
 	assert.Nil(t, err)
 	assert.Equal(t, g.Owner, "foo")
 	assert.Equal(t, g.Repo, "bar")
@@ -43,17 +46,17 @@ func TestNewEnterpriseGitHubDispatchBasic(t *testing.T) {
 }
 
 func TestNewGitHubDispatchInvalidUrl(t *testing.T) {
-	_, err := NewGitHubDispatch("https://github.com/foo/bar/baz", "foobar", nil)
+	_, err := NewGitHubDispatch("https://github.com/foo/bar/baz", "foobar", nil, "")
 	assert.NotNil(t, err)
 }
 
 func TestNewGitHubDispatchEmptyToken(t *testing.T) {
-	_, err := NewGitHubDispatch("https://github.com/foo/bar", "", nil)
+	_, err := NewGitHubDispatch("https://github.com/foo/bar", "", nil, "")
 	assert.NotNil(t, err)
 }
 
 func TestGitHubDispatch_PostUpdate(t *testing.T) {
-	githubDispatch, err := NewGitHubDispatch("https://github.com/foo/bar", "foobar", nil)
+	githubDispatch, err := NewGitHubDispatch("https://github.com/foo/bar", "foobar", nil, "")
 	require.NoError(t, err)
 
 	event := testEvent()
@@ -61,3 +64,98 @@ func TestGitHubDispatch_PostUpdate(t *testing.T) {
 	err = githubDispatch.Post(context.TODO(), event)
 	require.NoError(t, err)
 }
+
+func TestGitHubDispatch_PostInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     GitHubDispatchOptions
+		wantPath string
+		wantBody map[string]interface{}
+	}{
+		{
+			name: "repository dispatch with inputs",
+			opts: GitHubDispatchOptions{
+				Inputs: map[string]string{
+					"name":     "{{ .InvolvedObject.Name }}",
+					"metadata": "{{ .Metadata.test }}",
+					"missing":  "{{ .Metadata.missing }}",
+				},
+			},
+			wantPath: "/repos/foo/bar/dispatches",
+			wantBody: map[string]interface{}{
+				"event_type": "GitRepository/webapp.gitops-system",
+				"client_payload": map[string]interface{}{
+					"name":     "webapp",
+					"metadata": "metadata",
+					"missing":  "",
+				},
+			},
+		},
+		{
+			name: "workflow dispatch with default ref",
+			opts: GitHubDispatchOptions{
+				Workflow: "deploy.yaml",
+				Inputs: map[string]string{
+					"environment": "{{ .InvolvedObject.Namespace }}",
+				},
+			},
+			wantPath: "/repos/foo/bar/actions/workflows/deploy.yaml/dispatches",
+			wantBody: map[string]interface{}{
+				"ref": "main",
+				"inputs": map[string]interface{}{
+					"environment": "gitops-system",
+				},
+			},
+		},
+		{
+			name: "workflow dispatch with ref",
+			opts: GitHubDispatchOptions{
+				Workflow: "1234",
+				Ref:      "v1.0.0",
+			},
+			wantPath: "/repos/foo/bar/actions/workflows/1234/dispatches",
+			wantBody: map[string]interface{}{
+				"ref": "v1.0.0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			var gotBody map[string]interface{}
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer ts.Close()
+
+			opts := FactoryOptions{
+				URL:            "https://github.com/foo/bar",
+				Token:          "foobar",
+				APIBaseURL:     ts.URL,
+				GitHubDispatch: tt.opts,
+			}
+			n, err := gitHubDispatchNotifierFunc(opts)
+			require.NoError(t, err)
+
+			err = n.Post(context.TODO(), testEvent())
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPath, gotPath)
+			require.Equal(t, tt.wantBody, gotBody)
+		})
+	}
+}
+
+func TestGitHubDispatch_InvalidInput(t *testing.T) {
+	opts := FactoryOptions{
+		URL:   "https://github.com/foo/bar",
+		Token: "foobar",
+		GitHubDispatch: GitHubDispatchOptions{
+			Inputs: map[string]string{"name": "{{ .InvolvedObject.Name"},
+		},
+	}
+	_, err := gitHubDispatchNotifierFunc(opts)
+	require.ErrorContains(t, err, "invalid template for input 'name'")
+}
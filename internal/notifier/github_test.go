@@ -17,14 +17,24 @@ limitations under the License.
 package notifier
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v64/github"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 )
 
 func TestNewGitHubBasic(t *testing.T) {
-	g, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://github.com/foo/bar", "foobar", nil)
+	g, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://github.com/foo/bar", "foobar", nil, "")
 	assert.Nil(t, err)
 	assert.Equal(t, g.Owner, "foo")
 	assert.Equal(t, g.Repo, "bar")
@@ -32,23 +42,89 @@ func TestNewGitHubBasic(t *testing.T) {
 }
 
 func TestNewEmterpriseGitHubBasic(t *testing.T) {
-	g, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://foobar.com/foo/bar", "foobar", nil)
+	g, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://foobar.com/foo/bar", "foobar", nil, "")
 	assert.Nil(t, err)
 	assert.Equal(t, g.Owner, "foo")
 	assert.Equal(t, g.Repo, "bar")
 	assert.Equal(t, g.Client.BaseURL.Host, "foobar.com")
 }
 
+func TestNewEnterpriseGitHubSubpath(t *testing.T) {
+	g, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://foobar.com:8443/github/foo/bar", "foobar", nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, g.Owner, "foo")
+	assert.Equal(t, g.Repo, "bar")
+	assert.Equal(t, g.Client.BaseURL.String(), "https://foobar.com:8443/github/api/v3/")
+}
+
+func TestNewEnterpriseGitHubAPIBaseURL(t *testing.T) {
+	g, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "ssh://git@foobar.com:2222/foo/bar", "foobar", nil, "https://api.foobar.com/v3")
+	assert.Nil(t, err)
+	assert.Equal(t, g.Owner, "foo")
+	assert.Equal(t, g.Repo, "bar")
+	assert.Equal(t, g.Client.BaseURL.String(), "https://api.foobar.com/v3/")
+}
+
 func TestNewGitHubInvalidUrl(t *testing.T) {
-	_, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://github.com/foo/bar/baz", "foobar", nil)
+	_, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://github.com/foo/bar/baz", "foobar", nil, "")
 	assert.NotNil(t, err)
 }
 
 func TestNewGitHubEmptyToken(t *testing.T) {
-	_, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://github.com/foo/bar", "", nil)
+	_, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://github.com/foo/bar", "", nil, "")
 	assert.NotNil(t, err)
 }
 
+func TestGitHub_Preflight(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		scopes           []string
+		push             bool
+		wantErr          bool
+		wantInsufficient bool
+	}{
+		{name: "classic token with repo scope", status: http.StatusOK, scopes: []string{"repo, read:org"}, push: true},
+		{name: "classic token with repo:status scope", status: http.StatusOK, scopes: []string{"repo:status"}, push: true},
+		{name: "fine-grained token", status: http.StatusOK},
+		{name: "classic token without scope", status: http.StatusOK, scopes: []string{"read:org"}, push: true, wantErr: true, wantInsufficient: true},
+		{name: "classic token without push access", status: http.StatusOK, scopes: []string{"repo"}, wantErr: true, wantInsufficient: true},
+		{name: "repository not found", status: http.StatusNotFound, wantErr: true, wantInsufficient: true},
+		{name: "bad credentials", status: http.StatusUnauthorized, wantErr: true, wantInsufficient: true},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/api/v3/repos/foo/bar", r.URL.Path)
+				for _, s := range tt.scopes {
+					w.Header().Add("X-OAuth-Scopes", s)
+				}
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					repo := github.Repository{Permissions: map[string]bool{"push": tt.push}}
+					if tt.scopes == nil {
+						repo.Permissions = nil
+					}
+					_ = json.NewEncoder(w).Encode(repo)
+				}
+			}))
+			defer ts.Close()
+
+			g, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", ts.URL+"/foo/bar", "foobar", nil, "")
+			require.NoError(t, err)
+
+			err = g.Preflight(context.TODO())
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tt.wantInsufficient, errors.Is(err, ErrInsufficientPermissions))
+		})
+	}
+}
+
 func TestDuplicateGithubStatus(t *testing.T) {
 	assert := assert.New(t)
 
@@ -76,3 +152,62 @@ func ghStatus(state string, context string, description string) *github.RepoStat
 		Description: &description,
 	}
 }
+
+func TestGitHub_PostRetryCommitNotFound(t *testing.T) {
+	commitNotFoundBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { commitNotFoundBackoff = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} }()
+
+	tests := []struct {
+		name     string
+		notFound int
+		status   int
+		wantErr  bool
+		wantList int
+	}{
+		{name: "commit found", wantList: 1},
+		{name: "commit found after retry", notFound: 2, wantList: 3},
+		{name: "commit not found", notFound: 5, wantErr: true, wantList: 3},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true, wantList: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var list, create int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					list++
+					switch {
+					case tt.status != 0:
+						w.WriteHeader(tt.status)
+					case list <= tt.notFound:
+						w.WriteHeader(http.StatusNotFound)
+						_, _ = w.Write([]byte(`{"message":"No commit found for SHA"}`))
+					default:
+						_, _ = w.Write([]byte("[]"))
+					}
+					return
+				}
+				create++
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte("{}"))
+			}))
+			defer ts.Close()
+
+			g, err := NewGitHub("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", ts.URL+"/foo/bar", "foobar", nil, "")
+			require.NoError(t, err)
+
+			err = g.Post(context.TODO(), eventv1.Event{
+				InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Name: "podinfo"},
+				Severity:       eventv1.EventSeverityInfo,
+				Reason:         "ReconciliationSucceeded",
+				Metadata: map[string]string{
+					eventv1.MetaRevisionKey: "main@sha1:5394cb7f48332b2de7c17dd8b8384bbc84b7e738",
+				},
+			})
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantList, list)
+			if !tt.wantErr {
+				require.Equal(t, 1, create)
+			}
+		})
+	}
+}
@@ -18,11 +18,11 @@ package notifier
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"gitlab.com/gitlab-org/api/client-go"
 
@@ -30,13 +30,23 @@ import (
 	"github.com/fluxcd/pkg/apis/meta"
 )
 
+const (
+	// gitLabAccessToken is a personal, group or project access token,
+	// sent in the PRIVATE-TOKEN header.
+	gitLabAccessToken = "access"
+	// gitLabJobToken is a CI/CD job token, sent in the JOB-TOKEN header.
+	gitLabJobToken = "job"
+	// gitLabOAuthToken is an OAuth 2.0 token, sent as a bearer token.
+	gitLabOAuthToken = "oauth"
+)
+
 type GitLab struct {
 	Id          string
 	ProviderUID string
 	Client      *gitlab.Client
 }
 
-func NewGitLab(providerUID string, addr string, token string, certPool *x509.CertPool) (*GitLab, error) {
+func NewGitLab(providerUID string, addr string, token string, certPool *x509.CertPool, tokenType string) (*GitLab, error) {
 	if len(token) == 0 {
 		return nil, errors.New("gitlab token cannot be empty")
 	}
@@ -45,18 +55,35 @@ func NewGitLab(providerUID string, addr string, token string, certPool *x509.Cer
 	if err != nil {
 		return nil, err
 	}
+	// The web URLs of the projects in nested groups, e.g.
+	// https://gitlab.com/group/subgroup/project/-/tree/main,
+	// separate the project path from the page with '/-/'.
+	id, _, _ = strings.Cut(id, "/-/")
+	id = strings.Trim(id, "/")
+	if id == "" {
+		return nil, fmt.Errorf("invalid project path in address %q", addr)
+	}
 
-	opts := []gitlab.ClientOptionFunc{gitlab.WithBaseURL(host)}
-	if certPool != nil {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: certPool,
-			},
+	var tr http.RoundTripper
+	if tlsConfig := newTLSConfig(certPool); tlsConfig != nil {
+		tr = &http.Transport{
+			TLSClientConfig: tlsConfig,
 		}
-		hc := &http.Client{Transport: tr}
-		opts = append(opts, gitlab.WithHTTPClient(hc))
 	}
-	client, err := gitlab.NewClient(token, opts...)
+	hc := &http.Client{Transport: newUserAgentTransport(tr)}
+	opts := []gitlab.ClientOptionFunc{gitlab.WithBaseURL(host), gitlab.WithHTTPClient(hc)}
+	var client *gitlab.Client
+	switch tokenType {
+	case "", gitLabAccessToken:
+		client, err = gitlab.NewClient(token, opts...)
+	case gitLabJobToken:
+		client, err = gitlab.NewJobClient(token, opts...)
+	case gitLabOAuthToken:
+		client, err = gitlab.NewOAuthClient(token, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported gitlab token type %q, must be one of: %s, %s, %s",
+			tokenType, gitLabAccessToken, gitLabJobToken, gitLabOAuthToken)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -99,26 +126,30 @@ func (g *GitLab) Post(ctx context.Context, event eventv1.Event) error {
 		Description: desc,
 	}
 
-	getOpt := &gitlab.GetCommitStatusesOptions{}
-	statuses, _, err := g.Client.Commits.GetCommitStatuses(g.Id, rev, getOpt, gitlab.WithContext(ctx))
-	if err != nil {
-		return fmt.Errorf("unable to list commit status: %s", err)
-	}
-	if duplicateGitlabStatus(statuses, status) {
-		return nil
-	}
+	return retryCommitNotFound(ctx, isGitLabNotFound, func() error {
+		getOpt := &gitlab.GetCommitStatusesOptions{}
+		statuses, _, err := g.Client.Commits.GetCommitStatuses(g.Id, rev, getOpt, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("unable to list commit status: %w", err)
+		}
+		if duplicateGitlabStatus(statuses, status) {
+			return nil
+		}
 
-	setOpt := &gitlab.SetCommitStatusOptions{
-		Name:        &id,
-		Description: &desc,
-		State:       state,
-	}
-	_, _, err = g.Client.Commits.SetCommitStatus(g.Id, rev, setOpt, gitlab.WithContext(ctx))
-	if err != nil {
+		setOpt := &gitlab.SetCommitStatusOptions{
+			Name:        &id,
+			Description: &desc,
+			State:       state,
+		}
+		_, _, err = g.Client.Commits.SetCommitStatus(g.Id, rev, setOpt, gitlab.WithContext(ctx))
 		return err
-	}
+	})
+}
 
-	return nil
+// isGitLabNotFound returns true if the error is a 404 response from the
+// GitLab API, e.g. for a commit that is not yet known to the API.
+func isGitLabNotFound(err error) bool {
+	return errors.Is(err, gitlab.ErrNotFound)
 }
 
 func toGitLabState(severity string) (gitlab.BuildStateValue, error) {
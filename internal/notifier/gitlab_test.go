@@ -17,31 +17,119 @@ limitations under the License.
 package notifier
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 )
 
 func TestNewGitLabBasic(t *testing.T) {
-	g, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://gitlab.com/foo/bar", "foobar", nil)
+	g, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://gitlab.com/foo/bar", "foobar", nil, "")
 	assert.Nil(t, err)
 	assert.Equal(t, g.Id, "foo/bar")
 }
 
 func TestNewGitLabSubgroups(t *testing.T) {
-	g, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://gitlab.com/foo/bar/baz", "foobar", nil)
+	g, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://gitlab.com/foo/bar/baz", "foobar", nil, "")
 	assert.Nil(t, err)
 	assert.Equal(t, g.Id, "foo/bar/baz")
 }
 
+func TestNewGitLabNestedSubgroupsWebURL(t *testing.T) {
+	g, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://gitlab.com/foo/bar/baz/qux/-/tree/main", "foobar", nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, g.Id, "foo/bar/baz/qux")
+}
+
+func TestNewGitLabTokenTypes(t *testing.T) {
+	tests := []struct {
+		tokenType  string
+		wantHeader string
+		wantValue  string
+		wantErr    bool
+	}{
+		{tokenType: "", wantHeader: "PRIVATE-TOKEN", wantValue: "foobar"},
+		{tokenType: "access", wantHeader: "PRIVATE-TOKEN", wantValue: "foobar"},
+		{tokenType: "job", wantHeader: "JOB-TOKEN", wantValue: "foobar"},
+		{tokenType: "oauth", wantHeader: "Authorization", wantValue: "Bearer foobar"},
+		{tokenType: "deploy", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tokenType, func(t *testing.T) {
+			var header http.Header
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+				_, _ = w.Write([]byte("[]"))
+			}))
+			defer ts.Close()
+
+			g, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", ts.URL+"/foo/bar", "foobar", nil, tt.tokenType)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			_, _, err = g.Client.Commits.GetCommitStatuses(g.Id, "abc", nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantValue, header.Get(tt.wantHeader))
+		})
+	}
+}
+
 func TestNewGitLabSelfHosted(t *testing.T) {
-	g, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://example.com/foo/bar", "foo:bar", nil)
+	g, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://example.com/foo/bar", "foo:bar", nil, "")
 	assert.Nil(t, err)
 	assert.Equal(t, g.Id, "foo/bar")
 	assert.Equal(t, g.Client.BaseURL().Host, "example.com")
 }
 
 func TestNewGitLabEmptyToken(t *testing.T) {
-	_, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://gitlab.com/foo/bar", "", nil)
+	_, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", "https://gitlab.com/foo/bar", "", nil, "")
 	assert.NotNil(t, err)
 }
+
+func TestGitLab_PostRetryCommitNotFound(t *testing.T) {
+	commitNotFoundBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { commitNotFoundBackoff = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} }()
+
+	var list, create int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			list++
+			if list == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message":"404 Commit Not Found"}`))
+				return
+			}
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		create++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	g, err := NewGitLab("0c9c2e41-d2f9-4f9b-9c41-bebc1984d67a", ts.URL+"/foo/bar", "foobar", nil, "")
+	require.NoError(t, err)
+
+	err = g.Post(context.TODO(), eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Name: "podinfo"},
+		Severity:       eventv1.EventSeverityInfo,
+		Reason:         "ReconciliationSucceeded",
+		Metadata: map[string]string{
+			eventv1.MetaRevisionKey: "main@sha1:5394cb7f48332b2de7c17dd8b8384bbc84b7e738",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, list)
+	require.Equal(t, 1, create)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gitlab.com/gitlab-org/api/client-go"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+// defaultGitLabTriggerRef is the Git reference the pipelines are run for
+// when the address doesn't specify one.
+const defaultGitLabTriggerRef = "main"
+
+// GitLabTrigger runs GitLab CI/CD pipelines with a pipeline trigger token.
+type GitLabTrigger struct {
+	Id     string
+	Ref    string
+	Token  string
+	Client *gitlab.Client
+}
+
+// NewGitLabTrigger returns a GitLabTrigger for the project of the address.
+// The Git reference the pipelines are run for can be specified in the
+// address in the format of a project tree URL, e.g.
+// https://gitlab.com/group/project/-/tree/staging.
+func NewGitLabTrigger(addr string, token string, certPool *x509.CertPool) (*GitLabTrigger, error) {
+	if len(token) == 0 {
+		return nil, errors.New("gitlab trigger token cannot be empty")
+	}
+
+	host, id, err := parseGitAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	id, page, found := strings.Cut(id, "/-/")
+	id = strings.Trim(id, "/")
+	if id == "" {
+		return nil, fmt.Errorf("invalid project path in address %q", addr)
+	}
+	ref := defaultGitLabTriggerRef
+	if found {
+		var ok bool
+		ref, ok = strings.CutPrefix(page, "tree/")
+		ref = strings.Trim(ref, "/")
+		if !ok || ref == "" {
+			return nil, fmt.Errorf("invalid ref in address %q", addr)
+		}
+	}
+
+	var tr http.RoundTripper
+	if tlsConfig := newTLSConfig(certPool); tlsConfig != nil {
+		tr = &http.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	}
+	hc := &http.Client{Transport: newUserAgentTransport(tr)}
+	// The pipeline trigger API is authenticated with the trigger token
+	// sent in the request, not with an access token.
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(host), gitlab.WithHTTPClient(hc))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitLabTrigger{
+		Id:     id,
+		Ref:    ref,
+		Token:  token,
+		Client: client,
+	}, nil
+}
+
+// Post runs a GitLab pipeline with the event passed as CI/CD variables.
+func (g *GitLabTrigger) Post(ctx context.Context, event eventv1.Event) error {
+	// Skip Git commit status update event.
+	if event.HasMetadata(eventv1.MetaCommitStatusKey, eventv1.MetaCommitStatusUpdateValue) {
+		return nil
+	}
+
+	opts := &gitlab.RunPipelineTriggerOptions{
+		Ref:       gitlab.Ptr(g.Ref),
+		Token:     gitlab.Ptr(g.Token),
+		Variables: eventVariables(event),
+	}
+	_, _, err := g.Client.PipelineTriggers.RunPipelineTrigger(g.Id, opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("could not run gitlab pipeline trigger: %v", err)
+	}
+
+	return nil
+}
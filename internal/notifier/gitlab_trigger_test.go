@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+func TestNewGitLabTrigger(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		token   string
+		wantId  string
+		wantRef string
+		wantErr string
+	}{
+		{
+			name:    "project address",
+			addr:    "https://gitlab.com/foo/bar",
+			token:   "glptt-foobar",
+			wantId:  "foo/bar",
+			wantRef: "main",
+		},
+		{
+			name:    "tree address",
+			addr:    "https://gitlab.com/group/subgroup/bar/-/tree/staging",
+			token:   "glptt-foobar",
+			wantId:  "group/subgroup/bar",
+			wantRef: "staging",
+		},
+		{
+			name:    "non-tree address",
+			addr:    "https://gitlab.com/foo/bar/-/issues",
+			token:   "glptt-foobar",
+			wantErr: "invalid ref in address",
+		},
+		{
+			name:    "empty token",
+			addr:    "https://gitlab.com/foo/bar",
+			wantErr: "gitlab trigger token cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGitLabTrigger(tt.addr, tt.token, nil)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantId, g.Id)
+			require.Equal(t, tt.wantRef, g.Ref)
+		})
+	}
+}
+
+func TestGitLabTrigger_Post(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	g, err := NewGitLabTrigger(ts.URL+"/foo/bar/-/tree/staging", "glptt-foobar", nil)
+	require.NoError(t, err)
+
+	event := testEvent()
+	event.Metadata["kustomize.toolkit.fluxcd.io/revision"] = "main@sha1:5394cb7f"
+	err = g.Post(context.TODO(), event)
+	require.NoError(t, err)
+
+	require.Equal(t, "/api/v4/projects/foo%2Fbar/trigger/pipeline", path)
+	require.Equal(t, map[string]interface{}{
+		"ref":   "staging",
+		"token": "glptt-foobar",
+		"variables": map[string]interface{}{
+			"FLUX_EVENT_TYPE":          "GitRepository/webapp.gitops-system",
+			"FLUX_EVENT_KIND":          "GitRepository",
+			"FLUX_EVENT_NAME":          "webapp",
+			"FLUX_EVENT_NAMESPACE":     "gitops-system",
+			"FLUX_EVENT_SEVERITY":      "info",
+			"FLUX_EVENT_REASON":        "reason",
+			"FLUX_EVENT_MESSAGE":       "message",
+			"FLUX_EVENT_METADATA_TEST": "metadata",
+			"FLUX_EVENT_METADATA_KUSTOMIZE_TOOLKIT_FLUXCD_IO_REVISION": "main@sha1:5394cb7f",
+		},
+	}, body)
+}
+
+func TestGitLabTrigger_PostUpdate(t *testing.T) {
+	g, err := NewGitLabTrigger("https://gitlab.com/foo/bar", "glptt-foobar", nil)
+	require.NoError(t, err)
+
+	event := testEvent()
+	event.Metadata[eventv1.MetaCommitStatusKey] = eventv1.MetaCommitStatusUpdateValue
+	err = g.Post(context.TODO(), event)
+	require.NoError(t, err)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+// Jenkins is a notifier triggering a parameterized Jenkins job, with the
+// event passed as the job parameters.
+type Jenkins struct {
+	// URL is the URL of the Jenkins server.
+	URL *url.URL
+	// JobURL is the URL of the job.
+	JobURL   *url.URL
+	Username string
+	Token    string
+	Client   *retryablehttp.Client
+}
+
+type jenkinsCrumb struct {
+	Crumb             string `json:"crumb"`
+	CrumbRequestField string `json:"crumbRequestField"`
+}
+
+// NewJenkins creates and returns a new Jenkins notifier. The address is the
+// URL of the job, e.g. https://jenkins.example.com/job/folder/job/e2e, and
+// the token an API token of the user.
+func NewJenkins(addr string, username string, token string, certPool *x509.CertPool) (*Jenkins, error) {
+	if username == "" || token == "" {
+		return nil, errors.New("jenkins username and token cannot be empty")
+	}
+
+	jobURL, err := url.ParseRequestURI(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jenkins job URL %q: %w", addr, err)
+	}
+	if jobURL.Scheme != "http" && jobURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid Jenkins job URL %q: scheme must be http or https", addr)
+	}
+	jobURL.Path = strings.TrimSuffix(jobURL.Path, "/")
+	root, _, found := strings.Cut(jobURL.Path, "/job/")
+	if !found {
+		return nil, fmt.Errorf("invalid Jenkins job URL %q: path must contain /job/", addr)
+	}
+	serverURL := *jobURL
+	serverURL.Path = root
+
+	httpClient := retryablehttp.NewClient()
+	if tlsConfig := newTLSConfig(certPool); tlsConfig != nil {
+		httpClient.HTTPClient.Transport = &http.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	}
+	httpClient.HTTPClient.Transport = newUserAgentTransport(httpClient.HTTPClient.Transport)
+	// The crumbs are bound to the web session they are issued for.
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	httpClient.HTTPClient.Jar = jar
+	// Disable the timeout for the HTTP client,
+	// as we set the provider timeout on the context.
+	httpClient.HTTPClient.Timeout = 0
+	httpClient.RetryWaitMin = 2 * time.Second
+	httpClient.RetryWaitMax = 30 * time.Second
+	httpClient.RetryMax = 4
+	httpClient.Logger = nil
+
+	return &Jenkins{
+		URL:      &serverURL,
+		JobURL:   jobURL,
+		Username: username,
+		Token:    token,
+		Client:   httpClient,
+	}, nil
+}
+
+// Post triggers a build of the Jenkins job with the event variables as
+// parameters. Parameters not defined by the job are ignored by Jenkins.
+func (j *Jenkins) Post(ctx context.Context, event eventv1.Event) error {
+	// Skip Git commit status update event.
+	if event.HasMetadata(eventv1.MetaCommitStatusKey, eventv1.MetaCommitStatusUpdateValue) {
+		return nil
+	}
+
+	crumb, err := j.crumb(ctx)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	for k, v := range eventVariables(event) {
+		params.Set(k, v)
+	}
+	buildURL := j.JobURL.JoinPath("buildWithParameters")
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, buildURL.String(), []byte(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(j.Username, j.Token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if crumb != nil {
+		req.Header.Set(crumb.CrumbRequestField, crumb.Crumb)
+	}
+
+	resp, err := j.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not trigger jenkins build: %w", responseError(resp))
+	}
+	return nil
+}
+
+// crumb returns a crumb issued by the Jenkins server to protect the
+// requests against CSRF, or nil if the protection is disabled.
+func (j *Jenkins) crumb(ctx context.Context) (*jenkinsCrumb, error) {
+	crumbURL := j.URL.JoinPath("crumbIssuer", "api", "json")
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, crumbURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(j.Username, j.Token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get jenkins crumb: %w", statusCodeError(resp.StatusCode, b))
+	}
+
+	var crumb jenkinsCrumb
+	if err := json.Unmarshal(b, &crumb); err != nil {
+		return nil, fmt.Errorf("could not decode jenkins crumb: %w", err)
+	}
+	if crumb.Crumb == "" || crumb.CrumbRequestField == "" {
+		return nil, errors.New("could not decode jenkins crumb: missing crumb or request field")
+	}
+	return &crumb, nil
+}
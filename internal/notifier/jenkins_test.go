@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewJenkins(t *testing.T) {
+	tests := []struct {
+		name      string
+		addr      string
+		username  string
+		token     string
+		wantURL   string
+		wantJob   string
+		wantError string
+	}{
+		{
+			name:     "job URL",
+			addr:     "https://jenkins.example.com/job/e2e/",
+			username: "flux",
+			token:    "token",
+			wantURL:  "https://jenkins.example.com",
+			wantJob:  "https://jenkins.example.com/job/e2e",
+		},
+		{
+			name:     "job in folder under a path",
+			addr:     "https://example.com/jenkins/job/folder/job/e2e",
+			username: "flux",
+			token:    "token",
+			wantURL:  "https://example.com/jenkins",
+			wantJob:  "https://example.com/jenkins/job/folder/job/e2e",
+		},
+		{
+			name:      "server URL",
+			addr:      "https://jenkins.example.com",
+			username:  "flux",
+			token:     "token",
+			wantError: "path must contain /job/",
+		},
+		{
+			name:      "empty token",
+			addr:      "https://jenkins.example.com/job/e2e",
+			username:  "flux",
+			wantError: "jenkins username and token cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := NewJenkins(tt.addr, tt.username, tt.token, nil)
+			if tt.wantError != "" {
+				require.ErrorContains(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantURL, j.URL.String())
+			require.Equal(t, tt.wantJob, j.JobURL.String())
+		})
+	}
+}
+
+func TestJenkins_Post(t *testing.T) {
+	tests := []struct {
+		name      string
+		crumb     bool
+		wantCrumb string
+	}{
+		{
+			name:      "with crumb",
+			crumb:     true,
+			wantCrumb: "abc",
+		},
+		{
+			name:  "without crumb issuer",
+			crumb: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var built bool
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				require.True(t, ok)
+				require.Equal(t, "flux", username)
+				require.Equal(t, "token", password)
+
+				switch r.URL.Path {
+				case "/crumbIssuer/api/json":
+					if !tt.crumb {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "session", Path: "/"})
+					_, _ = w.Write([]byte(`{"crumb":"abc","crumbRequestField":"Jenkins-Crumb"}`))
+				case "/job/e2e/buildWithParameters":
+					require.NoError(t, r.ParseForm())
+					require.Equal(t, tt.wantCrumb, r.Header.Get("Jenkins-Crumb"))
+					if tt.crumb {
+						cookie, err := r.Cookie("JSESSIONID")
+						require.NoError(t, err)
+						require.Equal(t, "session", cookie.Value)
+					}
+					require.Equal(t, "GitRepository/webapp.gitops-system", r.PostForm.Get("FLUX_EVENT_TYPE"))
+					require.Equal(t, "metadata", r.PostForm.Get("FLUX_EVENT_METADATA_TEST"))
+					built = true
+					w.WriteHeader(http.StatusCreated)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer ts.Close()
+
+			j, err := NewJenkins(ts.URL+"/job/e2e", "flux", "token", nil)
+			require.NoError(t, err)
+
+			err = j.Post(context.TODO(), testEvent())
+			require.NoError(t, err)
+			require.True(t, built)
+		})
+	}
+}
+
+func TestJenkins_PostError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("No valid crumb was included in the request"))
+	}))
+	defer ts.Close()
+
+	j, err := NewJenkins(ts.URL+"/job/e2e", "flux", "token", nil)
+	require.NoError(t, err)
+
+	err = j.Post(context.TODO(), testEvent())
+	require.ErrorContains(t, err, "could not trigger jenkins build: request failed with status code 403")
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+// decryptJWE decrypts a JWE in the compact serialization encrypted with
+// RSA-OAEP-256 and A256GCM.
+func decryptJWE(t *testing.T, jwe string, key *rsa.PrivateKey) ([]byte, jweHeader) {
+	t.Helper()
+	parts := strings.Split(jwe, ".")
+	require.Len(t, parts, 5)
+
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		require.NoError(t, err)
+		return b
+	}
+
+	var header jweHeader
+	require.NoError(t, json.Unmarshal(decode(parts[0]), &header))
+
+	cek, err := rsa.DecryptOAEP(sha256.New(), nil, key, decode(parts[1]), nil)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	sealed := append(decode(parts[3]), decode(parts[4])...)
+	payload, err := gcm.Open(nil, decode(parts[2]), sealed, []byte(parts[0]))
+	require.NoError(t, err)
+	return payload, header
+}
+
+func TestParseJWEPublicKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	parsed, err := ParseJWEPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+	require.NoError(t, err)
+	require.True(t, key.PublicKey.Equal(parsed))
+
+	pkcs1 := x509.MarshalPKCS1PublicKey(&key.PublicKey)
+	parsed, err = ParseJWEPublicKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pkcs1}))
+	require.NoError(t, err)
+	require.True(t, key.PublicKey.Equal(parsed))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecPKIX, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	require.NoError(t, err)
+	_, err = ParseJWEPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ecPKIX}))
+	require.ErrorContains(t, err, "only RSA keys are supported")
+
+	_, err = ParseJWEPublicKey([]byte("not a key"))
+	require.Error(t, err)
+}
+
+func TestForwarder_PostEncrypted(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var received eventv1.Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, JWEContentType, r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "sha256="+sign(sha256.New, body, []byte("key")), r.Header.Get("X-Signature"))
+
+		payload, header := decryptJWE(t, string(body), key)
+		require.Equal(t, "RSA-OAEP-256", header.Algorithm)
+		require.Equal(t, "A256GCM", header.Encryption)
+		require.NoError(t, json.Unmarshal(payload, &received))
+	}))
+	defer ts.Close()
+
+	forwarder, err := NewForwarder(ts.URL, "", nil, nil, []byte("key"))
+	require.NoError(t, err)
+	forwarder.EncryptionKey = &key.PublicKey
+
+	event := testEvent()
+	require.NoError(t, forwarder.Post(context.TODO(), event))
+	require.Equal(t, event.Message, received.Message)
+	require.Equal(t, event.Metadata, received.Metadata)
+}
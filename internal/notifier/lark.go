@@ -11,6 +11,9 @@ import (
 
 type Lark struct {
 	URL string
+
+	// Appearance overrides the header templates and emojis of the messages.
+	Appearance Appearance
 }
 
 type LarkPayload struct {
@@ -73,6 +76,8 @@ func (l *Lark) Post(ctx context.Context, event eventv1.Event) error {
 		emoji = "🚨"
 		color = "red"
 	}
+	emoji = l.Appearance.emoji(event.Severity, emoji)
+	color = l.Appearance.color(event.Severity, color)
 
 	message := fmt.Sprintf("**%s**\n\n", event.Message)
 	for k, v := range event.Metadata {
@@ -19,6 +19,9 @@ type Matrix struct {
 	URL      string
 	RoomId   string
 	CertPool *x509.CertPool
+
+	// Appearance overrides the emojis of the messages.
+	Appearance Appearance
 }
 
 type MatrixPayload struct {
@@ -52,6 +55,7 @@ func (m *Matrix) Post(ctx context.Context, event eventv1.Event) error {
 	if event.Severity == eventv1.EventSeverityError {
 		emoji = "🚨"
 	}
+	emoji = m.Appearance.emoji(event.Severity, emoji)
 	var metadata string
 	for k, v := range event.Metadata {
 		metadata = metadata + fmt.Sprintf("- %s: %s\n", k, v)
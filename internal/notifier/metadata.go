@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"slices"
+)
+
+// MetadataFields configures how the event metadata is rendered as
+// structured fields by the chat notifiers.
+type MetadataFields struct {
+	// Order holds the keys rendered first, in the given order. The other
+	// keys are rendered after them, sorted alphabetically.
+	Order []string
+	// Exclude holds the keys that are not rendered.
+	Exclude []string
+}
+
+// metadataField is a key/value pair of the event metadata.
+type metadataField struct {
+	Key   string
+	Value string
+}
+
+// fields returns the event metadata as a list of key/value pairs, ordered
+// and filtered according to the configuration.
+func (m MetadataFields) fields(metadata map[string]string) []metadataField {
+	fields := make([]metadataField, 0, len(metadata))
+	seen := make(map[string]bool, len(metadata))
+	for _, k := range m.Order {
+		if v, ok := metadata[k]; ok && !seen[k] && !slices.Contains(m.Exclude, k) {
+			fields = append(fields, metadataField{Key: k, Value: v})
+			seen[k] = true
+		}
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		if !seen[k] && !slices.Contains(m.Exclude, k) {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fields = append(fields, metadataField{Key: k, Value: metadata[k]})
+	}
+	return fields
+}
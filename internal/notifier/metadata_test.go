@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataFields_fields(t *testing.T) {
+	metadata := map[string]string{
+		"summary":  "Cluster addons impacted",
+		"revision": "main@sha1:1234",
+		"env":      "prod",
+		"cluster":  "my-cluster",
+	}
+
+	tests := []struct {
+		name   string
+		config MetadataFields
+		want   []string
+	}{
+		{
+			name: "sorted by key",
+			want: []string{"cluster", "env", "revision", "summary"},
+		},
+		{
+			name:   "ordered keys first",
+			config: MetadataFields{Order: []string{"summary", "missing", "env", "summary"}},
+			want:   []string{"summary", "env", "cluster", "revision"},
+		},
+		{
+			name: "excluded keys",
+			config: MetadataFields{
+				Order:   []string{"revision", "summary"},
+				Exclude: []string{"summary", "cluster"},
+			},
+			want: []string{"revision", "env"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			for _, f := range tt.config.fields(metadata) {
+				require.Equal(t, metadata[f.Key], f.Value)
+				keys = append(keys, f.Key)
+			}
+			require.Equal(t, tt.want, keys)
+		})
+	}
+}
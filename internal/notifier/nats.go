@@ -21,6 +21,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"time"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/nats-io/nats.go"
@@ -84,10 +86,23 @@ func (n *NATS) Post(ctx context.Context, event eventv1.Event) error {
 }
 
 func (n *natsClient) publish(ctx context.Context, subject string, eventPayload []byte) (err error) {
-	opts := []nats.Option{nats.Name("NATS Provider Publisher")}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	opts := []nats.Option{
+		nats.Name("NATS Provider Publisher"),
+		nats.SetCustomDialer(natsDialer{ctx: ctx}),
+	}
 	if n.username != "" && n.password != "" {
 		opts = append(opts, nats.UserInfo(n.username, n.password))
 	}
+	// Bound the handshake with the server to the deadline of the context,
+	// the provider timeout.
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		opts = append(opts, nats.Timeout(time.Until(deadline)))
+	}
 
 	nc, err := nats.Connect(n.server, opts...)
 	if err != nil {
@@ -95,11 +110,32 @@ func (n *natsClient) publish(ctx context.Context, subject string, eventPayload [
 	}
 	defer nc.Close()
 
-	nc.Publish(subject, eventPayload)
-	nc.Flush()
-	if err = nc.LastError(); err != nil {
+	if err = nc.Publish(subject, eventPayload); err == nil {
+		if hasDeadline {
+			err = nc.FlushWithContext(ctx)
+		} else {
+			err = nc.Flush()
+		}
+	}
+	if err == nil {
+		err = nc.LastError()
+	}
+	if err != nil {
 		return fmt.Errorf("error publishing message to server: %w", err)
 	}
 
-	return err
+	return nil
+}
+
+// natsDialer connects to the NATS servers with the context given to Post,
+// which the NATS client doesn't accept.
+type natsDialer struct {
+	ctx context.Context
+}
+
+// Dial implements nats.CustomDialer, with the default connection timeout
+// of the NATS client.
+func (d natsDialer) Dial(network, address string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: nats.DefaultTimeout}
+	return dialer.DialContext(d.ctx, network, address)
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	. "github.com/onsi/gomega"
@@ -150,3 +152,42 @@ func TestNATSPost(t *testing.T) {
 		})
 	}
 }
+
+func TestNATSClientPublish_Context(t *testing.T) {
+	// A server accepting the connections without ever answering.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	client := &natsClient{server: "nats://" + l.Addr().String()}
+
+	t.Run("canceled context", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := client.publish(ctx, "test", []byte("{}"))
+		g.Expect(err).To(MatchError(context.Canceled))
+	})
+
+	t.Run("hung server", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := client.publish(ctx, "test", []byte("{}"))
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+}
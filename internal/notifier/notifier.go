@@ -18,10 +18,27 @@ package notifier
 
 import (
 	"context"
+	"errors"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 )
 
+// MetaCorrelationIDKey is the event metadata key of the ID correlating the
+// notifications sent to all providers for the same object and revision.
+const MetaCorrelationIDKey = "correlationID"
+
 type Interface interface {
 	Post(ctx context.Context, event eventv1.Event) error
 }
+
+// Preflighter is implemented by the notifiers able to verify their
+// credentials before any event is dispatched.
+type Preflighter interface {
+	// Preflight returns an error wrapping ErrInsufficientPermissions if the
+	// credentials can't be used to post notifications to the target.
+	Preflight(ctx context.Context) error
+}
+
+// ErrInsufficientPermissions is returned by Preflight when the credentials
+// of the notifier are rejected or lack the required permissions.
+var ErrInsufficientPermissions = errors.New("insufficient permissions")
@@ -36,6 +36,7 @@ type Opsgenie struct {
 
 type OpsgenieAlert struct {
 	Message     string            `json:"message"`
+	Alias       string            `json:"alias,omitempty"`
 	Description string            `json:"description"`
 	Details     map[string]string `json:"details"`
 }
@@ -74,6 +75,7 @@ func (s *Opsgenie) Post(ctx context.Context, event eventv1.Event) error {
 
 	payload := OpsgenieAlert{
 		Message:     event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+		Alias:       details[MetaCorrelationIDKey],
 		Description: event.Message,
 		Details:     details,
 	}
@@ -35,7 +35,7 @@ func TestOpsgenie_Post(t *testing.T) {
 		var payload OpsgenieAlert
 		err = json.Unmarshal(b, &payload)
 		require.NoError(t, err)
-
+		require.Equal(t, payload.Details[MetaCorrelationIDKey], payload.Alias)
 	}))
 	defer ts.Close()
 
@@ -55,6 +55,14 @@ func TestOpsgenie_Post(t *testing.T) {
 				return events
 			},
 		},
+		{
+			name: "test event with correlation ID",
+			event: func() v1beta1.Event {
+				events := testEvent()
+				events.Metadata[MetaCorrelationIDKey] = "abc123"
+				return events
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,6 +72,8 @@ func (p *PagerDuty) Post(ctx context.Context, event eventv1.Event) error {
 
 func toPagerDutyV2Event(event eventv1.Event, routingKey string) pagerduty.V2Event {
 	name, desc := formatNameAndDescription(event)
+	// The incidents are keyed by object, not by revision, for the fix of a
+	// failure, usually a new revision, to resolve them.
 	// Send resolve just in case an existing incident is open
 	e := pagerduty.V2Event{
 		RoutingKey: routingKey,
@@ -93,6 +95,9 @@ func toPagerDutyV2Event(event eventv1.Event, routingKey string) pagerduty.V2Even
 				"metadata": event.Metadata,
 			},
 		}
+		if id, ok := event.Metadata[MetaCorrelationIDKey]; ok && id != "" {
+			e.Payload.Details["correlation_id"] = id
+		}
 	}
 	return e
 }
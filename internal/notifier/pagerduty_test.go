@@ -142,6 +142,45 @@ func TestToPagerDutyV2Event(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "correlation ID",
+			e: eventv1.Event{
+				InvolvedObject: corev1.ObjectReference{
+					Kind:      "GitRepository",
+					Namespace: "flux-system",
+					Name:      "test-app",
+					UID:       "1234",
+				},
+				Severity:  "error",
+				Timestamp: metav1.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				Message:   "message",
+				Reason:    meta.FailedReason,
+				Metadata: map[string]string{
+					MetaCorrelationIDKey: "abc123",
+				},
+				ReportingController: "source-controller",
+			},
+			want: pagerduty.V2Event{
+				RoutingKey: routingKey,
+				Action:     "trigger",
+				DedupKey:   "1234",
+				Payload: &pagerduty.V2Payload{
+					Summary:   "failed: gitrepository/test-app",
+					Severity:  "error",
+					Source:    "Flux source-controller",
+					Timestamp: "2020-01-01T00:00:00Z",
+					Component: "test-app",
+					Group:     "GitRepository",
+					Details: map[string]interface{}{
+						"message": "message",
+						"metadata": map[string]string{
+							MetaCorrelationIDKey: "abc123",
+						},
+						"correlation_id": "abc123",
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// MetaPortEntityKey is the event metadata key holding the identifier of the
+// Port entity of the involved object, set with the
+// event.toolkit.fluxcd.io/port-entity annotation on the object.
+const MetaPortEntityKey = "port-entity"
+
+// Port is an implementation of the notification Interface that updates the
+// deployment state of the entities of a Port developer portal.
+type Port struct {
+	URL      string
+	ProxyURL string
+	CertPool *x509.CertPool
+	Token    string
+}
+
+// PortEntity is the request body upserting an entity with the Port API.
+type PortEntity struct {
+	Identifier string            `json:"identifier"`
+	Properties map[string]string `json:"properties"`
+}
+
+// NewPort returns a Port notifier upserting the entities of the given
+// blueprint with the API at the given address, e.g. https://api.getport.io.
+func NewPort(address string, proxyURL string, certPool *x509.CertPool, blueprint string, token string) (*Port, error) {
+	_, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Port API URL %s: '%w'", address, err)
+	}
+
+	if blueprint == "" {
+		return nil, errors.New("empty Port blueprint")
+	}
+
+	if token == "" {
+		return nil, errors.New("empty Port token")
+	}
+
+	return &Port{
+		URL: fmt.Sprintf("%s/v1/blueprints/%s/entities?upsert=true&merge=true",
+			strings.TrimSuffix(address, "/"), url.PathEscape(blueprint)),
+		ProxyURL: proxyURL,
+		CertPool: certPool,
+		Token:    token,
+	}, nil
+}
+
+// Post updates the deployment state of the Port entity of the involved
+// object. The trace events and the Git commit status updates are skipped.
+func (p *Port) Post(ctx context.Context, event eventv1.Event) error {
+	if event.Severity == eventv1.EventSeverityTrace ||
+		event.HasMetadata(eventv1.MetaCommitStatusKey, eventv1.MetaCommitStatusUpdateValue) {
+		return nil
+	}
+
+	identifier := event.Metadata[MetaPortEntityKey]
+	if identifier == "" {
+		identifier = event.InvolvedObject.Name
+	}
+
+	status := "Succeeded"
+	if event.Severity == eventv1.EventSeverityError {
+		status = "Failed"
+	}
+	properties := map[string]string{
+		"fluxStatus":    status,
+		"fluxReason":    event.Reason,
+		"fluxMessage":   event.Message,
+		"fluxUpdatedAt": event.Timestamp.UTC().Format(time.RFC3339),
+	}
+	if revision, ok := event.Metadata[eventv1.MetaRevisionKey]; ok {
+		properties["fluxRevision"] = revision
+	}
+
+	payload := PortEntity{
+		Identifier: identifier,
+		Properties: properties,
+	}
+
+	err := postMessage(ctx, p.URL, p.ProxyURL, p.CertPool, payload, func(req *retryablehttp.Request) {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	})
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+func TestPort_Post(t *testing.T) {
+	var requests int
+	var payload PortEntity
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "/v1/blueprints/service/entities", r.URL.Path)
+		require.Equal(t, "true", r.URL.Query().Get("upsert"))
+		require.Equal(t, "true", r.URL.Query().Get("merge"))
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	port, err := NewPort(ts.URL+"/", "", nil, "service", "token")
+	require.NoError(t, err)
+
+	event := testEvent()
+	event.Metadata[eventv1.MetaRevisionKey] = "main@sha1:1234"
+	require.NoError(t, port.Post(context.TODO(), event))
+	require.Equal(t, "webapp", payload.Identifier)
+	require.Equal(t, "Succeeded", payload.Properties["fluxStatus"])
+	require.Equal(t, "reason", payload.Properties["fluxReason"])
+	require.Equal(t, "message", payload.Properties["fluxMessage"])
+	require.Equal(t, "main@sha1:1234", payload.Properties["fluxRevision"])
+	require.NotEmpty(t, payload.Properties["fluxUpdatedAt"])
+
+	// The entity identifier is read from the event metadata.
+	event.Severity = eventv1.EventSeverityError
+	event.Metadata[MetaPortEntityKey] = "podinfo-production"
+	require.NoError(t, port.Post(context.TODO(), event))
+	require.Equal(t, "podinfo-production", payload.Identifier)
+	require.Equal(t, "Failed", payload.Properties["fluxStatus"])
+
+	// Trace events are skipped.
+	event.Severity = eventv1.EventSeverityTrace
+	require.NoError(t, port.Post(context.TODO(), event))
+	require.Equal(t, 2, requests)
+}
+
+func TestPort_Validation(t *testing.T) {
+	_, err := NewPort("https://api.getport.io", "", nil, "", "token")
+	require.ErrorContains(t, err, "empty Port blueprint")
+
+	_, err = NewPort("https://api.getport.io", "", nil, "service", "")
+	require.ErrorContains(t, err, "empty Port token")
+}
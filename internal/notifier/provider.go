@@ -0,0 +1,423 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// NewForProvider returns the notifier of the given Provider, along with
+// the secret values of the Provider, i.e. the token, password, headers,
+// and the credentials of the addresses, to be redacted from the messages.
+// The Secrets and ConfigMaps of the Provider are read with the given
+// reader, e.g. a cache, while the notifiers use the kubeClient. The proxy
+// is bypassed for the in-cluster addresses and the addresses matching the
+// noProxy entries.
+func NewForProvider(ctx context.Context, kubeClient client.Client, secretsReader client.Reader, provider apiv1.Provider, noProxy []string) (Interface, []string, error) {
+	factory, secrets, err := newProviderFactory(ctx, kubeClient, secretsReader, provider, noProxy)
+	if err != nil {
+		return nil, nil, err
+	}
+	sender, err := factory.Notifier(provider.Spec.Type)
+	if err != nil {
+		return nil, nil, RedactError(fmt.Errorf("failed to initialize notifier: %w", err), secrets)
+	}
+	return sender, secrets, nil
+}
+
+// WarmUpProvider resolves the hostname of the endpoint of the given
+// Provider, or of its proxy, and connects to it without sending a
+// notification, so that the misconfigured endpoints are reported before
+// the first alert. The errors are redacted from the Provider secrets.
+func WarmUpProvider(ctx context.Context, kubeClient client.Client, provider apiv1.Provider, noProxy []string) error {
+	factory, secrets, err := newProviderFactory(ctx, kubeClient, kubeClient, provider, noProxy)
+	if err != nil {
+		return err
+	}
+	if err := factory.WarmUp(WithResolver(ctx, ProviderResolver(&provider))); err != nil {
+		return RedactError(err, secrets)
+	}
+	return nil
+}
+
+// newProviderFactory returns the notifier factory of the given Provider,
+// configured with its addresses and the values of its Secrets, along with
+// the secret values to be redacted. The Secrets and ConfigMaps are read
+// with the secretsReader, and the notifiers are given the kubeClient.
+func newProviderFactory(ctx context.Context, kubeClient client.Client, secretsReader client.Reader, provider apiv1.Provider, noProxy []string) (*Factory, providerSecrets, error) {
+	logger := log.FromContext(ctx)
+
+	webhook := provider.Spec.Address
+	username := provider.Spec.Username
+	proxy := ""
+	token := ""
+	tokenType := ""
+	password := ""
+	headers := make(map[string]string)
+	var secrets providerSecrets
+	var encryptionKey *rsa.PublicKey
+	if ref := provider.Spec.AddressFrom; ref != nil {
+		var configMap corev1.ConfigMap
+		configMapName := types.NamespacedName{Namespace: provider.Namespace, Name: ref.ConfigMapKeyRef.Name}
+
+		err := secretsReader.Get(ctx, configMapName, &configMap)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read address configmap: %w", err)
+		}
+
+		val, ok := configMap.Data[ref.ConfigMapKeyRef.Key]
+		if !ok {
+			return nil, nil, fmt.Errorf("no '%s' key found in ConfigMap '%s'", ref.ConfigMapKeyRef.Key, configMap.Name)
+		}
+		if len(val) > 2048 {
+			return nil, nil, fmt.Errorf("invalid address in configmap: address exceeds maximum length of %d bytes", 2048)
+		}
+		webhook = strings.TrimSpace(val)
+	}
+
+	if provider.Spec.SecretRef != nil {
+		var secret corev1.Secret
+		secretName := types.NamespacedName{Namespace: provider.Namespace, Name: provider.Spec.SecretRef.Name}
+
+		err := secretsReader.Get(ctx, secretName, &secret)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read secret: %w", err)
+		}
+
+		if val, ok := secret.Data["address"]; ok {
+			if len(val) > 2048 {
+				return nil, nil, fmt.Errorf("invalid address in secret: address exceeds maximum length of %d bytes", 2048)
+			}
+			webhook = strings.TrimSpace(string(val))
+			// The whole address is a secret when read from the Secret,
+			// e.g. for the webhook URLs embedding a token in their path.
+			secrets.add(webhook)
+		}
+
+		if val, ok := secret.Data["password"]; ok {
+			password = strings.TrimSpace(string(val))
+		}
+
+		if val, ok := secret.Data["token"]; ok {
+			token = strings.TrimSpace(string(val))
+		}
+
+		if val, ok := secret.Data["tokenType"]; ok {
+			tokenType = strings.TrimSpace(string(val))
+		}
+
+		if val, ok := secret.Data["username"]; ok {
+			username = strings.TrimSpace(string(val))
+		}
+
+		// The JWE public key is optional, it's used to encrypt the payload of
+		// the generic Providers.
+		if val, ok := secret.Data["jwePublicKey"]; ok && (provider.Spec.Type == apiv1.GenericProvider ||
+			provider.Spec.Type == apiv1.GenericHMACProvider) {
+			encryptionKey, err = ParseJWEPublicKey(val)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid JWE public key in secret: %w", err)
+			}
+		}
+
+		if h, ok := secret.Data["headers"]; ok {
+			err := yaml.Unmarshal(h, &headers)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read headers from secret: %w", err)
+			}
+		}
+
+		var values []string
+		webhook, values, err = expandAddressPlaceholders(webhook, &secret)
+		if err != nil {
+			return nil, nil, err
+		}
+		secrets.add(values...)
+	}
+
+	if provider.Spec.ProxySecretRef != nil {
+		var secret corev1.Secret
+		secretName := types.NamespacedName{Namespace: provider.Namespace, Name: provider.Spec.ProxySecretRef.Name}
+
+		err := secretsReader.Get(ctx, secretName, &secret)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read proxy secret: %w", err)
+		}
+
+		proxyURL, err := proxyURLFromSecret(&secret)
+		if err != nil {
+			return nil, nil, err
+		}
+		proxy = proxyURL.String()
+	}
+
+	var certPool *x509.CertPool
+	var clientCert *tls.Certificate
+	if provider.Spec.CertSecretRef != nil {
+		var secret corev1.Secret
+		secretName := types.NamespacedName{Namespace: provider.Namespace, Name: provider.Spec.CertSecretRef.Name}
+
+		err := secretsReader.Get(ctx, secretName, &secret)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read cert secret: %w", err)
+		}
+
+		switch secret.Type {
+		case corev1.SecretTypeOpaque, corev1.SecretTypeTLS, "":
+		default:
+			return nil, nil, fmt.Errorf("cannot use Secret '%s' to get TLS certificate: invalid Secret type: '%s'", secret.Name, secret.Type)
+		}
+
+		caFile, ok := secret.Data["ca.crt"]
+		if !ok {
+			// TODO: Drop support for "caFile" field in v1 Provider API.
+			caFile, ok = secret.Data["caFile"]
+			if !ok {
+				return nil, nil, fmt.Errorf("no 'ca.crt' key found in Secret '%s'", secret.Name)
+			}
+			logger.Info("warning: specifying CA cert via 'caFile' is deprecated, please use 'ca.crt' instead")
+		}
+
+		certPool = x509.NewCertPool()
+		ok = certPool.AppendCertsFromPEM(caFile)
+		if !ok {
+			return nil, nil, fmt.Errorf("could not append to cert pool")
+		}
+
+		// The client certificate is optional, it's used for mTLS by the
+		// fluxrelay Providers.
+		if provider.Spec.Type == apiv1.FluxRelayProvider {
+			certPEM, keyPEM := secret.Data["tls.crt"], secret.Data["tls.key"]
+			if len(certPEM) > 0 && len(keyPEM) > 0 {
+				cert, err := tls.X509KeyPair(certPEM, keyPEM)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to parse the client certificate in Secret '%s': %w", secret.Name, err)
+				}
+				clientCert = &cert
+			}
+		}
+	}
+
+	// The azuredevops Providers can authenticate with an Entra access
+	// token obtained for a ServiceAccount instead of a personal access token.
+	if sa := provider.Spec.ServiceAccountName; sa != "" {
+		if !Capabilities(provider.Spec.Type).WorkloadIdentity {
+			return nil, nil, fmt.Errorf("serviceAccountName is not supported by the '%s' Provider", provider.Spec.Type)
+		}
+		var err error
+		token, err = AzureDevOpsWorkloadIdentityToken(ctx, kubeClient, provider.Namespace, sa)
+		if err != nil {
+			return nil, nil, err
+		}
+		tokenType = "bearer"
+	}
+
+	if webhook == "" {
+		return nil, nil, fmt.Errorf("provider has no address")
+	}
+
+	secrets.add(token, password)
+	for _, v := range headers {
+		secrets.add(v)
+	}
+	secrets.addURL(webhook)
+	secrets.addURL(proxy)
+
+	if proxy != "" && proxyBypassed(webhook, noProxy) {
+		proxy = ""
+	}
+
+	var metadataFields MetadataFields
+	if f := provider.Spec.MetadataFields; f != nil {
+		metadataFields = MetadataFields{Order: f.Order, Exclude: f.Exclude}
+	}
+
+	var hmacOptions HMACOptions
+	if h := provider.Spec.HMAC; h != nil {
+		hmacOptions = HMACOptions{Header: h.Header, Algorithm: h.Algorithm}
+	}
+
+	var gitHubDispatchOptions GitHubDispatchOptions
+	if d := provider.Spec.GitHubDispatch; d != nil {
+		gitHubDispatchOptions = GitHubDispatchOptions{Workflow: d.Workflow, Ref: d.Ref, Inputs: d.Inputs}
+	}
+
+	var appearance Appearance
+	if a := provider.Spec.Appearance; a != nil {
+		appearance = Appearance{Colors: map[string]string{}, Emojis: map[string]string{}}
+		for severity, sa := range map[string]*apiv1.SeverityAppearance{
+			eventv1.EventSeverityInfo:  a.Info,
+			eventv1.EventSeverityError: a.Error,
+		} {
+			if sa != nil {
+				appearance.Colors[severity] = sa.Color
+				appearance.Emojis[severity] = sa.Emoji
+			}
+		}
+	}
+
+	factory := NewFactory(FactoryOptions{
+		URL:             webhook,
+		ProxyURL:        proxy,
+		Username:        username,
+		Channel:         provider.Spec.Channel,
+		Token:           token,
+		Headers:         headers,
+		CertPool:        certPool,
+		Password:        password,
+		ProviderUID:     string(provider.UID),
+		MetadataFields:  metadataFields,
+		APIBaseURL:      provider.Spec.APIBaseURL,
+		TokenType:       tokenType,
+		CommitStatusKey: provider.Spec.CommitStatusKey,
+		ClientCert:      clientCert,
+		EncryptionKey:   encryptionKey,
+		HMAC:            hmacOptions,
+		GitHubDispatch:  gitHubDispatchOptions,
+		Appearance:      appearance,
+		KubeClient:      kubeClient,
+		Namespace:       provider.Namespace,
+	})
+	return factory, secrets, nil
+}
+
+// inClusterNoProxy lists the in-cluster domains for which the Provider
+// proxy is always bypassed.
+var inClusterNoProxy = []string{".svc", ".cluster.local"}
+
+// proxyBypassed returns true if the requests to the given address must not go
+// through the Provider proxy, either because the address is an in-cluster
+// service address or because it matches one of the noProxy entries.
+// The entries follow the NO_PROXY environment variable format.
+func proxyBypassed(address string, noProxy []string) bool {
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return false
+	}
+
+	// Single-label hosts such as 'alertmanager' are resolved against the
+	// cluster search domains.
+	host := u.Hostname()
+	if net.ParseIP(host) == nil && !strings.Contains(host, ".") {
+		return true
+	}
+
+	cfg := httpproxy.Config{
+		HTTPProxy:  "http://proxy",
+		HTTPSProxy: "http://proxy",
+		NoProxy:    strings.Join(append(slices.Clone(inClusterNoProxy), noProxy...), ","),
+	}
+	proxyURL, err := cfg.ProxyFunc()(u)
+	return err == nil && proxyURL == nil
+}
+
+// addressPlaceholder matches the ${key} placeholders of a Provider address,
+// the key being the name of a key of the Provider Secret.
+var addressPlaceholder = regexp.MustCompile(`\$\{([-._a-zA-Z0-9]+)\}`)
+
+// expandAddressPlaceholders substitutes the ${key} placeholders of the address
+// with the values of the matching keys of the given Secret, and returns the
+// substituted values to be redacted from the messages.
+func expandAddressPlaceholders(address string, secret *corev1.Secret) (string, []string, error) {
+	var values []string
+	var err error
+	expanded := addressPlaceholder.ReplaceAllStringFunc(address, func(placeholder string) string {
+		key := addressPlaceholder.FindStringSubmatch(placeholder)[1]
+		val, ok := secret.Data[key]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("no '%s' key found in Secret '%s' for the address placeholder", key, secret.Name)
+			}
+			return placeholder
+		}
+		value := strings.TrimSpace(string(val))
+		values = append(values, value)
+		return value
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if len(expanded) > 2048 {
+		return "", nil, fmt.Errorf("invalid address: address exceeds maximum length of %d bytes", 2048)
+	}
+	return expanded, values, nil
+}
+
+// ProviderResolver returns the notifier Resolver of the given Provider,
+// or nil when the Provider has no DNS configuration.
+func ProviderResolver(provider *apiv1.Provider) *Resolver {
+	dns := provider.Spec.DNS
+	if dns == nil || (dns.Nameserver == "" && len(dns.HostAliases) == 0) {
+		return nil
+	}
+	r := &Resolver{
+		HostAliases: make(map[string]string),
+		Nameserver:  dns.Nameserver,
+	}
+	for _, alias := range dns.HostAliases {
+		for _, hostname := range alias.Hostnames {
+			r.HostAliases[strings.ToLower(hostname)] = alias.IP
+		}
+	}
+	return r
+}
+
+// proxyURLFromSecret returns the proxy URL from the `address` key of the
+// given Secret, with the credentials from the `username` and `password` keys.
+func proxyURLFromSecret(secret *corev1.Secret) (*url.URL, error) {
+	address, ok := secret.Data["address"]
+	if !ok {
+		return nil, fmt.Errorf("no 'address' key found in proxy Secret '%s'", secret.Name)
+	}
+	proxyURL, err := url.Parse(strings.TrimSpace(string(address)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy address in Secret '%s': %w", secret.Name, err)
+	}
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid proxy address in Secret '%s': scheme must be http or https", secret.Name)
+	}
+
+	username, hasUsername := secret.Data["username"]
+	password, hasPassword := secret.Data["password"]
+	if hasUsername != hasPassword {
+		return nil, fmt.Errorf("invalid proxy Secret '%s': 'username' and 'password' keys must be set together", secret.Name)
+	}
+	if hasUsername {
+		proxyURL.User = url.UserPassword(strings.TrimSpace(string(username)), strings.TrimSpace(string(password)))
+	}
+	return proxyURL, nil
+}
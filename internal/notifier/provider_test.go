@@ -0,0 +1,541 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestNewForProvider(t *testing.T) {
+	secretName := "foo-secret"
+	certSecretName := "cert-secret"
+	proxySecretName := "proxy-secret"
+	configMapName := "foo-configmap"
+	tests := []struct {
+		name            string
+		providerSpec    *apiv1.ProviderSpec
+		secretType      corev1.SecretType
+		secretData      map[string][]byte
+		certSecretData  map[string][]byte
+		proxySecretData map[string][]byte
+		configMapData   map[string]string
+		wantErr         bool
+		wantAddress     string
+	}{
+		{
+			name: "no address, no secret ref",
+			providerSpec: &apiv1.ProviderSpec{
+				Type: "slack",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid address, no secret ref",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:    "slack",
+				Address: "https://example.com",
+			},
+		},
+		{
+			name: "reference to non-existing secret ref",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:      "slack",
+				SecretRef: &meta.LocalObjectReference{Name: "foo"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "reference to secret with valid address, proxy, headers",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:           "slack",
+				SecretRef:      &meta.LocalObjectReference{Name: secretName},
+				ProxySecretRef: &meta.LocalObjectReference{Name: proxySecretName},
+			},
+			secretData: map[string][]byte{
+				"address": []byte("https://example.com"),
+				"headers": []byte(`foo: bar`),
+			},
+			proxySecretData: map[string][]byte{
+				"address": []byte("https://exampleproxy.com"),
+			},
+		},
+		{
+			name: "reference to proxy secret with invalid address",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:           "slack",
+				Address:        "https://example.com",
+				ProxySecretRef: &meta.LocalObjectReference{Name: proxySecretName},
+			},
+			proxySecretData: map[string][]byte{
+				"address": []byte("https://exampleproxy.com|"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid headers in secret reference",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:      "slack",
+				SecretRef: &meta.LocalObjectReference{Name: secretName},
+			},
+			secretData: map[string][]byte{
+				"address": []byte("https://example.com"),
+				"headers": []byte("foo"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid spec address overridden by valid secret ref address",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:      "slack",
+				SecretRef: &meta.LocalObjectReference{Name: secretName},
+				Address:   "https://example.com|",
+			},
+			secretData: map[string][]byte{
+				"address": []byte("https://example.com"),
+			},
+		},
+		{
+			name: "reference to proxy secret with credentials",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:           "generic",
+				Address:        "https://example.com",
+				ProxySecretRef: &meta.LocalObjectReference{Name: proxySecretName},
+			},
+			proxySecretData: map[string][]byte{
+				"address":  []byte("http://exampleproxy.com:8080"),
+				"username": []byte("user"),
+				"password": []byte("pass"),
+			},
+		},
+		{
+			name: "reference to proxy secret with username and no password",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:           "generic",
+				Address:        "https://example.com",
+				ProxySecretRef: &meta.LocalObjectReference{Name: proxySecretName},
+			},
+			proxySecretData: map[string][]byte{
+				"address":  []byte("http://exampleproxy.com:8080"),
+				"username": []byte("user"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "reference to proxy secret with no address",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:           "generic",
+				Address:        "https://example.com",
+				ProxySecretRef: &meta.LocalObjectReference{Name: proxySecretName},
+			},
+			proxySecretData: map[string][]byte{
+				"proxy": []byte("http://exampleproxy.com:8080"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "reference to non-existing proxy secret",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:           "generic",
+				Address:        "https://example.com",
+				ProxySecretRef: &meta.LocalObjectReference{Name: proxySecretName},
+			},
+			wantErr: true,
+		},
+		{
+			name: "reference to unsupported cert secret type",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:          "slack",
+				Address:       "https://example.com",
+				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
+			},
+			secretType: corev1.SecretTypeDockercfg,
+			certSecretData: map[string][]byte{
+				"aaa": []byte("bbb"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "reference to non-existing cert secret",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:          "slack",
+				Address:       "https://example.com",
+				CertSecretRef: &meta.LocalObjectReference{Name: "some-secret"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "reference to cert secret without cert data",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:          "slack",
+				Address:       "https://example.com",
+				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
+			},
+			certSecretData: map[string][]byte{
+				"aaa": []byte("bbb"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "cert secret reference in ca.crt with valid CA",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:          "slack",
+				Address:       "https://example.com",
+				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
+			},
+			certSecretData: map[string][]byte{
+				// Based on https://pkg.go.dev/crypto/tls#X509KeyPair example.
+				"ca.crt": []byte(`-----BEGIN CERTIFICATE-----
+MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
+DgYDVQQKEwdBY21lIENvMB4XDTE3MTAyMDE5NDMwNloXDTE4MTAyMDE5NDMwNlow
+EjEQMA4GA1UEChMHQWNtZSBDbzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABD0d
+7VNhbWvZLWPuj/RtHFjvtJBEwOkhbN/BnnE8rnZR8+sbwnc/KhCk3FhnpHZnQz7B
+5aETbbIgmuvewdjvSBSjYzBhMA4GA1UdDwEB/wQEAwICpDATBgNVHSUEDDAKBggr
+BgEFBQcDATAPBgNVHRMBAf8EBTADAQH/MCkGA1UdEQQiMCCCDmxvY2FsaG9zdDo1
+NDUzgg4xMjcuMC4wLjE6NTQ1MzAKBggqhkjOPQQDAgNIADBFAiEA2zpJEPQyz6/l
+Wf86aX6PepsntZv2GYlA5UpabfT2EZICICpJ5h/iI+i341gBmLiAFQOyTDT+/wQc
+6MF9+Yw1Yy0t
+-----END CERTIFICATE-----`),
+			},
+		},
+		{
+			name: "cert secret reference in caFile with valid CA",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:          "slack",
+				Address:       "https://example.com",
+				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
+			},
+			certSecretData: map[string][]byte{
+				// Based on https://pkg.go.dev/crypto/tls#X509KeyPair example.
+				"caFile": []byte(`-----BEGIN CERTIFICATE-----
+MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
+DgYDVQQKEwdBY21lIENvMB4XDTE3MTAyMDE5NDMwNloXDTE4MTAyMDE5NDMwNlow
+EjEQMA4GA1UEChMHQWNtZSBDbzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABD0d
+7VNhbWvZLWPuj/RtHFjvtJBEwOkhbN/BnnE8rnZR8+sbwnc/KhCk3FhnpHZnQz7B
+5aETbbIgmuvewdjvSBSjYzBhMA4GA1UdDwEB/wQEAwICpDATBgNVHSUEDDAKBggr
+BgEFBQcDATAPBgNVHRMBAf8EBTADAQH/MCkGA1UdEQQiMCCCDmxvY2FsaG9zdDo1
+NDUzgg4xMjcuMC4wLjE6NTQ1MzAKBggqhkjOPQQDAgNIADBFAiEA2zpJEPQyz6/l
+Wf86aX6PepsntZv2GYlA5UpabfT2EZICICpJ5h/iI+i341gBmLiAFQOyTDT+/wQc
+6MF9+Yw1Yy0t
+-----END CERTIFICATE-----`),
+			},
+		},
+		{
+			name: "cert secret reference in both ca.crt and caFile",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:          "slack",
+				Address:       "https://example.com",
+				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
+			},
+			certSecretData: map[string][]byte{
+				// Based on https://pkg.go.dev/crypto/tls#X509KeyPair example.
+				"ca.crt": []byte(`-----BEGIN CERTIFICATE-----
+MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
+DgYDVQQKEwdBY21lIENvMB4XDTE3MTAyMDE5NDMwNloXDTE4MTAyMDE5NDMwNlow
+EjEQMA4GA1UEChMHQWNtZSBDbzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABD0d
+7VNhbWvZLWPuj/RtHFjvtJBEwOkhbN/BnnE8rnZR8+sbwnc/KhCk3FhnpHZnQz7B
+5aETbbIgmuvewdjvSBSjYzBhMA4GA1UdDwEB/wQEAwICpDATBgNVHSUEDDAKBggr
+BgEFBQcDATAPBgNVHRMBAf8EBTADAQH/MCkGA1UdEQQiMCCCDmxvY2FsaG9zdDo1
+NDUzgg4xMjcuMC4wLjE6NTQ1MzAKBggqhkjOPQQDAgNIADBFAiEA2zpJEPQyz6/l
+Wf86aX6PepsntZv2GYlA5UpabfT2EZICICpJ5h/iI+i341gBmLiAFQOyTDT+/wQc
+6MF9+Yw1Yy0t
+-----END CERTIFICATE-----`),
+				"caFile": []byte(`aaaaa`), // invalid
+			},
+		},
+		{
+			name: "cert secret reference with invalid CA",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:          "slack",
+				Address:       "https://example.com",
+				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
+			},
+			certSecretData: map[string][]byte{
+				"ca.crt": []byte(`aaaaa`),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported provider",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:    "foo",
+				Address: "https://example.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "address from configmap",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:    "generic",
+				Address: "https://example.com/spec",
+				AddressFrom: &apiv1.AddressSource{
+					ConfigMapKeyRef: apiv1.ConfigMapKeyReference{Name: configMapName, Key: "url"},
+				},
+			},
+			configMapData: map[string]string{
+				"url": "https://example.com/configmap\n",
+			},
+			wantAddress: "https://example.com/configmap",
+		},
+		{
+			name: "address from configmap overridden by secret address",
+			providerSpec: &apiv1.ProviderSpec{
+				Type: "generic",
+				AddressFrom: &apiv1.AddressSource{
+					ConfigMapKeyRef: apiv1.ConfigMapKeyReference{Name: configMapName, Key: "url"},
+				},
+				SecretRef: &meta.LocalObjectReference{Name: secretName},
+			},
+			configMapData: map[string]string{
+				"url": "https://example.com/configmap",
+			},
+			secretData: map[string][]byte{
+				"address": []byte("https://example.com/secret"),
+			},
+			wantAddress: "https://example.com/secret",
+		},
+		{
+			name: "reference to non-existing configmap",
+			providerSpec: &apiv1.ProviderSpec{
+				Type: "generic",
+				AddressFrom: &apiv1.AddressSource{
+					ConfigMapKeyRef: apiv1.ConfigMapKeyReference{Name: "foo", Key: "url"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "reference to missing configmap key",
+			providerSpec: &apiv1.ProviderSpec{
+				Type: "generic",
+				AddressFrom: &apiv1.AddressSource{
+					ConfigMapKeyRef: apiv1.ConfigMapKeyReference{Name: configMapName, Key: "url"},
+				},
+			},
+			configMapData: map[string]string{
+				"address": "https://example.com/configmap",
+			},
+			wantErr: true,
+		},
+		{
+			name: "address with placeholders from secret",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:      "generic",
+				Address:   "https://sms.example.com/send/${token}?account=${account-id}",
+				SecretRef: &meta.LocalObjectReference{Name: secretName},
+			},
+			secretData: map[string][]byte{
+				"token":      []byte("s3cr3t"),
+				"account-id": []byte("1234\n"),
+			},
+			wantAddress: "https://sms.example.com/send/s3cr3t?account=1234",
+		},
+		{
+			name: "address with placeholder missing from secret",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:      "generic",
+				Address:   "https://sms.example.com/send/${apikey}",
+				SecretRef: &meta.LocalObjectReference{Name: secretName},
+			},
+			secretData: map[string][]byte{
+				"token": []byte("s3cr3t"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "address in secret too long",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:      "msteams",
+				SecretRef: &meta.LocalObjectReference{Name: secretName},
+			},
+			secretData: map[string][]byte{
+				"address": []byte(fmt.Sprintf("https://example.org/%s", strings.Repeat("a", 2029))),
+			},
+			wantErr: true,
+		},
+		{
+			name: "address in secret exactly as long as possible",
+			providerSpec: &apiv1.ProviderSpec{
+				Type:      "msteams",
+				SecretRef: &meta.LocalObjectReference{Name: secretName},
+			},
+			secretData: map[string][]byte{
+				"address": []byte(fmt.Sprintf("https://example.org/%s", strings.Repeat("a", 2028))),
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create fake objects.
+			scheme := runtime.NewScheme()
+			require.NoError(t, apiv1.AddToScheme(scheme))
+			require.NoError(t, corev1.AddToScheme(scheme))
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if tt.secretData != nil {
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: secretName},
+					Data:       tt.secretData,
+				}
+				builder.WithObjects(secret)
+			}
+			if tt.certSecretData != nil {
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: certSecretName},
+					Type:       tt.secretType,
+					Data:       tt.certSecretData,
+				}
+				builder.WithObjects(secret)
+			}
+			if tt.proxySecretData != nil {
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: proxySecretName},
+					Data:       tt.proxySecretData,
+				}
+				builder.WithObjects(secret)
+			}
+			if tt.configMapData != nil {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: configMapName},
+					Data:       tt.configMapData,
+				}
+				builder.WithObjects(configMap)
+			}
+			provider := apiv1.Provider{Spec: *tt.providerSpec}
+
+			kubeClient := builder.Build()
+			n, _, err := NewForProvider(context.TODO(), kubeClient, kubeClient, provider, nil)
+			require.Equal(t, tt.wantErr, err != nil)
+			if tt.wantAddress != "" {
+				require.IsType(t, &Forwarder{}, n)
+				require.Equal(t, tt.wantAddress, n.(*Forwarder).URL)
+			}
+		})
+	}
+}
+
+func TestNewForProvider_secretsReader(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().Build()
+	secretsReader := fake.NewClientBuilder().Build()
+	provider := apiv1.Provider{
+		ObjectMeta: metav1.ObjectMeta{Name: "board", Namespace: "default"},
+		Spec: apiv1.ProviderSpec{
+			Type:    apiv1.StatusBoardProvider,
+			Address: "status-board",
+		},
+	}
+
+	// The notifiers update their objects with the uncached client.
+	n, _, err := NewForProvider(context.TODO(), kubeClient, secretsReader, provider, nil)
+	require.NoError(t, err)
+	require.IsType(t, &StatusBoard{}, n)
+	require.Same(t, kubeClient, n.(*StatusBoard).Client)
+}
+
+func TestProviderResolver(t *testing.T) {
+	require.Nil(t, ProviderResolver(&apiv1.Provider{}))
+	require.Nil(t, ProviderResolver(&apiv1.Provider{Spec: apiv1.ProviderSpec{DNS: &apiv1.ProviderDNS{}}}))
+
+	r := ProviderResolver(&apiv1.Provider{
+		Spec: apiv1.ProviderSpec{
+			DNS: &apiv1.ProviderDNS{
+				Nameserver: "10.0.0.10",
+				HostAliases: []apiv1.HostAlias{
+					{IP: "10.0.0.5", Hostnames: []string{"Hooks.example.com", "alerts.example.com"}},
+				},
+			},
+		},
+	})
+	require.Equal(t, &Resolver{
+		Nameserver: "10.0.0.10",
+		HostAliases: map[string]string{
+			"hooks.example.com":  "10.0.0.5",
+			"alerts.example.com": "10.0.0.5",
+		},
+	}, r)
+}
+
+func TestProxyBypassed(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		noProxy []string
+		want    bool
+	}{
+		{
+			name:    "public address",
+			address: "https://hooks.slack.com/services/x",
+			want:    false,
+		},
+		{
+			name:    "short service name",
+			address: "http://alertmanager:9093/api/v2/alerts",
+			want:    true,
+		},
+		{
+			name:    "service address",
+			address: "http://alertmanager.monitoring.svc:9093/api/v2/alerts",
+			want:    true,
+		},
+		{
+			name:    "fully qualified service address",
+			address: "http://alertmanager.monitoring.svc.cluster.local:9093/api/v2/alerts",
+			want:    true,
+		},
+		{
+			name:    "no-proxy domain",
+			address: "https://chat.internal.example.com/hooks/x",
+			noProxy: []string{".internal.example.com"},
+			want:    true,
+		},
+		{
+			name:    "no-proxy CIDR",
+			address: "http://10.0.12.4:8080/",
+			noProxy: []string{"10.0.0.0/16"},
+			want:    true,
+		},
+		{
+			name:    "IP outside of no-proxy CIDR",
+			address: "http://10.1.12.4:8080/",
+			noProxy: []string{"10.0.0.0/16"},
+			want:    false,
+		},
+		{
+			name:    "non-HTTP address",
+			address: "nats://nats",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, proxyBypassed(tt.address, tt.noProxy))
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+
+	"github.com/fluxcd/pkg/masktoken"
+)
+
+// minRedactedLength is the minimum length of the secret values redacted from
+// the messages, as shorter values, e.g. a header set to "1", would garble
+// them without leaking anything meaningful.
+const minRedactedLength = 4
+
+// providerSecrets collects the secret values of a Provider to be redacted
+// from the error messages, logs and Kubernetes events.
+type providerSecrets []string
+
+// add adds the given values to the secrets.
+func (p *providerSecrets) add(values ...string) {
+	for _, v := range values {
+		if len(v) >= minRedactedLength {
+			*p = append(*p, v)
+		}
+	}
+}
+
+// addURL adds the password and the query parameter values of the given URL
+// to the secrets.
+func (p *providerSecrets) addURL(address string) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return
+	}
+	if password, ok := u.User.Password(); ok {
+		p.add(password)
+	}
+	for _, values := range u.Query() {
+		p.add(values...)
+	}
+}
+
+// redact replaces the secret values in the given string with "*****".
+// The longest values are redacted first, so that a secret containing
+// another one is redacted as a whole.
+func redact(s string, secrets []string) string {
+	sorted := make([]string, len(secrets))
+	copy(sorted, secrets)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+	for _, secret := range sorted {
+		masked, err := masktoken.MaskTokenFromString(s, secret)
+		if err != nil {
+			continue
+		}
+		s = masked
+	}
+	return s
+}
+
+// RedactError returns the given error with the secret values redacted from
+// its message, or nil if the error is nil.
+func RedactError(err error, secrets []string) error {
+	if err == nil || len(secrets) == 0 {
+		return err
+	}
+	return errors.New(redact(err.Error(), secrets))
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Resolver configures how the hostnames of the provider endpoints are
+// resolved, e.g. in split-horizon DNS environments where the webhook
+// hostnames resolve differently than with the node resolver.
+type Resolver struct {
+	// HostAliases maps the hostnames to the IP addresses they resolve to,
+	// bypassing the DNS resolution.
+	HostAliases map[string]string
+
+	// Nameserver is the address of the DNS server resolving the hostnames
+	// not listed in HostAliases, in the form host:port. The port defaults
+	// to 53 when omitted.
+	Nameserver string
+}
+
+type resolverContextKey struct{}
+
+// WithResolver returns a copy of the context carrying the given Resolver,
+// which is used to resolve the hostnames of the requests sent to the
+// provider with the returned context. A nil Resolver leaves the context as is.
+func WithResolver(ctx context.Context, r *Resolver) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, resolverContextKey{}, r)
+}
+
+// resolverFromContext returns the Resolver carried by the given context,
+// or nil when the context doesn't carry one.
+func resolverFromContext(ctx context.Context) *Resolver {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(resolverContextKey{}).(*Resolver)
+	return r
+}
+
+// nameserver returns the address of the DNS server with the default port.
+func (r *Resolver) nameserver() string {
+	if _, _, err := net.SplitHostPort(r.Nameserver); err == nil {
+		return r.Nameserver
+	}
+	return net.JoinHostPort(strings.Trim(r.Nameserver, "[]"), "53")
+}
+
+// lookupHost resolves the given host with the DNS server of the Resolver.
+func (r *Resolver) lookupHost(ctx context.Context, host string) ([]string, error) {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, r.nameserver())
+		},
+	}
+	return resolver.LookupHost(ctx, host)
+}
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// resolverDialContext wraps the given dial function, or the one of the
+// default transport when nil, to resolve the dialed hostnames with the
+// Resolver of the request context, if any.
+func resolverDialContext(dial dialFunc) dialFunc {
+	if dial == nil {
+		dial = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		r := resolverFromContext(ctx)
+		if r == nil {
+			return dial(ctx, network, address)
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return dial(ctx, network, address)
+		}
+		if ip, ok := r.HostAliases[strings.ToLower(host)]; ok {
+			return dial(ctx, network, net.JoinHostPort(ip, port))
+		}
+		if r.Nameserver == "" || net.ParseIP(host) != nil {
+			return dial(ctx, network, address)
+		}
+		addrs, err := r.lookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var errs []error
+		for _, addr := range addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(addr, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// defaultResolverTransport is the default transport resolving the
+// hostnames with the Resolver of the request context.
+var defaultResolverTransport = func() *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.DialContext = resolverDialContext(tr.DialContext)
+	return tr
+}()
+
+// withResolver sets the dial function of the given transport to resolve
+// the hostnames with the Resolver of the request context. The default
+// transport, or nil, is replaced with a shared clone so that the global
+// default transport is left untouched.
+func withResolver(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil || rt == http.DefaultTransport {
+		return defaultResolverTransport
+	}
+	if tr, ok := rt.(*http.Transport); ok {
+		tr.DialContext = resolverDialContext(tr.DialContext)
+	}
+	return rt
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestPostMessage_ResolverHostAliases(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	address := "http://webhook.flux.invalid:" + u.Port()
+
+	ctx := WithResolver(context.Background(), &Resolver{
+		HostAliases: map[string]string{"webhook.flux.invalid": "127.0.0.1"},
+	})
+	require.NoError(t, postMessage(ctx, address, "", nil, map[string]string{}))
+}
+
+func TestPostMessage_ResolverNameserver(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	nameserver := newTestNameserver(t, "webhook.flux.internal.", [4]byte{127, 0, 0, 1})
+	ctx := WithResolver(context.Background(), &Resolver{Nameserver: nameserver})
+	require.NoError(t, postMessage(ctx, "http://webhook.flux.internal:"+u.Port(), "", nil, map[string]string{}))
+}
+
+func TestResolver_nameserver(t *testing.T) {
+	require.Equal(t, "10.0.0.10:53", (&Resolver{Nameserver: "10.0.0.10"}).nameserver())
+	require.Equal(t, "10.0.0.10:5353", (&Resolver{Nameserver: "10.0.0.10:5353"}).nameserver())
+	require.Equal(t, "[fd00::10]:53", (&Resolver{Nameserver: "fd00::10"}).nameserver())
+	require.Equal(t, "dns.example.com:53", (&Resolver{Nameserver: "dns.example.com"}).nameserver())
+}
+
+// newTestNameserver starts a UDP DNS server answering the A queries of the
+// given name with the given address, and returns its address.
+func newTestNameserver(t *testing.T, name string, addr [4]byte) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var req dnsmessage.Message
+			if err := req.Unpack(buf[:n]); err != nil || len(req.Questions) == 0 {
+				continue
+			}
+			q := req.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: req.ID, Response: true, Authoritative: true},
+				Questions: req.Questions,
+			}
+			if q.Name.String() == name && q.Type == dnsmessage.TypeA {
+				resp.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.AResource{A: addr},
+				}}
+			}
+			b, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(b, from)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
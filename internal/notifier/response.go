@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// responseBodyLimit is the maximum number of bytes of the provider response
+// body included in the error of a failed request.
+var responseBodyLimit = 1024
+
+// SetResponseBodyLimit sets the maximum number of bytes of the provider
+// response body included in the error of a failed request, which ends up
+// in the Kubernetes events and logs of the controller. A limit of zero or
+// less leaves the response body out of the errors.
+func SetResponseBodyLimit(limit int) {
+	responseBodyLimit = limit
+}
+
+// responseError returns the error of a request that failed with the given
+// response, including the beginning of the response body.
+func responseError(resp *http.Response) error {
+	var body []byte
+	if responseBodyLimit > 0 {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, int64(responseBodyLimit)+1))
+	}
+	return statusCodeError(resp.StatusCode, body)
+}
+
+// statusCodeError returns the error of a request that failed with the given
+// status code and response body.
+func statusCodeError(statusCode int, body []byte) error {
+	if excerpt := responseBodyExcerpt(body); excerpt != "" {
+		return fmt.Errorf("request failed with status code %d, %s", statusCode, excerpt)
+	}
+	return fmt.Errorf("request failed with status code %d", statusCode)
+}
+
+// responseBodyExcerpt returns the beginning of the given response body,
+// up to the responseBodyLimit, on a single line and with the invalid UTF-8
+// sequences and control characters removed.
+func responseBodyExcerpt(body []byte) string {
+	if responseBodyLimit <= 0 {
+		return ""
+	}
+	truncated := len(body) > responseBodyLimit
+	if truncated {
+		body = body[:responseBodyLimit]
+	}
+	s := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(string(body), ""))
+	s = strings.Join(strings.Fields(s), " ")
+	if truncated && s != "" {
+		s += " ..."
+	}
+	return s
+}
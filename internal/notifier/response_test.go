@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseBodyExcerpt(t *testing.T) {
+	defer SetResponseBodyLimit(1024)
+
+	require.Equal(t, "", responseBodyExcerpt(nil))
+	require.Equal(t, `{ "error": "invalid card", "line": 1 }`,
+		responseBodyExcerpt([]byte("{\n\t\"error\": \"invalid card\",\r\n\t\"line\": 1\x00\n}\n")))
+	require.Equal(t, "bad", responseBodyExcerpt([]byte("b\xffad")))
+
+	SetResponseBodyLimit(8)
+	require.Equal(t, "Bad Requ ...", responseBodyExcerpt([]byte("Bad Request: payload")))
+
+	SetResponseBodyLimit(0)
+	require.Equal(t, "", responseBodyExcerpt([]byte("Bad Request")))
+}
+
+func TestPostMessage_ResponseBody(t *testing.T) {
+	defer SetResponseBodyLimit(1024)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("{\n  \"error\": \"Summary or Text is required.\"\n}" + strings.Repeat(" ", 2048) + "trailer"))
+	}))
+	defer ts.Close()
+
+	err := postMessage(context.Background(), ts.URL, "", nil, map[string]string{})
+	require.EqualError(t, err, `request failed with status code 400, { "error": "Summary or Text is required." } ...`)
+
+	SetResponseBodyLimit(0)
+	err = postMessage(context.Background(), ts.URL, "", nil, map[string]string{})
+	require.EqualError(t, err, "request failed with status code 400")
+}
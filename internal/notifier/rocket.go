@@ -33,6 +33,9 @@ type Rocket struct {
 	Username string
 	Channel  string
 	CertPool *x509.CertPool
+
+	// Appearance overrides the colors and emojis of the messages.
+	Appearance Appearance
 }
 
 // NewRocket validates the Rocket URL and returns a Rocket object
@@ -67,6 +70,7 @@ func (s *Rocket) Post(ctx context.Context, event eventv1.Event) error {
 	if event.Severity == eventv1.EventSeverityError {
 		color = "#FF0000"
 	}
+	color = s.Appearance.color(event.Severity, color)
 
 	sfields := make([]SlackField, 0, len(event.Metadata))
 	for k, v := range event.Metadata {
@@ -75,7 +79,7 @@ func (s *Rocket) Post(ctx context.Context, event eventv1.Event) error {
 
 	a := SlackAttachment{
 		Color:      color,
-		AuthorName: fmt.Sprintf("%s/%s.%s", strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name, event.InvolvedObject.Namespace),
+		AuthorName: s.Appearance.withEmoji(event.Severity, fmt.Sprintf("%s/%s.%s", strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name, event.InvolvedObject.Namespace)),
 		Text:       event.Message,
 		MrkdwnIn:   []string{"text"},
 		Fields:     sfields,
@@ -18,7 +18,6 @@ package notifier
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"net/http"
@@ -35,17 +34,15 @@ type Sentry struct {
 // NewSentry creates a Sentry client from the provided Data Source Name (DSN)
 func NewSentry(certPool *x509.CertPool, dsn string, environment string) (*Sentry, error) {
 	tr := &http.Transport{}
-	if certPool != nil {
+	if tlsConfig := newTLSConfig(certPool); tlsConfig != nil {
 		tr = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: certPool,
-			},
+			TLSClientConfig: tlsConfig,
 		}
 	}
 	client, err := sentry.NewClient(sentry.ClientOptions{
 		Dsn:              dsn,
 		Environment:      environment,
-		HTTPTransport:    tr,
+		HTTPTransport:    newUserAgentTransport(tr),
 		TracesSampleRate: 1,
 	})
 	if err != nil {
@@ -136,6 +133,12 @@ func toSentryEvent(event eventv1.Event) *sentry.Event {
 		extra[k] = v
 	}
 
+	// Group the events of the same object and revision into a single issue
+	var fingerprint []string
+	if id, ok := event.Metadata[MetaCorrelationIDKey]; ok && id != "" {
+		fingerprint = []string{id}
+	}
+
 	// Construct event
 	return &sentry.Event{
 		Timestamp:   event.Timestamp.Time,
@@ -144,5 +147,6 @@ func toSentryEvent(event eventv1.Event) *sentry.Event {
 		Transaction: eventSummary(event),
 		Extra:       extra,
 		Message:     event.Message,
+		Fingerprint: fingerprint,
 	}
 }
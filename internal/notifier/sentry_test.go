@@ -67,6 +67,11 @@ func TestToSentryEvent(t *testing.T) {
 		"key2": "val2",
 	}, s.Extra)
 	assert.Equal(t, "message", s.Message)
+	assert.Nil(t, s.Fingerprint)
+
+	e.Metadata[MetaCorrelationIDKey] = "abc123"
+	s = toSentryEvent(e)
+	assert.Equal(t, []string{"abc123"}, s.Fingerprint)
 }
 
 func TestToSentrySpan(t *testing.T) {
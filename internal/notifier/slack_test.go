@@ -58,3 +58,24 @@ func TestSlack_PostUpdate(t *testing.T) {
 	err = slack.Post(context.TODO(), event)
 	require.NoError(t, err)
 }
+
+func TestSlack_PostAppearance(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload SlackPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		require.Equal(t, "#ff0000", payload.Attachments[0].Color)
+		require.Equal(t, ":fire: gitrepository/webapp.gitops-system", payload.Attachments[0].AuthorName)
+	}))
+	defer ts.Close()
+
+	slack, err := NewSlack(ts.URL, "", "", nil, "", "test")
+	require.NoError(t, err)
+	slack.Appearance = Appearance{
+		Colors: map[string]string{eventv1.EventSeverityError: "#ff0000"},
+		Emojis: map[string]string{eventv1.EventSeverityError: ":fire:"},
+	}
+
+	event := testEvent()
+	event.Severity = eventv1.EventSeverityError
+	require.NoError(t, slack.Post(context.TODO(), event))
+}
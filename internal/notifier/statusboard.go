@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+)
+
+// StatusBoardLabel marks the ConfigMaps maintained by the statusboard
+// notifier. ConfigMaps without this label are never updated.
+const StatusBoardLabel = "notification.toolkit.fluxcd.io/status-board"
+
+const (
+	statusBoardHealthy     = "Healthy"
+	statusBoardUnhealthy   = "Unhealthy"
+	statusBoardProgressing = "Progressing"
+)
+
+// StatusBoard is a notifier maintaining the deployment state of the
+// involved objects of the events in a ConfigMap, with one entry per object.
+type StatusBoard struct {
+	Client    client.Client
+	Namespace string
+	Name      string
+}
+
+// StatusBoardEntry is the state of an involved object recorded in the
+// status board ConfigMap, under the '<kind>.<namespace>.<name>' key.
+type StatusBoardEntry struct {
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Revision  string      `json:"revision,omitempty"`
+	Health    string      `json:"health"`
+	Reason    string      `json:"reason,omitempty"`
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// NewStatusBoard returns a StatusBoard maintaining the ConfigMap with the
+// given name in the given namespace.
+func NewStatusBoard(kubeClient client.Client, namespace string, name string) (*StatusBoard, error) {
+	if kubeClient == nil {
+		return nil, errors.New("statusboard requires a Kubernetes client")
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid ConfigMap name '%s': %v", name, errs)
+	}
+	return &StatusBoard{
+		Client:    kubeClient,
+		Namespace: namespace,
+		Name:      name,
+	}, nil
+}
+
+// Post records the state of the involved object of the event in the
+// status board, creating the ConfigMap if it doesn't exist. The revision of
+// the previous entry is kept for the events without revision.
+func (s *StatusBoard) Post(ctx context.Context, event eventv1.Event) error {
+	// Skip trace events and Git commit status update events.
+	if event.Severity == eventv1.EventSeverityTrace ||
+		event.HasMetadata(eventv1.MetaCommitStatusKey, eventv1.MetaCommitStatusUpdateValue) {
+		return nil
+	}
+
+	obj := event.InvolvedObject
+	key := fmt.Sprintf("%s.%s.%s", obj.Kind, obj.Namespace, obj.Name)
+	entry := StatusBoardEntry{
+		Kind:      obj.Kind,
+		Namespace: obj.Namespace,
+		Name:      obj.Name,
+		Revision:  event.Metadata[eventv1.MetaRevisionKey],
+		Health:    statusBoardHealth(event),
+		Reason:    event.Reason,
+		Timestamp: event.Timestamp,
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cm corev1.ConfigMap
+		err := s.Client.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, &cm)
+		if apierrors.IsNotFound(err) {
+			return s.create(ctx, key, entry)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get status board ConfigMap: %w", err)
+		}
+		if _, ok := cm.Labels[StatusBoardLabel]; !ok {
+			return fmt.Errorf("ConfigMap '%s/%s' is not a status board, missing the '%s' label",
+				s.Namespace, s.Name, StatusBoardLabel)
+		}
+
+		if entry.Revision == "" {
+			var previous StatusBoardEntry
+			if err := json.Unmarshal([]byte(cm.Data[key]), &previous); err == nil {
+				entry.Revision = previous.Revision
+			}
+		}
+		value, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal status board entry: %w", err)
+		}
+
+		patch := client.MergeFromWithOptions(cm.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[key] = string(value)
+		if err := s.Client.Patch(ctx, &cm, patch); err != nil {
+			if apierrors.IsConflict(err) {
+				return err
+			}
+			return fmt.Errorf("failed to patch status board ConfigMap: %w", err)
+		}
+		return nil
+	})
+}
+
+// create creates the status board ConfigMap with the given entry. A
+// conflict is returned if the ConfigMap was created concurrently, to retry
+// the update.
+func (s *StatusBoard) create(ctx context.Context, key string, entry StatusBoardEntry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status board entry: %w", err)
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.Namespace,
+			Name:      s.Name,
+			Labels:    map[string]string{StatusBoardLabel: "true"},
+		},
+		Data: map[string]string{key: string(value)},
+	}
+	err = s.Client.Create(ctx, cm)
+	if apierrors.IsAlreadyExists(err) {
+		return apierrors.NewConflict(corev1.Resource("configmaps"), s.Name, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create status board ConfigMap: %w", err)
+	}
+	return nil
+}
+
+// statusBoardHealth returns the health of the involved object of the event.
+func statusBoardHealth(event eventv1.Event) string {
+	switch {
+	case event.Severity == eventv1.EventSeverityError:
+		return statusBoardUnhealthy
+	case event.HasReason(meta.ProgressingReason):
+		return statusBoardProgressing
+	default:
+		return statusBoardHealthy
+	}
+}
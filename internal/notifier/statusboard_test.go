@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+)
+
+func TestNewStatusBoard_InvalidName(t *testing.T) {
+	_, err := NewStatusBoard(fake.NewClientBuilder().Build(), "default", "Status_Board")
+	require.ErrorContains(t, err, "invalid ConfigMap name")
+}
+
+func TestStatusBoard_Post(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().Build()
+	s, err := NewStatusBoard(kubeClient, "default", "status")
+	require.NoError(t, err)
+
+	readEntry := func() StatusBoardEntry {
+		t.Helper()
+		var cm corev1.ConfigMap
+		require.NoError(t, kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "status"}, &cm))
+		require.Equal(t, "true", cm.Labels[StatusBoardLabel])
+		var entry StatusBoardEntry
+		require.NoError(t, json.Unmarshal([]byte(cm.Data["GitRepository.gitops-system.webapp"]), &entry))
+		return entry
+	}
+
+	event := testEvent()
+	event.Timestamp = metav1.Unix(1700000000, 0)
+	event.Metadata[eventv1.MetaRevisionKey] = "main@sha1:5394cb7f"
+	require.NoError(t, s.Post(context.TODO(), event))
+
+	entry := readEntry()
+	require.Equal(t, "GitRepository", entry.Kind)
+	require.Equal(t, "gitops-system", entry.Namespace)
+	require.Equal(t, "webapp", entry.Name)
+	require.Equal(t, "main@sha1:5394cb7f", entry.Revision)
+	require.Equal(t, statusBoardHealthy, entry.Health)
+	require.Equal(t, "reason", entry.Reason)
+	require.True(t, entry.Timestamp.Equal(&event.Timestamp))
+
+	// The revision is kept for the events without revision.
+	event = testEvent()
+	event.Severity = eventv1.EventSeverityError
+	event.Reason = "HealthCheckFailed"
+	require.NoError(t, s.Post(context.TODO(), event))
+
+	entry = readEntry()
+	require.Equal(t, "main@sha1:5394cb7f", entry.Revision)
+	require.Equal(t, statusBoardUnhealthy, entry.Health)
+	require.Equal(t, "HealthCheckFailed", entry.Reason)
+
+	event = testEvent()
+	event.Reason = meta.ProgressingReason
+	event.Metadata[eventv1.MetaRevisionKey] = "main@sha1:a1b2c3d4"
+	require.NoError(t, s.Post(context.TODO(), event))
+
+	entry = readEntry()
+	require.Equal(t, "main@sha1:a1b2c3d4", entry.Revision)
+	require.Equal(t, statusBoardProgressing, entry.Health)
+}
+
+func TestStatusBoard_PostUnlabeledConfigMap(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "app-config",
+		},
+		Data: map[string]string{"key": "value"},
+	}
+	kubeClient := fake.NewClientBuilder().WithObjects(cm).Build()
+	s, err := NewStatusBoard(kubeClient, "default", "app-config")
+	require.NoError(t, err)
+
+	err = s.Post(context.TODO(), testEvent())
+	require.ErrorContains(t, err, "is not a status board")
+
+	var got corev1.ConfigMap
+	require.NoError(t, kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "app-config"}, &got))
+	require.Equal(t, map[string]string{"key": "value"}, got.Data)
+}
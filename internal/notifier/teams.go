@@ -21,7 +21,6 @@ import (
 	"crypto/x509"
 	"fmt"
 	"net/url"
-	"slices"
 	"strings"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
@@ -43,6 +42,10 @@ type MSTeams struct {
 	ProxyURL string
 	CertPool *x509.CertPool
 	Schema   int
+
+	// MetadataFields configures the rendering of the event metadata
+	// as facts. When no order is set, the summary is rendered first.
+	MetadataFields MetadataFields
 }
 
 // MSTeamsPayload holds the message card data
@@ -151,14 +154,20 @@ func (s *MSTeams) Post(ctx context.Context, event eventv1.Event) error {
 
 	objName := fmt.Sprintf("%s/%s.%s", strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name, event.InvolvedObject.Namespace)
 
+	metadataFields := s.MetadataFields
+	if len(metadataFields.Order) == 0 {
+		metadataFields.Order = []string{"summary"}
+	}
+	fields := metadataFields.fields(event.Metadata)
+
 	var payload any
 	switch s.Schema {
 	case msTeamsSchemaDeprecatedConnector:
-		payload = buildMSTeamsDeprecatedConnectorPayload(&event, objName)
+		payload = buildMSTeamsDeprecatedConnectorPayload(&event, objName, fields)
 	case msTeamsSchemaAdaptiveCard:
-		payload = buildMSTeamsAdaptiveCardPayload(&event, objName)
+		payload = buildMSTeamsAdaptiveCardPayload(&event, objName, fields)
 	default:
-		payload = buildMSTeamsAdaptiveCardPayload(&event, objName)
+		payload = buildMSTeamsAdaptiveCardPayload(&event, objName, fields)
 	}
 
 	err := postMessage(ctx, s.URL, s.ProxyURL, s.CertPool, payload)
@@ -169,12 +178,12 @@ func (s *MSTeams) Post(ctx context.Context, event eventv1.Event) error {
 	return nil
 }
 
-func buildMSTeamsDeprecatedConnectorPayload(event *eventv1.Event, objName string) *MSTeamsPayload {
-	facts := make([]MSTeamsField, 0, len(event.Metadata))
-	for k, v := range event.Metadata {
+func buildMSTeamsDeprecatedConnectorPayload(event *eventv1.Event, objName string, fields []metadataField) *MSTeamsPayload {
+	facts := make([]MSTeamsField, 0, len(fields))
+	for _, f := range fields {
 		facts = append(facts, MSTeamsField{
-			Name:  k,
-			Value: v,
+			Name:  f.Key,
+			Value: f.Value,
 		})
 	}
 
@@ -199,7 +208,7 @@ func buildMSTeamsDeprecatedConnectorPayload(event *eventv1.Event, objName string
 	return payload
 }
 
-func buildMSTeamsAdaptiveCardPayload(event *eventv1.Event, objName string) *msAdaptiveCardMessage {
+func buildMSTeamsAdaptiveCardPayload(event *eventv1.Event, objName string, fields []metadataField) *msAdaptiveCardMessage {
 	// Prepare message, add red color to error messages.
 	message := &msAdaptiveCardTextBlock{
 		Text: event.Message,
@@ -209,28 +218,13 @@ func buildMSTeamsAdaptiveCardPayload(event *eventv1.Event, objName string) *msAd
 		message.Color = "attention"
 	}
 
-	// Put "summary" first, then sort the rest of the metadata by key.
-	facts := make([]msAdaptiveCardFact, 0, len(event.Metadata))
-	const summaryKey = "summary"
-	if summary, ok := event.Metadata[summaryKey]; ok {
-		facts = append(facts, msAdaptiveCardFact{
-			Title: summaryKey,
-			Value: summary,
-		})
-	}
-	metadataFirstIndex := len(facts)
-	for k, v := range event.Metadata {
-		if k == summaryKey {
-			continue
-		}
+	facts := make([]msAdaptiveCardFact, 0, len(fields))
+	for _, f := range fields {
 		facts = append(facts, msAdaptiveCardFact{
-			Title: k,
-			Value: v,
+			Title: f.Key,
+			Value: f.Value,
 		})
 	}
-	slices.SortFunc(facts[metadataFirstIndex:], func(a, b msAdaptiveCardFact) int {
-		return strings.Compare(a.Title, b.Title)
-	})
 
 	// The card below was built with help from https://adaptivecards.io/designer using the Microsoft Teams host app.
 	payload := &msAdaptiveCardMessage{
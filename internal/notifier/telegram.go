@@ -6,25 +6,41 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/containrrr/shoutrrr"
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 )
 
+// telegramAPIURL is the address of the Telegram Bot API.
+const telegramAPIURL = "https://api.telegram.org"
+
 type Telegram struct {
-	Channel string
-	Token   string
-	send    func(url string, message string) error // this allows the send function to be overridden for testing
+	// URL is the address of the Telegram Bot API.
+	URL      string
+	Channel  string
+	Token    string
+	ProxyURL string
+
+	// Appearance overrides the emojis of the messages.
+	Appearance Appearance
+}
+
+// TelegramPayload holds the parameters of the sendMessage method of the
+// Telegram Bot API.
+type TelegramPayload struct {
+	ChatID    string `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode"`
 }
 
-func NewTelegram(channel, token string) (*Telegram, error) {
+func NewTelegram(proxyURL, channel, token string) (*Telegram, error) {
 	if channel == "" {
 		return nil, errors.New("empty Telegram channel")
 	}
 
 	return &Telegram{
-		Channel: channel,
-		Token:   token,
-		send:    shoutrrr.Send,
+		URL:      telegramAPIURL,
+		Channel:  channel,
+		Token:    token,
+		ProxyURL: proxyURL,
 	}, nil
 }
 
@@ -38,6 +54,7 @@ func (t *Telegram) Post(ctx context.Context, event eventv1.Event) error {
 	if event.Severity == eventv1.EventSeverityError {
 		emoji = "🚨"
 	}
+	emoji = t.Appearance.emoji(event.Severity, emoji)
 
 	heading := fmt.Sprintf("%s %s/%s/%s", emoji, strings.ToLower(event.InvolvedObject.Kind),
 		event.InvolvedObject.Name, event.InvolvedObject.Namespace)
@@ -46,9 +63,20 @@ func (t *Telegram) Post(ctx context.Context, event eventv1.Event) error {
 		metadata = metadata + fmt.Sprintf("\\- *%s*: %s\n", escapeString(k), escapeString(v))
 	}
 	message := fmt.Sprintf("*%s*\n%s\n%s", escapeString(heading), escapeString(event.Message), metadata)
-	url := fmt.Sprintf("telegram://%s@telegram?channels=%s&parseMode=markDownv2", t.Token, t.Channel)
-	err := t.send(url, message)
-	return err
+
+	address := fmt.Sprintf("%s/bot%s/sendMessage", t.URL, t.Token)
+	// The channel can be a comma-separated list of chat IDs.
+	for _, channel := range strings.Split(t.Channel, ",") {
+		payload := TelegramPayload{
+			ChatID:    strings.TrimSpace(channel),
+			Text:      message,
+			ParseMode: "MarkdownV2",
+		}
+		if err := postMessage(ctx, address, t.ProxyURL, nil, payload); err != nil {
+			return fmt.Errorf("postMessage failed: %w", err)
+		}
+	}
+	return nil
 }
 
 // The telegram API requires that some special characters are escaped
@@ -25,28 +25,26 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
 func TestTelegram_Post(t *testing.T) {
+	var chats []string
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/bottoken/sendMessage", r.URL.Path)
+
 		b, err := io.ReadAll(r.Body)
 		require.NoError(t, err)
 
-		var payload = WebexPayload{}
+		var payload = TelegramPayload{}
 		err = json.Unmarshal(b, &payload)
 		require.NoError(t, err)
-	}))
-	defer ts.Close()
-
-	telegram, err := NewTelegram("channel", "token")
-	require.NoError(t, err)
+		require.Equal(t, "MarkdownV2", payload.ParseMode)
+		chats = append(chats, payload.ChatID)
 
-	telegram.send = func(url, message string) error {
-		require.Equal(t, "telegram://token@telegram?channels=channel&parseMode=markDownv2", url)
-
-		lines := strings.Split(message, "\n")
+		lines := strings.Split(payload.Text, "\n")
 		require.Len(t, lines, 5)
 		slices.Sort(lines[2:4])
 		require.Equal(t, "*💫 gitrepository/webapp/gitops\\-system*", lines[0])
@@ -56,12 +54,34 @@ func TestTelegram_Post(t *testing.T) {
 			"\\- *test*: metadata",
 			"",
 		}, lines[2:])
+	}))
+	defer ts.Close()
 
-		return nil
-	}
+	telegram, err := NewTelegram("", "channel,@other", "token")
+	require.NoError(t, err)
+	telegram.URL = ts.URL
 
 	ev := testEvent()
 	ev.Metadata["kubernetes.io/somekey"] = "some.value"
 	err = telegram.Post(context.TODO(), ev)
 	require.NoError(t, err)
+	require.Equal(t, []string{"channel", "@other"}, chats)
+}
+
+func TestTelegram_PostContext(t *testing.T) {
+	unblock := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer ts.Close()
+	defer close(unblock)
+
+	telegram, err := NewTelegram("", "channel", "token")
+	require.NoError(t, err)
+	telegram.URL = ts.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = telegram.Post(ctx, testEvent())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
 }
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// tlsMinVersions maps the accepted values of the minimum TLS version
+// to their crypto/tls constants.
+var tlsMinVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var (
+	tlsMinVersion   uint16
+	tlsCipherSuites []uint16
+)
+
+// SetTLSOptions sets the minimum TLS version, e.g. "1.2" or "1.3", and the
+// cipher suites, by their IANA names, of the TLS connections to the
+// providers. An empty version and no cipher suites leave the Go defaults.
+// Only the cipher suites considered secure by crypto/tls are accepted, the
+// cipher suites of TLS 1.3 are not configurable.
+func SetTLSOptions(minVersion string, cipherSuites []string) error {
+	var version uint16
+	if minVersion != "" {
+		v, ok := tlsMinVersions[minVersion]
+		if !ok {
+			return fmt.Errorf("unsupported TLS version '%s', must be one of: 1.2, 1.3", minVersion)
+		}
+		version = v
+	}
+
+	secure := make(map[string]uint16)
+	for _, cs := range tls.CipherSuites() {
+		secure[cs.Name] = cs.ID
+	}
+	var ids []uint16
+	for _, name := range cipherSuites {
+		id, ok := secure[name]
+		if !ok {
+			return fmt.Errorf("unsupported or insecure TLS cipher suite '%s'", name)
+		}
+		ids = append(ids, id)
+	}
+
+	tlsMinVersion = version
+	tlsCipherSuites = ids
+	return nil
+}
+
+// newTLSConfig returns the TLS configuration of the connections to a
+// provider trusting the given CA certificates, with the minimum version
+// and cipher suites set with SetTLSOptions. It returns nil when there is
+// nothing to configure, so that the callers keep the default transports.
+func newTLSConfig(certPool *x509.CertPool) *tls.Config {
+	if certPool == nil && tlsMinVersion == 0 && len(tlsCipherSuites) == 0 {
+		return nil
+	}
+	return &tls.Config{
+		RootCAs:      certPool,
+		MinVersion:   tlsMinVersion,
+		CipherSuites: tlsCipherSuites,
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTLSOptions(t *testing.T) {
+	defer SetTLSOptions("", nil)
+
+	require.Nil(t, newTLSConfig(nil))
+
+	require.NoError(t, SetTLSOptions("1.3", []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}))
+	cfg := newTLSConfig(nil)
+	require.NotNil(t, cfg)
+	require.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+	require.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, cfg.CipherSuites)
+
+	require.ErrorContains(t, SetTLSOptions("1.1", nil), "unsupported TLS version")
+	require.ErrorContains(t, SetTLSOptions("", []string{"TLS_RSA_WITH_RC4_128_SHA"}), "TLS_RSA_WITH_RC4_128_SHA")
+	// The options are left as is when invalid.
+	require.Equal(t, uint16(tls.VersionTLS13), newTLSConfig(nil).MinVersion)
+}
+
+func TestNewTLSConfig_MinVersion(t *testing.T) {
+	defer SetTLSOptions("", nil)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	ts.StartTLS()
+	defer ts.Close()
+
+	certPool := x509.NewCertPool()
+	certPool.AddCert(ts.Certificate())
+	get := func() error {
+		hc := &http.Client{Transport: &http.Transport{TLSClientConfig: newTLSConfig(certPool)}}
+		resp, err := hc.Get(ts.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	require.NoError(t, get())
+
+	require.NoError(t, SetTLSOptions("1.3", nil))
+	require.ErrorContains(t, get(), "protocol version")
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// userAgentProduct is the product token of the User-Agent header sent with
+// the requests of the controller.
+const userAgentProduct = "notification-controller"
+
+var (
+	userAgentVersion = "0.0.0-dev.0"
+	userAgentSuffix  string
+)
+
+// SetUserAgent sets the controller version and the optional suffix of the
+// User-Agent header sent with the requests of the controller. The suffix is
+// appended as-is and allows operators to identify their installation, e.g.
+// for provider-side auditing.
+func SetUserAgent(version, suffix string) {
+	if version != "" {
+		userAgentVersion = version
+	}
+	userAgentSuffix = strings.TrimSpace(suffix)
+}
+
+// UserAgent returns the User-Agent header value of the controller, in the
+// form notification-controller/<version> [<comment>] [<suffix>], where the
+// comment identifies the requester, e.g. provider/slack.
+func UserAgent(comment string) string {
+	parts := []string{userAgentProduct + "/" + userAgentVersion}
+	if comment != "" {
+		parts = append(parts, comment)
+	}
+	if userAgentSuffix != "" {
+		parts = append(parts, userAgentSuffix)
+	}
+	return strings.Join(parts, " ")
+}
+
+type providerContextKey struct{}
+
+// WithProvider returns a copy of the context carrying the given provider
+// type, which is added to the User-Agent of the requests sent to the
+// provider with the returned context.
+func WithProvider(ctx context.Context, provider string) context.Context {
+	return context.WithValue(ctx, providerContextKey{}, provider)
+}
+
+// userAgentFromContext returns the User-Agent for the requests sent with the
+// given context.
+func userAgentFromContext(ctx context.Context) string {
+	return userAgentForProvider(ctx, "")
+}
+
+// userAgentForProvider returns the User-Agent for the requests sent with the
+// given context, falling back to the given provider type when the context
+// doesn't carry one.
+func userAgentForProvider(ctx context.Context, provider string) string {
+	if ctx != nil {
+		if p, ok := ctx.Value(providerContextKey{}).(string); ok && p != "" {
+			provider = p
+		}
+	}
+	if provider == "" {
+		return UserAgent("")
+	}
+	return UserAgent("provider/" + provider)
+}
+
+// userAgentTransport sets the User-Agent of the controller on the requests
+// of the provider SDKs, overriding the one set by the SDKs.
+type userAgentTransport struct {
+	base http.RoundTripper
+
+	// provider is the provider type used for the SDKs that don't
+	// send their requests with the context given to Post.
+	provider string
+}
+
+// newUserAgentTransport wraps the given transport, or the default one
+// when nil, to set the User-Agent of the controller. The transport
+// resolves the hostnames with the Resolver of the request context.
+func newUserAgentTransport(base http.RoundTripper) http.RoundTripper {
+	return &userAgentTransport{base: withResolver(base)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgentForProvider(req.Context(), t.provider))
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAgent(t *testing.T) {
+	defer SetUserAgent("0.0.0-dev.0", "")
+
+	SetUserAgent("v1.5.0", "")
+	require.Equal(t, "notification-controller/v1.5.0", UserAgent(""))
+	require.Equal(t, "notification-controller/v1.5.0 provider/slack", UserAgent("provider/slack"))
+
+	SetUserAgent("", " acme-platform ")
+	require.Equal(t, "notification-controller/v1.5.0 provider/slack acme-platform", UserAgent("provider/slack"))
+	require.Equal(t, "notification-controller/v1.5.0 acme-platform", userAgentFromContext(context.Background()))
+	require.Equal(t, "notification-controller/v1.5.0 provider/generic acme-platform",
+		userAgentFromContext(WithProvider(context.Background(), "generic")))
+}
+
+func Test_postMessage_userAgent(t *testing.T) {
+	defer SetUserAgent("0.0.0-dev.0", "")
+	SetUserAgent("v1.5.0", "acme")
+
+	var userAgent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+	}))
+	defer ts.Close()
+
+	ctx := WithProvider(context.Background(), "slack")
+	err := postMessage(ctx, ts.URL, "", nil, map[string]string{"status": "success"})
+	require.NoError(t, err)
+	require.Equal(t, "notification-controller/v1.5.0 provider/slack acme", userAgent)
+}
+
+func Test_userAgentTransport(t *testing.T) {
+	defer SetUserAgent("0.0.0-dev.0", "")
+	SetUserAgent("v1.5.0", "")
+
+	var userAgent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name      string
+		transport http.RoundTripper
+		ctx       context.Context
+		want      string
+	}{
+		{
+			name:      "overrides the SDK User-Agent",
+			transport: newUserAgentTransport(nil),
+			ctx:       WithProvider(context.Background(), "github"),
+			want:      "notification-controller/v1.5.0 provider/github",
+		},
+		{
+			name:      "without provider",
+			transport: newUserAgentTransport(nil),
+			ctx:       context.Background(),
+			want:      "notification-controller/v1.5.0",
+		},
+		{
+			name:      "falls back to the transport provider",
+			transport: &userAgentTransport{base: http.DefaultTransport, provider: "gitea"},
+			ctx:       context.Background(),
+			want:      "notification-controller/v1.5.0 provider/gitea",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(tt.ctx, http.MethodGet, ts.URL, nil)
+			require.NoError(t, err)
+			req.Header.Set("User-Agent", "go-sdk/v1")
+
+			resp, err := (&http.Client{Transport: tt.transport}).Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, tt.want, userAgent)
+			require.Equal(t, "go-sdk/v1", req.UserAgent())
+		})
+	}
+}
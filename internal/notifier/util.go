@@ -17,10 +17,14 @@ limitations under the License.
 package notifier
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -47,11 +51,22 @@ func parseGitAddress(s string) (string, string, error) {
 	return host, id, nil
 }
 
+// maxStatusDescriptionLength is the maximum length of the commit status
+// description accepted by GitHub, the lowest among the Git providers.
+const maxStatusDescriptionLength = 140
+
 func formatNameAndDescription(event eventv1.Event) (string, string) {
 	name := fmt.Sprintf("%v/%v", event.InvolvedObject.Kind, event.InvolvedObject.Name)
 	name = strings.ToLower(name)
 	desc := strings.Join(splitCamelcase(event.Reason), " ")
 	desc = strings.ToLower(desc)
+	// Include the images updated by image automation in the description.
+	if images, ok := event.Metadata["images"]; ok && images != "" {
+		desc = fmt.Sprintf("%s: %s", desc, images)
+		if len(desc) > maxStatusDescriptionLength {
+			desc = desc[:maxStatusDescriptionLength-3] + "..."
+		}
+	}
 	return name, desc
 }
 
@@ -108,6 +123,25 @@ func splitCamelcase(src string) (entries []string) {
 	return
 }
 
+// metaOriginRevisionKey is the event metadata key holding the revision of
+// the Git commit an artifact was built from, e.g. for OCI artifacts.
+const metaOriginRevisionKey = "originRevision"
+
+// parseCommitRevision returns the commit hash of the event revision. When the
+// event has an origin revision, i.e. the revision is not a Git commit but e.g.
+// the digest of an OCI artifact, the commit hash of the origin revision is
+// returned instead.
+func parseCommitRevision(event eventv1.Event) (string, error) {
+	if origin, ok := event.Metadata[metaOriginRevisionKey]; ok {
+		return parseRevision(origin)
+	}
+	revString, ok := event.Metadata[eventv1.MetaRevisionKey]
+	if !ok {
+		return "", errors.New("missing revision metadata")
+	}
+	return parseRevision(revString)
+}
+
 func parseRevision(rev string) (string, error) {
 	hash := git.ExtractHashFromRevision(git.TransformRevision(rev))
 	if hash.Algorithm() == git.HashTypeUnknown {
@@ -116,6 +150,60 @@ func parseRevision(rev string) (string, error) {
 	return hash.String(), nil
 }
 
+// commitNotFoundBackoff holds the delays between the attempts of a commit
+// status update that failed because the commit could not be found. Git
+// providers may respond with a 404 for a commit that was just pushed until
+// it has been replicated across their backends.
+var commitNotFoundBackoff = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+
+// retryCommitNotFound calls fn and retries it with backoff for as long as it
+// returns an error for which notFound is true, up to len(commitNotFoundBackoff)
+// times. Any other error, or the context being done, stops the retries and
+// the last error is returned.
+func retryCommitNotFound(ctx context.Context, notFound func(error) bool, fn func() error) error {
+	err := fn()
+	for _, delay := range commitNotFoundBackoff {
+		if err == nil || !notFound(err) {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = fn()
+	}
+	return err
+}
+
+// eventVariableInvalidChars matches the characters of the metadata keys
+// that are not allowed in variable names.
+var eventVariableInvalidChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// eventVariables returns the variables describing the event, passed to the
+// CI pipelines and jobs triggered by the notifiers. The metadata keys are
+// upper-cased, with the characters not allowed in variable names replaced by
+// underscores.
+func eventVariables(event eventv1.Event) map[string]string {
+	vars := map[string]string{
+		"FLUX_EVENT_TYPE": fmt.Sprintf("%s/%s.%s",
+			event.InvolvedObject.Kind, event.InvolvedObject.Name, event.InvolvedObject.Namespace),
+		"FLUX_EVENT_KIND":      event.InvolvedObject.Kind,
+		"FLUX_EVENT_NAME":      event.InvolvedObject.Name,
+		"FLUX_EVENT_NAMESPACE": event.InvolvedObject.Namespace,
+		"FLUX_EVENT_SEVERITY":  event.Severity,
+		"FLUX_EVENT_REASON":    event.Reason,
+		"FLUX_EVENT_MESSAGE":   event.Message,
+	}
+	for k, v := range event.Metadata {
+		key := eventVariableInvalidChars.ReplaceAllString(strings.ToUpper(k), "_")
+		vars["FLUX_EVENT_METADATA_"+key] = v
+	}
+	return vars
+}
+
 func sha1String(str string) string {
 	bs := []byte(str)
 	return fmt.Sprintf("%x", sha1.Sum(bs))
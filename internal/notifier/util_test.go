@@ -17,6 +17,7 @@ limitations under the License.
 package notifier
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -38,6 +39,26 @@ func TestUtil_NameAndDescription(t *testing.T) {
 	require.Equal(t, "apply succeeded", desc)
 }
 
+func TestUtil_NameAndDescription_Images(t *testing.T) {
+	event := eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "ImageUpdateAutomation",
+			Name: "flux-system",
+		},
+		Reason: "Succeeded",
+		Metadata: map[string]string{
+			"images": "ghcr.io/stefanprodan/podinfo:6.5.4",
+		},
+	}
+	_, desc := formatNameAndDescription(event)
+	require.Equal(t, "succeeded: ghcr.io/stefanprodan/podinfo:6.5.4", desc)
+
+	event.Metadata["images"] = strings.Repeat("ghcr.io/stefanprodan/podinfo:6.5.4, ", 10)
+	_, desc = formatNameAndDescription(event)
+	require.Len(t, desc, maxStatusDescriptionLength)
+	require.True(t, strings.HasSuffix(desc, "..."))
+}
+
 func TestUtil_ParseRevision(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -90,6 +111,45 @@ func TestUtil_ParseRevision(t *testing.T) {
 	}
 }
 
+func TestUtil_ParseCommitRevision(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		expect   string
+		wantErr  bool
+	}{
+		{
+			name:     "git revision",
+			metadata: map[string]string{"revision": "main@sha1:a1afe267b54f38b46b487f6e938a6fd508278c07"},
+			expect:   "a1afe267b54f38b46b487f6e938a6fd508278c07",
+		},
+		{
+			name: "origin revision of OCI artifact",
+			metadata: map[string]string{
+				"revision":       "latest@sha256:9d4e4b8a2b6f4f3e4b0d1e5c2b3d0c9a8f7e6d5c4b3a29180706050403020100",
+				"originRevision": "main@sha1:a1afe267b54f38b46b487f6e938a6fd508278c07",
+			},
+			expect: "a1afe267b54f38b46b487f6e938a6fd508278c07",
+		},
+		{
+			name:     "missing revision",
+			metadata: map[string]string{},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev, err := parseCommitRevision(eventv1.Event{Metadata: tt.metadata})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, rev)
+		})
+	}
+}
+
 func TestUtil_ParseGitHttps(t *testing.T) {
 	addr := "https://github.com/foo/bar"
 	host, id, err := parseGitAddress(addr)
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// WarmUp resolves the hostname of the endpoint of the notifiers, or of the
+// proxy when set, and connects to it without sending a request. The TLS
+// handshake is completed for the https endpoints. The hostname is resolved
+// with the Resolver of the given context, if any.
+func (f Factory) WarmUp(ctx context.Context) error {
+	address := f.URL
+	if f.ProxyURL != "" {
+		address = f.ProxyURL
+	}
+	return warmUp(ctx, address, f.CertPool)
+}
+
+// warmUp connects to the host of the given address. The addresses which
+// aren't http or https URLs, e.g. the NATS servers or the Azure Event Hub
+// connection strings, are skipped.
+func warmUp(ctx context.Context, address string, certPool *x509.CertPool) error {
+	u, err := url.Parse(address)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+
+	var port string
+	switch u.Scheme {
+	case "http":
+		port = "80"
+	case "https":
+		port = "443"
+	default:
+		return nil
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	hostport := net.JoinHostPort(u.Hostname(), port)
+
+	conn, err := resolverDialContext(nil)(ctx, "tcp", hostport)
+	if err != nil {
+		return fmt.Errorf("failed to connect to '%s': %w", hostport, err)
+	}
+	defer conn.Close()
+
+	if u.Scheme != "https" {
+		return nil
+	}
+	tlsConfig := newTLSConfig(certPool)
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig.ServerName = u.Hostname()
+	if err := tls.Client(conn, tlsConfig).HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("TLS handshake with '%s' failed: %w", hostport, err)
+	}
+	return nil
+}
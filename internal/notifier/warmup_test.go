@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmUp(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("warm-up must not send requests")
+	}))
+	defer ts.Close()
+	certPool := x509.NewCertPool()
+	certPool.AddCert(ts.Certificate())
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	// A port without listener.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := "http://" + l.Addr().String()
+	require.NoError(t, l.Close())
+
+	t.Run("completes the TLS handshake", func(t *testing.T) {
+		require.NoError(t, warmUp(context.Background(), ts.URL, certPool))
+	})
+
+	t.Run("fails with an unknown CA", func(t *testing.T) {
+		err := warmUp(context.Background(), ts.URL, nil)
+		require.ErrorContains(t, err, "TLS handshake with")
+	})
+
+	t.Run("fails to connect", func(t *testing.T) {
+		err := warmUp(context.Background(), closed, nil)
+		require.ErrorContains(t, err, "failed to connect to")
+	})
+
+	t.Run("resolves the hostname with the Resolver", func(t *testing.T) {
+		ctx := WithResolver(context.Background(), &Resolver{
+			HostAliases: map[string]string{"example.com": "127.0.0.1"},
+		})
+		require.NoError(t, warmUp(ctx, "https://example.com:"+u.Port()+"/hooks", certPool))
+	})
+
+	t.Run("skips the non-HTTP addresses", func(t *testing.T) {
+		require.NoError(t, warmUp(context.Background(), "nats://nats.flux.invalid:4222", nil))
+		require.NoError(t, warmUp(context.Background(), "Endpoint=sb://hub.flux.invalid/;SharedAccessKey=key", nil))
+		require.NoError(t, warmUp(context.Background(), "", nil))
+	})
+
+	t.Run("connects to the proxy", func(t *testing.T) {
+		f := Factory{FactoryOptions: FactoryOptions{
+			URL:      closed,
+			ProxyURL: ts.URL,
+			CertPool: certPool,
+		}}
+		require.NoError(t, f.WarmUp(context.Background()))
+	})
+}
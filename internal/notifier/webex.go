@@ -54,6 +54,8 @@ type Webex struct {
 	ProxyURL string
 	// optional: x509 cert is no longer needed to post to a webex space
 	CertPool *x509.CertPool
+	// optional: overrides the emojis of the messages
+	Appearance Appearance
 }
 
 // WebexPayload holds the message text
@@ -85,6 +87,7 @@ func (s *Webex) CreateMarkdown(event *eventv1.Event) string {
 	if event.Severity == eventv1.EventSeverityError {
 		emoji = "💣"
 	}
+	emoji = s.Appearance.emoji(event.Severity, emoji)
 	fmt.Fprintf(&b, "%s **%s/%s.%s**\n", emoji, strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name, event.InvolvedObject.Namespace)
 	fmt.Fprintf(&b, "%s\n", event.Message)
 
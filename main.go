@@ -17,8 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/sethvargo/go-limiter/memorystore"
@@ -26,7 +29,9 @@ import (
 	"github.com/slok/go-http-metrics/middleware"
 	flag "github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/utils/pointer"
@@ -34,8 +39,10 @@ import (
 	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	crtlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/fluxcd/pkg/runtime/acl"
 	"github.com/fluxcd/pkg/runtime/client"
@@ -48,16 +55,34 @@ import (
 	"github.com/fluxcd/pkg/runtime/probes"
 
 	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+	apiv1b1 "github.com/fluxcd/notification-controller/api/v1beta1"
 	apiv1b2 "github.com/fluxcd/notification-controller/api/v1beta2"
 	apiv1b3 "github.com/fluxcd/notification-controller/api/v1beta3"
 	"github.com/fluxcd/notification-controller/internal/controller"
+	"github.com/fluxcd/notification-controller/internal/conversion"
 	"github.com/fluxcd/notification-controller/internal/features"
-	"github.com/fluxcd/notification-controller/internal/server"
+	"github.com/fluxcd/notification-controller/internal/fips"
+	"github.com/fluxcd/notification-controller/internal/notifier"
+	"github.com/fluxcd/notification-controller/pkg/server"
 	// +kubebuilder:scaffold:imports
 )
 
 const controllerName = "notification-controller"
 
+// VERSION is the version of the controller, set at build time with
+// -ldflags "-X main.VERSION=<version>".
+var VERSION = "0.0.0-dev.0"
+
+const (
+	// modeAll runs the reconcilers along with the event and receiver servers.
+	modeAll = "all"
+
+	// modeServerOnly runs only the event and receiver servers, without
+	// reconcilers nor leader election, to scale the ingestion of events and
+	// webhooks independently of the controller leader.
+	modeServerOnly = "server-only"
+)
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -65,8 +90,10 @@ var (
 
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
+	_ = apiextensionsv1.AddToScheme(scheme)
 
 	_ = apiv1.AddToScheme(scheme)
+	_ = apiv1b1.AddToScheme(scheme)
 	_ = apiv1b2.AddToScheme(scheme)
 	_ = apiv1b3.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
@@ -79,7 +106,7 @@ func main() {
 		healthAddr            string
 		metricsAddr           string
 		concurrent            int
-		watchAllNamespaces    bool
+		watchOptions          helper.WatchOptions
 		rateLimitInterval     time.Duration
 		clientOptions         client.Options
 		logOptions            logger.Options
@@ -88,6 +115,34 @@ func main() {
 		rateLimiterOptions    helper.RateLimiterOptions
 		featureGates          feathelper.FeatureGates
 		exportHTTPPathMetrics bool
+		eventsH2C             bool
+		receiverAsyncWorkers  int
+		receiverPatchQPS      float32
+		receiverPatchBurst    int
+		receiverAPIVersions   map[string]string
+		webhookPathsEndpoint  bool
+		namespacedWebhooks    bool
+		providerTimeouts      server.ProviderTimeoutOptions
+		eventsCacheMaxAge     time.Duration
+		eventsHistorySize     int
+		involvedObjectEvents  bool
+		replayDir             string
+		noProxy               []string
+		providerWarmUp        bool
+		mode                  string
+		relayBufferDir        string
+		execSocketDir         string
+		namespaceQuota        int
+		ingestQuotas          map[string]int
+		coalesceAlerts        bool
+		secretsCacheTTL       time.Duration
+		conversionOptions     conversion.Options
+		userAgentSuffix       string
+		responseBodyLimit     int
+		tlsMinVersion         string
+		tlsCipherSuites       []string
+		fipsMode              bool
+		listenOptions         server.ListenOptions
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
@@ -95,10 +150,60 @@ func main() {
 	flag.StringVar(&healthAddr, "health-addr", ":9440", "The address the health endpoint binds to.")
 	flag.StringVar(&receiverAddr, "receiverAddr", ":9292", "The address the webhook receiver endpoint binds to.")
 	flag.IntVar(&concurrent, "concurrent", 4, "The number of concurrent notification reconciles.")
-	flag.BoolVar(&watchAllNamespaces, "watch-all-namespaces", true,
-		"Watch for custom resources in all namespaces, if set to false it will only watch the runtime namespace.")
+	flag.IntVar(&receiverAsyncWorkers, "receiver-async-workers", 0,
+		"The number of workers annotating the resources of a Receiver after the webhook request is acknowledged. When set to 0, resources are annotated before responding to the request.")
+	flag.Float32Var(&receiverPatchQPS, "receiver-patch-qps", 0,
+		"The maximum queries per second of the annotation patches requested by the Receivers. When set to 0, the patches are only limited by the Kubernetes client rate limit.")
+	flag.IntVar(&receiverPatchBurst, "receiver-patch-burst", 10,
+		"The maximum burst of the annotation patches requested by the Receivers, used with --receiver-patch-qps.")
+	flag.StringToStringVar(&receiverAPIVersions, "receiver-api-versions", nil,
+		"The API versions of the kinds of the Receiver resources without apiVersion, e.g. 'OCIRepository=source.toolkit.fluxcd.io/v1', overriding the versions discovered from the cluster.")
+	flag.BoolVar(&webhookPathsEndpoint, "enable-webhook-paths-endpoint", false,
+		"Serve the list of Receiver webhook paths and their Ready state on the metrics address at "+server.WebhookPathsEndpoint+", to the users allowed to list the Receivers.")
+	flag.BoolVar(&namespacedWebhooks, "receiver-namespaced-webhook-paths", false,
+		"Generate the Receiver webhook paths under /hook/<namespace>/ so that ingress controllers can apply per-tenant policies by path prefix. The flat /hook/<digest> paths keep being served.")
 	flag.DurationVar(&rateLimitInterval, "rate-limit-interval", 5*time.Minute, "Interval in which rate limit has effect.")
+	flag.BoolVar(&eventsH2C, "events-h2c", false, "Enable HTTP/2 cleartext (h2c) on the event endpoint, allowing clients to multiplex events over a single connection.")
+	flag.DurationVar(&eventsCacheMaxAge, "events-cache-max-age", 0,
+		"The maximum age of the last-known Alerts and Providers used to dispatch events when the Kubernetes API is unavailable. Zero disables the fallback.")
+	flag.IntVar(&eventsHistorySize, "events-history-size", 0,
+		"The number of recently dispatched notifications served on the event address at "+server.NotificationsEndpoint+". Zero disables the endpoint.")
+	flag.BoolVar(&involvedObjectEvents, "involved-object-events", false,
+		"Record a Kubernetes Event on the involved object of each dispatched notification, noting the Provider it was sent to and whether it failed.")
+	flag.StringSliceVar(&noProxy, "no-proxy", nil,
+		"The hosts, domains and CIDRs for which the Provider proxies are bypassed, in addition to the in-cluster service addresses.")
+	flag.BoolVar(&providerWarmUp, "provider-warm-up", false,
+		"Resolve the endpoints of the Providers and connect to them, without sending notifications, when the Providers become ready, and emit a warning event when they can't be reached.")
+	flag.StringVar(&relayBufferDir, "relay-buffer-dir", "",
+		"The directory where the events of the fluxrelay Providers are buffered when they can't be delivered. When empty, the undelivered events are dropped.")
+	flag.StringVar(&execSocketDir, "exec-provider-socket-dir", "/var/run/notifier",
+		"The directory the Unix domain sockets of the exec Providers must be located in.")
+	flag.IntVar(&namespaceQuota, "namespace-dispatch-quota", 0,
+		"The maximum number of notifications dispatched per minute for the Alerts of a namespace, the events exceeding the quota are dropped. Zero disables the quota.")
+	flag.StringToIntVar(&ingestQuotas, "event-ingest-quotas", nil,
+		"The maximum number of events accepted per minute from a reporting controller, e.g. 'source-controller=600', the events exceeding the quota are dropped. The controllers without quota are not limited.")
+	flag.BoolVar(&coalesceAlerts, "coalesce-alerts", false,
+		"Dispatch an event only once per Provider when several Alerts referencing the same Provider match the event, e.g. overlapping wildcard Alerts.")
+	flag.DurationVar(&secretsCacheTTL, "provider-secrets-cache-ttl", 0,
+		"The duration for which the Secrets and ConfigMaps of the Providers read when dispatching the events are cached, e.g. 30s. Zero disables the cache. Ignored when the CacheSecretsAndConfigMaps feature gate is enabled.")
+	flag.StringVar(&mode, "mode", modeAll,
+		"The mode the controller runs in, either '"+modeAll+"' or '"+modeServerOnly+"'. In '"+modeServerOnly+"' mode, only the event and receiver servers are run, without reconcilers nor leader election.")
 	flag.BoolVar(&exportHTTPPathMetrics, "export-http-path-metrics", false, "When enabled, the requests full path is included in the HTTP server metrics (risk as high cardinality")
+	flag.StringVar(&userAgentSuffix, "user-agent-suffix", "",
+		"The suffix appended to the User-Agent header of the requests sent to the providers and to validate the receivers' webhooks, e.g. to identify the organization operating the controller.")
+	flag.IntVar(&responseBodyLimit, "provider-response-body-limit", 1024,
+		"The maximum number of bytes of the provider response body included in the errors of the failed notifications. Zero leaves the response body out of the errors.")
+	flag.StringVar(&tlsMinVersion, "provider-tls-min-version", "",
+		"The minimum TLS version of the connections to the providers, either '1.2' or '1.3'. Defaults to the Go default.")
+	flag.StringSliceVar(&tlsCipherSuites, "provider-tls-cipher-suites", nil,
+		"The comma-separated list of the TLS 1.2 cipher suites, by their IANA names, allowed for the connections to the providers. Defaults to the Go default.")
+
+	flag.BoolVar(&fipsMode, "fips", false,
+		"Enable the FIPS mode, in which the controller refuses to start when not built with GOEXPERIMENT=boringcrypto, and refuses the SHA-1 HMAC signatures of the receivers and generic-hmac providers.")
+
+	flag.StringVar(&replayDir, "replay-dir", "",
+		"Replay the webhook fixtures found in this directory through the receiver pipeline at startup. For development only.")
+	_ = flag.CommandLine.MarkHidden("replay-dir")
 
 	clientOptions.BindFlags(flag.CommandLine)
 	logOptions.BindFlags(flag.CommandLine)
@@ -106,21 +211,81 @@ func main() {
 	aclOptions.BindFlags(flag.CommandLine)
 	rateLimiterOptions.BindFlags(flag.CommandLine)
 	featureGates.BindFlags(flag.CommandLine)
+	providerTimeouts.BindFlags(flag.CommandLine)
+	listenOptions.BindFlags(flag.CommandLine)
+	conversionOptions.BindFlags(flag.CommandLine)
+	watchOptions.BindFlags(flag.CommandLine)
 
 	flag.Parse()
 
 	logger.SetLogger(logger.NewLogger(logOptions))
 
+	if mode != modeAll && mode != modeServerOnly {
+		setupLog.Error(fmt.Errorf("unsupported mode '%s'", mode), "invalid mode flag")
+		os.Exit(1)
+	}
+
+	if fipsMode {
+		if err := fips.Enable(); err != nil {
+			setupLog.Error(err, "unable to enable the FIPS mode")
+			os.Exit(1)
+		}
+		setupLog.Info("FIPS mode enabled")
+	}
+
+	notifier.SetUserAgent(VERSION, userAgentSuffix)
+	notifier.SetResponseBodyLimit(responseBodyLimit)
+	if err := notifier.SetTLSOptions(tlsMinVersion, tlsCipherSuites); err != nil {
+		setupLog.Error(err, "invalid provider TLS flags")
+		os.Exit(1)
+	}
+
+	if err := server.ValidateAPIVersions(receiverAPIVersions); err != nil {
+		setupLog.Error(err, "invalid receiver API versions flag")
+		os.Exit(1)
+	}
+
+	if !filepath.IsAbs(execSocketDir) {
+		setupLog.Error(fmt.Errorf("'%s' is not an absolute path", execSocketDir), "invalid exec provider socket directory flag")
+		os.Exit(1)
+	}
+
+	if err := server.ValidateIngestQuotas(ingestQuotas); err != nil {
+		setupLog.Error(err, "invalid event ingestion quotas flag")
+		os.Exit(1)
+	}
+
+	if err := providerTimeouts.Validate(); err != nil {
+		setupLog.Error(err, "invalid provider timeout flags")
+		os.Exit(1)
+	}
+
+	if err := listenOptions.Validate(); err != nil {
+		setupLog.Error(err, "invalid listen flags")
+		os.Exit(1)
+	}
+
 	if err := featureGates.WithLogger(setupLog).SupportedFeatures(features.FeatureGates()); err != nil {
 		setupLog.Error(err, "unable to load feature gates")
 		os.Exit(1)
 	}
 
 	watchNamespace := ""
-	if !watchAllNamespaces {
+	if !watchOptions.AllNamespaces {
 		watchNamespace = os.Getenv("RUNTIME_NAMESPACE")
 	}
 
+	watchSelector, err := helper.GetWatchSelector(watchOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure watch label selector for manager")
+		os.Exit(1)
+	}
+
+	leaderElectionID := fmt.Sprintf("%s-leader-election", controllerName)
+	if watchOptions.LabelSelector != "" {
+		leaderElectionID = leaderelection.GenerateID(leaderElectionID, watchOptions.LabelSelector)
+	}
+
 	var disableCacheFor []ctrlclient.Object
 	shouldCache, err := features.Enabled(features.CacheSecretsAndConfigMaps)
 	if err != nil {
@@ -130,17 +295,45 @@ func main() {
 	if !shouldCache {
 		disableCacheFor = append(disableCacheFor, &corev1.Secret{}, &corev1.ConfigMap{})
 	}
+	// The cached Secrets and ConfigMaps are kept up to date with the watch
+	// events, which makes the TTL cache of the event server redundant.
+	if shouldCache && secretsCacheTTL > 0 {
+		setupLog.Info("ignoring the provider secrets cache TTL, the Secrets and ConfigMaps are cached by the " +
+			features.CacheSecretsAndConfigMaps + " feature gate")
+		secretsCacheTTL = 0
+	}
+
+	arbitraryResources, err := features.Enabled(features.ReceiverArbitraryResources)
+	if err != nil {
+		setupLog.Error(err, "unable to check feature gate "+features.ReceiverArbitraryResources)
+		os.Exit(1)
+	}
 
 	restConfig := client.GetConfigOrDie(clientOptions)
+
+	// The manager isn't started yet, the serving certificate of the
+	// conversion webhook is read with a client without cache.
+	certClient, err := ctrlclient.New(restConfig, ctrlclient.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create the conversion webhook certificate client")
+		os.Exit(1)
+	}
+	caBundle, err := conversion.EnsureCertificate(context.Background(), certClient, conversionOptions.CertDir,
+		types.NamespacedName{Name: conversionOptions.CertSecretName, Namespace: os.Getenv("RUNTIME_NAMESPACE")},
+		conversionOptions.ServiceName)
+	if err != nil {
+		setupLog.Error(err, "unable to set up the conversion webhook certificate")
+		os.Exit(1)
+	}
 	mgrConfig := ctrl.Options{
 		Scheme:                        scheme,
-		HealthProbeBindAddress:        healthAddr,
-		LeaderElection:                leaderElectionOptions.Enable,
+		HealthProbeBindAddress:        listenOptions.BindAddress(healthAddr),
+		LeaderElection:                leaderElectionOptions.Enable && mode != modeServerOnly,
 		LeaderElectionReleaseOnCancel: leaderElectionOptions.ReleaseOnCancel,
 		LeaseDuration:                 &leaderElectionOptions.LeaseDuration,
 		RenewDeadline:                 &leaderElectionOptions.RenewDeadline,
 		RetryPeriod:                   &leaderElectionOptions.RetryPeriod,
-		LeaderElectionID:              fmt.Sprintf("%s-leader-election", controllerName),
+		LeaderElectionID:              leaderElectionID,
 		Logger:                        ctrl.Log,
 		Controller: config.Controller{
 			RecoverPanic:            pointer.Bool(true),
@@ -152,16 +345,27 @@ func main() {
 			},
 		},
 		Metrics: metricsserver.Options{
-			BindAddress:   metricsAddr,
+			BindAddress:   listenOptions.BindAddress(metricsAddr),
 			ExtraHandlers: pprof.GetHandlers(),
 		},
+		WebhookServer: ctrlwebhook.NewServer(ctrlwebhook.Options{
+			Port:    conversionOptions.Port,
+			CertDir: conversionOptions.CertDir,
+		}),
 	}
 
+	// Only the Alerts, Providers and Receivers matching the watch label
+	// selector are reconciled and used by the event and receiver servers.
+	mgrConfig.Cache = ctrlcache.Options{
+		ByObject: map[ctrlclient.Object]ctrlcache.ByObject{
+			&apiv1.Alert{}:    {Label: watchSelector},
+			&apiv1.Provider{}: {Label: watchSelector},
+			&apiv1.Receiver{}: {Label: watchSelector},
+		},
+	}
 	if watchNamespace != "" {
-		mgrConfig.Cache = ctrlcache.Options{
-			DefaultNamespaces: map[string]ctrlcache.Config{
-				watchNamespace: ctrlcache.Config{},
-			},
+		mgrConfig.Cache.DefaultNamespaces = map[string]ctrlcache.Config{
+			watchNamespace: ctrlcache.Config{},
 		}
 	}
 
@@ -173,39 +377,88 @@ func main() {
 
 	probes.SetupChecks(mgr, setupLog)
 
-	metricsH := helper.NewMetrics(mgr, metrics.MustMakeRecorder(), apiv1.NotificationFinalizer)
-
-	if err = (&controller.ProviderReconciler{
-		Client:         mgr.GetClient(),
-		ControllerName: controllerName,
-		EventRecorder:  mgr.GetEventRecorderFor(controllerName),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Provider")
+	if err := mgr.AddMetricsServerExtraHandler(server.OpenMetricsEndpoint, server.OpenMetricsHandler()); err != nil {
+		setupLog.Error(err, "unable to register OpenMetrics endpoint")
 		os.Exit(1)
 	}
 
-	if err = (&controller.AlertReconciler{
-		Client:         mgr.GetClient(),
-		ControllerName: controllerName,
-		EventRecorder:  mgr.GetEventRecorderFor(controllerName),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Alert")
-		os.Exit(1)
+	if webhookPathsEndpoint {
+		if err := mgr.AddMetricsServerExtraHandler(server.WebhookPathsEndpoint,
+			server.WebhookPathsHandler(mgr.GetClient(), ctrl.Log.WithName("webhook-paths"))); err != nil {
+			setupLog.Error(err, "unable to register webhook paths endpoint")
+			os.Exit(1)
+		}
 	}
 
-	if err = (&controller.ReceiverReconciler{
-		Client:         mgr.GetClient(),
-		ControllerName: controllerName,
-		Metrics:        metricsH,
-		EventRecorder:  mgr.GetEventRecorderFor(controllerName),
-	}).SetupWithManagerAndOptions(mgr, controller.ReceiverReconcilerOptions{
-		RateLimiter: helper.GetRateLimiter(rateLimiterOptions),
-	}); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Receiver")
-		os.Exit(1)
+	metricsH := helper.NewMetrics(mgr, metrics.MustMakeRecorder(), apiv1.NotificationFinalizer)
+
+	// The reconcilers aren't run by the server-only replicas.
+	if mode == modeAll {
+		if err = (&controller.ProviderReconciler{
+			Client:         mgr.GetClient(),
+			ControllerName: controllerName,
+			Metrics:        metricsH,
+			EventRecorder:  mgr.GetEventRecorderFor(controllerName),
+			NoProxy:        noProxy,
+			WarmUp:         providerWarmUp,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Provider")
+			os.Exit(1)
+		}
+
+		if err = (&controller.AlertReconciler{
+			Client:         mgr.GetClient(),
+			ControllerName: controllerName,
+			Metrics:        metricsH,
+			EventRecorder:  mgr.GetEventRecorderFor(controllerName),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Alert")
+			os.Exit(1)
+		}
+
+		if err = (&controller.ReceiverReconciler{
+			Client:                 mgr.GetClient(),
+			ControllerName:         controllerName,
+			Metrics:                metricsH,
+			EventRecorder:          mgr.GetEventRecorderFor(controllerName),
+			NamespacedWebhookPaths: namespacedWebhooks,
+			ArbitraryResources:     arbitraryResources,
+		}).SetupWithManagerAndOptions(mgr, controller.ReceiverReconcilerOptions{
+			RateLimiter: helper.GetRateLimiter(rateLimiterOptions),
+		}); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Receiver")
+			os.Exit(1)
+		}
+	} else {
+		// The webhook receiver looks up the Receivers by their webhook path,
+		// which is otherwise indexed by the Receiver reconciler.
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1.Receiver{},
+			server.WebhookPathIndexKey, server.IndexReceiverWebhookPath); err != nil {
+			setupLog.Error(err, "unable to index the Receivers by webhook path")
+			os.Exit(1)
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
+	if err := conversion.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create the conversion webhook")
+		os.Exit(1)
+	}
+	// The CA bundle is injected by the leader, the server-only replicas
+	// don't take part in the leader election.
+	if mode == modeAll {
+		if err := mgr.Add(&conversion.CABundleInjector{
+			Reader:   mgr.GetAPIReader(),
+			Writer:   mgr.GetClient(),
+			CABundle: caBundle,
+			CRDs:     conversion.CRDs,
+			Logger:   ctrl.Log.WithName("conversion"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the conversion webhook CA bundle injection")
+			os.Exit(1)
+		}
+	}
+
 	ctx := ctrl.SetupSignalHandler()
 	store, err := memorystore.New(&memorystore.Config{
 		Interval: rateLimitInterval,
@@ -222,11 +475,45 @@ func main() {
 			Registry: crtlmetrics.Registry,
 		}),
 	})
-	eventServer := server.NewEventServer(eventsAddr, ctrl.Log, mgr.GetClient(), mgr.GetEventRecorderFor(controllerName), aclOptions.NoCrossNamespaceRefs, exportHTTPPathMetrics)
+	execProvider, err := features.Enabled(features.ExecProvider)
+	if err != nil {
+		setupLog.Error(err, "unable to check feature gate "+features.ExecProvider)
+		os.Exit(1)
+	}
+
+	eventServer := server.NewEventServer(eventsAddr, ctrl.Log, mgr.GetClient(), mgr.GetEventRecorderFor(controllerName), server.EventServerOptions{
+		NoCrossNamespaceRefs:  aclOptions.NoCrossNamespaceRefs,
+		ExportHTTPPathMetrics: exportHTTPPathMetrics,
+		EnableH2C:             eventsH2C,
+		ProviderTimeouts:      providerTimeouts,
+		CacheMaxAge:           eventsCacheMaxAge,
+		HistorySize:           eventsHistorySize,
+		InvolvedObjectEvents:  involvedObjectEvents,
+		ExecProvider:          execProvider,
+		ExecSocketDir:         execSocketDir,
+		NoProxy:               noProxy,
+		RelayBufferDir:        relayBufferDir,
+		NamespaceQuota:        namespaceQuota,
+		IngestQuotas:          ingestQuotas,
+		CoalesceAlerts:        coalesceAlerts,
+		SecretsCacheTTL:       secretsCacheTTL,
+		HelmReleaseReader:     mgr.GetCache(),
+		RestConfig:            restConfig,
+		ListenOptions:         listenOptions,
+	})
 	go eventServer.ListenAndServe(ctx.Done(), eventMdlw, store)
 
 	setupLog.Info("starting webhook receiver server", "addr", receiverAddr)
-	receiverServer := server.NewReceiverServer(receiverAddr, ctrl.Log, mgr.GetClient(), exportHTTPPathMetrics)
+	receiverServer := server.NewReceiverServer(receiverAddr, ctrl.Log, mgr.GetClient(), server.ReceiverServerOptions{
+		ExportHTTPPathMetrics: exportHTTPPathMetrics,
+		AsyncWorkers:          receiverAsyncWorkers,
+		ArbitraryResources:    arbitraryResources,
+		Dispatcher:            eventServer,
+		PatchQPS:              receiverPatchQPS,
+		PatchBurst:            receiverPatchBurst,
+		APIVersions:           receiverAPIVersions,
+		ListenOptions:         listenOptions,
+	})
 	receiverMdlw := middleware.New(middleware.Config{
 		Recorder: prommetrics.NewRecorder(prommetrics.Config{
 			Prefix:   "gotk_receiver",
@@ -235,6 +522,38 @@ func main() {
 	})
 	go receiverServer.ListenAndServe(ctx.Done(), receiverMdlw)
 
+	// Report the pod as unready, and restart it, when the servers are not
+	// listening or the receiver workers are stuck.
+	for name, check := range map[string]func(*http.Request) error{
+		"event-server":    eventServer.HealthCheck,
+		"receiver-server": receiverServer.HealthCheck,
+	} {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			setupLog.Error(err, "unable to set up ready check", "name", name)
+			os.Exit(1)
+		}
+		if err := mgr.AddHealthzCheck(name, check); err != nil {
+			setupLog.Error(err, "unable to set up health check", "name", name)
+			os.Exit(1)
+		}
+	}
+
+	if replayDir != "" {
+		replayer := server.NewWebhookReplayer(mgr.GetClient(), ctrl.Log, arbitraryResources)
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			if !mgr.GetCache().WaitForCacheSync(ctx) {
+				return nil
+			}
+			if _, err := replayer.ReplayDir(ctx, replayDir); err != nil {
+				setupLog.Error(err, "webhook fixtures replay failed", "dir", replayDir)
+			}
+			return nil
+		})); err != nil {
+			setupLog.Error(err, "unable to set up webhook fixtures replay")
+			os.Exit(1)
+		}
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// dispatchedProviders records the Providers an event was dispatched to,
+// to dispatch it only once per Provider when several Alerts referencing
+// the same Provider match the event.
+// A nil dispatchedProviders is valid and doesn't coalesce anything.
+type dispatchedProviders map[types.NamespacedName]struct{}
+
+// newDispatchedProviders returns an empty set of Providers, or nil when
+// the coalescing of the Alerts is disabled.
+func newDispatchedProviders(enabled bool) dispatchedProviders {
+	if !enabled {
+		return nil
+	}
+	return make(dispatchedProviders)
+}
+
+// pending returns the given Alert with only the Provider references the
+// event hasn't been dispatched to yet. The Alert is returned as is when
+// all of its Providers are pending.
+func (d dispatchedProviders) pending(alert *apiv1.Alert) *apiv1.Alert {
+	if d == nil {
+		return alert
+	}
+	refs := make([]meta.LocalObjectReference, 0, len(alert.Spec.ProviderRefs))
+	for _, ref := range alert.Spec.ProviderRefs {
+		if _, ok := d[types.NamespacedName{Namespace: alert.Namespace, Name: ref.Name}]; !ok {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == len(alert.Spec.ProviderRefs) {
+		return alert
+	}
+	pending := alert.DeepCopy()
+	pending.Spec.ProviderRefs = refs
+	return pending
+}
+
+// add records that the event was dispatched to the Providers of the Alert.
+func (d dispatchedProviders) add(alert *apiv1.Alert) {
+	if d == nil {
+		return
+	}
+	for _, ref := range alert.Spec.ProviderRefs {
+		d[types.NamespacedName{Namespace: alert.Namespace, Name: ref.Name}] = struct{}{}
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestDispatchedProviders(t *testing.T) {
+	g := NewWithT(t)
+
+	alert := func(name string, providers ...string) *apiv1.Alert {
+		a := &apiv1.Alert{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		for _, p := range providers {
+			a.Spec.ProviderRefs = append(a.Spec.ProviderRefs, meta.LocalObjectReference{Name: p})
+		}
+		return a
+	}
+
+	// A nil set doesn't coalesce anything.
+	disabled := newDispatchedProviders(false)
+	g.Expect(disabled).To(BeNil())
+	wildcard := alert("wildcard", "slack")
+	disabled.add(wildcard)
+	g.Expect(disabled.pending(wildcard)).To(BeIdenticalTo(wildcard))
+
+	d := newDispatchedProviders(true)
+	g.Expect(d.pending(wildcard)).To(BeIdenticalTo(wildcard))
+	d.add(wildcard)
+
+	specific := alert("specific", "slack", "teams")
+	pending := d.pending(specific)
+	g.Expect(pending.Spec.ProviderRefs).To(Equal([]meta.LocalObjectReference{{Name: "teams"}}))
+	g.Expect(specific.Spec.ProviderRefs).To(HaveLen(2))
+	d.add(pending)
+
+	g.Expect(d.pending(alert("duplicate", "slack", "teams")).Spec.ProviderRefs).To(BeEmpty())
+
+	// The Providers of other namespaces are distinct.
+	other := alert("other", "slack")
+	other.Namespace = "other"
+	g.Expect(d.pending(other)).To(BeIdenticalTo(other))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// alertRateLimitPruneInterval is the minimum period between the pruning of
+// the expired keys of the alertRateLimiter.
+const alertRateLimitPruneInterval = time.Minute
+
+type eventRateLimitContextKey struct{}
+
+// eventRateLimit is carried by the context of the events rate limited with
+// the global interval, and records whether the event was dispatched to the
+// Alerts overriding the rate limit interval.
+type eventRateLimit struct {
+	overridden bool
+}
+
+// eventRateLimitFromContext returns the eventRateLimit carried by the given
+// context, or nil if the event was not rate limited with the global interval.
+func eventRateLimitFromContext(ctx context.Context) *eventRateLimit {
+	l, _ := ctx.Value(eventRateLimitContextKey{}).(*eventRateLimit)
+	return l
+}
+
+// alertRateLimiter rate limits the duplicate events of the Alerts overriding
+// the rate limit interval, keyed by Alert and event.
+type alertRateLimiter struct {
+	mu         sync.Mutex
+	expiries   map[string]time.Time
+	lastPruned time.Time
+}
+
+func newAlertRateLimiter() *alertRateLimiter {
+	return &alertRateLimiter{expiries: make(map[string]time.Time)}
+}
+
+// allow returns if the event with the given key can be dispatched, i.e. if
+// it was not dispatched within the interval, and records its dispatch.
+func (l *alertRateLimiter) allow(key string, interval time.Duration) bool {
+	if l == nil || interval <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if now.Sub(l.lastPruned) > alertRateLimitPruneInterval {
+		for key, expiry := range l.expiries {
+			if now.After(expiry) {
+				delete(l.expiries, key)
+			}
+		}
+		l.lastPruned = now
+	}
+	if expiry, ok := l.expiries[key]; ok && now.Before(expiry) {
+		return false
+	}
+	l.expiries[key] = now.Add(interval)
+	return true
+}
+
+// alertsOverridingRateLimit returns the Alerts overriding the rate limit
+// interval of the event server.
+func alertsOverridingRateLimit(alerts []apiv1.Alert) []apiv1.Alert {
+	results := make([]apiv1.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.Spec.RateLimitInterval != nil {
+			results = append(results, alert)
+		}
+	}
+	return results
+}
+
+// rateLimitAlerts returns the Alerts to which the given event is dispatched.
+// The Alerts without rateLimitInterval share the global rate limiting of the
+// event server, and receive the event only if it was not rate limited. The
+// other Alerts rate limit the event with their own interval, a zero interval
+// disabling the rate limiting.
+func (s *EventServer) rateLimitAlerts(ctx context.Context, event *eventv1.Event, alerts []apiv1.Alert) []apiv1.Alert {
+	limited := eventRateLimitFromContext(ctx)
+	key := eventKey(event)
+
+	results := make([]apiv1.Alert, 0, len(alerts))
+	for i := range alerts {
+		alert := &alerts[i]
+		interval := alert.Spec.RateLimitInterval
+		if interval == nil {
+			if limited == nil {
+				results = append(results, *alert)
+			}
+			continue
+		}
+		if !s.alertRateLimiter.allow(alert.Namespace+"/"+alert.Name+"/"+key, interval.Duration) {
+			log.FromContext(ctx).V(1).Info("discarding event, rate limiting duplicate events",
+				alert.Kind, client.ObjectKeyFromObject(alert))
+			continue
+		}
+		if limited != nil {
+			limited.overridden = true
+		}
+		results = append(results, *alert)
+	}
+	return results
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sethvargo/go-limiter/httplimit"
+	"github.com/sethvargo/go-limiter/memorystore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestAlertRateLimiter(t *testing.T) {
+	g := NewWithT(t)
+
+	l := newAlertRateLimiter()
+	g.Expect(l.allow("a", time.Hour)).To(BeTrue())
+	g.Expect(l.allow("a", time.Hour)).To(BeFalse())
+	g.Expect(l.allow("b", time.Hour)).To(BeTrue())
+	g.Expect(l.allow("c", 0)).To(BeTrue())
+	g.Expect(l.allow("c", 0)).To(BeTrue())
+	g.Expect(l.allow("d", time.Millisecond)).To(BeTrue())
+	time.Sleep(2 * time.Millisecond)
+	g.Expect(l.allow("d", time.Millisecond)).To(BeTrue())
+
+	var nilLimiter *alertRateLimiter
+	g.Expect(nilLimiter.allow("a", time.Hour)).To(BeTrue())
+}
+
+func TestRateLimitAlerts(t *testing.T) {
+	g := NewWithT(t)
+
+	alert := func(name string, interval *metav1.Duration) apiv1.Alert {
+		return apiv1.Alert{
+			TypeMeta:   metav1.TypeMeta{Kind: apiv1.AlertKind},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       apiv1.AlertSpec{RateLimitInterval: interval},
+		}
+	}
+	alerts := []apiv1.Alert{
+		alert("default", nil),
+		alert("audit", &metav1.Duration{Duration: 0}),
+		alert("chat", &metav1.Duration{Duration: time.Hour}),
+	}
+	names := func(alerts []apiv1.Alert) []string {
+		var names []string
+		for _, a := range alerts {
+			names = append(names, a.Name)
+		}
+		return names
+	}
+	event := &eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Name: "apps", Namespace: "default"},
+		Message:        "Health check passed",
+	}
+	s := &EventServer{alertRateLimiter: newAlertRateLimiter()}
+
+	g.Expect(names(alertsOverridingRateLimit(alerts))).To(Equal([]string{"audit", "chat"}))
+
+	g.Expect(names(s.rateLimitAlerts(context.TODO(), event, alerts))).To(Equal([]string{"default", "audit", "chat"}))
+
+	// Rate limited with the global interval.
+	limited := &eventRateLimit{}
+	ctx := context.WithValue(context.TODO(), eventRateLimitContextKey{}, limited)
+	g.Expect(names(s.rateLimitAlerts(ctx, event, alerts))).To(Equal([]string{"audit"}))
+	g.Expect(limited.overridden).To(BeTrue())
+
+	limited = &eventRateLimit{}
+	ctx = context.WithValue(context.TODO(), eventRateLimitContextKey{}, limited)
+	g.Expect(s.rateLimitAlerts(ctx, event, alerts[2:])).To(BeEmpty())
+	g.Expect(limited.overridden).To(BeFalse())
+}
+
+func TestRateLimitMiddleware_overridden(t *testing.T) {
+	g := NewWithT(t)
+
+	store, err := memorystore.New(&memorystore.Config{
+		Tokens:   1,
+		Interval: 10 * time.Minute,
+	})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	limitMiddleware, err := httplimit.NewMiddleware(store, eventKeyFunc)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	calls := 0
+	handler := rateLimitMiddleware(limitMiddleware.Handle)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if limited := eventRateLimitFromContext(r.Context()); limited != nil {
+			limited.overridden = true
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	event := &eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Name: "audit", Namespace: "rate-limit"},
+		Message:        "Health check passed",
+	}
+	for range 2 {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/", nil)
+		handler.ServeHTTP(res, req.WithContext(context.WithValue(req.Context(), eventContextKey{}, event)))
+		g.Expect(res.Code).To(Equal(http.StatusAccepted))
+		g.Expect(res.Header().Get("Retry-After")).To(BeEmpty())
+	}
+
+	// The rate limited events are passed to the handler only once.
+	g.Expect(calls).To(Equal(2))
+}
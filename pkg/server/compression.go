@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+)
+
+// maxDecompressedBodySize is the maximum size of a gzip request body once
+// decompressed.
+const maxDecompressedBodySize = 32 << 20
+
+// gzipMiddleware decompresses the body of the requests sent with the
+// 'Content-Encoding: gzip' header, so that the other http handlers can read
+// the body as if it were sent uncompressed. Bodies exceeding
+// maxDecompressedBodySize once decompressed fail to be read.
+func gzipMiddleware(logger logr.Logger, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(strings.TrimSpace(r.Header.Get("Content-Encoding")), "gzip") {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			logger.Error(err, "decompressing the request body failed")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+
+		r.Body = http.MaxBytesReader(w, gz, maxDecompressedBodySize)
+		r.Header.Del("Content-Encoding")
+		r.Header.Del("Content-Length")
+		r.ContentLength = -1
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	log "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestGzipMiddleware(t *testing.T) {
+	compress := func(b []byte) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write(b)
+		_ = gz.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		body     []byte
+		encoding string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "passes uncompressed bodies through",
+			body:     []byte(`{"message":"hello"}`),
+			wantCode: http.StatusOK,
+			wantBody: `{"message":"hello"}`,
+		},
+		{
+			name:     "decompresses gzip bodies",
+			body:     compress([]byte(`{"message":"hello"}`)),
+			encoding: "gzip",
+			wantCode: http.StatusOK,
+			wantBody: `{"message":"hello"}`,
+		},
+		{
+			name:     "rejects invalid gzip bodies",
+			body:     []byte(`{"message":"hello"}`),
+			encoding: "gzip",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "rejects bodies exceeding the maximum size",
+			body:     compress(make([]byte, maxDecompressedBodySize+1)),
+			encoding: "gzip",
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			handler := gzipMiddleware(log.Log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+					return
+				}
+				g.Expect(r.Header.Get("Content-Encoding")).To(BeEmpty())
+				_, _ = w.Write(b)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			g.Expect(res.Code).To(Equal(tt.wantCode))
+			if tt.wantBody != "" {
+				g.Expect(res.Body.String()).To(Equal(tt.wantBody))
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// dispatchQuotaWindow is the period over which the notifications of a
+// namespace are counted against its quota.
+const dispatchQuotaWindow = time.Minute
+
+var quotaDropped = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gotk_event_quota_dropped_total",
+		Help: "The number of notifications dropped because the dispatch quota of the Alert namespace is exceeded.",
+	},
+	[]string{"namespace"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(quotaDropped)
+}
+
+// dispatchQuota limits the number of notifications dispatched for the Alerts
+// of each namespace per dispatchQuotaWindow, so that a noisy tenant can't
+// delay the notifications of the other tenants.
+// A nil *dispatchQuota is valid and doesn't limit anything.
+type dispatchQuota struct {
+	limit int
+
+	mu      sync.Mutex
+	windows map[string]*quotaWindow
+}
+
+type quotaWindow struct {
+	start time.Time
+	count int
+}
+
+// newDispatchQuota returns a quota allowing the given number of
+// notifications per namespace and window, or nil if the limit is not
+// greater than zero.
+func newDispatchQuota(limit int) *dispatchQuota {
+	if limit <= 0 {
+		return nil
+	}
+	return &dispatchQuota{
+		limit:   limit,
+		windows: make(map[string]*quotaWindow),
+	}
+}
+
+// allow returns if a notification can be dispatched for an Alert of the
+// given namespace, counting it against the quota of the namespace.
+func (q *dispatchQuota) allow(namespace string) bool {
+	if q == nil {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	w, ok := q.windows[namespace]
+	if !ok || now.Sub(w.start) >= dispatchQuotaWindow {
+		w = &quotaWindow{start: now}
+		q.windows[namespace] = w
+	}
+	if w.count >= q.limit {
+		quotaDropped.WithLabelValues(namespace).Inc()
+		return false
+	}
+	w.count++
+	return true
+}
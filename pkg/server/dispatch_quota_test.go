@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDispatchQuota(t *testing.T) {
+	g := NewWithT(t)
+
+	// A nil quota allows everything.
+	var unlimited *dispatchQuota
+	g.Expect(newDispatchQuota(0)).To(BeNil())
+	g.Expect(unlimited.allow("foo")).To(BeTrue())
+
+	q := newDispatchQuota(2)
+	g.Expect(q.allow("tenant-a")).To(BeTrue())
+	g.Expect(q.allow("tenant-a")).To(BeTrue())
+	g.Expect(q.allow("tenant-a")).To(BeFalse())
+	g.Expect(testutil.ToFloat64(quotaDropped.WithLabelValues("tenant-a"))).To(Equal(float64(1)))
+
+	// The quota is per namespace.
+	g.Expect(q.allow("tenant-b")).To(BeTrue())
+
+	// The quota is reset in the next window.
+	q.windows["tenant-a"].start = time.Now().Add(-dispatchQuotaWindow)
+	g.Expect(q.allow("tenant-a")).To(BeTrue())
+}
@@ -0,0 +1,26 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package server implements the event server, dispatching the events of the
+// Flux controllers to the Providers of the matching Alerts, and the receiver
+// server, requesting the reconciliation of the resources of the Receivers
+// called by webhooks. The servers can be embedded in other programs with
+// NewEventServer and NewReceiverServer, given a Kubernetes client with the
+// notification.toolkit.fluxcd.io/v1 types registered in its scheme. The
+// optional features are configured with EventServerOptions and
+// ReceiverServerOptions, whose zero values disable them, so that new options
+// don't change the constructors.
+package server
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+var eventCacheStaleness = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "gotk_event_cache_staleness_seconds",
+		Help: "The age of the last-known objects served by the event server when the Kubernetes API is unavailable, zero when the objects are up to date.",
+	},
+	[]string{"kind"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(eventCacheStaleness)
+}
+
+// lastKnownCache holds the last-known Alerts and Providers read by the event
+// server, so that events can still be dispatched during short Kubernetes API
+// outages. Objects older than maxAge are never served.
+// A nil *lastKnownCache is valid and caches nothing.
+type lastKnownCache struct {
+	maxAge time.Duration
+
+	mu            sync.RWMutex
+	alerts        []apiv1.Alert
+	alertsUpdated time.Time
+	providers     map[types.NamespacedName]cachedProvider
+}
+
+type cachedProvider struct {
+	provider apiv1.Provider
+	updated  time.Time
+}
+
+// newLastKnownCache returns a cache serving objects up to the given max age,
+// or nil if the max age is not greater than zero.
+func newLastKnownCache(maxAge time.Duration) *lastKnownCache {
+	if maxAge <= 0 {
+		return nil
+	}
+	return &lastKnownCache{
+		maxAge:    maxAge,
+		providers: make(map[types.NamespacedName]cachedProvider),
+	}
+}
+
+// setAlerts records the given Alerts as the last-known ones. The slice must
+// not be modified afterwards.
+func (c *lastKnownCache) setAlerts(alerts []apiv1.Alert) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.alerts = alerts
+	c.alertsUpdated = time.Now()
+	eventCacheStaleness.WithLabelValues(apiv1.AlertKind).Set(0)
+}
+
+// getAlerts returns the last-known Alerts, if not older than the max age.
+// The returned slice must not be modified.
+func (c *lastKnownCache) getAlerts() ([]apiv1.Alert, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.alertsUpdated.IsZero() {
+		return nil, false
+	}
+	age := time.Since(c.alertsUpdated)
+	if age > c.maxAge {
+		return nil, false
+	}
+	eventCacheStaleness.WithLabelValues(apiv1.AlertKind).Set(age.Seconds())
+	return c.alerts, true
+}
+
+// setProvider records the given Provider as the last-known one.
+func (c *lastKnownCache) setProvider(provider apiv1.Provider) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := types.NamespacedName{Namespace: provider.Namespace, Name: provider.Name}
+	c.providers[key] = cachedProvider{provider: provider, updated: time.Now()}
+	eventCacheStaleness.WithLabelValues(apiv1.ProviderKind).Set(0)
+}
+
+// deleteProvider removes the Provider with the given key from the cache.
+func (c *lastKnownCache) deleteProvider(key types.NamespacedName) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.providers, key)
+}
+
+// getProvider returns the last-known Provider with the given key, if not
+// older than the max age.
+func (c *lastKnownCache) getProvider(key types.NamespacedName) (apiv1.Provider, bool) {
+	if c == nil {
+		return apiv1.Provider{}, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cached, ok := c.providers[key]
+	if !ok {
+		return apiv1.Provider{}, false
+	}
+	age := time.Since(cached.updated)
+	if age > c.maxAge {
+		return apiv1.Provider{}, false
+	}
+	eventCacheStaleness.WithLabelValues(apiv1.ProviderKind).Set(age.Seconds())
+	return *cached.provider.DeepCopy(), true
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	log "sigs.k8s.io/controller-runtime/pkg/log"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestEventServer_lastKnownCache(t *testing.T) {
+	g := NewWithT(t)
+
+	testNamespace := "foo-ns"
+
+	provider := &apiv1.Provider{}
+	provider.Name = "provider-foo"
+	provider.Namespace = testNamespace
+	provider.Spec = apiv1.ProviderSpec{
+		Type:    "generic",
+		Address: "https://example.com",
+	}
+
+	alert := &apiv1.Alert{}
+	alert.Name = "alert-foo"
+	alert.Namespace = testNamespace
+	alert.Spec = apiv1.AlertSpec{
+		ProviderRefs: []meta.LocalObjectReference{{Name: provider.Name}},
+		EventSources: []apiv1.CrossNamespaceObjectReference{
+			{Kind: "Kustomization", Name: "*"},
+		},
+	}
+
+	event := &eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "kustomize.toolkit.fluxcd.io/v1",
+			Kind:       "Kustomization",
+			Name:       "foo",
+			Namespace:  testNamespace,
+		},
+		Severity: eventv1.EventSeverityInfo,
+	}
+
+	apiUnavailable := false
+	errUnavailable := errors.New("connection refused")
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
+	kclient := fakeclient.NewClientBuilder().WithScheme(scheme).
+		WithObjects(provider, alert).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if apiUnavailable {
+					return errUnavailable
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if apiUnavailable {
+					return errUnavailable
+				}
+				return c.List(ctx, list, opts...)
+			},
+		}).Build()
+
+	eventServer := &EventServer{
+		kubeClient:    kclient,
+		logger:        log.Log,
+		EventRecorder: record.NewFakeRecorder(32),
+		cache:         newLastKnownCache(time.Minute),
+	}
+
+	// Populate the cache.
+	alerts, err := eventServer.getAllAlertsForEvent(context.TODO(), event)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(alerts).To(HaveLen(1))
+	_, _, n, _, _, err := eventServer.getNotificationParams(context.TODO(), event, &alerts[0], alerts[0].Spec.ProviderRefs[0])
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(n).ToNot(BeNil())
+
+	// Serve the last-known objects while the API is unavailable.
+	apiUnavailable = true
+	alerts, err = eventServer.getAllAlertsForEvent(context.TODO(), event)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(alerts).To(HaveLen(1))
+	_, _, n, _, _, err = eventServer.getNotificationParams(context.TODO(), event, &alerts[0], alerts[0].Spec.ProviderRefs[0])
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(n).ToNot(BeNil())
+
+	// Stop serving the last-known objects once older than the max age.
+	eventServer.cache.alertsUpdated = time.Now().Add(-2 * time.Minute)
+	key := types.NamespacedName{Namespace: provider.Namespace, Name: provider.Name}
+	eventServer.cache.providers[key] = cachedProvider{provider: *provider, updated: time.Now().Add(-2 * time.Minute)}
+	_, err = eventServer.getAllAlertsForEvent(context.TODO(), event)
+	g.Expect(err).To(MatchError(errUnavailable))
+	_, _, _, _, _, err = eventServer.getNotificationParams(context.TODO(), event, alert, alert.Spec.ProviderRefs[0])
+	g.Expect(err).To(MatchError(errUnavailable))
+
+	// Forget deleted providers.
+	apiUnavailable = false
+	eventServer.cache.setProvider(*provider)
+	g.Expect(kclient.Delete(context.TODO(), provider)).To(Succeed())
+	_, _, _, _, _, err = eventServer.getNotificationParams(context.TODO(), event, alert, alert.Spec.ProviderRefs[0])
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(eventServer.cache.providers).ToNot(HaveKey(key))
+}
+
+func TestEventServer_lastKnownCacheDisabled(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
+	kclient := fakeclient.NewClientBuilder().WithScheme(scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				return errors.New("connection refused")
+			},
+		}).Build()
+
+	eventServer := &EventServer{
+		kubeClient:    kclient,
+		logger:        log.Log,
+		EventRecorder: record.NewFakeRecorder(32),
+		cache:         newLastKnownCache(0),
+	}
+	g.Expect(eventServer.cache).To(BeNil())
+
+	_, err := eventServer.getAllAlertsForEvent(context.TODO(), &eventv1.Event{})
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	"github.com/fluxcd/notification-controller/internal/notifier"
+)
+
+const (
+	helmReleaseKind  = "HelmRelease"
+	helmReleaseGroup = "helm.toolkit.fluxcd.io"
+
+	imageUpdateAutomationKind  = "ImageUpdateAutomation"
+	imageUpdateAutomationGroup = "image.toolkit.fluxcd.io"
+)
+
+var (
+	helmActionRegexp  = regexp.MustCompile(`^Helm (install|upgrade|test|rollback|uninstall)\b`)
+	helmReleaseRegexp = regexp.MustCompile(`\brelease ([^\s/]+/[^\s]+\.v\d+)`)
+	helmChartRegexp   = regexp.MustCompile(`\bwith chart ([^\s@]+)@([^\s:]+)`)
+
+	imagePushRegexp = regexp.MustCompile(`^(?:pushed commit '([0-9a-f]+)' to branch '([^']+)'|Committed and pushed change ([0-9a-f]+) to (\S+))`)
+)
+
+// enrichEvent adds structured metadata to events of the involved object
+// kinds that only describe their changes in the event message, so that
+// providers can render them as fields instead of a raw message string.
+// The metadata keys are prefixed with the involved object's API Group and
+// never override the metadata sent by the emitting controller.
+func (s *EventServer) enrichEvent(ctx context.Context, event *eventv1.Event) {
+	group := event.InvolvedObject.GroupVersionKind().Group
+	switch {
+	case event.InvolvedObject.Kind == helmReleaseKind && group == helmReleaseGroup:
+		s.enrichHelmReleaseEvent(ctx, event)
+	case event.InvolvedObject.Kind == imageUpdateAutomationKind && group == imageUpdateAutomationGroup:
+		enrichImageUpdateAutomationEvent(event)
+	}
+}
+
+// addCorrelationID sets the correlation ID in the metadata of the
+// notification. The ID is computed from the involved object and the
+// revision reported by its controller, so that the notifications sent to
+// different providers for the same failure can be traced back to each other.
+func addCorrelationID(event, notification *eventv1.Event) {
+	obj := event.InvolvedObject
+	revisionKey := obj.GroupVersionKind().Group + "/" + eventv1.MetaRevisionKey
+	id := fmt.Sprintf("%s/%s/%s/%s", obj.Kind, obj.Namespace, obj.Name, event.Metadata[revisionKey])
+
+	if notification.Metadata == nil {
+		notification.Metadata = make(map[string]string)
+	}
+	notification.Metadata[notifier.MetaCorrelationIDKey] = fmt.Sprintf("%x", sha256.Sum256([]byte(id)))
+}
+
+// addEventMetadata adds the given metadata to the event with the group
+// prefix, skipping the keys already set by the emitting controller.
+func addEventMetadata(event *eventv1.Event, group string, meta map[string]string) {
+	if len(meta) == 0 {
+		return
+	}
+	if event.Metadata == nil {
+		event.Metadata = make(map[string]string)
+	}
+	for k, v := range meta {
+		key := group + "/" + k
+		if _, ok := event.Metadata[key]; !ok {
+			event.Metadata[key] = v
+		}
+	}
+}
+
+// enrichHelmReleaseEvent extracts the Helm action, release and chart from
+// the message of a helm-controller event and looks up the previously
+// deployed chart version in the HelmRelease status history, read through
+// the helmReleaseReader.
+func (s *EventServer) enrichHelmReleaseEvent(ctx context.Context, event *eventv1.Event) {
+	meta := make(map[string]string)
+
+	if m := helmActionRegexp.FindStringSubmatch(event.Message); m != nil {
+		meta["action"] = m[1]
+	}
+	if m := helmReleaseRegexp.FindStringSubmatch(event.Message); m != nil {
+		meta["release"] = m[1]
+	}
+	chartVersion := ""
+	if m := helmChartRegexp.FindStringSubmatch(event.Message); m != nil {
+		meta["chart"] = m[1]
+		meta["chartVersion"] = m[2]
+		chartVersion = m[2]
+	}
+	if len(meta) == 0 {
+		return
+	}
+
+	if previous := s.previousHelmChartVersion(ctx, event, chartVersion); previous != "" {
+		meta["previousChartVersion"] = previous
+	}
+
+	addEventMetadata(event, helmReleaseGroup, meta)
+}
+
+// previousHelmChartVersion returns the chart version of the release
+// preceding the given chart version in the HelmRelease status history.
+// It returns an empty string if the HelmRelease or its history can't be
+// read, or if the chart version didn't change.
+func (s *EventServer) previousHelmChartVersion(ctx context.Context, event *eventv1.Event, chartVersion string) string {
+	if chartVersion == "" {
+		return ""
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(event.InvolvedObject.GroupVersionKind())
+	if err := s.helmReleaseReader.Get(ctx, types.NamespacedName{
+		Namespace: event.InvolvedObject.Namespace,
+		Name:      event.InvolvedObject.Name,
+	}, obj); err != nil {
+		log.FromContext(ctx).V(1).Info("unable to read HelmRelease history", "error", err.Error())
+		return ""
+	}
+
+	// The history is sorted from the latest to the oldest release.
+	history, _, _ := unstructured.NestedSlice(obj.Object, "status", "history")
+	if len(history) < 2 {
+		return ""
+	}
+	snapshot, ok := history[1].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	version, _, _ := unstructured.NestedString(snapshot, "chartVersion")
+	if version == chartVersion {
+		return ""
+	}
+	return version
+}
+
+// enrichImageUpdateAutomationEvent extracts the pushed commit, the branch
+// and the updated images from the message of an image-automation-controller
+// event. The updated images are read from the "Images:" list of the commit
+// message, as rendered by the default commit message template.
+func enrichImageUpdateAutomationEvent(event *eventv1.Event) {
+	meta := make(map[string]string)
+
+	if m := imagePushRegexp.FindStringSubmatch(event.Message); m != nil {
+		if m[1] != "" {
+			meta["commit"], meta["branch"] = m[1], m[2]
+		} else {
+			meta["commit"], meta["branch"] = m[3], m[4]
+		}
+	}
+	if images := updatedImages(event.Message); len(images) > 0 {
+		meta["images"] = strings.Join(images, ", ")
+	}
+
+	addEventMetadata(event, imageUpdateAutomationGroup, meta)
+}
+
+// updatedImages returns the items of the "Images:" list found in the given
+// commit message.
+func updatedImages(message string) []string {
+	var images []string
+	inList := false
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "Images:":
+			inList = true
+		case inList && strings.HasPrefix(line, "- "):
+			if image := strings.TrimSpace(strings.TrimPrefix(line, "- ")); image != "" {
+				images = append(images, image)
+			}
+		case inList:
+			inList = false
+		}
+	}
+	return images
+}
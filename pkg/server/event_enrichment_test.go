@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	"github.com/fluxcd/notification-controller/internal/notifier"
+)
+
+func TestEnrichEvent_HelmRelease(t *testing.T) {
+	helmRelease := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "helm.toolkit.fluxcd.io/v2",
+		"kind":       "HelmRelease",
+		"metadata": map[string]interface{}{
+			"name":      "podinfo",
+			"namespace": "apps",
+		},
+		"status": map[string]interface{}{
+			"history": []interface{}{
+				map[string]interface{}{"chartName": "podinfo", "chartVersion": "6.5.4", "version": int64(3)},
+				map[string]interface{}{"chartName": "podinfo", "chartVersion": "6.5.3", "version": int64(2)},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		kind     string
+		message  string
+		metadata map[string]string
+		want     map[string]string
+	}{
+		{
+			name:    "upgrade with previous chart version",
+			kind:    "HelmRelease",
+			message: "Helm upgrade succeeded for release apps/podinfo.v3 with chart podinfo@6.5.4",
+			want: map[string]string{
+				"helm.toolkit.fluxcd.io/action":               "upgrade",
+				"helm.toolkit.fluxcd.io/release":              "apps/podinfo.v3",
+				"helm.toolkit.fluxcd.io/chart":                "podinfo",
+				"helm.toolkit.fluxcd.io/chartVersion":         "6.5.4",
+				"helm.toolkit.fluxcd.io/previousChartVersion": "6.5.3",
+			},
+		},
+		{
+			name:    "failed upgrade with error message",
+			kind:    "HelmRelease",
+			message: "Helm upgrade failed for release apps/podinfo.v3 with chart podinfo@6.5.4: timed out waiting for the condition",
+			want: map[string]string{
+				"helm.toolkit.fluxcd.io/action":               "upgrade",
+				"helm.toolkit.fluxcd.io/release":              "apps/podinfo.v3",
+				"helm.toolkit.fluxcd.io/chart":                "podinfo",
+				"helm.toolkit.fluxcd.io/chartVersion":         "6.5.4",
+				"helm.toolkit.fluxcd.io/previousChartVersion": "6.5.3",
+			},
+		},
+		{
+			name:    "rollback to the previous chart version",
+			kind:    "HelmRelease",
+			message: "Helm rollback to previous release apps/podinfo.v2 with chart podinfo@6.5.3 succeeded",
+			want: map[string]string{
+				"helm.toolkit.fluxcd.io/action":       "rollback",
+				"helm.toolkit.fluxcd.io/release":      "apps/podinfo.v2",
+				"helm.toolkit.fluxcd.io/chart":        "podinfo",
+				"helm.toolkit.fluxcd.io/chartVersion": "6.5.3",
+			},
+		},
+		{
+			name:    "does not override controller metadata",
+			kind:    "HelmRelease",
+			message: "Helm install succeeded for release apps/podinfo.v1 with chart podinfo@6.5.4",
+			metadata: map[string]string{
+				"helm.toolkit.fluxcd.io/chart": "oci://ghcr.io/stefanprodan/charts/podinfo",
+			},
+			want: map[string]string{
+				"helm.toolkit.fluxcd.io/action":               "install",
+				"helm.toolkit.fluxcd.io/release":              "apps/podinfo.v1",
+				"helm.toolkit.fluxcd.io/chart":                "oci://ghcr.io/stefanprodan/charts/podinfo",
+				"helm.toolkit.fluxcd.io/chartVersion":         "6.5.4",
+				"helm.toolkit.fluxcd.io/previousChartVersion": "6.5.3",
+			},
+		},
+		{
+			name:    "message without Helm details",
+			kind:    "HelmRelease",
+			message: "HelmChart 'apps/apps-podinfo' is not ready",
+		},
+		{
+			name:    "other kinds are ignored",
+			kind:    "Kustomization",
+			message: "Helm upgrade succeeded for release apps/podinfo.v3 with chart podinfo@6.5.4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// The HelmReleases are read from the helmReleaseReader only.
+			reader := fakeclient.NewClientBuilder().WithScheme(runtime.NewScheme()).
+				WithObjects(helmRelease.DeepCopy()).Build()
+			s := &EventServer{
+				kubeClient:        fakeclient.NewClientBuilder().WithScheme(runtime.NewScheme()).Build(),
+				helmReleaseReader: reader,
+				EventRecorder:     record.NewFakeRecorder(32),
+			}
+
+			apiVersion := "helm.toolkit.fluxcd.io/v2"
+			if tt.kind != "HelmRelease" {
+				apiVersion = "kustomize.toolkit.fluxcd.io/v1"
+			}
+			event := &eventv1.Event{
+				InvolvedObject: corev1.ObjectReference{
+					APIVersion: apiVersion,
+					Kind:       tt.kind,
+					Name:       "podinfo",
+					Namespace:  "apps",
+				},
+				Message:  tt.message,
+				Metadata: tt.metadata,
+			}
+
+			s.enrichEvent(context.TODO(), event)
+			if tt.want == nil {
+				g.Expect(event.Metadata).To(BeEmpty())
+			} else {
+				g.Expect(event.Metadata).To(Equal(tt.want))
+			}
+		})
+	}
+}
+
+func TestEnrichEvent_ImageUpdateAutomation(t *testing.T) {
+	commitMessage := `Update from image update automation
+
+Automation name: flux-system/flux-system
+
+Files:
+- apps/podinfo.yaml
+Objects:
+- Deployment podinfo
+Images:
+- ghcr.io/stefanprodan/podinfo:6.5.4
+- ghcr.io/stefanprodan/podinfo-ui:1.2.0
+`
+
+	tests := []struct {
+		name     string
+		message  string
+		metadata map[string]string
+		want     map[string]string
+	}{
+		{
+			name:    "pushed commit",
+			message: "pushed commit 'a1afe26' to branch 'main'\n" + commitMessage,
+			want: map[string]string{
+				"image.toolkit.fluxcd.io/commit": "a1afe26",
+				"image.toolkit.fluxcd.io/branch": "main",
+				"image.toolkit.fluxcd.io/images": "ghcr.io/stefanprodan/podinfo:6.5.4, ghcr.io/stefanprodan/podinfo-ui:1.2.0",
+			},
+		},
+		{
+			name:    "legacy pushed commit",
+			message: "Committed and pushed change a1afe26 to main\n" + commitMessage,
+			want: map[string]string{
+				"image.toolkit.fluxcd.io/commit": "a1afe26",
+				"image.toolkit.fluxcd.io/branch": "main",
+				"image.toolkit.fluxcd.io/images": "ghcr.io/stefanprodan/podinfo:6.5.4, ghcr.io/stefanprodan/podinfo-ui:1.2.0",
+			},
+		},
+		{
+			name:    "custom commit message",
+			message: "pushed commit 'a1afe26' to branch 'main'\nchore: update images",
+			metadata: map[string]string{
+				"image.toolkit.fluxcd.io/images": "podinfo:6.5.4",
+			},
+			want: map[string]string{
+				"image.toolkit.fluxcd.io/commit": "a1afe26",
+				"image.toolkit.fluxcd.io/branch": "main",
+				"image.toolkit.fluxcd.io/images": "podinfo:6.5.4",
+			},
+		},
+		{
+			name:    "no changes",
+			message: "no updates made",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s := &EventServer{}
+			event := &eventv1.Event{
+				InvolvedObject: corev1.ObjectReference{
+					APIVersion: "image.toolkit.fluxcd.io/v1beta2",
+					Kind:       "ImageUpdateAutomation",
+					Name:       "flux-system",
+					Namespace:  "flux-system",
+				},
+				Message:  tt.message,
+				Metadata: tt.metadata,
+			}
+
+			s.enrichEvent(context.TODO(), event)
+			if tt.want == nil {
+				g.Expect(event.Metadata).To(BeEmpty())
+			} else {
+				g.Expect(event.Metadata).To(Equal(tt.want))
+			}
+		})
+	}
+}
+
+func TestAddCorrelationID(t *testing.T) {
+	g := NewWithT(t)
+
+	newEvent := func(name, revision string) *eventv1.Event {
+		return &eventv1.Event{
+			InvolvedObject: corev1.ObjectReference{
+				APIVersion: "kustomize.toolkit.fluxcd.io/v1",
+				Kind:       "Kustomization",
+				Name:       name,
+				Namespace:  "flux-system",
+			},
+			Metadata: map[string]string{
+				"kustomize.toolkit.fluxcd.io/revision": revision,
+			},
+		}
+	}
+	correlationID := func(event *eventv1.Event) string {
+		notification := &eventv1.Event{}
+		addCorrelationID(event, notification)
+		return notification.Metadata[notifier.MetaCorrelationIDKey]
+	}
+
+	id := correlationID(newEvent("apps", "main@sha1:a1afe267"))
+	g.Expect(id).To(HaveLen(64))
+	g.Expect(correlationID(newEvent("apps", "main@sha1:a1afe267"))).To(Equal(id))
+	g.Expect(correlationID(newEvent("apps", "main@sha1:b2bfe267"))).ToNot(Equal(id))
+	g.Expect(correlationID(newEvent("infra", "main@sha1:a1afe267"))).ToNot(Equal(id))
+}
@@ -0,0 +1,600 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+	"github.com/fluxcd/notification-controller/internal/alertmetrics"
+	"github.com/fluxcd/notification-controller/internal/features"
+	"github.com/fluxcd/notification-controller/internal/notifier"
+)
+
+var suspendedDropped = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gotk_alerts_suspended_dropped_total",
+		Help: "The number of events dropped because the matching Alert or its Provider is suspended.",
+	},
+	[]string{"kind", "name", "namespace"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(suspendedDropped)
+}
+
+func involvedObjectString(o corev1.ObjectReference) string {
+	return fmt.Sprintf("%s/%s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+func crossNSObjectRefString(o apiv1.CrossNamespaceObjectReference) string {
+	return fmt.Sprintf("%s/%s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+func (s *EventServer) handleEvent() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		event := r.Context().Value(eventContextKey{}).(*eventv1.Event)
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		w.WriteHeader(s.dispatchEvent(ctx, event))
+	}
+}
+
+// DispatchEvent dispatches the given event to the matching Alerts, like the
+// events received on the event endpoint. It's used for the events generated
+// by the controller itself, such as the events of the Receivers.
+func (s *EventServer) DispatchEvent(ctx context.Context, event *eventv1.Event) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	eventID := newEventID()
+	ctx = withEventID(ctx, eventID)
+	ctx = log.IntoContext(ctx, s.logger.WithValues("eventInvolvedObject", event.InvolvedObject, "eventID", eventID))
+	s.dispatchEvent(ctx, event)
+}
+
+// dispatchEvent dispatches the given event to the matching Alerts and returns
+// the HTTP status code of the outcome.
+func (s *EventServer) dispatchEvent(ctx context.Context, event *eventv1.Event) int {
+	eventLogger := log.FromContext(ctx)
+
+	// Remove any internal metadata before further processing the event.
+	excludeInternalMetadata(event)
+
+	alerts, err := s.getAllAlertsForEvent(ctx, event)
+	if err != nil {
+		eventLogger.Error(err, "failed to get alerts for the event")
+	}
+
+	if len(alerts) == 0 {
+		// The events rate limited by the event server are logged there.
+		if eventRateLimitFromContext(ctx) == nil {
+			eventLogger.Info("discarding event, no alerts found for the involved object")
+		}
+		return http.StatusAccepted
+	}
+
+	alerts = s.rateLimitAlerts(ctx, event, alerts)
+	if len(alerts) == 0 {
+		return http.StatusTooManyRequests
+	}
+
+	eventLogger.Info("dispatching event", "message", event.Message)
+
+	// Add structured metadata extracted from the event message.
+	s.enrichEvent(ctx, event)
+
+	// Dispatch notifications, within the quota of the Alert namespaces.
+	throttled := 0
+	dispatched := newDispatchedProviders(s.coalesceAlerts)
+	for i := range alerts {
+		alert := &alerts[i]
+		alertLogger := eventLogger.WithValues(alert.Kind, client.ObjectKeyFromObject(alert))
+		ctx := log.IntoContext(ctx, alertLogger)
+		alert = dispatched.pending(alert)
+		if dispatched != nil && len(alert.Spec.ProviderRefs) == 0 {
+			alertLogger.V(1).Info("discarding event, already dispatched to the providers of the alert")
+			continue
+		}
+		if !s.quota.allow(alert.Namespace) {
+			alertLogger.Info("discarding event, the dispatch quota of the namespace is exceeded")
+			throttled++
+			continue
+		}
+		dispatched.add(alert)
+		if err := s.dispatchNotification(ctx, event, alert); err != nil {
+			alertLogger.Error(err, "failed to dispatch notification")
+			s.Eventf(alert, corev1.EventTypeWarning, "NotificationDispatchFailed",
+				"failed to dispatch notification for %s: %s", involvedObjectString(event.InvolvedObject), err)
+		}
+	}
+
+	// Let the sender retry later if the event was dropped for all the
+	// Alerts because of their namespace quota.
+	if throttled == len(alerts) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusAccepted
+}
+
+func (s *EventServer) getAllAlertsForEvent(ctx context.Context, event *eventv1.Event) ([]apiv1.Alert, error) {
+	var allAlerts apiv1.AlertList
+	err := s.kubeClient.List(ctx, &allAlerts)
+	if err != nil {
+		alerts, ok := s.cache.getAlerts()
+		if !ok {
+			return nil, fmt.Errorf("failed listing alerts: %w", err)
+		}
+		log.FromContext(ctx).Error(err, "failed listing alerts, using the last-known alerts")
+		allAlerts.Items = alerts
+	} else {
+		s.cache.setAlerts(allAlerts.Items)
+	}
+
+	// Merge the cluster-wide NotificationPolicies into the Alerts, and drop
+	// the matching Alerts within a suppression window.
+	policies := s.getNotificationPolicies(ctx)
+	alerts := applyNotificationPolicies(allAlerts.Items, policies)
+
+	// The events rate limited with the global interval are only matched
+	// against the Alerts overriding the rate limit interval.
+	if eventRateLimitFromContext(ctx) != nil {
+		alerts = alertsOverridingRateLimit(alerts)
+	}
+
+	alerts = s.filterAlertsForEvent(ctx, alerts, event)
+	return suppressAlerts(ctx, alerts, policies, time.Now()), nil
+}
+
+// filterAlertsForEvent filters a given set of alerts against a given event,
+// checking if the event matches with any of the alert event sources and is
+// allowed by the exclusion list.
+func (s *EventServer) filterAlertsForEvent(ctx context.Context, alerts []apiv1.Alert, event *eventv1.Event) []apiv1.Alert {
+	logger := log.FromContext(ctx)
+
+	results := make([]apiv1.Alert, 0)
+	for i := range alerts {
+		alert := &alerts[i]
+		alertLogger := logger.WithValues(alert.Kind, client.ObjectKeyFromObject(alert))
+		ctx := log.IntoContext(ctx, alertLogger)
+
+		// Check if the event matches any of the alert sources.
+		if !s.eventMatchesAlertSources(ctx, event, alert) {
+			continue
+		}
+		// Check if the event message is allowed for the alert based on the
+		// inclusion list.
+		if !s.messageIsIncluded(ctx, event.Message, alert, alert.Spec.InclusionList) {
+			continue
+		}
+		// Check if the event message is allowed for the alert based on the
+		// exclusion list.
+		if s.messageIsExcluded(ctx, event.Message, alert, alert.Spec.ExclusionList) {
+			continue
+		}
+		// Skip suspended alert, counting the dropped event.
+		if alert.Spec.Suspend {
+			suspendedDropped.WithLabelValues(apiv1.AlertKind, alert.Name, alert.Namespace).Inc()
+			continue
+		}
+		// Check if the event is sampled for the alert, errors are always
+		// dispatched.
+		sampled, err := eventIsSampled(event, alert)
+		if err != nil {
+			alertLogger.Error(err, "invalid sample rate, dispatching the event")
+		}
+		if !sampled {
+			alertLogger.V(1).Info("discarding event, not sampled")
+			continue
+		}
+		alertmetrics.RecordMatched(alert.Namespace, alert.Name)
+		results = append(results, *alert)
+	}
+	return results
+}
+
+// eventMatchesAlertSources returns if a given event matches with any of the
+// alert sources, and is allowed by the inclusion and exclusion lists of the
+// matching source.
+func (s *EventServer) eventMatchesAlertSources(ctx context.Context, event *eventv1.Event, alert *apiv1.Alert) bool {
+	for _, source := range alert.Spec.EventSources {
+		if source.Namespace == "" {
+			source.Namespace = alert.Namespace
+		}
+		if s.eventMatchesAlertSource(ctx, event, alert, source) &&
+			s.messageIsIncluded(ctx, event.Message, alert, source.InclusionList) &&
+			!s.messageIsExcluded(ctx, event.Message, alert, source.ExclusionList) {
+			return true
+		}
+	}
+	return false
+}
+
+// messageIsIncluded returns if the given message matches with the given
+// inclusion rules of the alert.
+func (s *EventServer) messageIsIncluded(ctx context.Context, msg string, alert *apiv1.Alert, inclusionList []string) bool {
+	if len(inclusionList) == 0 {
+		return true
+	}
+
+	for _, exp := range inclusionList {
+		if r, err := regexp.Compile(exp); err == nil {
+			if r.Match([]byte(msg)) {
+				return true
+			}
+		} else {
+			log.FromContext(ctx).Error(err, fmt.Sprintf("failed to compile inclusion regex: %s", exp))
+			s.Eventf(alert, corev1.EventTypeWarning,
+				"InvalidConfig", "failed to compile inclusion regex: %s", exp)
+		}
+	}
+	return false
+}
+
+// messageIsExcluded returns if the given message matches with the given
+// exclusion rules of the alert.
+func (s *EventServer) messageIsExcluded(ctx context.Context, msg string, alert *apiv1.Alert, exclusionList []string) bool {
+	if len(exclusionList) == 0 {
+		return false
+	}
+
+	for _, exp := range exclusionList {
+		if r, err := regexp.Compile(exp); err == nil {
+			if r.Match([]byte(msg)) {
+				return true
+			}
+		} else {
+			log.FromContext(ctx).Error(err, fmt.Sprintf("failed to compile exclusion regex: %s", exp))
+			s.Eventf(alert, corev1.EventTypeWarning, "InvalidConfig",
+				"failed to compile exclusion regex: %s", exp)
+		}
+	}
+	return false
+}
+
+// dispatchNotification constructs and sends notifications from the given event
+// and alert data to each Provider of the alert.
+func (s *EventServer) dispatchNotification(ctx context.Context, event *eventv1.Event, alert *apiv1.Alert) error {
+	var errs []error
+	for _, providerRef := range alert.Spec.ProviderRefs {
+		if err := s.dispatchNotificationToProvider(ctx, event, alert, providerRef); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dispatchNotificationToProvider constructs and sends notification from the
+// given event and alert data to the given Provider.
+func (s *EventServer) dispatchNotificationToProvider(ctx context.Context, event *eventv1.Event, alert *apiv1.Alert, providerRef meta.LocalObjectReference) error {
+	sender, provider, notification, secrets, timeout, err := s.getNotificationParams(ctx, event, alert, providerRef)
+	if err != nil {
+		return err
+	}
+	// Skip when either sender or notification couldn't be created.
+	if sender == nil || notification == nil {
+		return nil
+	}
+
+	go func(n notifier.Interface, e eventv1.Event) {
+		pctx := notifier.WithResolver(notifier.WithProvider(context.Background(), provider.Spec.Type), notifier.ProviderResolver(provider))
+		pctx = withEventID(pctx, eventIDFromContext(ctx))
+		pctx, cancel := context.WithTimeout(pctx, timeout)
+		defer cancel()
+		err := notifier.RedactError(postNotification(pctx, log.FromContext(ctx), n, e, provider.Spec.Type, providerRef.Name, alert.Namespace), secrets)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "failed to send notification")
+			s.Eventf(alert, corev1.EventTypeWarning, "NotificationDispatchFailed",
+				"failed to send notification for %s: %s", involvedObjectString(event.InvolvedObject), err)
+		} else {
+			alertmetrics.RecordNotificationSent(alert.Namespace, alert.Name)
+		}
+		s.history.add(alert, providerRef.Name, &e, err)
+		if s.involvedObjectEvents {
+			s.recordInvolvedObjectEvent(e.InvolvedObject, alert, providerRef.Name, err)
+		}
+	}(sender, *notification)
+
+	return nil
+}
+
+// recordInvolvedObjectEvent records a Kubernetes Event on the involved object
+// of a notification, noting to which Provider the notification was sent and
+// whether it failed.
+func (s *EventServer) recordInvolvedObjectEvent(obj corev1.ObjectReference, alert *apiv1.Alert, provider string, err error) {
+	if err != nil {
+		s.Eventf(&obj, corev1.EventTypeWarning, "NotificationDispatchFailed",
+			"failed to send notification to provider '%s/%s' for alert '%s': %s", alert.Namespace, provider, alert.Name, err)
+		return
+	}
+	s.Eventf(&obj, corev1.EventTypeNormal, "NotificationSent",
+		"notification sent to provider '%s/%s' for alert '%s'", alert.Namespace, provider, alert.Name)
+}
+
+// getNotificationParams constructs the notification parameters from the given
+// event, alert and Provider reference, and returns a notifier, the Provider,
+// event, the Provider secret values and timeout for sending the notification.
+// The returned event is a mutated form of the input event based on the alert
+// configuration.
+func (s *EventServer) getNotificationParams(ctx context.Context, event *eventv1.Event, alert *apiv1.Alert, providerRef meta.LocalObjectReference) (notifier.Interface, *apiv1.Provider, *eventv1.Event, []string, time.Duration, error) {
+	// Check if event comes from a different namespace.
+	if s.noCrossNamespaceRefs && event.InvolvedObject.Namespace != alert.Namespace {
+		accessDenied := fmt.Errorf(
+			"alert '%s/%s' can't process event from '%s', cross-namespace references have been blocked",
+			alert.Namespace, alert.Name, involvedObjectString(event.InvolvedObject))
+		return nil, nil, nil, nil, 0, fmt.Errorf("discarding event, access denied to cross-namespace sources: %w", accessDenied)
+	}
+
+	var provider apiv1.Provider
+	providerName := types.NamespacedName{Namespace: alert.Namespace, Name: providerRef.Name}
+
+	err := s.kubeClient.Get(ctx, providerName, &provider)
+	switch {
+	case apierrors.IsNotFound(err):
+		s.cache.deleteProvider(providerName)
+		return nil, nil, nil, nil, 0, fmt.Errorf("failed to read provider: %w", err)
+	case err != nil:
+		cached, ok := s.cache.getProvider(providerName)
+		if !ok {
+			return nil, nil, nil, nil, 0, fmt.Errorf("failed to read provider: %w", err)
+		}
+		log.FromContext(ctx).Error(err, "failed to read provider, using the last-known provider")
+		provider = cached
+	default:
+		s.cache.setProvider(provider)
+	}
+
+	// Skip if the provider is suspended.
+	if provider.Spec.Suspend {
+		suspendedDropped.WithLabelValues(apiv1.ProviderKind, provider.Name, provider.Namespace).Inc()
+		return nil, nil, nil, nil, 0, nil
+	}
+
+	if provider.Spec.Type == apiv1.ExecProvider && !s.execProvider {
+		return nil, nil, nil, nil, 0, fmt.Errorf("provider '%s' of type '%s' is disabled, enable the %s feature gate to use it",
+			provider.Name, provider.Spec.Type, features.ExecProvider)
+	}
+
+	sender, secrets, err := notifier.NewForProvider(ctx, s.kubeClient, s.secretsClient, provider, s.noProxy)
+	if err != nil {
+		return nil, nil, nil, nil, 0, fmt.Errorf("failed to initialize notifier for provider '%s': %w", provider.Name, err)
+	}
+	if exec, ok := sender.(*notifier.Exec); ok {
+		if err := exec.ValidateSocketDir(s.execSocketDir); err != nil {
+			return nil, nil, nil, nil, 0, fmt.Errorf("failed to initialize notifier for provider '%s': %w", provider.Name, err)
+		}
+	}
+	if relay, ok := sender.(*notifier.FluxRelay); ok && s.relayBufferDir != "" {
+		relay.BufferDir = filepath.Join(s.relayBufferDir, provider.Namespace, provider.Name)
+	}
+
+	notification := *event.DeepCopy()
+	s.combineEventMetadata(ctx, &notification, alert)
+	s.formatEventMetadata(ctx, &notification, alert)
+	addCorrelationID(event, &notification)
+	if err := s.applyNotificationTemplate(ctx, &notification, alert, provider.Spec.Type); err != nil {
+		return nil, nil, nil, nil, 0, err
+	}
+	truncateMessage(&notification, provider.Spec.MessageTruncation)
+
+	timeout, outOfBounds := s.providerTimeouts.timeoutFor(&provider)
+	if outOfBounds {
+		log.FromContext(ctx).Info("provider timeout is out of bounds, using the closest bound instead",
+			"provider", provider.Name, "timeout", provider.Spec.Timeout.Duration.String(), "boundTimeout", timeout.String())
+	}
+
+	return sender, &provider, &notification, secrets, timeout, nil
+}
+
+// eventMatchesAlertSource returns if a given event matches with the given alert
+// source configuration, severity and reason.
+func (s *EventServer) eventMatchesAlertSource(ctx context.Context, event *eventv1.Event, alert *apiv1.Alert, source apiv1.CrossNamespaceObjectReference) bool {
+	logger := log.FromContext(ctx)
+
+	// No match if the event and source don't have the same namespace and kind.
+	if event.InvolvedObject.Namespace != source.Namespace ||
+		event.InvolvedObject.Kind != source.Kind {
+		return false
+	}
+
+	// No match if the alert doesn't dispatch events of this severity.
+	if !alert.MatchesSeverity(event.Severity) {
+		return false
+	}
+
+	// No match if the alert doesn't dispatch events of this reason.
+	if !alert.MatchesReason(event.Reason) {
+		return false
+	}
+
+	// No match if the source name isn't wildcard, and source and event names
+	// don't match.
+	if source.Name != "*" && source.Name != event.InvolvedObject.Name {
+		return false
+	}
+
+	// Match if no match labels specified.
+	if source.MatchLabels == nil {
+		return true
+	}
+
+	// Perform label selector matching.
+	var obj metav1.PartialObjectMetadata
+	obj.SetGroupVersionKind(event.InvolvedObject.GroupVersionKind())
+	obj.SetName(event.InvolvedObject.Name)
+	obj.SetNamespace(event.InvolvedObject.Namespace)
+
+	reader, err := s.sourceReader(alert)
+	if err != nil {
+		logger.Error(err, "error impersonating the Alert ServiceAccount")
+		s.Eventf(alert, corev1.EventTypeWarning, "ImpersonationFailed",
+			"error impersonating ServiceAccount '%s': %s", alert.Spec.ServiceAccountName, err)
+		return false
+	}
+
+	if err := reader.Get(ctx, types.NamespacedName{
+		Namespace: event.InvolvedObject.Namespace,
+		Name:      event.InvolvedObject.Name,
+	}, &obj); err != nil {
+		// The object of a deletion event may be gone by the time the event
+		// is received, in which case its labels can no longer be matched.
+		if apierrors.IsNotFound(err) && event.Reason == apiv1.DeletionReason {
+			logger.V(1).Info("skipping deletion event, the involved object no longer exists",
+				"involvedObject", involvedObjectString(event.InvolvedObject))
+			return false
+		}
+		logger.Error(err, "error getting the involved object")
+		s.Eventf(alert, corev1.EventTypeWarning, "SourceFetchFailed",
+			"error getting source object %s", involvedObjectString(event.InvolvedObject))
+		return false
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels: source.MatchLabels,
+	})
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("error using matchLabels from event source %s", crossNSObjectRefString(source)))
+		s.Eventf(alert, corev1.EventTypeWarning, "InvalidConfig",
+			"error using matchLabels from event source %s", crossNSObjectRefString(source))
+		return false
+	}
+
+	return sel.Matches(labels.Set(obj.GetLabels()))
+}
+
+// combineEventMetadata combines all the sources of metadata for the event
+// according to the precedence order defined in RFC 0008. From lowest to
+// highest precedence, the sources are:
+//
+// 1) Event metadata keys prefixed with the Event API Group stripped of the prefix.
+//
+// 2) Alert .spec.eventMetadata with the keys as they are.
+//
+// 3) Alert .spec.summary with the key "summary".
+//
+// 4) Event metadata keys prefixed with the involved object's API Group stripped of the prefix.
+//
+// At the end of the process key conflicts are detected and a single
+// info-level log is emitted to warn users about all the conflicts,
+// but only if at least one conflict is found.
+func (s *EventServer) combineEventMetadata(ctx context.Context, event *eventv1.Event, alert *apiv1.Alert) {
+	const (
+		sourceEventGroup         = "involved object annotations"
+		sourceAlertEventMetadata = "Alert object .spec.eventMetadata"
+		sourceAlertSummary       = "Alert object .spec.summary"
+		sourceObjectGroup        = "involved object controller metadata"
+
+		summaryKey = "summary"
+	)
+
+	l := log.FromContext(ctx)
+	metadata := make(map[string]string)
+	metadataSources := make(map[string][]string)
+
+	// 1) Event metadata keys prefixed with the Event API Group stripped of the prefix.
+	const eventGroupPrefix = eventv1.Group + "/"
+	for k, v := range event.Metadata {
+		if strings.HasPrefix(k, eventGroupPrefix) {
+			key := strings.TrimPrefix(k, eventGroupPrefix)
+			metadata[key] = v
+			metadataSources[key] = append(metadataSources[key], sourceEventGroup)
+		}
+	}
+
+	// 2) Alert .spec.eventMetadata with the keys as they are.
+	for k, v := range alert.Spec.EventMetadata {
+		metadata[k] = v
+		metadataSources[k] = append(metadataSources[k], sourceAlertEventMetadata)
+	}
+
+	// 3) Alert .spec.summary with the key "summary".
+	if alert.Spec.Summary != "" {
+		metadata[summaryKey] = alert.Spec.Summary
+		metadataSources[summaryKey] = append(metadataSources[summaryKey], sourceAlertSummary)
+		l.Info("warning: specifying an alert summary with '.spec.summary' is deprecated, use '.spec.eventMetadata.summary' instead")
+	}
+
+	// 4) Event metadata keys prefixed with the involved object's API Group stripped of the prefix.
+	objectGroupPrefix := event.InvolvedObject.GroupVersionKind().Group + "/"
+	for k, v := range event.Metadata {
+		if strings.HasPrefix(k, objectGroupPrefix) {
+			key := strings.TrimPrefix(k, objectGroupPrefix)
+			metadata[key] = v
+			metadataSources[key] = append(metadataSources[key], sourceObjectGroup)
+		}
+	}
+
+	// Detect key conflicts and emit warnings if any.
+	type keyConflict struct {
+		Key     string   `json:"key"`
+		Sources []string `json:"sources"`
+	}
+	var conflictingKeys []*keyConflict
+	conflictEventAnnotations := make(map[string]string)
+	for key, sources := range metadataSources {
+		if len(sources) > 1 {
+			conflictingKeys = append(conflictingKeys, &keyConflict{key, sources})
+			conflictEventAnnotations[key] = strings.Join(sources, ", ")
+		}
+	}
+	if len(conflictingKeys) > 0 {
+		const msg = "metadata key conflicts detected (please refer to the Alert API docs and Flux RFC 0008 for more information)"
+		slices.SortFunc(conflictingKeys, func(a, b *keyConflict) int { return strings.Compare(a.Key, b.Key) })
+		l.Info("warning: "+msg, "conflictingKeys", conflictingKeys)
+		s.AnnotatedEventf(alert, conflictEventAnnotations, corev1.EventTypeWarning, "MetadataAppendFailed", "%s", msg)
+	}
+
+	if len(metadata) > 0 {
+		event.Metadata = metadata
+	}
+}
+
+// excludeInternalMetadata removes any internal metadata from the given event.
+func excludeInternalMetadata(event *eventv1.Event) {
+	if len(event.Metadata) == 0 {
+		return
+	}
+	objectGroup := event.InvolvedObject.GetObjectKind().GroupVersionKind().Group
+	tokenKey := fmt.Sprintf("%s/%s", objectGroup, eventv1.MetaTokenKey)
+	excludeList := []string{tokenKey}
+	for _, key := range excludeList {
+		delete(event.Metadata, key)
+	}
+}
@@ -18,14 +18,14 @@ package server
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
-	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -37,16 +37,15 @@ import (
 	"github.com/fluxcd/pkg/apis/meta"
 
 	apiv1 "github.com/fluxcd/notification-controller/api/v1"
-	apiv1beta3 "github.com/fluxcd/notification-controller/api/v1beta3"
 )
 
 func TestFilterAlertsForEvent(t *testing.T) {
 	testNamespace := "foo-ns"
 
-	testProvider := &apiv1beta3.Provider{}
+	testProvider := &apiv1.Provider{}
 	testProvider.Name = "provider-foo"
 	testProvider.Namespace = testNamespace
-	testProvider.Spec = apiv1beta3.ProviderSpec{
+	testProvider.Spec = apiv1.ProviderSpec{
 		Type:    "generic",
 		Address: "https://example.com",
 	}
@@ -65,12 +64,12 @@ func TestFilterAlertsForEvent(t *testing.T) {
 
 	tests := []struct {
 		name             string
-		alertSpecs       []apiv1beta3.AlertSpec
+		alertSpecs       []apiv1.AlertSpec
 		resultAlertCount int
 	}{
 		{
 			name: "all match",
-			alertSpecs: []apiv1beta3.AlertSpec{
+			alertSpecs: []apiv1.AlertSpec{
 				{
 					EventSources: []apiv1.CrossNamespaceObjectReference{
 						{
@@ -92,7 +91,7 @@ func TestFilterAlertsForEvent(t *testing.T) {
 		},
 		{
 			name: "some suspended alerts",
-			alertSpecs: []apiv1beta3.AlertSpec{
+			alertSpecs: []apiv1.AlertSpec{
 				{
 					EventSources: []apiv1.CrossNamespaceObjectReference{
 						{
@@ -113,9 +112,47 @@ func TestFilterAlertsForEvent(t *testing.T) {
 			},
 			resultAlertCount: 1,
 		},
+		{
+			name: "alerts with event source inclusion and exclusion lists",
+			alertSpecs: []apiv1.AlertSpec{
+				{
+					EventSources: []apiv1.CrossNamespaceObjectReference{
+						{
+							Kind:          "Kustomization",
+							Name:          "*",
+							ExclusionList: []string{"excluded"},
+						},
+					},
+				},
+				{
+					EventSources: []apiv1.CrossNamespaceObjectReference{
+						{
+							Kind:          "Kustomization",
+							Name:          "*",
+							InclusionList: []string{"included"},
+						},
+					},
+				},
+				{
+					EventSources: []apiv1.CrossNamespaceObjectReference{
+						{
+							Kind:          "Kustomization",
+							Name:          "*",
+							InclusionList: []string{"included"},
+						},
+						{
+							Kind:          "Kustomization",
+							Name:          "foo",
+							InclusionList: []string{"excluded"},
+						},
+					},
+				},
+			},
+			resultAlertCount: 1,
+		},
 		{
 			name: "alerts with inclusion list unmatch",
-			alertSpecs: []apiv1beta3.AlertSpec{
+			alertSpecs: []apiv1.AlertSpec{
 				{
 					EventSources: []apiv1.CrossNamespaceObjectReference{
 						{
@@ -130,7 +167,7 @@ func TestFilterAlertsForEvent(t *testing.T) {
 		},
 		{
 			name: "alerts with inclusion list match",
-			alertSpecs: []apiv1beta3.AlertSpec{
+			alertSpecs: []apiv1.AlertSpec{
 				{
 					EventSources: []apiv1.CrossNamespaceObjectReference{
 						{
@@ -154,7 +191,7 @@ func TestFilterAlertsForEvent(t *testing.T) {
 		},
 		{
 			name: "alerts with invalid inclusion rule",
-			alertSpecs: []apiv1beta3.AlertSpec{
+			alertSpecs: []apiv1.AlertSpec{
 				{
 					EventSources: []apiv1.CrossNamespaceObjectReference{
 						{
@@ -169,7 +206,7 @@ func TestFilterAlertsForEvent(t *testing.T) {
 		},
 		{
 			name: "alerts with exclusion list match",
-			alertSpecs: []apiv1beta3.AlertSpec{
+			alertSpecs: []apiv1.AlertSpec{
 				{
 					EventSources: []apiv1.CrossNamespaceObjectReference{
 						{
@@ -192,7 +229,7 @@ func TestFilterAlertsForEvent(t *testing.T) {
 		},
 		{
 			name: "alerts with invalid exclusion rule",
-			alertSpecs: []apiv1beta3.AlertSpec{
+			alertSpecs: []apiv1.AlertSpec{
 				{
 					EventSources: []apiv1.CrossNamespaceObjectReference{
 						{
@@ -215,7 +252,7 @@ func TestFilterAlertsForEvent(t *testing.T) {
 		},
 		{
 			name: "alerts with inclusion and exclusion list match",
-			alertSpecs: []apiv1beta3.AlertSpec{
+			alertSpecs: []apiv1.AlertSpec{
 				{
 					EventSources: []apiv1.CrossNamespaceObjectReference{
 						{
@@ -239,7 +276,7 @@ func TestFilterAlertsForEvent(t *testing.T) {
 		},
 		{
 			name: "event source NS is not overwritten by alert NS",
-			alertSpecs: []apiv1beta3.AlertSpec{
+			alertSpecs: []apiv1.AlertSpec{
 				{
 					EventSources: []apiv1.CrossNamespaceObjectReference{
 						{
@@ -258,12 +295,12 @@ func TestFilterAlertsForEvent(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 
-			alerts := []apiv1beta3.Alert{}
+			alerts := []apiv1.Alert{}
 			for i, alertSpec := range tt.alertSpecs {
 				// Add the default provider ref for this test.
-				alertSpec.ProviderRef = meta.LocalObjectReference{Name: testProvider.Name}
+				alertSpec.ProviderRefs = []meta.LocalObjectReference{{Name: testProvider.Name}}
 				// Create new Alert with the spec.
-				alert := apiv1beta3.Alert{}
+				alert := apiv1.Alert{}
 				alert.Name = "test-alert-" + strconv.Itoa(i)
 				alert.Namespace = testNamespace
 				alert.Spec = alertSpec
@@ -272,7 +309,7 @@ func TestFilterAlertsForEvent(t *testing.T) {
 
 			// Create fake objects and event server.
 			scheme := runtime.NewScheme()
-			g.Expect(apiv1beta3.AddToScheme(scheme)).ToNot(HaveOccurred())
+			g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
 			builder := fakeclient.NewClientBuilder().WithScheme(scheme)
 			builder.WithObjects(testProvider)
 			eventServer := EventServer{
@@ -296,19 +333,19 @@ func TestDispatchNotification(t *testing.T) {
 	}))
 	defer rcvServer.Close()
 
-	testProvider := &apiv1beta3.Provider{}
+	testProvider := &apiv1.Provider{}
 	testProvider.Name = "provider-foo"
 	testProvider.Namespace = testNamespace
-	testProvider.Spec = apiv1beta3.ProviderSpec{
+	testProvider.Spec = apiv1.ProviderSpec{
 		Type:    "generic",
 		Address: rcvServer.URL,
 	}
 
-	testAlert := &apiv1beta3.Alert{}
+	testAlert := &apiv1.Alert{}
 	testAlert.Name = "alert-foo"
 	testAlert.Namespace = testNamespace
-	testAlert.Spec = apiv1beta3.AlertSpec{
-		ProviderRef: meta.LocalObjectReference{Name: testProvider.Name},
+	testAlert.Spec = apiv1.AlertSpec{
+		ProviderRefs: []meta.LocalObjectReference{{Name: testProvider.Name}},
 	}
 
 	// Event involved object.
@@ -324,6 +361,7 @@ func TestDispatchNotification(t *testing.T) {
 		name              string
 		providerNamespace string
 		providerSuspended bool
+		extraProviderRef  string
 		wantErr           bool
 	}{
 		{
@@ -338,6 +376,11 @@ func TestDispatchNotification(t *testing.T) {
 			name:              "provider suspended, skip",
 			providerSuspended: true,
 		},
+		{
+			name:             "one of the providers not found",
+			extraProviderRef: "provider-bar",
+			wantErr:          true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -352,10 +395,13 @@ func TestDispatchNotification(t *testing.T) {
 				provider.Namespace = tt.providerNamespace
 			}
 			provider.Spec.Suspend = tt.providerSuspended
+			if tt.extraProviderRef != "" {
+				alert.Spec.ProviderRefs = append(alert.Spec.ProviderRefs, meta.LocalObjectReference{Name: tt.extraProviderRef})
+			}
 
 			// Create fake objects and event server.
 			scheme := runtime.NewScheme()
-			g.Expect(apiv1beta3.AddToScheme(scheme)).ToNot(HaveOccurred())
+			g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
 			g.Expect(corev1.AddToScheme(scheme)).ToNot(HaveOccurred())
 			builder := fakeclient.NewClientBuilder().WithScheme(scheme)
 			builder.WithObjects(provider)
@@ -371,6 +417,113 @@ func TestDispatchNotification(t *testing.T) {
 	}
 }
 
+func TestSuspendedDropped(t *testing.T) {
+	g := NewWithT(t)
+
+	provider := &apiv1.Provider{}
+	provider.Name = "suspended-provider"
+	provider.Namespace = "suspend-ns"
+	provider.Spec = apiv1.ProviderSpec{
+		Type:    "generic",
+		Address: "https://example.com",
+		Suspend: true,
+	}
+
+	alert := apiv1.Alert{}
+	alert.Name = "suspended-alert"
+	alert.Namespace = "suspend-ns"
+	alert.Spec = apiv1.AlertSpec{
+		ProviderRefs: []meta.LocalObjectReference{{Name: provider.Name}},
+		EventSources: []apiv1.CrossNamespaceObjectReference{{Kind: "Kustomization", Name: "*"}},
+		Suspend:      true,
+	}
+	event := &eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Kustomization",
+			Name:      "foo",
+			Namespace: "suspend-ns",
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
+	eventServer := EventServer{
+		kubeClient:    fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(provider).Build(),
+		logger:        log.Log,
+		EventRecorder: record.NewFakeRecorder(32),
+	}
+
+	// Events not matching the suspended Alert are not counted.
+	other := event.DeepCopy()
+	other.InvolvedObject.Kind = "HelmRelease"
+	g.Expect(eventServer.filterAlertsForEvent(context.TODO(), []apiv1.Alert{alert}, other)).To(BeEmpty())
+	g.Expect(testutil.ToFloat64(suspendedDropped.WithLabelValues(apiv1.AlertKind, alert.Name, alert.Namespace))).To(Equal(float64(0)))
+
+	g.Expect(eventServer.filterAlertsForEvent(context.TODO(), []apiv1.Alert{alert}, event)).To(BeEmpty())
+	g.Expect(testutil.ToFloat64(suspendedDropped.WithLabelValues(apiv1.AlertKind, alert.Name, alert.Namespace))).To(Equal(float64(1)))
+
+	alert.Spec.Suspend = false
+	g.Expect(eventServer.dispatchNotification(context.TODO(), event, &alert)).To(Succeed())
+	g.Expect(testutil.ToFloat64(suspendedDropped.WithLabelValues(apiv1.ProviderKind, provider.Name, provider.Namespace))).To(Equal(float64(1)))
+}
+
+func TestDispatchNotification_involvedObjectEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	rcvServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rcvServer.Close()
+
+	provider := &apiv1.Provider{}
+	provider.Name = "provider-foo"
+	provider.Namespace = "foo-ns"
+	provider.Spec = apiv1.ProviderSpec{
+		Type:    "generic",
+		Address: rcvServer.URL,
+	}
+
+	alert := &apiv1.Alert{}
+	alert.Name = "alert-foo"
+	alert.Namespace = "foo-ns"
+	alert.Spec = apiv1.AlertSpec{
+		ProviderRefs: []meta.LocalObjectReference{{Name: provider.Name}},
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
+	eventRecorder := record.NewFakeRecorder(32)
+	eventServer := EventServer{
+		kubeClient:           fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(provider).Build(),
+		logger:               log.Log,
+		EventRecorder:        eventRecorder,
+		involvedObjectEvents: true,
+	}
+
+	event := &eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "kustomize.toolkit.fluxcd.io/v1",
+			Kind:       "Kustomization",
+			Name:       "foo",
+			Namespace:  "foo-ns",
+		},
+		Severity: eventv1.EventSeverityError,
+		Message:  "Health check failed",
+	}
+	g.Expect(eventServer.dispatchNotification(context.TODO(), event, alert)).To(Succeed())
+
+	var events []string
+	g.Eventually(func() []string {
+		select {
+		case e := <-eventRecorder.Events:
+			events = append(events, e)
+		default:
+		}
+		return events
+	}).Should(ContainElement(
+		HavePrefix("Warning NotificationDispatchFailed failed to send notification to provider 'foo-ns/provider-foo' for alert 'alert-foo'")))
+}
+
 func TestGetNotificationParams(t *testing.T) {
 	testNamespace := "foo-ns"
 
@@ -379,20 +532,20 @@ func TestGetNotificationParams(t *testing.T) {
 	testSecret.Name = "secret-foo"
 	testSecret.Namespace = testNamespace
 
-	testProvider := &apiv1beta3.Provider{}
+	testProvider := &apiv1.Provider{}
 	testProvider.Name = "provider-foo"
 	testProvider.Namespace = testNamespace
-	testProvider.Spec = apiv1beta3.ProviderSpec{
+	testProvider.Spec = apiv1.ProviderSpec{
 		Type:      "generic",
 		Address:   "https://example.com",
 		SecretRef: &meta.LocalObjectReference{Name: testSecret.Name},
 	}
 
-	testAlert := &apiv1beta3.Alert{}
+	testAlert := &apiv1.Alert{}
 	testAlert.Name = "alert-foo"
 	testAlert.Namespace = testNamespace
-	testAlert.Spec = apiv1beta3.AlertSpec{
-		ProviderRef: meta.LocalObjectReference{Name: testProvider.Name},
+	testAlert.Spec = apiv1.AlertSpec{
+		ProviderRefs: []meta.LocalObjectReference{{Name: testProvider.Name}},
 	}
 
 	// Event involved object.
@@ -411,8 +564,11 @@ func TestGetNotificationParams(t *testing.T) {
 		alertEventMetadata map[string]string
 		providerNamespace  string
 		providerSuspended  bool
+		providerType       string
 		secretNamespace    string
 		noCrossNSRefs      bool
+		execProvider       bool
+		execSocketDir      string
 		eventMetadata      map[string]string
 		wantErr            bool
 	}{
@@ -444,6 +600,24 @@ func TestGetNotificationParams(t *testing.T) {
 			secretNamespace: "bar-ns",
 			wantErr:         true,
 		},
+		{
+			name:         "exec provider with feature gate disabled",
+			providerType: apiv1.ExecProvider,
+			wantErr:      true,
+		},
+		{
+			name:          "exec provider with feature gate enabled",
+			providerType:  apiv1.ExecProvider,
+			execProvider:  true,
+			execSocketDir: "/var/run",
+		},
+		{
+			name:          "exec provider with socket outside of the socket dir",
+			providerType:  apiv1.ExecProvider,
+			execProvider:  true,
+			execSocketDir: "/var/run/notifier",
+			wantErr:       true,
+		},
 		{
 			name:         "alert with summary, no event metadata",
 			alertSummary: "some summary text",
@@ -489,6 +663,10 @@ func TestGetNotificationParams(t *testing.T) {
 				provider.Namespace = tt.providerNamespace
 			}
 			provider.Spec.Suspend = tt.providerSuspended
+			if tt.providerType != "" {
+				provider.Spec.Type = tt.providerType
+				provider.Spec.Address = "unix:///var/run/notifier.sock"
+			}
 			if tt.secretNamespace != "" {
 				secret.Namespace = tt.secretNamespace
 			}
@@ -498,7 +676,7 @@ func TestGetNotificationParams(t *testing.T) {
 
 			// Create fake objects and event server.
 			scheme := runtime.NewScheme()
-			g.Expect(apiv1beta3.AddToScheme(scheme)).ToNot(HaveOccurred())
+			g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
 			g.Expect(corev1.AddToScheme(scheme)).ToNot(HaveOccurred())
 			builder := fakeclient.NewClientBuilder().WithScheme(scheme)
 			builder.WithObjects(provider, secret)
@@ -506,10 +684,12 @@ func TestGetNotificationParams(t *testing.T) {
 				kubeClient:           builder.Build(),
 				logger:               log.Log,
 				noCrossNamespaceRefs: tt.noCrossNSRefs,
+				execProvider:         tt.execProvider,
+				execSocketDir:        tt.execSocketDir,
 				EventRecorder:        record.NewFakeRecorder(32),
 			}
 
-			_, n, _, _, err := eventServer.getNotificationParams(context.TODO(), event, alert)
+			_, _, n, _, _, err := eventServer.getNotificationParams(context.TODO(), event, alert, alert.Spec.ProviderRefs[0])
 			g.Expect(err != nil).To(Equal(tt.wantErr))
 			if tt.alertSummary != "" {
 				g.Expect(n.Metadata["summary"]).To(Equal(tt.alertSummary))
@@ -525,275 +705,6 @@ func TestGetNotificationParams(t *testing.T) {
 	}
 }
 
-func TestCreateNotifier(t *testing.T) {
-	secretName := "foo-secret"
-	certSecretName := "cert-secret"
-	tests := []struct {
-		name           string
-		providerSpec   *apiv1beta3.ProviderSpec
-		secretType     corev1.SecretType
-		secretData     map[string][]byte
-		certSecretData map[string][]byte
-		wantErr        bool
-	}{
-		{
-			name: "no address, no secret ref",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type: "slack",
-			},
-			wantErr: true,
-		},
-		{
-			name: "valid address, no secret ref",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:    "slack",
-				Address: "https://example.com",
-			},
-		},
-		{
-			name: "reference to non-existing secret ref",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:      "slack",
-				SecretRef: &meta.LocalObjectReference{Name: "foo"},
-			},
-			wantErr: true,
-		},
-		{
-			name: "reference to secret with valid address, proxy, headers",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:      "slack",
-				SecretRef: &meta.LocalObjectReference{Name: secretName},
-			},
-			secretData: map[string][]byte{
-				"address": []byte("https://example.com"),
-				"proxy":   []byte("https://exampleproxy.com"),
-				"headers": []byte(`foo: bar`),
-			},
-		},
-		{
-			name: "reference to secret with invalid proxy",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:      "slack",
-				SecretRef: &meta.LocalObjectReference{Name: secretName},
-			},
-			secretData: map[string][]byte{
-				"address": []byte("https://example.com"),
-				"proxy":   []byte("https://exampleproxy.com|"),
-			},
-			wantErr: true,
-		},
-		{
-			name: "invalid headers in secret reference",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:      "slack",
-				SecretRef: &meta.LocalObjectReference{Name: secretName},
-			},
-			secretData: map[string][]byte{
-				"address": []byte("https://example.com"),
-				"headers": []byte("foo"),
-			},
-			wantErr: true,
-		},
-		{
-			name: "invalid spec address overridden by valid secret ref address",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:      "slack",
-				SecretRef: &meta.LocalObjectReference{Name: secretName},
-				Address:   "https://example.com|",
-			},
-			secretData: map[string][]byte{
-				"address": []byte("https://example.com"),
-			},
-		},
-		{
-			name: "invalid spec proxy overridden by valid secret ref proxy",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:      "slack",
-				SecretRef: &meta.LocalObjectReference{Name: secretName},
-				Proxy:     "https://example.com|",
-			},
-			secretData: map[string][]byte{
-				"address": []byte("https://example.com"),
-				"proxy":   []byte("https://example.com"),
-			},
-		},
-		{
-			name: "reference to unsupported cert secret type",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:          "slack",
-				Address:       "https://example.com",
-				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
-			},
-			secretType: corev1.SecretTypeDockercfg,
-			certSecretData: map[string][]byte{
-				"aaa": []byte("bbb"),
-			},
-			wantErr: true,
-		},
-		{
-			name: "reference to non-existing cert secret",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:          "slack",
-				Address:       "https://example.com",
-				CertSecretRef: &meta.LocalObjectReference{Name: "some-secret"},
-			},
-			wantErr: true,
-		},
-		{
-			name: "reference to cert secret without cert data",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:          "slack",
-				Address:       "https://example.com",
-				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
-			},
-			certSecretData: map[string][]byte{
-				"aaa": []byte("bbb"),
-			},
-			wantErr: true,
-		},
-		{
-			name: "cert secret reference in ca.crt with valid CA",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:          "slack",
-				Address:       "https://example.com",
-				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
-			},
-			certSecretData: map[string][]byte{
-				// Based on https://pkg.go.dev/crypto/tls#X509KeyPair example.
-				"ca.crt": []byte(`-----BEGIN CERTIFICATE-----
-MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
-DgYDVQQKEwdBY21lIENvMB4XDTE3MTAyMDE5NDMwNloXDTE4MTAyMDE5NDMwNlow
-EjEQMA4GA1UEChMHQWNtZSBDbzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABD0d
-7VNhbWvZLWPuj/RtHFjvtJBEwOkhbN/BnnE8rnZR8+sbwnc/KhCk3FhnpHZnQz7B
-5aETbbIgmuvewdjvSBSjYzBhMA4GA1UdDwEB/wQEAwICpDATBgNVHSUEDDAKBggr
-BgEFBQcDATAPBgNVHRMBAf8EBTADAQH/MCkGA1UdEQQiMCCCDmxvY2FsaG9zdDo1
-NDUzgg4xMjcuMC4wLjE6NTQ1MzAKBggqhkjOPQQDAgNIADBFAiEA2zpJEPQyz6/l
-Wf86aX6PepsntZv2GYlA5UpabfT2EZICICpJ5h/iI+i341gBmLiAFQOyTDT+/wQc
-6MF9+Yw1Yy0t
------END CERTIFICATE-----`),
-			},
-		},
-		{
-			name: "cert secret reference in caFile with valid CA",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:          "slack",
-				Address:       "https://example.com",
-				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
-			},
-			certSecretData: map[string][]byte{
-				// Based on https://pkg.go.dev/crypto/tls#X509KeyPair example.
-				"caFile": []byte(`-----BEGIN CERTIFICATE-----
-MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
-DgYDVQQKEwdBY21lIENvMB4XDTE3MTAyMDE5NDMwNloXDTE4MTAyMDE5NDMwNlow
-EjEQMA4GA1UEChMHQWNtZSBDbzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABD0d
-7VNhbWvZLWPuj/RtHFjvtJBEwOkhbN/BnnE8rnZR8+sbwnc/KhCk3FhnpHZnQz7B
-5aETbbIgmuvewdjvSBSjYzBhMA4GA1UdDwEB/wQEAwICpDATBgNVHSUEDDAKBggr
-BgEFBQcDATAPBgNVHRMBAf8EBTADAQH/MCkGA1UdEQQiMCCCDmxvY2FsaG9zdDo1
-NDUzgg4xMjcuMC4wLjE6NTQ1MzAKBggqhkjOPQQDAgNIADBFAiEA2zpJEPQyz6/l
-Wf86aX6PepsntZv2GYlA5UpabfT2EZICICpJ5h/iI+i341gBmLiAFQOyTDT+/wQc
-6MF9+Yw1Yy0t
------END CERTIFICATE-----`),
-			},
-		},
-		{
-			name: "cert secret reference in both ca.crt and caFile",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:          "slack",
-				Address:       "https://example.com",
-				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
-			},
-			certSecretData: map[string][]byte{
-				// Based on https://pkg.go.dev/crypto/tls#X509KeyPair example.
-				"ca.crt": []byte(`-----BEGIN CERTIFICATE-----
-MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
-DgYDVQQKEwdBY21lIENvMB4XDTE3MTAyMDE5NDMwNloXDTE4MTAyMDE5NDMwNlow
-EjEQMA4GA1UEChMHQWNtZSBDbzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABD0d
-7VNhbWvZLWPuj/RtHFjvtJBEwOkhbN/BnnE8rnZR8+sbwnc/KhCk3FhnpHZnQz7B
-5aETbbIgmuvewdjvSBSjYzBhMA4GA1UdDwEB/wQEAwICpDATBgNVHSUEDDAKBggr
-BgEFBQcDATAPBgNVHRMBAf8EBTADAQH/MCkGA1UdEQQiMCCCDmxvY2FsaG9zdDo1
-NDUzgg4xMjcuMC4wLjE6NTQ1MzAKBggqhkjOPQQDAgNIADBFAiEA2zpJEPQyz6/l
-Wf86aX6PepsntZv2GYlA5UpabfT2EZICICpJ5h/iI+i341gBmLiAFQOyTDT+/wQc
-6MF9+Yw1Yy0t
------END CERTIFICATE-----`),
-				"caFile": []byte(`aaaaa`), // invalid
-			},
-		},
-		{
-			name: "cert secret reference with invalid CA",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:          "slack",
-				Address:       "https://example.com",
-				CertSecretRef: &meta.LocalObjectReference{Name: certSecretName},
-			},
-			certSecretData: map[string][]byte{
-				"ca.crt": []byte(`aaaaa`),
-			},
-			wantErr: true,
-		},
-		{
-			name: "unsupported provider",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:    "foo",
-				Address: "https://example.com",
-			},
-			wantErr: true,
-		},
-		{
-			name: "address in secret too long",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:      "msteams",
-				SecretRef: &meta.LocalObjectReference{Name: secretName},
-			},
-			secretData: map[string][]byte{
-				"address": []byte(fmt.Sprintf("https://example.org/%s", strings.Repeat("a", 2029))),
-			},
-			wantErr: true,
-		},
-		{
-			name: "address in secret exactly as long as possible",
-			providerSpec: &apiv1beta3.ProviderSpec{
-				Type:      "msteams",
-				SecretRef: &meta.LocalObjectReference{Name: secretName},
-			},
-			secretData: map[string][]byte{
-				"address": []byte(fmt.Sprintf("https://example.org/%s", strings.Repeat("a", 2028))),
-			},
-			wantErr: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			g := NewWithT(t)
-
-			// Create fake objects and event server.
-			scheme := runtime.NewScheme()
-			g.Expect(apiv1beta3.AddToScheme(scheme)).ToNot(HaveOccurred())
-			g.Expect(corev1.AddToScheme(scheme)).ToNot(HaveOccurred())
-			builder := fakeclient.NewClientBuilder().WithScheme(scheme)
-			if tt.secretData != nil {
-				secret := &corev1.Secret{
-					ObjectMeta: metav1.ObjectMeta{Name: secretName},
-					Data:       tt.secretData,
-				}
-				builder.WithObjects(secret)
-			}
-			if tt.certSecretData != nil {
-				secret := &corev1.Secret{
-					ObjectMeta: metav1.ObjectMeta{Name: certSecretName},
-					Type:       tt.secretType,
-					Data:       tt.certSecretData,
-				}
-				builder.WithObjects(secret)
-			}
-			provider := apiv1beta3.Provider{Spec: *tt.providerSpec}
-
-			_, _, err := createNotifier(context.TODO(), builder.Build(), provider)
-			g.Expect(err != nil).To(Equal(tt.wantErr))
-		})
-	}
-}
-
 func TestEventMatchesAlert(t *testing.T) {
 	testNamespace := "foo-ns"
 	involvedObj := corev1.ObjectReference{
@@ -807,7 +718,9 @@ func TestEventMatchesAlert(t *testing.T) {
 		name          string
 		event         *eventv1.Event
 		source        apiv1.CrossNamespaceObjectReference
-		severity      string
+		severities    []string
+		reasons       []string
+		account       string
 		resourcesFile string
 		wantResult    bool
 	}{
@@ -819,7 +732,6 @@ func TestEventMatchesAlert(t *testing.T) {
 				Name:      "*",
 				Namespace: "test-ns",
 			},
-			severity:   "info",
 			wantResult: false,
 		},
 		{
@@ -830,11 +742,10 @@ func TestEventMatchesAlert(t *testing.T) {
 				Name:      "*",
 				Namespace: testNamespace,
 			},
-			severity:   "info",
 			wantResult: false,
 		},
 		{
-			name: "event and alert severity mismatch, alert severity error",
+			name: "event severity not dispatched by the alert",
 			event: &eventv1.Event{
 				InvolvedObject: involvedObj,
 				Severity:       "info",
@@ -844,11 +755,11 @@ func TestEventMatchesAlert(t *testing.T) {
 				Name:      "*",
 				Namespace: testNamespace,
 			},
-			severity:   "error",
+			severities: []string{"error"},
 			wantResult: false,
 		},
 		{
-			name: "event and alert severity mismatch, alert severity info",
+			name: "alert dispatching all severities",
 			event: &eventv1.Event{
 				InvolvedObject: involvedObj,
 				Severity:       "error",
@@ -858,7 +769,34 @@ func TestEventMatchesAlert(t *testing.T) {
 				Name:      "*",
 				Namespace: testNamespace,
 			},
-			severity:   "info",
+			wantResult: true,
+		},
+		{
+			name: "event reason not dispatched by the alert",
+			event: &eventv1.Event{
+				InvolvedObject: involvedObj,
+				Reason:         "ReconciliationSucceeded",
+			},
+			source: apiv1.CrossNamespaceObjectReference{
+				Kind:      "Kustomization",
+				Name:      "*",
+				Namespace: testNamespace,
+			},
+			reasons:    []string{apiv1.DeletionReason},
+			wantResult: false,
+		},
+		{
+			name: "event reason dispatched by the alert",
+			event: &eventv1.Event{
+				InvolvedObject: involvedObj,
+				Reason:         apiv1.DeletionReason,
+			},
+			source: apiv1.CrossNamespaceObjectReference{
+				Kind:      "Kustomization",
+				Name:      "*",
+				Namespace: testNamespace,
+			},
+			reasons:    []string{apiv1.DeletionReason},
 			wantResult: true,
 		},
 		{
@@ -869,7 +807,6 @@ func TestEventMatchesAlert(t *testing.T) {
 				Name:      "*",
 				Namespace: testNamespace,
 			},
-			severity:   "info",
 			wantResult: true,
 		},
 		{
@@ -880,7 +817,6 @@ func TestEventMatchesAlert(t *testing.T) {
 				Name:      "bar",
 				Namespace: testNamespace,
 			},
-			severity:   "info",
 			wantResult: false,
 		},
 		{
@@ -891,7 +827,6 @@ func TestEventMatchesAlert(t *testing.T) {
 				Name:      "foo",
 				Namespace: testNamespace,
 			},
-			severity:   "info",
 			wantResult: true,
 		},
 		{
@@ -906,7 +841,6 @@ func TestEventMatchesAlert(t *testing.T) {
 					"app": "podinfo",
 				},
 			},
-			severity:   "info",
 			wantResult: true,
 		},
 		{
@@ -921,7 +855,6 @@ func TestEventMatchesAlert(t *testing.T) {
 					"aaa": "bbb",
 				},
 			},
-			severity:   "info",
 			wantResult: false,
 		},
 		{
@@ -935,7 +868,37 @@ func TestEventMatchesAlert(t *testing.T) {
 					"aaa": "bbb",
 				},
 			},
-			severity:   "info",
+			wantResult: false,
+		},
+		{
+			name:          "label selector, impersonation not configured",
+			resourcesFile: "./testdata/kustomization.yaml",
+			event:         &eventv1.Event{InvolvedObject: involvedObj},
+			source: apiv1.CrossNamespaceObjectReference{
+				Kind:      "Kustomization",
+				Name:      "*",
+				Namespace: testNamespace,
+				MatchLabels: map[string]string{
+					"app": "podinfo",
+				},
+			},
+			account:    "tenant",
+			wantResult: false,
+		},
+		{
+			name: "label selector, deleted object",
+			event: &eventv1.Event{
+				InvolvedObject: involvedObj,
+				Reason:         apiv1.DeletionReason,
+			},
+			source: apiv1.CrossNamespaceObjectReference{
+				Kind:      "Kustomization",
+				Name:      "*",
+				Namespace: testNamespace,
+				MatchLabels: map[string]string{
+					"app": "podinfo",
+				},
+			},
 			wantResult: false,
 		},
 	}
@@ -945,7 +908,7 @@ func TestEventMatchesAlert(t *testing.T) {
 			g := NewWithT(t)
 
 			scheme := runtime.NewScheme()
-			g.Expect(apiv1beta3.AddToScheme(scheme)).ToNot(HaveOccurred())
+			g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
 
 			builder := fakeclient.NewClientBuilder().WithScheme(scheme)
 
@@ -956,23 +919,31 @@ func TestEventMatchesAlert(t *testing.T) {
 				builder.WithObjects(obj)
 			}
 
+			recorder := record.NewFakeRecorder(32)
 			eventServer := EventServer{
 				kubeClient:    builder.Build(),
 				logger:        log.Log,
-				EventRecorder: record.NewFakeRecorder(32),
+				EventRecorder: recorder,
 			}
-			alert := &apiv1beta3.Alert{
+			alert := &apiv1.Alert{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "test-alert",
 					Namespace: "test-ns",
 				},
-				Spec: apiv1beta3.AlertSpec{
-					EventSeverity: tt.severity,
+				Spec: apiv1.AlertSpec{
+					EventSeverities:    tt.severities,
+					EventReasons:       tt.reasons,
+					ServiceAccountName: tt.account,
 				},
 			}
 
 			result := eventServer.eventMatchesAlertSource(context.TODO(), tt.event, alert, tt.source)
 			g.Expect(result).To(Equal(tt.wantResult))
+
+			// Deleted objects must not be reported as fetch failures.
+			if tt.event.Reason == apiv1.DeletionReason {
+				g.Expect(recorder.Events).To(BeEmpty())
+			}
 		})
 	}
 }
@@ -980,13 +951,13 @@ func TestEventMatchesAlert(t *testing.T) {
 func TestCombineEventMetadata(t *testing.T) {
 	for name, tt := range map[string]struct {
 		event            eventv1.Event
-		alert            apiv1beta3.Alert
+		alert            apiv1.Alert
 		expectedMetadata map[string]string
 		conflictEvent    string
 	}{
 		"empty metadata": {
 			event:            eventv1.Event{},
-			alert:            apiv1beta3.Alert{},
+			alert:            apiv1.Alert{},
 			expectedMetadata: nil,
 		},
 		"all metadata sources work": {
@@ -998,8 +969,8 @@ func TestCombineEventMetadata(t *testing.T) {
 					"event.toolkit.fluxcd.io/objectMetadata2":         "objectMetadataValue2",
 				},
 			},
-			alert: apiv1beta3.Alert{
-				Spec: apiv1beta3.AlertSpec{
+			alert: apiv1.Alert{
+				Spec: apiv1.AlertSpec{
 					Summary: "summaryValue",
 					EventMetadata: map[string]string{
 						"foo": "bar",
@@ -1023,8 +994,8 @@ func TestCombineEventMetadata(t *testing.T) {
 					"event.toolkit.fluxcd.io/summary": "objectSummary",
 				},
 			},
-			alert: apiv1beta3.Alert{
-				Spec: apiv1beta3.AlertSpec{
+			alert: apiv1.Alert{
+				Spec: apiv1.AlertSpec{
 					Summary: "alertSummary",
 				},
 			},
@@ -1035,8 +1006,8 @@ func TestCombineEventMetadata(t *testing.T) {
 		},
 		"alert event metadata is overriden by summary": {
 			event: eventv1.Event{},
-			alert: apiv1beta3.Alert{
-				Spec: apiv1beta3.AlertSpec{
+			alert: apiv1.Alert{
+				Spec: apiv1.AlertSpec{
 					Summary: "alertSummary",
 					EventMetadata: map[string]string{
 						"summary": "eventMetadataSummary",
@@ -1054,8 +1025,8 @@ func TestCombineEventMetadata(t *testing.T) {
 					"kustomize.toolkit.fluxcd.io/summary": "controllerSummary",
 				},
 			},
-			alert: apiv1beta3.Alert{
-				Spec: apiv1beta3.AlertSpec{
+			alert: apiv1.Alert{
+				Spec: apiv1.AlertSpec{
 					Summary: "alertSummary",
 				},
 			},
@@ -1075,8 +1046,8 @@ func TestCombineEventMetadata(t *testing.T) {
 					"event.toolkit.fluxcd.io/objectMetadataOverridenByController":     "objectMetadataValue3",
 				},
 			},
-			alert: apiv1beta3.Alert{
-				Spec: apiv1beta3.AlertSpec{
+			alert: apiv1.Alert{
+				Spec: apiv1.AlertSpec{
 					EventMetadata: map[string]string{
 						"objectMetadataOverridenByAlert":     "alertMetadataValue1",
 						"alertMetadata":                      "alertMetadataValue2",
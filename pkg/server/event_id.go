@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// EventIDHeader is the response header carrying the ID assigned by the
+// event server to the received event. The ID is logged with the processing
+// of the event and attached as an exemplar to the notification latency
+// metric, so that a missing notification can be traced end-to-end.
+const EventIDHeader = "X-Event-ID"
+
+// OpenMetricsEndpoint is the path of the metrics endpoint serving the
+// metrics in the OpenMetrics format, which includes the exemplars.
+const OpenMetricsEndpoint = "/metrics/openmetrics"
+
+type eventIDContextKey struct{}
+
+// newEventID returns a new random event ID.
+func newEventID() string {
+	return uuid.NewString()
+}
+
+// withEventID returns a copy of the context carrying the given event ID.
+func withEventID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, eventIDContextKey{}, id)
+}
+
+// eventIDFromContext returns the event ID carried by the given context,
+// or an empty string when the context doesn't carry one.
+func eventIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(eventIDContextKey{}).(string)
+	return id
+}
+
+// observeWithEventID records the given value with the event ID of the
+// context as exemplar, when the context carries one.
+func observeWithEventID(ctx context.Context, o prometheus.Observer, value float64) {
+	eo, ok := o.(prometheus.ExemplarObserver)
+	id := eventIDFromContext(ctx)
+	if !ok || id == "" {
+		o.Observe(value)
+		return
+	}
+	eo.ObserveWithExemplar(value, prometheus.Labels{"event_id": id})
+}
+
+// OpenMetricsHandler returns the handler serving the controller metrics in
+// the OpenMetrics format, for the scrapers collecting the exemplars.
+func OpenMetricsHandler() http.Handler {
+	return promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{
+		ErrorHandling:     promhttp.ContinueOnError,
+		EnableOpenMetrics: true,
+	})
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+func TestEventServer_eventID(t *testing.T) {
+	g := NewWithT(t)
+
+	var served string
+	s := &EventServer{logger: log.Log}
+	handler := s.eventMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = eventIDFromContext(r.Context())
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	b, err := json.Marshal(eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Name: "app", Namespace: "default"},
+		Severity:       eventv1.EventSeverityInfo,
+		Reason:         "ReconciliationSucceeded",
+		Message:        "applied",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(b)))
+	g.Expect(res.Code).To(Equal(http.StatusAccepted))
+	g.Expect(served).ToNot(BeEmpty())
+	g.Expect(res.Header().Get(EventIDHeader)).To(Equal(served))
+}
+
+func TestPostNotification_exemplar(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := withEventID(context.TODO(), "3f1c6a52-exemplar")
+	g.Expect(postNotification(ctx, logr.Discard(), successNotifier{}, eventv1.Event{}, "exemplar", "ok", "default")).To(Succeed())
+
+	req := httptest.NewRequest(http.MethodGet, OpenMetricsEndpoint, nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	res := httptest.NewRecorder()
+	OpenMetricsHandler().ServeHTTP(res, req)
+	g.Expect(res.Code).To(Equal(http.StatusOK))
+	g.Expect(res.Body.String()).To(ContainSubstring(`event_id="3f1c6a52-exemplar"`))
+}
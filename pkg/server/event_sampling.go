@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+var sampledDropped = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gotk_alerts_sampled_dropped_total",
+		Help: "The number of info events dropped by the sample rate of the matching Alert.",
+	},
+	[]string{"name", "namespace"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(sampledDropped)
+}
+
+// sampleRandom returns a pseudo-random number in [0.0,1.0), it's replaced
+// in tests to make the sampling deterministic.
+var sampleRandom = rand.Float64
+
+// eventIsSampled returns if the given event is dispatched by the alert
+// according to its sample rate. Only info events are sampled, the events
+// of other severities are always dispatched.
+func eventIsSampled(event *eventv1.Event, alert *apiv1.Alert) (bool, error) {
+	if alert.Spec.SampleRate == "" || event.Severity != eventv1.EventSeverityInfo {
+		return true, nil
+	}
+	rate, err := strconv.ParseFloat(alert.Spec.SampleRate, 64)
+	if err != nil {
+		return true, err
+	}
+	if sampleRandom() < rate {
+		return true, nil
+	}
+	sampledDropped.WithLabelValues(alert.Name, alert.Namespace).Inc()
+	return false, nil
+}
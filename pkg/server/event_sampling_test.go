@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestEventIsSampled(t *testing.T) {
+	g := NewWithT(t)
+
+	random := 0.5
+	sampleRandom = func() float64 { return random }
+	defer func() { sampleRandom = rand.Float64 }()
+
+	alert := &apiv1.Alert{
+		ObjectMeta: metav1.ObjectMeta{Name: "sampled", Namespace: "default"},
+		Spec:       apiv1.AlertSpec{SampleRate: "0.1"},
+	}
+	info := &eventv1.Event{Severity: eventv1.EventSeverityInfo}
+	errorEvent := &eventv1.Event{Severity: eventv1.EventSeverityError}
+
+	// Info events above the sample rate are dropped.
+	sampled, err := eventIsSampled(info, alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(sampled).To(BeFalse())
+	g.Expect(testutil.ToFloat64(sampledDropped.WithLabelValues("sampled", "default"))).To(Equal(float64(1)))
+
+	// Errors are always dispatched.
+	sampled, err = eventIsSampled(errorEvent, alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(sampled).To(BeTrue())
+
+	// Info events below the sample rate are dispatched.
+	random = 0.05
+	sampled, err = eventIsSampled(info, alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(sampled).To(BeTrue())
+
+	// All the events are dispatched without a sample rate.
+	random = 0.99
+	alert.Spec.SampleRate = ""
+	sampled, err = eventIsSampled(info, alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(sampled).To(BeTrue())
+
+	// Invalid sample rates dispatch the events.
+	alert.Spec.SampleRate = "ten"
+	sampled, err = eventIsSampled(info, alert)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(sampled).To(BeTrue())
+}
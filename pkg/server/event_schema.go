@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+
+	"github.com/fluxcd/notification-controller/internal/notifier"
+)
+
+const (
+	// EventSchemaEndpoint is the path of the event server endpoint serving
+	// the JSON Schema of the supported events.
+	EventSchemaEndpoint = "/api/v1/schema/event"
+
+	// EventVersionHeader is the header of the event requests holding the
+	// version of the event payload, e.g. event.toolkit.fluxcd.io/v1beta1.
+	EventVersionHeader = notifier.EventVersionHeader
+)
+
+// supportedEventVersions are the versions of the event payload accepted by
+// the event server.
+var supportedEventVersions = []string{
+	notifier.EventVersion,
+}
+
+//go:embed event_schema.json
+var eventSchema []byte
+
+// handleEventSchema serves the JSON Schema of the supported events.
+func (s *EventServer) handleEventSchema() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.Header().Set(EventVersionHeader, strings.Join(supportedEventVersions, ", "))
+		if _, err := w.Write(eventSchema); err != nil {
+			s.logger.Error(err, "unable to write the event schema")
+		}
+	})
+}
+
+// eventVersionMiddleware rejects the events with an unsupported version in
+// the EventVersionHeader with 415 Unsupported Media Type, so that the
+// emitters of a newer version can fall back to a version supported by this
+// server. The events without the header are accepted.
+func (s *EventServer) eventVersionMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := r.Header.Get(EventVersionHeader)
+		if version == "" || inList(supportedEventVersions, version) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		eventRejected.WithLabelValues(rejectReasonUnsupportedVersion).Inc()
+		s.logger.Info("rejecting event", "reason", rejectReasonUnsupportedVersion, "version", version)
+		w.Header().Set(EventVersionHeader, strings.Join(supportedEventVersions, ", "))
+		writeProblem(w, s.logger, http.StatusUnsupportedMediaType,
+			"unsupported event version '"+version+"', supported versions: "+strings.Join(supportedEventVersions, ", "))
+	})
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	log "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestEventSchema(t *testing.T) {
+	g := NewWithT(t)
+
+	s := &EventServer{logger: log.Log}
+	res := httptest.NewRecorder()
+	s.handleEventSchema().ServeHTTP(res, httptest.NewRequest(http.MethodGet, EventSchemaEndpoint, nil))
+	g.Expect(res.Code).To(Equal(http.StatusOK))
+	g.Expect(res.Header().Get("Content-Type")).To(Equal("application/schema+json"))
+	g.Expect(res.Header().Get(EventVersionHeader)).To(Equal("event.toolkit.fluxcd.io/v1beta1"))
+
+	var schema struct {
+		ID       string   `json:"$id"`
+		Required []string `json:"required"`
+	}
+	g.Expect(json.Unmarshal(res.Body.Bytes(), &schema)).To(Succeed())
+	g.Expect(schema.ID).To(Equal("event.toolkit.fluxcd.io/v1beta1"))
+	g.Expect(schema.Required).To(ContainElements("involvedObject", "severity", "timestamp", "message", "reason"))
+
+	res = httptest.NewRecorder()
+	s.handleEventSchema().ServeHTTP(res, httptest.NewRequest(http.MethodPost, EventSchemaEndpoint, nil))
+	g.Expect(res.Code).To(Equal(http.StatusMethodNotAllowed))
+}
+
+func TestEventVersionMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		wantStatus int
+	}{
+		{
+			name:       "no version",
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "supported version",
+			version:    "event.toolkit.fluxcd.io/v1beta1",
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "unsupported version",
+			version:    "event.toolkit.fluxcd.io/v1",
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s := &EventServer{logger: log.Log}
+			handler := s.eventVersionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			rejected := testutil.ToFloat64(eventRejected.WithLabelValues(rejectReasonUnsupportedVersion))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.version != "" {
+				req.Header.Set(EventVersionHeader, tt.version)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+			g.Expect(res.Code).To(Equal(tt.wantStatus))
+
+			if tt.wantStatus != http.StatusUnsupportedMediaType {
+				return
+			}
+			g.Expect(res.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+			g.Expect(res.Header().Get(EventVersionHeader)).To(Equal("event.toolkit.fluxcd.io/v1beta1"))
+			g.Expect(testutil.ToFloat64(eventRejected.WithLabelValues(rejectReasonUnsupportedVersion))).To(Equal(rejected + 1))
+
+			var problem problemDetails
+			g.Expect(json.Unmarshal(res.Body.Bytes(), &problem)).To(Succeed())
+			g.Expect(problem.Status).To(Equal(http.StatusUnsupportedMediaType))
+		})
+	}
+}
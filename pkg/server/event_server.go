@@ -0,0 +1,580 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sethvargo/go-limiter"
+	"github.com/sethvargo/go-limiter/httplimit"
+	"github.com/slok/go-http-metrics/middleware"
+	"github.com/slok/go-http-metrics/middleware/std"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"k8s.io/client-go/rest"
+	kuberecorder "k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts/token,verbs=create
+// +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases,verbs=get;list;watch
+// +kubebuilder:rbac:groups=notification.toolkit.fluxcd.io,resources=alerts,verbs=get;list
+// +kubebuilder:rbac:groups=notification.toolkit.fluxcd.io,resources=providers,verbs=get
+
+type eventContextKey struct{}
+
+// EventServer handles event POST requests
+type EventServer struct {
+	port                  string
+	logger                logr.Logger
+	kubeClient            client.Client
+	secretsClient         client.Reader
+	helmReleaseReader     client.Reader
+	noCrossNamespaceRefs  bool
+	exportHTTPPathMetrics bool
+	enableH2C             bool
+	providerTimeouts      ProviderTimeoutOptions
+	cache                 *lastKnownCache
+	history               *notificationHistory
+	involvedObjectEvents  bool
+	execProvider          bool
+	execSocketDir         string
+	noProxy               []string
+	relayBufferDir        string
+	quota                 *dispatchQuota
+	ingestQuota           *ingestQuota
+	restConfig            *rest.Config
+	impersonatedClients   sync.Map
+	idempotencyKeys       *idempotencyKeys
+	alertRateLimiter      *alertRateLimiter
+	coalesceAlerts        bool
+	listenOptions         ListenOptions
+	listening             atomic.Bool
+	kuberecorder.EventRecorder
+}
+
+// EventServerOptions configures an EventServer. The zero value of the
+// options disables the optional features.
+type EventServerOptions struct {
+	// NoCrossNamespaceRefs denies the Alerts referencing the objects of other
+	// namespaces.
+	NoCrossNamespaceRefs bool
+
+	// ExportHTTPPathMetrics labels the HTTP metrics with the request paths.
+	ExportHTTPPathMetrics bool
+
+	// EnableH2C accepts HTTP/2 cleartext requests.
+	EnableH2C bool
+
+	// ProviderTimeouts bounds the timeouts of the Providers.
+	ProviderTimeouts ProviderTimeoutOptions
+
+	// CacheMaxAge is the maximum age of the last-known Alerts and Providers
+	// used to dispatch the events when the Kubernetes API is unavailable.
+	CacheMaxAge time.Duration
+
+	// HistorySize is the number of recently dispatched notifications served
+	// at NotificationsEndpoint.
+	HistorySize int
+
+	// InvolvedObjectEvents records a Kubernetes Event on the involved object
+	// of each dispatched notification.
+	InvolvedObjectEvents bool
+
+	// ExecProvider allows the exec Providers.
+	ExecProvider bool
+
+	// ExecSocketDir is the directory the sockets of the exec Providers
+	// must be located in.
+	ExecSocketDir string
+
+	// NoProxy lists the hosts, domains and CIDRs for which the Provider
+	// proxies are bypassed.
+	NoProxy []string
+
+	// RelayBufferDir is the directory where the undelivered events of the
+	// fluxrelay Providers are buffered.
+	RelayBufferDir string
+
+	// NamespaceQuota is the maximum number of notifications dispatched per
+	// minute for the Alerts of a namespace.
+	NamespaceQuota int
+
+	// IngestQuotas maps the reporting controllers to the maximum number of
+	// events accepted per minute from them.
+	IngestQuotas map[string]int
+
+	// CoalesceAlerts dispatches an event only once per Provider when several
+	// Alerts referencing the same Provider match it.
+	CoalesceAlerts bool
+
+	// SecretsCacheTTL is the duration for which the Secrets and ConfigMaps
+	// of the Providers are cached.
+	SecretsCacheTTL time.Duration
+
+	// HelmReleaseReader reads the HelmReleases whose status history enriches
+	// their events, e.g. the manager cache, so that the events don't cause
+	// requests to the Kubernetes API. Defaults to the Kubernetes client.
+	HelmReleaseReader client.Reader
+
+	// RestConfig is used to impersonate the ServiceAccounts of the Alerts.
+	RestConfig *rest.Config
+
+	// ListenOptions selects the IP family the server is bound to.
+	ListenOptions ListenOptions
+}
+
+// NewEventServer returns an HTTP server that handles events on the given
+// port, configured with the given options.
+func NewEventServer(port string, logger logr.Logger, kubeClient client.Client, eventRecorder kuberecorder.EventRecorder, opts EventServerOptions) *EventServer {
+	helmReleaseReader := opts.HelmReleaseReader
+	if helmReleaseReader == nil {
+		helmReleaseReader = kubeClient
+	}
+	return &EventServer{
+		port:                  port,
+		logger:                logger.WithName("event-server"),
+		kubeClient:            kubeClient,
+		secretsClient:         newSecretCache(kubeClient, opts.SecretsCacheTTL),
+		helmReleaseReader:     helmReleaseReader,
+		EventRecorder:         eventRecorder,
+		noCrossNamespaceRefs:  opts.NoCrossNamespaceRefs,
+		exportHTTPPathMetrics: opts.ExportHTTPPathMetrics,
+		enableH2C:             opts.EnableH2C,
+		providerTimeouts:      opts.ProviderTimeouts,
+		cache:                 newLastKnownCache(opts.CacheMaxAge),
+		history:               newNotificationHistory(opts.HistorySize),
+		involvedObjectEvents:  opts.InvolvedObjectEvents,
+		execProvider:          opts.ExecProvider,
+		execSocketDir:         opts.ExecSocketDir,
+		noProxy:               opts.NoProxy,
+		relayBufferDir:        opts.RelayBufferDir,
+		quota:                 newDispatchQuota(opts.NamespaceQuota),
+		ingestQuota:           newIngestQuota(opts.IngestQuotas),
+		restConfig:            opts.RestConfig,
+		idempotencyKeys:       newIdempotencyKeys(idempotencyKeyTTL, idempotencyMaxKeys),
+		alertRateLimiter:      newAlertRateLimiter(),
+		coalesceAlerts:        opts.CoalesceAlerts,
+		listenOptions:         opts.ListenOptions,
+	}
+}
+
+// ListenAndServe starts the HTTP server on the specified port
+func (s *EventServer) ListenAndServe(stopCh <-chan struct{}, mdlw middleware.Middleware, store limiter.Store) {
+	limitMiddleware, err := httplimit.NewMiddleware(store, eventKeyFunc)
+	if err != nil {
+		s.logger.Error(err, "Event server crashed")
+		os.Exit(1)
+	}
+	var handler http.Handler = http.HandlerFunc(s.handleEvent())
+	for _, middleware := range []func(http.Handler) http.Handler{
+		rateLimitMiddleware(limitMiddleware.Handle),
+		s.eventMiddleware,
+		func(h http.Handler) http.Handler { return gzipMiddleware(s.logger, h) },
+		s.idempotencyMiddleware,
+		s.eventVersionMiddleware,
+	} {
+		handler = middleware(handler)
+	}
+	mux := http.NewServeMux()
+	path := "/"
+	mux.Handle(path, handler)
+	mux.Handle(EventSchemaEndpoint, s.handleEventSchema())
+	if s.history != nil {
+		mux.Handle(NotificationsEndpoint, s.handleNotifications())
+	}
+	handlerID := path
+	if s.exportHTTPPathMetrics {
+		handlerID = ""
+	}
+	h := std.Handler(handlerID, mdlw, mux)
+	if s.enableH2C {
+		// Accept HTTP/2 without TLS (prior knowledge or upgrade) so that
+		// chatty clients can multiplex events over a single connection.
+		h = h2c.NewHandler(h, &http2.Server{})
+	}
+	srv := &http.Server{
+		Handler: h,
+	}
+
+	ln, err := s.listenOptions.Listen(s.port)
+	if err != nil {
+		s.logger.Error(err, "Event server crashed")
+		os.Exit(1)
+	}
+	s.logger.Info("Event server started", "url", listenURL(ln))
+	s.listening.Store(true)
+
+	go func() {
+		if err := srv.Serve(ln); err != http.ErrServerClosed {
+			s.logger.Error(err, "Event server crashed")
+			os.Exit(1)
+		}
+	}()
+
+	// wait for SIGTERM or SIGINT
+	<-stopCh
+	s.listening.Store(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		s.logger.Error(err, "Event server graceful shutdown failed")
+	} else {
+		s.logger.Info("Event server stopped")
+	}
+}
+
+// eventMiddleware rejects the events with missing or invalid fields, cleans
+// up the event metadata using cleanupMetadata() and adds the cleaned event in
+// the request context which can then be queried and used directly by the
+// other http handlers. This middleware also adds a logger with the event's
+// involved object's reference information to the request context. When the
+// request body is a JSON array of events, the events are passed in order to
+// the other http handlers, see serveEventList.
+func (s *EventServer) eventMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.logger.Error(err, "reading the request body failed")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := r.Body.Close(); err != nil {
+			s.logger.Error(err, "closing the request body failed")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+			var events []eventv1.Event
+			if err := json.Unmarshal(body, &events); err != nil {
+				s.logger.Error(err, "decoding the request body failed")
+				rejectEvent(w, s.logger, "decoding the request body failed", nil)
+				return
+			}
+			s.serveEventList(w, r, events, h)
+			return
+		}
+
+		event := &eventv1.Event{}
+		err = json.Unmarshal(body, event)
+		if err != nil {
+			s.logger.Error(err, "decoding the request body failed")
+			rejectEvent(w, s.logger, "decoding the request body failed", nil)
+			return
+		}
+
+		s.serveEvent(w, r, event, h)
+	})
+}
+
+// serveEvent validates the given event and serves it with the given handler.
+func (s *EventServer) serveEvent(w http.ResponseWriter, r *http.Request, event *eventv1.Event, h http.Handler) {
+	eventID := newEventID()
+	w.Header().Set(EventIDHeader, eventID)
+
+	if violations := validateEvent(event); len(violations) > 0 {
+		rejectEvent(w, s.logger.WithValues("eventInvolvedObject", event.InvolvedObject, "eventID", eventID),
+			"missing or invalid event fields", violations)
+		return
+	}
+
+	cleanupMetadata(event)
+
+	eventLogger := s.logger.WithValues("eventInvolvedObject", event.InvolvedObject, "eventID", eventID)
+
+	if !s.ingestQuota.allow(event.ReportingController) {
+		eventLogger.V(1).Info("discarding event, the ingestion quota of the reporting controller is exceeded",
+			"reportingController", event.ReportingController)
+		writeProblem(w, eventLogger, http.StatusTooManyRequests,
+			fmt.Sprintf("the ingestion quota of the reporting controller '%s' is exceeded", event.ReportingController))
+		return
+	}
+
+	enhancedCtx := context.WithValue(r.Context(), eventContextKey{}, event)
+	enhancedCtx = withEventID(enhancedCtx, eventID)
+	enhancedCtx = log.IntoContext(enhancedCtx, eventLogger)
+	enhancedReq := r.WithContext(enhancedCtx)
+
+	h.ServeHTTP(w, enhancedReq)
+}
+
+// eventListResult is the outcome of an event of a list of events.
+type eventListResult struct {
+	Status  int             `json:"status"`
+	EventID string          `json:"eventID,omitempty"`
+	Problem *problemDetails `json:"problem,omitempty"`
+}
+
+// serveEventList serves the given events in order, each of them going
+// through the validation, the rate limiting and the alerts matching as if
+// it were sent in its own request. The response status is 202 when all the
+// events are accepted and 207 otherwise, with the outcome of each event in
+// the response body.
+func (s *EventServer) serveEventList(w http.ResponseWriter, r *http.Request, events []eventv1.Event, h http.Handler) {
+	status := http.StatusAccepted
+	results := make([]eventListResult, 0, len(events))
+	for i := range events {
+		rec := httptest.NewRecorder()
+		s.serveEvent(rec, r, &events[i], h)
+
+		result := eventListResult{Status: rec.Code, EventID: rec.Header().Get(EventIDHeader)}
+		if rec.Header().Get("Content-Type") == "application/problem+json" {
+			result.Problem = &problemDetails{}
+			if err := json.Unmarshal(rec.Body.Bytes(), result.Problem); err != nil {
+				result.Problem = nil
+			}
+		}
+		if result.Status != http.StatusAccepted {
+			status = http.StatusMultiStatus
+		}
+		results = append(results, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		s.logger.Error(err, "writing the event list results failed")
+	}
+}
+
+// cleanupMetadata removes metadata entries which are not used for alerting.
+// In particular, it removes the checksum and digest metadata entries and
+// keeps only the metadata entries that are prefixed with either the event
+// group prefix or the involved object's group prefix.
+func cleanupMetadata(event *eventv1.Event) {
+	const eventGroupPrefix = eventv1.Group + "/"
+	objectGroupPrefix := event.InvolvedObject.GetObjectKind().GroupVersionKind().Group + "/"
+	excludeList := []string{
+		fmt.Sprintf("%s%s", objectGroupPrefix, eventv1.MetaChecksumKey),
+		fmt.Sprintf("%s%s", objectGroupPrefix, eventv1.MetaDigestKey),
+	}
+
+	// Filter other meta based on group prefix, while filtering out excludes
+	meta := make(map[string]string)
+	for key, val := range event.Metadata {
+		if !inList(excludeList, key) &&
+			(strings.HasPrefix(key, eventGroupPrefix) || strings.HasPrefix(key, objectGroupPrefix)) {
+			meta[key] = val
+		}
+	}
+
+	event.Metadata = meta
+}
+
+func inList(l []string, i string) bool {
+	for _, v := range l {
+		if strings.EqualFold(v, i) {
+			return true
+		}
+	}
+	return false
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	Status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.Status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// rateLimitedEvents counts the events discarded by the rate limiter.
+var rateLimitedEvents = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gotk_event_rate_limited_total",
+		Help: "The number of events discarded by the rate limiting of duplicate events, by involved object.",
+	},
+	[]string{"kind", "namespace", "name"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(rateLimitedEvents)
+}
+
+// rateLimitRecorder holds back the response written by the rate limiter
+// when the event is rate limited, so that it can be replaced with one
+// naming the involved object.
+type rateLimitRecorder struct {
+	http.ResponseWriter
+	passed  bool
+	limited bool
+}
+
+func (r *rateLimitRecorder) WriteHeader(status int) {
+	if status == http.StatusTooManyRequests {
+		r.limited = true
+		return
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *rateLimitRecorder) Write(b []byte) (int, error) {
+	if r.limited {
+		return len(b), nil
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// rateLimitOverrideWriter writes the response of an event rate limited with
+// the global interval: the response of the handler if the event was
+// dispatched to the Alerts overriding the rate limit interval, or the
+// rejection of the event otherwise.
+type rateLimitOverrideWriter struct {
+	http.ResponseWriter
+	rateLimit   *eventRateLimit
+	reject      func()
+	wroteHeader bool
+	rejected    bool
+}
+
+func (w *rateLimitOverrideWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if !w.rateLimit.overridden {
+		w.rejected = true
+		w.reject()
+		return
+	}
+	w.Header().Del("Retry-After")
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *rateLimitOverrideWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// rateLimitMiddleware wraps the given rate limiting middleware to log and
+// count the rate limited events, and to name their involved object in the
+// 429 responses. The rate limited events are passed once to the handler,
+// which only dispatches them to the Alerts overriding the rate limit
+// interval, and are rejected if there are none. The responses of the
+// handlers behind the rate limiter are passed through untouched.
+func rateLimitMiddleware(limit func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		limited := limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if recorder, ok := w.(*rateLimitRecorder); ok {
+				recorder.passed = true
+				w = recorder.ResponseWriter
+			}
+			h.ServeHTTP(w, r)
+		}))
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := &rateLimitRecorder{ResponseWriter: w}
+			limited.ServeHTTP(recorder, r)
+			if recorder.passed || !recorder.limited {
+				return
+			}
+
+			// The rate limited event is still dispatched to the Alerts
+			// overriding the rate limit interval, if any.
+			rateLimit := &eventRateLimit{}
+			writer := &rateLimitOverrideWriter{
+				ResponseWriter: w,
+				rateLimit:      rateLimit,
+				reject: func() {
+					event := r.Context().Value(eventContextKey{}).(*eventv1.Event)
+					obj := event.InvolvedObject
+					rateLimitedEvents.WithLabelValues(obj.Kind, obj.Namespace, obj.Name).Inc()
+					log.FromContext(r.Context()).V(1).
+						Info("Discarding event, rate limiting duplicate events")
+
+					http.Error(w, fmt.Sprintf("%s: rate limiting duplicate events of %s",
+						http.StatusText(http.StatusTooManyRequests), involvedObjectString(obj)), http.StatusTooManyRequests)
+				},
+			}
+			h.ServeHTTP(writer, r.WithContext(context.WithValue(r.Context(), eventRateLimitContextKey{}, rateLimit)))
+			if !writer.wroteHeader {
+				writer.WriteHeader(http.StatusOK)
+			}
+		})
+	}
+}
+
+// eventKeyFunc generates a unique key for an event based on the provided HTTP
+// request, which can be used to deduplicate events. The key is calculated by
+// concatenating specific event attributes and hashing them using SHA-256.
+// The key is then returned as a hex-encoded string.
+//
+// The event attributes are prefixed with an identifier to avoid collisions
+// between different event attributes.
+func eventKeyFunc(r *http.Request) (string, error) {
+	event := r.Context().Value(eventContextKey{}).(*eventv1.Event)
+	return eventKey(event), nil
+}
+
+// eventKey returns the rate limiting key of the given event, see eventKeyFunc.
+func eventKey(event *eventv1.Event) string {
+	comps := []string{
+		"event",
+		"name=" + event.InvolvedObject.Name,
+		"namespace=" + event.InvolvedObject.Namespace,
+		"kind=" + event.InvolvedObject.Kind,
+		"message=" + event.Message,
+	}
+
+	objectGroup := event.InvolvedObject.GetObjectKind().GroupVersionKind().Group
+
+	revisionKey := fmt.Sprintf("%s/%s", objectGroup, eventv1.MetaRevisionKey)
+	revision, ok := event.Metadata[revisionKey]
+	if ok {
+		comps = append(comps, "revision="+revision)
+	}
+
+	tokenKey := fmt.Sprintf("%s/%s", objectGroup, eventv1.MetaTokenKey)
+	token, ok := event.Metadata[tokenKey]
+	if ok {
+		comps = append(comps, "token="+token)
+	}
+
+	key := strings.Join(comps, "/")
+	digest := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("%x", digest)
+}
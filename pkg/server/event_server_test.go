@@ -19,6 +19,7 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -31,10 +32,12 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/sethvargo/go-limiter/httplimit"
 	"github.com/sethvargo/go-limiter/memorystore"
 	prommetrics "github.com/slok/go-http-metrics/metrics/prometheus"
 	"github.com/slok/go-http-metrics/middleware"
+	"golang.org/x/net/http2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -48,7 +51,6 @@ import (
 	"github.com/fluxcd/pkg/apis/meta"
 
 	apiv1 "github.com/fluxcd/notification-controller/api/v1"
-	apiv1beta3 "github.com/fluxcd/notification-controller/api/v1beta3"
 )
 
 func TestEventServer(t *testing.T) {
@@ -64,20 +66,19 @@ func TestEventServer(t *testing.T) {
 	}))
 	defer rcvServer.Close()
 
-	provider := &apiv1beta3.Provider{}
+	provider := &apiv1.Provider{}
 	provider.Name = "provider-foo"
 	provider.Namespace = testNamespace
-	provider.Spec = apiv1beta3.ProviderSpec{
+	provider.Spec = apiv1.ProviderSpec{
 		Type:    "generic",
 		Address: rcvServer.URL,
 	}
 
-	testAlert := &apiv1beta3.Alert{}
+	testAlert := &apiv1.Alert{}
 	testAlert.Name = "alert-foo"
 	testAlert.Namespace = testNamespace
-	testAlert.Spec = apiv1beta3.AlertSpec{
-		ProviderRef:   meta.LocalObjectReference{Name: provider.Name},
-		EventSeverity: "info",
+	testAlert.Spec = apiv1.AlertSpec{
+		ProviderRefs: []meta.LocalObjectReference{{Name: provider.Name}},
 		EventSources: []apiv1.CrossNamespaceObjectReference{
 			{
 				Kind:      "Bucket",
@@ -110,7 +111,7 @@ func TestEventServer(t *testing.T) {
 	g.Expect(err).ToNot(HaveOccurred())
 
 	scheme := runtime.NewScheme()
-	g.Expect(apiv1beta3.AddToScheme(scheme)).ToNot(HaveOccurred())
+	g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
 	g.Expect(corev1.AddToScheme(scheme)).ToNot(HaveOccurred())
 
 	// Create a fake kube client with the above objects.
@@ -136,11 +137,11 @@ func TestEventServer(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create memory storage")
 	}
-	eventServer := NewEventServer("127.0.0.1:"+eventServerPort,
-		log.Log, kclient, record.NewFakeRecorder(32), true, true)
+	eventServer := NewEventServer("localhost:"+eventServerPort, log.Log, kclient, record.NewFakeRecorder(32), EventServerOptions{NoCrossNamespaceRefs: true, ExportHTTPPathMetrics: true})
 	stopCh := make(chan struct{})
 	go eventServer.ListenAndServe(stopCh, eventMdlw, store)
 	defer close(stopCh)
+	waitForServer(g, "localhost:"+eventServerPort)
 
 	// Create a base event which is copied and mutated in the test cases.
 	testEvent := eventv1.Event{
@@ -330,6 +331,81 @@ func TestEventServer(t *testing.T) {
 	}
 }
 
+func TestEventServer_H2C(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).ToNot(HaveOccurred())
+	kclient := fakeclient.NewClientBuilder().WithScheme(scheme).Build()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	g.Expect(err).ToNot(HaveOccurred())
+	addr := l.Addr().String()
+	g.Expect(l.Close()).ToNot(HaveOccurred())
+
+	eventMdlw := middleware.New(middleware.Config{
+		Recorder: prommetrics.NewRecorder(prommetrics.Config{
+			Prefix: "gotk_event_h2c",
+		}),
+	})
+	store, err := memorystore.New(&memorystore.Config{
+		Interval: 5 * time.Minute,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	eventServer := NewEventServer(addr, log.Log, kclient, record.NewFakeRecorder(32), EventServerOptions{EnableH2C: true})
+	stopCh := make(chan struct{})
+	go eventServer.ListenAndServe(stopCh, eventMdlw, store)
+	defer close(stopCh)
+	waitForServer(g, addr)
+
+	// Use HTTP/2 with prior knowledge over a plain TCP connection.
+	h2cClient := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	event := eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Bucket",
+			Name:      "hyacinth",
+			Namespace: "default",
+		},
+		Severity:            eventv1.EventSeverityInfo,
+		Timestamp:           metav1.Now(),
+		Message:             "well that happened",
+		Reason:              "event-happened",
+		ReportingController: "source-controller",
+	}
+	for i := 0; i < 3; i++ {
+		event.Message = fmt.Sprintf("event %d", i)
+		buf := &bytes.Buffer{}
+		g.Expect(json.NewEncoder(buf).Encode(event)).To(Succeed())
+		res, err := h2cClient.Post("http://"+addr, "application/json", buf)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res.ProtoMajor).To(Equal(2))
+		g.Expect(res.StatusCode).To(Equal(http.StatusAccepted))
+		g.Expect(res.Body.Close()).To(Succeed())
+	}
+}
+
+// waitForServer blocks until the server listening on addr accepts
+// connections.
+func waitForServer(g *WithT, addr string) {
+	g.Eventually(func() error {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}, "5s", "0.1s").Should(Succeed())
+}
+
 func TestEventKeyFunc(t *testing.T) {
 	g := NewWithT(t)
 
@@ -531,6 +607,59 @@ func TestEventKeyFunc(t *testing.T) {
 	}
 }
 
+func TestRateLimitMiddleware(t *testing.T) {
+	g := NewWithT(t)
+
+	store, err := memorystore.New(&memorystore.Config{
+		Tokens:   1,
+		Interval: 10 * time.Minute,
+	})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	limitMiddleware, err := httplimit.NewMiddleware(store, eventKeyFunc)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	handler := rateLimitMiddleware(limitMiddleware.Handle)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := r.Context().Value(eventContextKey{}).(*eventv1.Event)
+		if event.Reason == "QuotaExceeded" {
+			http.Error(w, "namespace quota exceeded", http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	serve := func(name, reason string) *httptest.ResponseRecorder {
+		event := &eventv1.Event{
+			InvolvedObject: corev1.ObjectReference{
+				Kind:      "Kustomization",
+				Name:      name,
+				Namespace: "rate-limit",
+			},
+			Severity: eventv1.EventSeverityInfo,
+			Reason:   reason,
+			Message:  "Health check passed",
+		}
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/", nil)
+		handler.ServeHTTP(res, req.WithContext(context.WithValue(req.Context(), eventContextKey{}, event)))
+		return res
+	}
+	counter := rateLimitedEvents.WithLabelValues("Kustomization", "rate-limit", "apps")
+
+	res := serve("apps", "Progressing")
+	g.Expect(res.Code).To(Equal(http.StatusAccepted))
+	g.Expect(testutil.ToFloat64(counter)).To(Equal(float64(0)))
+
+	res = serve("apps", "Progressing")
+	g.Expect(res.Code).To(Equal(http.StatusTooManyRequests))
+	g.Expect(res.Header().Get("Retry-After")).ToNot(BeEmpty())
+	g.Expect(res.Body.String()).To(Equal("Too Many Requests: rate limiting duplicate events of Kustomization/rate-limit/apps\n"))
+	g.Expect(testutil.ToFloat64(counter)).To(Equal(float64(1)))
+
+	res = serve("infra", "QuotaExceeded")
+	g.Expect(res.Code).To(Equal(http.StatusTooManyRequests))
+	g.Expect(res.Body.String()).To(Equal("namespace quota exceeded\n"))
+	g.Expect(testutil.ToFloat64(rateLimitedEvents.WithLabelValues("Kustomization", "rate-limit", "infra"))).To(Equal(float64(0)))
+}
+
 func TestCleanupMetadata(t *testing.T) {
 	group := "kustomize.toolkit.fluxcd.io"
 	involvedObj := corev1.ObjectReference{
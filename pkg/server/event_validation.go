@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+// Reasons for rejecting an event, used as label values of the
+// gotk_event_rejected_total metric.
+const (
+	rejectReasonInvalidBody        = "invalid_body"
+	rejectReasonMissingKind        = "missing_involved_object_kind"
+	rejectReasonMissingName        = "missing_involved_object_name"
+	rejectReasonMissingNamespace   = "missing_involved_object_namespace"
+	rejectReasonInvalidSeverity    = "invalid_severity"
+	rejectReasonMissingTimestamp   = "missing_timestamp"
+	rejectReasonUnsupportedVersion = "unsupported_version"
+)
+
+var eventRejected = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gotk_event_rejected_total",
+		Help: "The number of events rejected by the event server, per reason.",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(eventRejected)
+}
+
+// invalidParam describes an invalid field of the event.
+type invalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// problemDetails is the RFC 9457 response body of rejected events.
+type problemDetails struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail"`
+	InvalidParams []invalidParam `json:"invalid-params,omitempty"`
+}
+
+// eventViolation is a field of the event failing validation.
+type eventViolation struct {
+	param  invalidParam
+	reason string
+}
+
+// validateEvent returns the fields of the event that are missing or invalid.
+func validateEvent(event *eventv1.Event) []eventViolation {
+	var violations []eventViolation
+	if event.InvolvedObject.Kind == "" {
+		violations = append(violations, eventViolation{
+			param:  invalidParam{Name: "involvedObject.kind", Reason: "must not be empty"},
+			reason: rejectReasonMissingKind,
+		})
+	}
+	if event.InvolvedObject.Name == "" {
+		violations = append(violations, eventViolation{
+			param:  invalidParam{Name: "involvedObject.name", Reason: "must not be empty"},
+			reason: rejectReasonMissingName,
+		})
+	}
+	if event.InvolvedObject.Namespace == "" {
+		violations = append(violations, eventViolation{
+			param:  invalidParam{Name: "involvedObject.namespace", Reason: "must not be empty"},
+			reason: rejectReasonMissingNamespace,
+		})
+	}
+	switch event.Severity {
+	case eventv1.EventSeverityInfo, eventv1.EventSeverityError, eventv1.EventSeverityTrace:
+	default:
+		violations = append(violations, eventViolation{
+			param: invalidParam{Name: "severity", Reason: fmt.Sprintf("must be one of %s, %s, %s",
+				eventv1.EventSeverityInfo, eventv1.EventSeverityError, eventv1.EventSeverityTrace)},
+			reason: rejectReasonInvalidSeverity,
+		})
+	}
+	if event.Timestamp.IsZero() {
+		violations = append(violations, eventViolation{
+			param:  invalidParam{Name: "timestamp", Reason: "must not be empty"},
+			reason: rejectReasonMissingTimestamp,
+		})
+	}
+	return violations
+}
+
+// rejectEvent records the rejection of an event with the reason of the first
+// violation, and writes a problem details response with all the violations.
+func rejectEvent(w http.ResponseWriter, logger logr.Logger, detail string, violations []eventViolation) {
+	reason := rejectReasonInvalidBody
+	params := make([]invalidParam, 0, len(violations))
+	names := make([]string, 0, len(violations))
+	for _, v := range violations {
+		params = append(params, v.param)
+		names = append(names, v.param.Name)
+	}
+	if len(violations) > 0 {
+		reason = violations[0].reason
+		detail = fmt.Sprintf("%s: %s", detail, strings.Join(names, ", "))
+	}
+	eventRejected.WithLabelValues(reason).Inc()
+	logger.Info("rejecting event", "reason", reason, "detail", detail)
+
+	writeProblem(w, logger, http.StatusBadRequest, detail, params...)
+}
+
+// writeProblem writes a problem details response with the given status.
+func writeProblem(w http.ResponseWriter, logger logr.Logger, status int, detail string, params ...invalidParam) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(problemDetails{
+		Type:          "about:blank",
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        detail,
+		InvalidParams: params,
+	}); err != nil {
+		logger.Error(err, "writing the problem details failed")
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	log "sigs.k8s.io/controller-runtime/pkg/log"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+func TestEventMiddleware_validation(t *testing.T) {
+	validEvent := func() *eventv1.Event {
+		return &eventv1.Event{
+			InvolvedObject: corev1.ObjectReference{
+				APIVersion: "kustomize.toolkit.fluxcd.io/v1",
+				Kind:       "Kustomization",
+				Name:       "foo",
+				Namespace:  "foo-ns",
+			},
+			Severity:            eventv1.EventSeverityInfo,
+			Timestamp:           metav1.Now(),
+			Message:             "Reconciliation finished",
+			Reason:              "ReconciliationSucceeded",
+			ReportingController: "kustomize-controller",
+		}
+	}
+
+	tests := []struct {
+		name       string
+		body       func() []byte
+		wantReason string
+		wantParams []string
+	}{
+		{
+			name: "valid event",
+			body: func() []byte {
+				b, _ := json.Marshal(validEvent())
+				return b
+			},
+		},
+		{
+			name:       "invalid body",
+			body:       func() []byte { return []byte("{") },
+			wantReason: rejectReasonInvalidBody,
+		},
+		{
+			name: "missing involved object",
+			body: func() []byte {
+				e := validEvent()
+				e.InvolvedObject = corev1.ObjectReference{}
+				b, _ := json.Marshal(e)
+				return b
+			},
+			wantReason: rejectReasonMissingKind,
+			wantParams: []string{"involvedObject.kind", "involvedObject.name", "involvedObject.namespace"},
+		},
+		{
+			name: "invalid severity",
+			body: func() []byte {
+				e := validEvent()
+				e.Severity = "warning"
+				b, _ := json.Marshal(e)
+				return b
+			},
+			wantReason: rejectReasonInvalidSeverity,
+			wantParams: []string{"severity"},
+		},
+		{
+			name: "missing timestamp",
+			body: func() []byte {
+				e := validEvent()
+				e.Timestamp = metav1.Time{}
+				b, _ := json.Marshal(e)
+				return b
+			},
+			wantReason: rejectReasonMissingTimestamp,
+			wantParams: []string{"timestamp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s := &EventServer{logger: log.Log}
+			handler := s.eventMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			}))
+
+			var rejected float64
+			if tt.wantReason != "" {
+				rejected = testutil.ToFloat64(eventRejected.WithLabelValues(tt.wantReason))
+			}
+
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(tt.body())))
+
+			if tt.wantReason == "" {
+				g.Expect(res.Code).To(Equal(http.StatusAccepted))
+				return
+			}
+
+			g.Expect(res.Code).To(Equal(http.StatusBadRequest))
+			g.Expect(res.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+			g.Expect(testutil.ToFloat64(eventRejected.WithLabelValues(tt.wantReason))).To(Equal(rejected + 1))
+
+			var problem problemDetails
+			g.Expect(json.Unmarshal(res.Body.Bytes(), &problem)).To(Succeed())
+			g.Expect(problem.Status).To(Equal(http.StatusBadRequest))
+			var params []string
+			for _, p := range problem.InvalidParams {
+				params = append(params, p.Name)
+			}
+			g.Expect(params).To(Equal(tt.wantParams))
+		})
+	}
+}
+
+func TestEventMiddleware_eventList(t *testing.T) {
+	g := NewWithT(t)
+
+	event := func(name string) eventv1.Event {
+		return eventv1.Event{
+			InvolvedObject: corev1.ObjectReference{
+				APIVersion: "kustomize.toolkit.fluxcd.io/v1",
+				Kind:       "Kustomization",
+				Name:       name,
+				Namespace:  "foo-ns",
+			},
+			Severity:            eventv1.EventSeverityInfo,
+			Timestamp:           metav1.Now(),
+			Message:             "Reconciliation finished",
+			Reason:              "ReconciliationSucceeded",
+			ReportingController: "kustomize-controller",
+		}
+	}
+
+	var served []string
+	s := &EventServer{logger: log.Log}
+	handler := s.eventMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := r.Context().Value(eventContextKey{}).(*eventv1.Event)
+		served = append(served, e.InvolvedObject.Name)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	b, err := json.Marshal([]eventv1.Event{event("foo"), event("bar")})
+	g.Expect(err).ToNot(HaveOccurred())
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(b)))
+	g.Expect(res.Code).To(Equal(http.StatusAccepted))
+	g.Expect(served).To(Equal([]string{"foo", "bar"}))
+
+	invalid := event("baz")
+	invalid.Severity = "warning"
+	b, err = json.Marshal([]eventv1.Event{invalid, event("qux")})
+	g.Expect(err).ToNot(HaveOccurred())
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(b)))
+	g.Expect(res.Code).To(Equal(http.StatusMultiStatus))
+	g.Expect(served).To(Equal([]string{"foo", "bar", "qux"}))
+
+	var results []eventListResult
+	g.Expect(json.Unmarshal(res.Body.Bytes(), &results)).To(Succeed())
+	g.Expect(results).To(HaveLen(2))
+	g.Expect(results[0].Status).To(Equal(http.StatusBadRequest))
+	g.Expect(results[0].Problem).ToNot(BeNil())
+	g.Expect(results[0].Problem.InvalidParams[0].Name).To(Equal("severity"))
+	g.Expect(results[1].Status).To(Equal(http.StatusAccepted))
+	g.Expect(results[1].Problem).To(BeNil())
+	g.Expect(results[0].EventID).ToNot(BeEmpty())
+	g.Expect(results[1].EventID).ToNot(BeEmpty())
+	g.Expect(results[0].EventID).ToNot(Equal(results[1].EventID))
+}
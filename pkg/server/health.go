@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// receiverQueueStallTimeout is the duration after which a full receiver
+// queue from which no request has been taken is reported as unhealthy.
+const receiverQueueStallTimeout = 2 * time.Minute
+
+// HealthCheck returns an error if the event server is not listening. It
+// implements the controller-runtime healthz.Checker signature.
+func (s *EventServer) HealthCheck(_ *http.Request) error {
+	if !s.listening.Load() {
+		return errors.New("event server is not listening")
+	}
+	return nil
+}
+
+// HealthCheck returns an error if the receiver server is not listening, or
+// if the queue of asynchronous requests has been full without any request
+// being taken by the workers for longer than receiverQueueStallTimeout. It
+// implements the controller-runtime healthz.Checker signature.
+func (s *ReceiverServer) HealthCheck(_ *http.Request) error {
+	if !s.listening.Load() {
+		return errors.New("receiver server is not listening")
+	}
+	if s.queue != nil && len(s.queue) == cap(s.queue) {
+		stalled := time.Since(time.Unix(0, s.lastDequeue.Load()))
+		if stalled > receiverQueueStallTimeout {
+			return fmt.Errorf("receiver queue is saturated, no request was processed for %s",
+				stalled.Round(time.Second))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fluxcd/pkg/runtime/logger"
+	. "github.com/onsi/gomega"
+)
+
+func TestEventServer_HealthCheck(t *testing.T) {
+	g := NewWithT(t)
+
+	s := &EventServer{}
+	g.Expect(s.HealthCheck(nil)).To(MatchError(ContainSubstring("not listening")))
+
+	s.listening.Store(true)
+	g.Expect(s.HealthCheck(nil)).To(Succeed())
+}
+
+func TestReceiverServer_HealthCheck(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewReceiverServer("", logger.NewLogger(logger.Options{}), nil, ReceiverServerOptions{AsyncWorkers: 1})
+	g.Expect(s.HealthCheck(nil)).To(MatchError(ContainSubstring("not listening")))
+
+	s.listening.Store(true)
+	s.lastDequeue.Store(time.Now().Add(-2 * receiverQueueStallTimeout).UnixNano())
+	g.Expect(s.HealthCheck(nil)).To(Succeed())
+
+	for i := 0; i < receiverQueueSize; i++ {
+		s.queue <- receiverRequest{}
+	}
+	g.Expect(s.HealthCheck(nil)).To(MatchError(ContainSubstring("receiver queue is saturated")))
+
+	s.lastDequeue.Store(time.Now().UnixNano())
+	g.Expect(s.HealthCheck(nil)).To(Succeed())
+
+	synchronous := NewReceiverServer("", logger.NewLogger(logger.Options{}), nil, ReceiverServerOptions{})
+	synchronous.listening.Store(true)
+	g.Expect(synchronous.HealthCheck(nil)).To(Succeed())
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/fluxcd/notification-controller/internal/notifier"
+)
+
+// idempotencyKeyTTL is the period during which the idempotency key of an
+// accepted request is remembered.
+const idempotencyKeyTTL = time.Hour
+
+// idempotencyMaxKeys is the maximum number of idempotency keys remembered,
+// the oldest keys are forgotten first when it is reached.
+const idempotencyMaxKeys = 10000
+
+var idempotentDuplicates = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "gotk_event_idempotent_duplicates_total",
+		Help: "The number of event requests discarded because their idempotency key was already accepted or in flight.",
+	},
+)
+
+func init() {
+	metrics.Registry.MustRegister(idempotentDuplicates)
+}
+
+// idempotencyKeys remembers the idempotency keys of the accepted and the
+// in-flight requests, see notifier.IdempotencyKeyHeader.
+type idempotencyKeys struct {
+	ttl     time.Duration
+	maxKeys int
+
+	mu sync.Mutex
+	// keys indexes the entries of order by key.
+	keys map[string]*list.Element
+	// order holds the idempotencyKey entries from the oldest to the newest.
+	order *list.List
+}
+
+// idempotencyKey is an entry of idempotencyKeys. The time is when the key was
+// reserved until it is accepted.
+type idempotencyKey struct {
+	key      string
+	time     time.Time
+	inFlight bool
+}
+
+func newIdempotencyKeys(ttl time.Duration, maxKeys int) *idempotencyKeys {
+	return &idempotencyKeys{
+		ttl:     ttl,
+		maxKeys: maxKeys,
+		keys:    make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// reserve records the given key as in-flight and returns true, unless the key
+// is already in-flight or was accepted within the TTL, which is reported by
+// inFlight. The expired keys are pruned, and the oldest keys are evicted when
+// the maximum number of keys is reached.
+func (k *idempotencyKeys) reserve(key string) (reserved, inFlight bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := time.Now()
+	for e := k.order.Front(); e != nil; e = k.order.Front() {
+		if now.Sub(e.Value.(*idempotencyKey).time) <= k.ttl {
+			break
+		}
+		k.remove(e)
+	}
+	if e, ok := k.keys[key]; ok {
+		return false, e.Value.(*idempotencyKey).inFlight
+	}
+	for k.order.Len() >= k.maxKeys {
+		k.remove(k.order.Front())
+	}
+	k.keys[key] = k.order.PushBack(&idempotencyKey{
+		key:      key,
+		time:     now,
+		inFlight: true,
+	})
+	return true, false
+}
+
+// accept records the given in-flight key as accepted.
+func (k *idempotencyKeys) accept(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if e, ok := k.keys[key]; ok {
+		entry := e.Value.(*idempotencyKey)
+		entry.time = time.Now()
+		entry.inFlight = false
+		k.order.MoveToBack(e)
+	}
+}
+
+// release forgets the given in-flight key, allowing it to be retried.
+func (k *idempotencyKeys) release(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if e, ok := k.keys[key]; ok && e.Value.(*idempotencyKey).inFlight {
+		k.remove(e)
+	}
+}
+
+// remove deletes the given entry, k.mu must be held.
+func (k *idempotencyKeys) remove(e *list.Element) {
+	delete(k.keys, e.Value.(*idempotencyKey).key)
+	k.order.Remove(e)
+}
+
+// idempotencyMiddleware acknowledges the requests carrying an idempotency key
+// that was already accepted without handling them again, so that the events
+// relayed by another notification-controller are dispatched only once even
+// if their delivery is retried. The requests with a key that is still being
+// handled are rejected with a conflict. The keys of the requests that are
+// not fully accepted, including the partially dispatched ones, are not
+// recorded, allowing them to be retried.
+func (s *EventServer) idempotencyMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(notifier.IdempotencyKeyHeader)
+		if key == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		reserved, inFlight := s.idempotencyKeys.reserve(key)
+		if !reserved {
+			idempotentDuplicates.Inc()
+			if inFlight {
+				s.logger.V(1).Info("Discarding event, idempotency key in flight", "idempotencyKey", key)
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			s.logger.V(1).Info("Discarding event, idempotency key already accepted", "idempotencyKey", key)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		// Release the key if the request isn't accepted, e.g. on panic.
+		defer s.idempotencyKeys.release(key)
+
+		recorder := &statusRecorder{
+			ResponseWriter: w,
+			Status:         200,
+		}
+		h.ServeHTTP(recorder, r)
+
+		if recorder.Status == http.StatusOK || recorder.Status == http.StatusAccepted {
+			s.idempotencyKeys.accept(key)
+		}
+	})
+}
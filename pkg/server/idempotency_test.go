@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/fluxcd/notification-controller/internal/notifier"
+)
+
+func TestEventServer_idempotencyMiddleware(t *testing.T) {
+	g := NewWithT(t)
+
+	s := &EventServer{
+		logger:          log.Log,
+		idempotencyKeys: newIdempotencyKeys(time.Hour, 10),
+	}
+
+	handled := 0
+	status := http.StatusAccepted
+	h := s.idempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handled++
+		w.WriteHeader(status)
+	}))
+
+	serve := func(key string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if key != "" {
+			req.Header.Set(notifier.IdempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Requests without a key are always handled.
+	g.Expect(serve("")).To(Equal(http.StatusAccepted))
+	g.Expect(serve("")).To(Equal(http.StatusAccepted))
+	g.Expect(handled).To(Equal(2))
+
+	// Requests with an accepted key are acknowledged only.
+	g.Expect(serve("foo")).To(Equal(http.StatusAccepted))
+	g.Expect(serve("foo")).To(Equal(http.StatusAccepted))
+	g.Expect(handled).To(Equal(3))
+
+	// Requests that are not accepted can be retried.
+	status = http.StatusTooManyRequests
+	g.Expect(serve("bar")).To(Equal(http.StatusTooManyRequests))
+	status = http.StatusAccepted
+	g.Expect(serve("bar")).To(Equal(http.StatusAccepted))
+	g.Expect(serve("bar")).To(Equal(http.StatusAccepted))
+	g.Expect(handled).To(Equal(5))
+
+	// Partially dispatched requests can be retried.
+	status = http.StatusMultiStatus
+	g.Expect(serve("baz")).To(Equal(http.StatusMultiStatus))
+	g.Expect(serve("baz")).To(Equal(http.StatusMultiStatus))
+	g.Expect(handled).To(Equal(7))
+
+	// Requests with a key in flight are rejected.
+	reserved, _ := s.idempotencyKeys.reserve("qux")
+	g.Expect(reserved).To(BeTrue())
+	g.Expect(serve("qux")).To(Equal(http.StatusConflict))
+	g.Expect(handled).To(Equal(7))
+}
+
+func TestIdempotencyKeys_expiry(t *testing.T) {
+	g := NewWithT(t)
+
+	k := newIdempotencyKeys(time.Hour, 10)
+	reserved, _ := k.reserve("foo")
+	g.Expect(reserved).To(BeTrue())
+	k.accept("foo")
+	reserved, inFlight := k.reserve("foo")
+	g.Expect(reserved).To(BeFalse())
+	g.Expect(inFlight).To(BeFalse())
+
+	reserved, _ = k.reserve("bar")
+	g.Expect(reserved).To(BeTrue())
+	reserved, inFlight = k.reserve("bar")
+	g.Expect(reserved).To(BeFalse())
+	g.Expect(inFlight).To(BeTrue())
+	k.release("bar")
+	g.Expect(k.keys).ToNot(HaveKey("bar"))
+
+	// Expired keys are pruned when reserving new ones.
+	k.keys["foo"].Value.(*idempotencyKey).time = time.Now().Add(-2 * time.Hour)
+	reserved, _ = k.reserve("baz")
+	g.Expect(reserved).To(BeTrue())
+	g.Expect(k.keys).To(HaveLen(1))
+	g.Expect(k.keys).To(HaveKey("baz"))
+}
+
+func TestIdempotencyKeys_maxKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	k := newIdempotencyKeys(time.Hour, 3)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		reserved, _ := k.reserve(key)
+		g.Expect(reserved).To(BeTrue())
+		k.accept(key)
+	}
+
+	// The oldest key is evicted.
+	g.Expect(k.keys).To(HaveLen(3))
+	g.Expect(k.order.Len()).To(Equal(3))
+	g.Expect(k.keys).ToNot(HaveKey("a"))
+	reserved, _ := k.reserve("d")
+	g.Expect(reserved).To(BeFalse())
+}
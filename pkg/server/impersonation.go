@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=impersonate
+
+// sourceReader returns the client used to read the event source objects of
+// the given Alert, impersonating the ServiceAccount of the Alert if any so
+// that the RBAC of the tenant applies to the label matching.
+func (s *EventServer) sourceReader(alert *apiv1.Alert) (client.Reader, error) {
+	if alert.Spec.ServiceAccountName == "" {
+		return s.kubeClient, nil
+	}
+	if s.restConfig == nil {
+		return nil, fmt.Errorf("impersonation of ServiceAccount '%s' is not configured", alert.Spec.ServiceAccountName)
+	}
+
+	username := fmt.Sprintf("system:serviceaccount:%s:%s", alert.Namespace, alert.Spec.ServiceAccountName)
+	if c, ok := s.impersonatedClients.Load(username); ok {
+		return c.(client.Reader), nil
+	}
+
+	cfg := rest.CopyConfig(s.restConfig)
+	cfg.Impersonate = rest.ImpersonationConfig{UserName: username}
+	c, err := client.New(cfg, client.Options{
+		Scheme: s.kubeClient.Scheme(),
+		Mapper: s.kubeClient.RESTMapper(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a client impersonating '%s': %w", username, err)
+	}
+	s.impersonatedClients.Store(username, c)
+	return c, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestEventServer_sourceReader(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+	kclient := fakeclient.NewClientBuilder().WithScheme(scheme).Build()
+
+	alert := &apiv1.Alert{}
+	alert.Name = "alert"
+	alert.Namespace = "tenant"
+
+	s := &EventServer{kubeClient: kclient}
+
+	// The controller client is used without a ServiceAccount.
+	reader, err := s.sourceReader(alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reader).To(BeIdenticalTo(kclient))
+
+	// Impersonation requires a REST config.
+	alert.Spec.ServiceAccountName = "reader"
+	_, err = s.sourceReader(alert)
+	g.Expect(err).To(MatchError(ContainSubstring("not configured")))
+
+	// The impersonating clients are reused.
+	s.restConfig = &rest.Config{Host: "https://example.com"}
+	reader, err = s.sourceReader(alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reader).ToNot(BeIdenticalTo(kclient))
+	_, ok := s.impersonatedClients.Load("system:serviceaccount:tenant:reader")
+	g.Expect(ok).To(BeTrue())
+
+	again, err := s.sourceReader(alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(again).To(BeIdenticalTo(reader))
+}
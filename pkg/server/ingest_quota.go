@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// ingestQuotaWindow is the period over which the events of a reporting
+// controller are counted against its quota.
+const ingestQuotaWindow = time.Minute
+
+// otherControllers is the label of the ingestion metrics for the reporting
+// controllers that are neither Flux controllers nor given a quota.
+const otherControllers = "other"
+
+// fluxControllers is the set of the reporting controllers labelled by name
+// in the ingestion metrics without being given a quota.
+var fluxControllers = map[string]bool{
+	"source-controller":           true,
+	"kustomize-controller":        true,
+	"helm-controller":             true,
+	"notification-controller":     true,
+	"image-reflector-controller":  true,
+	"image-automation-controller": true,
+}
+
+var (
+	eventIngested = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gotk_event_ingest_total",
+			Help: "The number of valid events received, by reporting controller.",
+		},
+		[]string{"reporting_controller"},
+	)
+	ingestDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gotk_event_ingest_dropped_total",
+			Help: "The number of events dropped because the ingestion quota of the reporting controller is exceeded.",
+		},
+		[]string{"reporting_controller"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(eventIngested, ingestDropped)
+}
+
+// ValidateIngestQuotas returns an error if one of the given quotas, indexed
+// by reporting controller, is not greater than zero.
+func ValidateIngestQuotas(quotas map[string]int) error {
+	for controller, limit := range quotas {
+		if controller == "" {
+			return fmt.Errorf("invalid ingestion quota: the reporting controller name is empty")
+		}
+		if limit <= 0 {
+			return fmt.Errorf("invalid ingestion quota %d for '%s': must be greater than zero", limit, controller)
+		}
+	}
+	return nil
+}
+
+// ingestQuota limits the number of events accepted from each reporting
+// controller per ingestQuotaWindow, so that an event storm of a controller
+// can't crowd out the notifications of the other controllers. The
+// controllers without quota are not limited.
+// A nil *ingestQuota is valid and doesn't limit anything.
+type ingestQuota struct {
+	limits map[string]int
+
+	mu      sync.Mutex
+	windows map[string]*quotaWindow
+}
+
+// newIngestQuota returns a quota allowing the given number of events per
+// reporting controller and window, or nil if there are no limits.
+func newIngestQuota(limits map[string]int) *ingestQuota {
+	if len(limits) == 0 {
+		return nil
+	}
+	return &ingestQuota{
+		limits:  limits,
+		windows: make(map[string]*quotaWindow),
+	}
+}
+
+// allow records an event of the given reporting controller and returns if
+// it can be accepted, counting it against the quota of the controller.
+func (q *ingestQuota) allow(controller string) bool {
+	eventIngested.WithLabelValues(q.metricLabel(controller)).Inc()
+	if q == nil {
+		return true
+	}
+	limit, ok := q.limits[controller]
+	if !ok {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	w, ok := q.windows[controller]
+	if !ok || now.Sub(w.start) >= ingestQuotaWindow {
+		w = &quotaWindow{start: now}
+		q.windows[controller] = w
+	}
+	if w.count >= limit {
+		ingestDropped.WithLabelValues(q.metricLabel(controller)).Inc()
+		return false
+	}
+	w.count++
+	return true
+}
+
+// metricLabel returns the label of the given reporting controller in the
+// ingestion metrics. The reporting controller of an event is set by the
+// client, hence the controllers that are neither Flux controllers nor given
+// a quota are counted as otherControllers, to bound the number of series.
+func (q *ingestQuota) metricLabel(controller string) string {
+	if fluxControllers[controller] {
+		return controller
+	}
+	if q != nil {
+		if _, ok := q.limits[controller]; ok {
+			return controller
+		}
+	}
+	return otherControllers
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestIngestQuota(t *testing.T) {
+	g := NewWithT(t)
+
+	// A nil quota allows everything, and counts the events.
+	var unlimited *ingestQuota
+	g.Expect(newIngestQuota(nil)).To(BeNil())
+	before := testutil.ToFloat64(eventIngested.WithLabelValues("helm-controller"))
+	g.Expect(unlimited.allow("helm-controller")).To(BeTrue())
+	g.Expect(testutil.ToFloat64(eventIngested.WithLabelValues("helm-controller"))).To(Equal(before + 1))
+
+	q := newIngestQuota(map[string]int{"source-controller": 2})
+	g.Expect(q.allow("source-controller")).To(BeTrue())
+	g.Expect(q.allow("source-controller")).To(BeTrue())
+	g.Expect(q.allow("source-controller")).To(BeFalse())
+	g.Expect(testutil.ToFloat64(ingestDropped.WithLabelValues("source-controller"))).To(Equal(float64(1)))
+
+	// The controllers without quota are not limited.
+	for range 5 {
+		g.Expect(q.allow("kustomize-controller")).To(BeTrue())
+	}
+
+	// The quota is reset in the next window.
+	q.windows["source-controller"].start = time.Now().Add(-ingestQuotaWindow)
+	g.Expect(q.allow("source-controller")).To(BeTrue())
+}
+
+func TestIngestQuota_metricLabel(t *testing.T) {
+	g := NewWithT(t)
+
+	var unlimited *ingestQuota
+	g.Expect(unlimited.metricLabel("helm-controller")).To(Equal("helm-controller"))
+	g.Expect(unlimited.metricLabel("tf-controller")).To(Equal(otherControllers))
+
+	q := newIngestQuota(map[string]int{"tf-controller": 2})
+	g.Expect(q.metricLabel("helm-controller")).To(Equal("helm-controller"))
+	g.Expect(q.metricLabel("tf-controller")).To(Equal("tf-controller"))
+	g.Expect(q.metricLabel("random-1234")).To(Equal(otherControllers))
+
+	before := testutil.ToFloat64(eventIngested.WithLabelValues(otherControllers))
+	g.Expect(q.allow("random-1234")).To(BeTrue())
+	g.Expect(testutil.ToFloat64(eventIngested.WithLabelValues(otherControllers))).To(Equal(before + 1))
+}
+
+func TestValidateIngestQuotas(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ValidateIngestQuotas(nil)).To(Succeed())
+	g.Expect(ValidateIngestQuotas(map[string]int{"source-controller": 600})).To(Succeed())
+	g.Expect(ValidateIngestQuotas(map[string]int{"source-controller": 0})).ToNot(Succeed())
+	g.Expect(ValidateIngestQuotas(map[string]int{"": 10})).ToNot(Succeed())
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/pflag"
+)
+
+const flagIPFamily = "ip-family"
+
+// The IP families the servers can be bound to.
+const (
+	// IPFamilyDualStack binds the servers to both IPv4 and IPv6 when the
+	// address host is empty or unspecified.
+	IPFamilyDualStack = "dual-stack"
+
+	// IPFamilyIPv4 binds the servers to IPv4 only.
+	IPFamilyIPv4 = "ipv4"
+
+	// IPFamilyIPv6 binds the servers to IPv6 only.
+	IPFamilyIPv6 = "ipv6"
+)
+
+// ListenOptions holds the IP family the event, receiver and metrics servers
+// are bound to.
+type ListenOptions struct {
+	// IPFamily is one of dual-stack, ipv4 or ipv6, empty means dual-stack.
+	IPFamily string
+}
+
+// BindFlags will parse the given pflag.FlagSet for listen option flags and
+// set the ListenOptions accordingly.
+func (o *ListenOptions) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.IPFamily, flagIPFamily, IPFamilyDualStack,
+		fmt.Sprintf("The IP family the event, receiver and metrics servers bind to when their address has no host, one of '%s', '%s' or '%s'.",
+			IPFamilyDualStack, IPFamilyIPv4, IPFamilyIPv6))
+}
+
+// Validate returns an error if the IP family is not supported.
+func (o ListenOptions) Validate() error {
+	switch o.IPFamily {
+	case "", IPFamilyDualStack, IPFamilyIPv4, IPFamilyIPv6:
+		return nil
+	default:
+		return fmt.Errorf("--%s must be one of '%s', '%s' or '%s', got '%s'",
+			flagIPFamily, IPFamilyDualStack, IPFamilyIPv4, IPFamilyIPv6, o.IPFamily)
+	}
+}
+
+// Network returns the network to listen on for the IP family.
+func (o ListenOptions) Network() string {
+	switch o.IPFamily {
+	case IPFamilyIPv4:
+		return "tcp4"
+	case IPFamilyIPv6:
+		return "tcp6"
+	default:
+		return "tcp"
+	}
+}
+
+// BindAddress returns the given address with the unspecified IP of the IP
+// family as host when the host is empty, e.g. [::]:9090 for :9090 with
+// ipv6. The address is returned as-is for dual-stack, or when it can't be
+// parsed or has a host.
+func (o ListenOptions) BindAddress(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	switch o.IPFamily {
+	case IPFamilyIPv4:
+		return net.JoinHostPort(net.IPv4zero.String(), port)
+	case IPFamilyIPv6:
+		return net.JoinHostPort(net.IPv6unspecified.String(), port)
+	default:
+		return addr
+	}
+}
+
+// Listen announces on the given address for the IP family.
+func (o ListenOptions) Listen(addr string) (net.Listener, error) {
+	return net.Listen(o.Network(), o.BindAddress(addr))
+}
+
+// listenURL returns the URL of the server listening on the given listener,
+// with the IPv6 hosts enclosed in square brackets.
+func listenURL(l net.Listener) string {
+	return "http://" + l.Addr().String()
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestListenOptions_Validate(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, family := range []string{"", IPFamilyDualStack, IPFamilyIPv4, IPFamilyIPv6} {
+		g.Expect(ListenOptions{IPFamily: family}.Validate()).To(Succeed())
+	}
+	err := ListenOptions{IPFamily: "ipv5"}.Validate()
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("--ip-family"))
+}
+
+func TestListenOptions_BindAddress(t *testing.T) {
+	tests := []struct {
+		family      string
+		addr        string
+		wantAddr    string
+		wantNetwork string
+	}{
+		{family: "", addr: ":9090", wantAddr: ":9090", wantNetwork: "tcp"},
+		{family: IPFamilyDualStack, addr: ":9090", wantAddr: ":9090", wantNetwork: "tcp"},
+		{family: IPFamilyIPv4, addr: ":9090", wantAddr: "0.0.0.0:9090", wantNetwork: "tcp4"},
+		{family: IPFamilyIPv6, addr: ":9090", wantAddr: "[::]:9090", wantNetwork: "tcp6"},
+		{family: IPFamilyIPv6, addr: "[fd00::1]:9090", wantAddr: "[fd00::1]:9090", wantNetwork: "tcp6"},
+		{family: IPFamilyIPv4, addr: "10.0.0.1:9090", wantAddr: "10.0.0.1:9090", wantNetwork: "tcp4"},
+		{family: IPFamilyIPv6, addr: "0", wantAddr: "0", wantNetwork: "tcp6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.family+tt.addr, func(t *testing.T) {
+			g := NewWithT(t)
+
+			o := ListenOptions{IPFamily: tt.family}
+			g.Expect(o.BindAddress(tt.addr)).To(Equal(tt.wantAddr))
+			g.Expect(o.Network()).To(Equal(tt.wantNetwork))
+		})
+	}
+}
+
+func TestListenOptions_Listen(t *testing.T) {
+	t.Run("ipv4", func(t *testing.T) {
+		g := NewWithT(t)
+
+		l, err := ListenOptions{IPFamily: IPFamilyIPv4}.Listen("127.0.0.1:0")
+		g.Expect(err).ToNot(HaveOccurred())
+		defer l.Close()
+		g.Expect(listenURL(l)).To(HavePrefix("http://127.0.0.1:"))
+
+		_, err = ListenOptions{IPFamily: IPFamilyIPv4}.Listen("[::1]:0")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("ipv6", func(t *testing.T) {
+		g := NewWithT(t)
+
+		if l, err := net.Listen("tcp6", "[::1]:0"); err != nil {
+			t.Skip("IPv6 is not available")
+		} else {
+			l.Close()
+		}
+
+		l, err := ListenOptions{IPFamily: IPFamilyIPv6}.Listen("[::1]:0")
+		g.Expect(err).ToNot(HaveOccurred())
+		defer l.Close()
+		g.Expect(listenURL(l)).To(HavePrefix("http://[::1]:"))
+
+		_, err = ListenOptions{IPFamily: IPFamilyIPv6}.Listen("127.0.0.1:0")
+		g.Expect(err).To(HaveOccurred())
+	})
+}
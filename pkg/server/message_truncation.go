@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"strings"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// truncationMarker replaces the truncated part of the messages.
+const truncationMarker = "..."
+
+// truncateMessage truncates the message of the notification according to
+// the given policy, so that it fits the limits of the provider API instead
+// of being rejected.
+func truncateMessage(notification *eventv1.Event, policy *apiv1.MessageTruncation) {
+	if policy == nil || policy.MaxLength <= 0 {
+		return
+	}
+	message := []rune(notification.Message)
+	if len(message) <= policy.MaxLength {
+		return
+	}
+
+	var suffix string
+	if policy.DetailsURL != "" {
+		obj := notification.InvolvedObject
+		suffix = "\nFull message: " + strings.NewReplacer(
+			"{namespace}", obj.Namespace,
+			"{kind}", obj.Kind,
+			"{name}", obj.Name,
+		).Replace(policy.DetailsURL)
+	}
+
+	keep := max(policy.MaxLength-len([]rune(truncationMarker))-len([]rune(suffix)), 0)
+	switch policy.Strategy {
+	case apiv1.TruncateHead:
+		notification.Message = truncationMarker + string(message[len(message)-keep:]) + suffix
+	default:
+		notification.Message = string(message[:keep]) + truncationMarker + suffix
+	}
+}
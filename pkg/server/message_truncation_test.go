@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestTruncateMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		policy  *apiv1.MessageTruncation
+		want    string
+	}{
+		{
+			name:    "no policy",
+			message: "Helm upgrade failed",
+			want:    "Helm upgrade failed",
+		},
+		{
+			name:    "message within limit",
+			message: "Helm upgrade failed",
+			policy:  &apiv1.MessageTruncation{MaxLength: 19},
+			want:    "Helm upgrade failed",
+		},
+		{
+			name:    "truncate tail",
+			message: "Helm upgrade failed",
+			policy:  &apiv1.MessageTruncation{MaxLength: 15, Strategy: apiv1.TruncateTail},
+			want:    "Helm upgrade...",
+		},
+		{
+			name:    "truncate head",
+			message: "Helm upgrade failed",
+			policy:  &apiv1.MessageTruncation{MaxLength: 10, Strategy: apiv1.TruncateHead},
+			want:    "... failed",
+		},
+		{
+			name:    "multi-byte characters",
+			message: "héllo wörld",
+			policy:  &apiv1.MessageTruncation{MaxLength: 8},
+			want:    "héllo...",
+		},
+		{
+			name:    "details link",
+			message: "Helm upgrade failed",
+			policy: &apiv1.MessageTruncation{
+				MaxLength:  60,
+				DetailsURL: "https://dashboard.example.com/{namespace}/{kind}/{name}",
+			},
+			want: "Helm upgrade failed",
+		},
+		{
+			name:    "details link on truncated message",
+			message: "Helm upgrade failed for release apps/podinfo.v3 with chart podinfo@6.5.4: timed out waiting for the condition",
+			policy: &apiv1.MessageTruncation{
+				MaxLength:  84,
+				DetailsURL: "https://dashboard.example.com/{namespace}/{kind}/{name}",
+			},
+			want: "Helm upgrade...\nFull message: https://dashboard.example.com/apps/HelmRelease/podinfo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			notification := &eventv1.Event{
+				InvolvedObject: corev1.ObjectReference{
+					Kind:      "HelmRelease",
+					Name:      "podinfo",
+					Namespace: "apps",
+				},
+				Message: tt.message,
+			}
+			truncateMessage(notification, tt.policy)
+			g.Expect(notification.Message).To(Equal(tt.want))
+			if tt.policy != nil {
+				g.Expect(len([]rune(notification.Message))).To(BeNumerically("<=", tt.policy.MaxLength))
+			}
+		})
+	}
+}
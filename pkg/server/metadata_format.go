@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// shortSHALength is the length of the digests shortened by shortSHA.
+const shortSHALength = 7
+
+// metadataFormatFuncs are the functions formatting the event metadata
+// values, by their name in the Alert .spec.eventMetadataFormat.
+var metadataFormatFuncs = map[string]func(string) string{
+	"shortSHA":         shortSHA,
+	"trimRef":          trimRef,
+	"humanizeDuration": humanizeDuration,
+}
+
+// formatEventMetadata formats the metadata values of the event with the
+// functions of the alert .spec.eventMetadataFormat. The unknown functions are
+// reported with a warning event on the alert.
+func (s *EventServer) formatEventMetadata(ctx context.Context, event *eventv1.Event, alert *apiv1.Alert) {
+	for key, name := range alert.Spec.EventMetadataFormat {
+		format, ok := metadataFormatFuncs[name]
+		if !ok {
+			log.FromContext(ctx).Error(fmt.Errorf("unknown function '%s'", name),
+				"failed to format event metadata", "key", key)
+			s.Eventf(alert, corev1.EventTypeWarning, "InvalidConfig",
+				"unknown event metadata format function '%s' for key '%s'", name, key)
+			continue
+		}
+		if val, ok := event.Metadata[key]; ok {
+			event.Metadata[key] = format(val)
+		}
+	}
+}
+
+// shortSHA shortens the digest of a revision, e.g. main@sha1:6d7e4a3 for
+// main@sha1:6d7e4a3c5e6f..., or a plain Git commit SHA.
+func shortSHA(revision string) string {
+	prefix, digest := "", revision
+	if i := strings.LastIndex(revision, ":"); i >= 0 {
+		prefix, digest = revision[:i+1], revision[i+1:]
+	} else if i := strings.LastIndex(revision, "@"); i >= 0 {
+		prefix, digest = revision[:i+1], revision[i+1:]
+	}
+	if len(digest) <= shortSHALength || !isHex(digest) {
+		return revision
+	}
+	return prefix + digest[:shortSHALength]
+}
+
+// trimRef trims the refs/heads/ and refs/tags/ prefixes of a revision, e.g.
+// main@sha1:6d7e4a3 for refs/heads/main@sha1:6d7e4a3.
+func trimRef(revision string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		if strings.HasPrefix(revision, prefix) {
+			return strings.TrimPrefix(revision, prefix)
+		}
+	}
+	return revision
+}
+
+// humanizeDuration rounds a duration, given in the Go format or as a number
+// of seconds, to the second, or to the millisecond below one second, and
+// drops the zero units, e.g. 1m30s for 1m30.123456789s and 2h for 2h0m0s.
+func humanizeDuration(value string) string {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return value
+		}
+		d = time.Duration(seconds * float64(time.Second))
+	}
+	if d < time.Second && d > -time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	out := d.Round(time.Second).String()
+	if strings.HasSuffix(out, "m0s") {
+		out = strings.TrimSuffix(out, "0s")
+	}
+	if strings.HasSuffix(out, "h0m") {
+		out = strings.TrimSuffix(out, "0m")
+	}
+	return out
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestFormatEventMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	s := &EventServer{EventRecorder: recorder}
+	alert := &apiv1.Alert{
+		Spec: apiv1.AlertSpec{
+			EventMetadataFormat: map[string]string{
+				"revision": "shortSHA",
+				"ref":      "trimRef",
+				"duration": "humanizeDuration",
+				"missing":  "shortSHA",
+				"other":    "upperCase",
+			},
+		},
+	}
+	event := &eventv1.Event{
+		Metadata: map[string]string{
+			"revision": "main@sha1:6d7e4a3c5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b",
+			"ref":      "refs/tags/v1.2.3",
+			"duration": "1m30.123456789s",
+			"other":    "unchanged",
+		},
+	}
+
+	s.formatEventMetadata(context.TODO(), event, alert)
+	g.Expect(event.Metadata).To(Equal(map[string]string{
+		"revision": "main@sha1:6d7e4a3",
+		"ref":      "v1.2.3",
+		"duration": "1m30s",
+		"other":    "unchanged",
+	}))
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring("unknown event metadata format function 'upperCase'"))
+}
+
+func TestMetadataFormatFuncs(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    func(string) string
+		value string
+		want  string
+	}{
+		{name: "shortSHA revision", fn: shortSHA, value: "main@sha1:6d7e4a3c5e6f7a8b", want: "main@sha1:6d7e4a3"},
+		{name: "shortSHA OCI digest", fn: shortSHA, value: "latest@sha256:0123456789abcdef", want: "latest@sha256:0123456"},
+		{name: "shortSHA plain SHA", fn: shortSHA, value: "6d7e4a3c5e6f7a8b", want: "6d7e4a3"},
+		{name: "shortSHA semver", fn: shortSHA, value: "6.5.0", want: "6.5.0"},
+		{name: "shortSHA not hex", fn: shortSHA, value: "main@sha1:not-a-digest", want: "main@sha1:not-a-digest"},
+		{name: "trimRef branch", fn: trimRef, value: "refs/heads/main@sha1:6d7e4a3", want: "main@sha1:6d7e4a3"},
+		{name: "trimRef no ref", fn: trimRef, value: "main@sha1:6d7e4a3", want: "main@sha1:6d7e4a3"},
+		{name: "humanizeDuration seconds", fn: humanizeDuration, value: "1m30.6s", want: "1m31s"},
+		{name: "humanizeDuration hours", fn: humanizeDuration, value: "2h0m0.2s", want: "2h"},
+		{name: "humanizeDuration minutes", fn: humanizeDuration, value: "5m0s", want: "5m"},
+		{name: "humanizeDuration milliseconds", fn: humanizeDuration, value: "0.1234567s", want: "123ms"},
+		{name: "humanizeDuration number", fn: humanizeDuration, value: "90.4", want: "1m30s"},
+		{name: "humanizeDuration invalid", fn: humanizeDuration, value: "soon", want: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tt.fn(tt.value)).To(Equal(tt.want))
+		})
+	}
+}
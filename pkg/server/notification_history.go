@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// NotificationsEndpoint is the path of the event server endpoint listing the
+// recently dispatched notifications.
+const NotificationsEndpoint = "/api/v1/notifications"
+
+// notificationRecord describes a notification dispatched to a Provider.
+type notificationRecord struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Namespace      string    `json:"namespace"`
+	Alert          string    `json:"alert"`
+	Provider       string    `json:"provider"`
+	InvolvedObject string    `json:"involvedObject"`
+	Severity       string    `json:"severity"`
+	Reason         string    `json:"reason"`
+	Message        string    `json:"message"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// notificationHistory is a fixed size ring buffer of the recently
+// dispatched notifications.
+type notificationHistory struct {
+	mu      sync.Mutex
+	records []notificationRecord
+	next    int
+	full    bool
+}
+
+// newNotificationHistory returns a notificationHistory holding up to size
+// records, or nil if size is not positive.
+func newNotificationHistory(size int) *notificationHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &notificationHistory{records: make([]notificationRecord, size)}
+}
+
+// add records the dispatch of the given notification to the Provider.
+func (h *notificationHistory) add(alert *apiv1.Alert, provider string, notification *eventv1.Event, err error) {
+	if h == nil {
+		return
+	}
+	record := notificationRecord{
+		Timestamp:      time.Now(),
+		Namespace:      alert.Namespace,
+		Alert:          alert.Name,
+		Provider:       provider,
+		InvolvedObject: involvedObjectString(notification.InvolvedObject),
+		Severity:       notification.Severity,
+		Reason:         notification.Reason,
+		Message:        notification.Message,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the records of the given namespace, or of all namespaces if
+// empty, from the most recent to the oldest.
+func (h *notificationHistory) list(namespace string) []notificationRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := h.next
+	if h.full {
+		n = len(h.records)
+	}
+	records := make([]notificationRecord, 0, n)
+	for i := 1; i <= n; i++ {
+		record := h.records[(h.next-i+len(h.records))%len(h.records)]
+		if namespace == "" || record.Namespace == namespace {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// handleNotifications returns an http.Handler listing the recently
+// dispatched notifications of the namespace given in the query. The request
+// must bear the token of a user allowed to list the Alerts of the namespace,
+// or of all namespaces if no namespace is given.
+func (s *EventServer) handleNotifications() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		namespace := r.URL.Query().Get("namespace")
+		if status, err := authorizeList(r.Context(), s.kubeClient, r, "alerts", namespace); err != nil {
+			s.logger.V(1).Info("rejecting notifications request", "namespace", namespace, "error", err.Error())
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.history.list(namespace)); err != nil {
+			s.logger.Error(err, "unable to write notifications")
+		}
+	})
+}
+
+// authorizeList authenticates the bearer token of the request with a
+// TokenReview, and checks with a SubjectAccessReview that its user can list
+// the given resource of the notification API in the given namespace, or in
+// all namespaces if empty. It returns the HTTP status code to respond with
+// when the request is not authorized.
+func authorizeList(ctx context.Context, kubeClient client.Client, r *http.Request, resource, namespace string) (int, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return http.StatusUnauthorized, fmt.Errorf("missing bearer token")
+	}
+
+	review := &authnv1.TokenReview{Spec: authnv1.TokenReviewSpec{Token: token}}
+	if err := kubeClient.Create(ctx, review); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("unable to review token: %w", err)
+	}
+	if !review.Status.Authenticated {
+		return http.StatusUnauthorized, fmt.Errorf("invalid bearer token")
+	}
+
+	user := review.Status.User
+	extra := make(map[string]authzv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authzv1.ExtraValue(v)
+	}
+	access := &authzv1.SubjectAccessReview{
+		Spec: authzv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authzv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "list",
+				Group:     apiv1.GroupVersion.Group,
+				Resource:  resource,
+			},
+		},
+	}
+	if err := kubeClient.Create(ctx, access); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("unable to review access: %w", err)
+	}
+	if !access.Status.Allowed {
+		return http.StatusForbidden, fmt.Errorf("user '%s' can't list %s in namespace '%s'", user.Username, resource, namespace)
+	}
+	return 0, nil
+}
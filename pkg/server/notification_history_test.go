@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	log "sigs.k8s.io/controller-runtime/pkg/log"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestNotificationHistory(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(newNotificationHistory(0)).To(BeNil())
+
+	history := newNotificationHistory(3)
+	for i, ns := range []string{"a", "b", "a", "a"} {
+		alert := &apiv1.Alert{}
+		alert.Namespace = ns
+		alert.Name = "alert"
+		event := &eventv1.Event{
+			InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Name: "app", Namespace: ns},
+			Message:        string(rune('0' + i)),
+		}
+		var err error
+		if i == 3 {
+			err = errors.New("timeout")
+		}
+		history.add(alert, "slack", event, err)
+	}
+
+	records := history.list("")
+	g.Expect(records).To(HaveLen(3))
+	g.Expect(records[0].Message).To(Equal("3"))
+	g.Expect(records[0].Error).To(Equal("timeout"))
+	g.Expect(records[2].Message).To(Equal("1"))
+
+	records = history.list("a")
+	g.Expect(records).To(HaveLen(2))
+	g.Expect(records[0].Message).To(Equal("3"))
+	g.Expect(records[1].Message).To(Equal("2"))
+}
+
+func TestEventServer_handleNotifications(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		namespace  string
+		wantStatus int
+		wantLen    int
+	}{
+		{
+			name:       "missing token",
+			namespace:  "apps",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid token",
+			token:      "invalid",
+			namespace:  "apps",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "allowed namespace",
+			token:      "viewer",
+			namespace:  "apps",
+			wantStatus: http.StatusOK,
+			wantLen:    1,
+		},
+		{
+			name:       "forbidden namespace",
+			token:      "viewer",
+			namespace:  "flux-system",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "all namespaces",
+			token:      "admin",
+			wantStatus: http.StatusOK,
+			wantLen:    2,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	kclient := fakeclient.NewClientBuilder().WithScheme(scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				switch o := obj.(type) {
+				case *authnv1.TokenReview:
+					if o.Spec.Token == "viewer" || o.Spec.Token == "admin" {
+						o.Status.Authenticated = true
+						o.Status.User.Username = o.Spec.Token
+					}
+				case *authzv1.SubjectAccessReview:
+					attrs := o.Spec.ResourceAttributes
+					o.Status.Allowed = attrs.Verb == "list" && attrs.Resource == "alerts" &&
+						(o.Spec.User == "admin" || attrs.Namespace == "apps")
+				default:
+					return c.Create(ctx, obj, opts...)
+				}
+				return nil
+			},
+		}).Build()
+
+	s := &EventServer{
+		kubeClient: kclient,
+		logger:     log.Log,
+		history:    newNotificationHistory(10),
+	}
+	for _, ns := range []string{"apps", "flux-system"} {
+		alert := &apiv1.Alert{}
+		alert.Namespace = ns
+		alert.Name = "alert"
+		s.history.add(alert, "slack", &eventv1.Event{Message: "Reconciliation finished"}, nil)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			req := httptest.NewRequest(http.MethodGet, NotificationsEndpoint+"?namespace="+tt.namespace, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			res := httptest.NewRecorder()
+			s.handleNotifications().ServeHTTP(res, req)
+
+			g.Expect(res.Code).To(Equal(tt.wantStatus))
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var records []notificationRecord
+			g.Expect(json.Unmarshal(res.Body.Bytes(), &records)).To(Succeed())
+			g.Expect(records).To(HaveLen(tt.wantLen))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// +kubebuilder:rbac:groups=notification.toolkit.fluxcd.io,resources=notificationpolicies,verbs=get;list;watch
+
+// getNotificationPolicies returns the NotificationPolicies of the cluster,
+// sorted by name. The events are dispatched without the policies when they
+// can't be listed.
+func (s *EventServer) getNotificationPolicies(ctx context.Context) []apiv1.NotificationPolicy {
+	var policies apiv1.NotificationPolicyList
+	if err := s.kubeClient.List(ctx, &policies); err != nil {
+		log.FromContext(ctx).Error(err, "failed listing notification policies, dispatching the event without them")
+		return nil
+	}
+	slices.SortFunc(policies.Items, func(a, b apiv1.NotificationPolicy) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return policies.Items
+}
+
+// applyNotificationPolicies returns the given Alerts with the exclusion
+// lists and the event metadata of the NotificationPolicies applying to
+// their namespace merged into their spec. The Alerts not subject to any
+// policy are returned as is.
+func applyNotificationPolicies(alerts []apiv1.Alert, policies []apiv1.NotificationPolicy) []apiv1.Alert {
+	if len(policies) == 0 {
+		return alerts
+	}
+
+	results := make([]apiv1.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		merged := false
+		for i := range policies {
+			policy := &policies[i]
+			if !policy.AppliesTo(alert.Namespace) ||
+				(len(policy.Spec.ExclusionList) == 0 && len(policy.Spec.EventMetadata) == 0) {
+				continue
+			}
+			if !merged {
+				alert = *alert.DeepCopy()
+				merged = true
+			}
+			alert.Spec.ExclusionList = append(alert.Spec.ExclusionList, policy.Spec.ExclusionList...)
+			if len(policy.Spec.EventMetadata) > 0 && alert.Spec.EventMetadata == nil {
+				alert.Spec.EventMetadata = make(map[string]string, len(policy.Spec.EventMetadata))
+			}
+			for k, v := range policy.Spec.EventMetadata {
+				alert.Spec.EventMetadata[k] = v
+			}
+		}
+		results = append(results, alert)
+	}
+	return results
+}
+
+// suppressAlerts returns the given Alerts without the ones whose namespace
+// is in a suppression window, at the given time, of a NotificationPolicy.
+func suppressAlerts(ctx context.Context, alerts []apiv1.Alert, policies []apiv1.NotificationPolicy, now time.Time) []apiv1.Alert {
+	if len(policies) == 0 {
+		return alerts
+	}
+
+	results := make([]apiv1.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		suppressed := false
+		for i := range policies {
+			policy := &policies[i]
+			if policy.AppliesTo(alert.Namespace) && policy.Suppresses(now) {
+				log.FromContext(ctx).Info("discarding event, suppressed by notification policy",
+					alert.Kind, client.ObjectKeyFromObject(&alert), apiv1.NotificationPolicyKind, policy.Name)
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			results = append(results, alert)
+		}
+	}
+	return results
+}
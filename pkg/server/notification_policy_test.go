@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestSuppressionWindow_Contains(t *testing.T) {
+	// 2024-06-01 is a Saturday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		window apiv1.SuppressionWindow
+		time   time.Time
+		want   bool
+	}{
+		{
+			name:   "within the window",
+			window: apiv1.SuppressionWindow{Start: "02:00", End: "04:00"},
+			time:   at(1, 3, 0),
+			want:   true,
+		},
+		{
+			name:   "at the end of the window",
+			window: apiv1.SuppressionWindow{Start: "02:00", End: "04:00"},
+			time:   at(1, 4, 0),
+		},
+		{
+			name:   "on another day",
+			window: apiv1.SuppressionWindow{Start: "02:00", End: "04:00", Days: []string{"Sunday"}},
+			time:   at(1, 3, 0),
+		},
+		{
+			name:   "spanning midnight, before midnight",
+			window: apiv1.SuppressionWindow{Start: "22:00", End: "02:00", Days: []string{"Saturday"}},
+			time:   at(1, 23, 0),
+			want:   true,
+		},
+		{
+			name:   "spanning midnight, after midnight of the start day",
+			window: apiv1.SuppressionWindow{Start: "22:00", End: "02:00", Days: []string{"Saturday"}},
+			time:   at(2, 1, 0),
+			want:   true,
+		},
+		{
+			name:   "spanning midnight, after midnight of another day",
+			window: apiv1.SuppressionWindow{Start: "22:00", End: "02:00", Days: []string{"Saturday"}},
+			time:   at(1, 1, 0),
+		},
+		{
+			name:   "invalid window",
+			window: apiv1.SuppressionWindow{Start: "2am", End: "04:00"},
+			time:   at(1, 3, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tt.window.Contains(tt.time)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestApplyNotificationPolicies(t *testing.T) {
+	g := NewWithT(t)
+
+	alerts := []apiv1.Alert{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "alert"},
+			Spec: apiv1.AlertSpec{
+				ExclusionList: []string{"^skip"},
+				EventMetadata: map[string]string{"env": "dev", "team": "apps"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "alert"},
+		},
+	}
+	policies := []apiv1.NotificationPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "defaults"},
+			Spec: apiv1.NotificationPolicySpec{
+				ExclusionList:      []string{"no changes"},
+				EventMetadata:      map[string]string{"env": "prod", "cluster": "eu-1"},
+				ExcludedNamespaces: []string{"platform"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "suspended"},
+			Spec: apiv1.NotificationPolicySpec{
+				ExclusionList: []string{".*"},
+				Suspend:       true,
+			},
+		},
+	}
+
+	results := applyNotificationPolicies(alerts, policies)
+	g.Expect(results).To(HaveLen(2))
+	g.Expect(results[0].Spec.ExclusionList).To(Equal([]string{"^skip", "no changes"}))
+	g.Expect(results[0].Spec.EventMetadata).To(Equal(map[string]string{"env": "prod", "team": "apps", "cluster": "eu-1"}))
+	g.Expect(results[1]).To(Equal(alerts[1]))
+
+	// The given Alerts are not modified.
+	g.Expect(alerts[0].Spec.ExclusionList).To(Equal([]string{"^skip"}))
+	g.Expect(alerts[0].Spec.EventMetadata).To(HaveKeyWithValue("env", "dev"))
+}
+
+func TestSuppressAlerts(t *testing.T) {
+	g := NewWithT(t)
+
+	alerts := []apiv1.Alert{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "alert"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "alert"}},
+	}
+	policies := []apiv1.NotificationPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "maintenance"},
+			Spec: apiv1.NotificationPolicySpec{
+				SuppressionWindows: []apiv1.SuppressionWindow{{Start: "02:00", End: "04:00"}},
+				ExcludedNamespaces: []string{"platform"},
+			},
+		},
+	}
+	within := time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC)
+	outside := time.Date(2024, 6, 1, 5, 0, 0, 0, time.UTC)
+
+	g.Expect(suppressAlerts(context.TODO(), alerts, policies, within)).To(Equal(alerts[1:]))
+	g.Expect(suppressAlerts(context.TODO(), alerts, policies, outside)).To(Equal(alerts))
+	g.Expect(suppressAlerts(context.TODO(), alerts, nil, within)).To(Equal(alerts))
+}
+
+func TestGetAllAlertsForEvent_NotificationPolicies(t *testing.T) {
+	g := NewWithT(t)
+
+	alert := &apiv1.Alert{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alert"},
+		Spec: apiv1.AlertSpec{
+			ProviderRefs: []meta.LocalObjectReference{{Name: "provider"}},
+			EventSources: []apiv1.CrossNamespaceObjectReference{{Kind: "Kustomization", Name: "*"}},
+		},
+	}
+	policy := &apiv1.NotificationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "defaults"},
+		Spec: apiv1.NotificationPolicySpec{
+			ExclusionList: []string{"no changes"},
+			EventMetadata: map[string]string{"cluster": "eu-1"},
+		},
+	}
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+	s := &EventServer{
+		kubeClient:    fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(alert, policy).Build(),
+		logger:        log.Log,
+		EventRecorder: record.NewFakeRecorder(32),
+	}
+	newEvent := func(msg string) *eventv1.Event {
+		return &eventv1.Event{
+			InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Namespace: "default", Name: "apps"},
+			Message:        msg,
+		}
+	}
+
+	alerts, err := s.getAllAlertsForEvent(context.TODO(), newEvent("applied"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(alerts).To(HaveLen(1))
+	g.Expect(alerts[0].Spec.EventMetadata).To(HaveKeyWithValue("cluster", "eu-1"))
+
+	alerts, err = s.getAllAlertsForEvent(context.TODO(), newEvent("no changes"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(alerts).To(BeEmpty())
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// +kubebuilder:rbac:groups=notification.toolkit.fluxcd.io,resources=notificationtemplates,verbs=get;list;watch
+
+// applyNotificationTemplate replaces the message of the notification with
+// the message rendered by the NotificationTemplate of the Alert, for the
+// Providers of the given type.
+func (s *EventServer) applyNotificationTemplate(ctx context.Context, notification *eventv1.Event, alert *apiv1.Alert, providerType string) error {
+	ref := alert.Spec.TemplateRef
+	if ref == nil {
+		return nil
+	}
+
+	var nt apiv1.NotificationTemplate
+	if err := s.kubeClient.Get(ctx, types.NamespacedName{Namespace: alert.Namespace, Name: ref.Name}, &nt); err != nil {
+		return fmt.Errorf("failed to read notification template: %w", err)
+	}
+
+	text := nt.MessageTemplate(providerType)
+	if text == "" {
+		return nil
+	}
+	msg, err := renderMessageTemplate(nt.Name, text, notification)
+	if err != nil {
+		return fmt.Errorf("failed to render notification template '%s': %w", nt.Name, err)
+	}
+	notification.Message = msg
+	return nil
+}
+
+// renderMessageTemplate renders the given Go template with the event.
+func renderMessageTemplate(name, text string, event *eventv1.Event) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, event); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
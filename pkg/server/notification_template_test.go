@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestApplyNotificationTemplate(t *testing.T) {
+	g := NewWithT(t)
+
+	nt := &apiv1.NotificationTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "platform"},
+		Spec: apiv1.NotificationTemplateSpec{
+			Message: "{{ .InvolvedObject.Kind }}/{{ .InvolvedObject.Name }}: {{ .Message }}",
+			ProviderMessages: map[string]string{
+				apiv1.SlackProvider:  "*{{ .InvolvedObject.Name }}* {{ .Message }} ({{ .Metadata.revision }})",
+				apiv1.GitHubProvider: "",
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+	s := &EventServer{
+		kubeClient: fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(nt).Build(),
+	}
+
+	alert := &apiv1.Alert{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alert"},
+		Spec: apiv1.AlertSpec{
+			TemplateRef: &meta.LocalObjectReference{Name: "platform"},
+		},
+	}
+	newEvent := func() *eventv1.Event {
+		return &eventv1.Event{
+			InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Name: "apps"},
+			Message:        "applied",
+			Metadata:       map[string]string{"revision": "main@sha1:1234"},
+		}
+	}
+
+	for _, tt := range []struct {
+		providerType string
+		want         string
+	}{
+		{providerType: apiv1.GenericProvider, want: "Kustomization/apps: applied"},
+		{providerType: apiv1.SlackProvider, want: "*apps* applied (main@sha1:1234)"},
+		{providerType: apiv1.GitHubProvider, want: "applied"},
+	} {
+		event := newEvent()
+		g.Expect(s.applyNotificationTemplate(context.TODO(), event, alert, tt.providerType)).To(Succeed())
+		g.Expect(event.Message).To(Equal(tt.want), tt.providerType)
+	}
+
+	// The Alerts without a template are not changed.
+	event := newEvent()
+	g.Expect(s.applyNotificationTemplate(context.TODO(), event, &apiv1.Alert{}, apiv1.SlackProvider)).To(Succeed())
+	g.Expect(event.Message).To(Equal("applied"))
+
+	alert.Spec.TemplateRef.Name = "missing"
+	g.Expect(s.applyNotificationTemplate(context.TODO(), newEvent(), alert, apiv1.SlackProvider)).
+		To(MatchError(ContainSubstring("failed to read notification template")))
+}
+
+func TestRenderMessageTemplate_Invalid(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := renderMessageTemplate("invalid", "{{ .Message", &eventv1.Event{})
+	g.Expect(err).To(HaveOccurred())
+}
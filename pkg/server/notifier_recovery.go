@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	"github.com/fluxcd/notification-controller/internal/notifier"
+)
+
+var notifierPanics = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gotk_notifier_panics_total",
+		Help: "The number of panics recovered while sending notifications, per Provider.",
+	},
+	[]string{"type", "name", "namespace"},
+)
+
+var notificationLatency = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "gotk_notification_latency_seconds",
+		Help:    "The duration of sending notifications to the Providers, per Provider type and outcome.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15, 30, 60},
+	},
+	[]string{"type", "outcome"},
+)
+
+// The outcomes of sending a notification.
+const (
+	notificationSuccess = "success"
+	notificationFailure = "failure"
+	notificationTimeout = "timeout"
+)
+
+func init() {
+	metrics.Registry.MustRegister(notifierPanics, notificationLatency)
+}
+
+// postNotification sends the event with the given notifier of the Provider
+// with the given type, name and namespace, and records its duration by
+// outcome. A panic of the notifier is recovered and returned as an error, so
+// that a faulty notifier doesn't crash the controller and stop the delivery
+// of all the notifications.
+func postNotification(ctx context.Context, logger logr.Logger, n notifier.Interface, event eventv1.Event,
+	providerType, name, namespace string) (err error) {
+	start := time.Now()
+	defer func() {
+		outcome := notificationSuccess
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+			outcome = notificationTimeout
+		default:
+			outcome = notificationFailure
+		}
+		observeWithEventID(ctx, notificationLatency.WithLabelValues(providerType, outcome), time.Since(start).Seconds())
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			notifierPanics.WithLabelValues(providerType, name, namespace).Inc()
+			// The panic value is only returned with the error, which
+			// has the Provider secrets redacted by the caller.
+			logger.Error(errors.New("notifier panicked"), "recovered from notifier panic",
+				"provider", namespace+"/"+name, "type", providerType, "stacktrace", string(debug.Stack()))
+			err = fmt.Errorf("notifier of type '%s' panicked: %v", providerType, r)
+		}
+	}()
+	return n.Post(ctx, event)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+)
+
+type panickingNotifier struct{}
+
+func (panickingNotifier) Post(context.Context, eventv1.Event) error {
+	var m map[string]string
+	m["key"] = "value"
+	return nil
+}
+
+type failingNotifier struct{}
+
+func (failingNotifier) Post(context.Context, eventv1.Event) error {
+	return errors.New("boom")
+}
+
+func TestPostNotification(t *testing.T) {
+	g := NewWithT(t)
+
+	err := postNotification(context.TODO(), logr.Discard(), panickingNotifier{}, eventv1.Event{}, "generic", "panicking", "default")
+	g.Expect(err).To(MatchError(ContainSubstring("notifier of type 'generic' panicked: assignment to entry in nil map")))
+	g.Expect(testutil.ToFloat64(notifierPanics.WithLabelValues("generic", "panicking", "default"))).To(Equal(float64(1)))
+
+	err = postNotification(context.TODO(), logr.Discard(), failingNotifier{}, eventv1.Event{}, "generic", "failing", "default")
+	g.Expect(err).To(MatchError("boom"))
+	g.Expect(testutil.ToFloat64(notifierPanics.WithLabelValues("generic", "failing", "default"))).To(BeZero())
+}
+
+type successNotifier struct{}
+
+func (successNotifier) Post(context.Context, eventv1.Event) error {
+	return nil
+}
+
+type blockingNotifier struct{}
+
+func (blockingNotifier) Post(ctx context.Context, _ eventv1.Event) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestPostNotification_latency(t *testing.T) {
+	g := NewWithT(t)
+
+	series := testutil.CollectAndCount(notificationLatency)
+
+	g.Expect(postNotification(context.TODO(), logr.Discard(), successNotifier{}, eventv1.Event{}, "latency", "ok", "default")).To(Succeed())
+	g.Expect(postNotification(context.TODO(), logr.Discard(), failingNotifier{}, eventv1.Event{}, "latency", "failing", "default")).ToNot(Succeed())
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	g.Expect(postNotification(ctx, logr.Discard(), blockingNotifier{}, eventv1.Event{}, "latency", "blocking", "default")).ToNot(Succeed())
+
+	// One series per outcome: success, failure and timeout.
+	g.Expect(testutil.CollectAndCount(notificationLatency)).To(Equal(series + 3))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+const (
+	flagDefaultProviderTimeout = "default-provider-timeout"
+	flagMinProviderTimeout     = "min-provider-timeout"
+	flagMaxProviderTimeout     = "max-provider-timeout"
+)
+
+// ProviderTimeoutOptions holds the timeout used for sending alerts to the
+// Providers that don't specify one, and the bounds enforced on the
+// Provider .spec.timeout.
+type ProviderTimeoutOptions struct {
+	// Default is the timeout used when .spec.timeout is not set.
+	Default time.Duration
+
+	// Min is the lower bound of the timeout, zero means no bound.
+	Min time.Duration
+
+	// Max is the upper bound of the timeout, zero means no bound.
+	Max time.Duration
+}
+
+// BindFlags will parse the given pflag.FlagSet for provider timeout option
+// flags and set the ProviderTimeoutOptions accordingly.
+func (o *ProviderTimeoutOptions) BindFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&o.Default, flagDefaultProviderTimeout, 15*time.Second,
+		"The timeout for sending alerts to the Providers that don't specify one in '.spec.timeout'.")
+	fs.DurationVar(&o.Min, flagMinProviderTimeout, 0,
+		"The minimum timeout for sending alerts to a Provider, shorter Provider timeouts are raised to this value. Zero means no minimum.")
+	fs.DurationVar(&o.Max, flagMaxProviderTimeout, 0,
+		"The maximum timeout for sending alerts to a Provider, longer Provider timeouts are lowered to this value. Zero means no maximum.")
+}
+
+// Validate returns an error if the default timeout is not within the bounds.
+func (o ProviderTimeoutOptions) Validate() error {
+	if o.Default <= 0 {
+		return fmt.Errorf("--%s must be greater than zero", flagDefaultProviderTimeout)
+	}
+	if o.Min < 0 || o.Max < 0 {
+		return fmt.Errorf("--%s and --%s can't be negative", flagMinProviderTimeout, flagMaxProviderTimeout)
+	}
+	if o.Min > 0 && o.Default < o.Min {
+		return fmt.Errorf("--%s (%s) is lower than --%s (%s)", flagDefaultProviderTimeout, o.Default, flagMinProviderTimeout, o.Min)
+	}
+	if o.Max > 0 && o.Default > o.Max {
+		return fmt.Errorf("--%s (%s) is greater than --%s (%s)", flagDefaultProviderTimeout, o.Default, flagMaxProviderTimeout, o.Max)
+	}
+	return nil
+}
+
+// timeoutFor returns the timeout for sending alerts to the given Provider,
+// and whether the Provider .spec.timeout was out of bounds.
+func (o ProviderTimeoutOptions) timeoutFor(provider *apiv1.Provider) (time.Duration, bool) {
+	if provider.Spec.Timeout == nil {
+		if o.Default <= 0 {
+			return provider.GetTimeout(), false
+		}
+		return o.Default, false
+	}
+
+	timeout := provider.Spec.Timeout.Duration
+	switch {
+	case o.Min > 0 && timeout < o.Min:
+		return o.Min, true
+	case o.Max > 0 && timeout > o.Max:
+		return o.Max, true
+	default:
+		return timeout, false
+	}
+}
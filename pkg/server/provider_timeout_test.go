@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestProviderTimeoutOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ProviderTimeoutOptions
+		wantErr string
+	}{
+		{
+			name: "default only",
+			opts: ProviderTimeoutOptions{Default: 15 * time.Second},
+		},
+		{
+			name: "default within bounds",
+			opts: ProviderTimeoutOptions{Default: 15 * time.Second, Min: 5 * time.Second, Max: time.Minute},
+		},
+		{
+			name:    "zero default",
+			opts:    ProviderTimeoutOptions{},
+			wantErr: "--default-provider-timeout must be greater than zero",
+		},
+		{
+			name:    "negative bound",
+			opts:    ProviderTimeoutOptions{Default: 15 * time.Second, Max: -time.Second},
+			wantErr: "can't be negative",
+		},
+		{
+			name:    "default lower than min",
+			opts:    ProviderTimeoutOptions{Default: time.Second, Min: 5 * time.Second},
+			wantErr: "--default-provider-timeout (1s) is lower than --min-provider-timeout (5s)",
+		},
+		{
+			name:    "default greater than max",
+			opts:    ProviderTimeoutOptions{Default: time.Minute, Max: 30 * time.Second},
+			wantErr: "--default-provider-timeout (1m0s) is greater than --max-provider-timeout (30s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := tt.opts.Validate()
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestProviderTimeoutOptions_timeoutFor(t *testing.T) {
+	opts := ProviderTimeoutOptions{Default: 30 * time.Second, Min: 5 * time.Second, Max: time.Minute}
+
+	tests := []struct {
+		name            string
+		opts            ProviderTimeoutOptions
+		timeout         *metav1.Duration
+		wantTimeout     time.Duration
+		wantOutOfBounds bool
+	}{
+		{
+			name:        "no options and no timeout",
+			wantTimeout: 15 * time.Second,
+		},
+		{
+			name:        "default timeout",
+			opts:        opts,
+			wantTimeout: 30 * time.Second,
+		},
+		{
+			name:        "timeout within bounds",
+			opts:        opts,
+			timeout:     &metav1.Duration{Duration: 10 * time.Second},
+			wantTimeout: 10 * time.Second,
+		},
+		{
+			name:            "timeout lower than min",
+			opts:            opts,
+			timeout:         &metav1.Duration{Duration: time.Second},
+			wantTimeout:     5 * time.Second,
+			wantOutOfBounds: true,
+		},
+		{
+			name:            "timeout greater than max",
+			opts:            opts,
+			timeout:         &metav1.Duration{Duration: time.Hour},
+			wantTimeout:     time.Minute,
+			wantOutOfBounds: true,
+		},
+		{
+			name:        "no bounds",
+			opts:        ProviderTimeoutOptions{Default: 30 * time.Second},
+			timeout:     &metav1.Duration{Duration: time.Hour},
+			wantTimeout: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			provider := &apiv1.Provider{}
+			provider.Spec.Timeout = tt.timeout
+
+			timeout, outOfBounds := tt.opts.timeoutFor(provider)
+			g.Expect(timeout).To(Equal(tt.wantTimeout))
+			g.Expect(outOfBounds).To(Equal(tt.wantOutOfBounds))
+		})
+	}
+}
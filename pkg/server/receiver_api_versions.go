@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// defaultFluxAPIVersions is a map of Flux API kinds to their API versions,
+// used when the versions can't be discovered from the cluster.
+var defaultFluxAPIVersions = map[string]string{
+	"Bucket":                "source.toolkit.fluxcd.io/v1",
+	"HelmChart":             "source.toolkit.fluxcd.io/v1",
+	"HelmRepository":        "source.toolkit.fluxcd.io/v1",
+	"GitRepository":         "source.toolkit.fluxcd.io/v1",
+	"OCIRepository":         "source.toolkit.fluxcd.io/v1beta2",
+	"ImageRepository":       "image.toolkit.fluxcd.io/v1beta2",
+	"ImagePolicy":           "image.toolkit.fluxcd.io/v1beta2",
+	"ImageUpdateAutomation": "image.toolkit.fluxcd.io/v1beta2",
+}
+
+// ValidateAPIVersions returns an error if one of the given API versions,
+// indexed by kind, isn't a valid group/version.
+func ValidateAPIVersions(apiVersions map[string]string) error {
+	for kind, apiVersion := range apiVersions {
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return fmt.Errorf("invalid API version '%s' for kind '%s': %w", apiVersion, kind, err)
+		}
+		if gv.Group == "" || gv.Version == "" {
+			return fmt.Errorf("invalid API version '%s' for kind '%s': expected group/version", apiVersion, kind)
+		}
+	}
+	return nil
+}
+
+// fluxAPIVersion returns the API version of the Flux resources of the given
+// kind. The version configured for the kind takes precedence, followed by
+// the preferred version served by the cluster for the group of the default
+// version, so that the new Flux API versions are used without updating the
+// defaults. An empty string is returned for the kinds without a default.
+func (s *ReceiverServer) fluxAPIVersion(kind string) string {
+	if apiVersion, ok := s.apiVersions[kind]; ok {
+		return apiVersion
+	}
+
+	apiVersion := defaultFluxAPIVersions[kind]
+	if apiVersion == "" || s.kubeClient == nil || s.kubeClient.RESTMapper() == nil {
+		return apiVersion
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return apiVersion
+	}
+	mapping, err := s.kubeClient.RESTMapper().RESTMapping(gv.WithKind(kind).GroupKind())
+	if err != nil {
+		s.logger.V(1).Info("unable to discover the API version, using the default version",
+			"kind", kind, "apiVersion", apiVersion, "error", err.Error())
+		return apiVersion
+	}
+	return mapping.GroupVersionKind.GroupVersion().String()
+}
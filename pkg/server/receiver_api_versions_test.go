@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fluxcd/pkg/runtime/logger"
+)
+
+func Test_fluxAPIVersion(t *testing.T) {
+	mapper := apimeta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{
+		Group:   "source.toolkit.fluxcd.io",
+		Version: "v1",
+		Kind:    "OCIRepository",
+	}, apimeta.RESTScopeNamespace)
+	kclient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithRESTMapper(mapper).Build()
+
+	tests := []struct {
+		name        string
+		apiVersions map[string]string
+		kind        string
+		want        string
+	}{
+		{
+			name: "discovers the version served by the cluster",
+			kind: "OCIRepository",
+			want: "source.toolkit.fluxcd.io/v1",
+		},
+		{
+			name: "falls back to the default version",
+			kind: "ImagePolicy",
+			want: "image.toolkit.fluxcd.io/v1beta2",
+		},
+		{
+			name:        "prefers the configured version",
+			apiVersions: map[string]string{"OCIRepository": "source.toolkit.fluxcd.io/v1beta2"},
+			kind:        "OCIRepository",
+			want:        "source.toolkit.fluxcd.io/v1beta2",
+		},
+		{
+			name:        "configures the version of a kind without default",
+			apiVersions: map[string]string{"Kustomization": "kustomize.toolkit.fluxcd.io/v1"},
+			kind:        "Kustomization",
+			want:        "kustomize.toolkit.fluxcd.io/v1",
+		},
+		{
+			name: "returns no version for unknown kinds",
+			kind: "Kustomization",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s := NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{APIVersions: tt.apiVersions})
+			g.Expect(s.fluxAPIVersion(tt.kind)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestValidateAPIVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ValidateAPIVersions(nil)).To(Succeed())
+	g.Expect(ValidateAPIVersions(map[string]string{
+		"OCIRepository": "source.toolkit.fluxcd.io/v1",
+	})).To(Succeed())
+	g.Expect(ValidateAPIVersions(map[string]string{
+		"OCIRepository": "v1",
+	})).ToNot(Succeed())
+	g.Expect(ValidateAPIVersions(map[string]string{
+		"OCIRepository": "source.toolkit.fluxcd.io/v1/extra",
+	})).ToNot(Succeed())
+}
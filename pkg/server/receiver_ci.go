@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// errWebhookIgnored is returned by the validation of the webhooks that are
+// authentic but shouldn't trigger the resources, e.g. a failed CI run.
+var errWebhookIgnored = errors.New("webhook ignored")
+
+// ciRun is a CI pipeline run parsed from a webhook payload.
+type ciRun struct {
+	// Event is the webhook event type, matched against the Receiver events.
+	Event string
+	// Pipeline identifies the pipeline of the run.
+	Pipeline string
+	// Succeeded is true if the run completed successfully.
+	Succeeded bool
+}
+
+// ciVendor validates and parses the webhooks of a CI vendor for the ci
+// Receiver type.
+type ciVendor struct {
+	name string
+	// matches returns true if the request was sent by the vendor.
+	matches func(r *http.Request) bool
+	// verify returns an error if the request is not authenticated by the
+	// Receiver token.
+	verify func(r *http.Request, body []byte, token string) error
+	// parse returns the run the payload is about.
+	parse func(r *http.Request, body []byte) (ciRun, error)
+}
+
+// ciVendors are the CI vendors supported by the ci Receiver type, the first
+// vendor matching a request is used.
+var ciVendors = []ciVendor{
+	buildkiteVendor,
+	circleCIVendor,
+}
+
+var buildkiteVendor = ciVendor{
+	name: "Buildkite",
+	matches: func(r *http.Request) bool {
+		return r.Header.Get("X-Buildkite-Event") != ""
+	},
+	verify: func(r *http.Request, _ []byte, token string) error {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Buildkite-Token")), []byte(token)) != 1 {
+			return errors.New("the X-Buildkite-Token header value does not match the receiver token")
+		}
+		return nil
+	},
+	parse: func(r *http.Request, body []byte) (ciRun, error) {
+		var p struct {
+			Build struct {
+				State string `json:"state"`
+			} `json:"build"`
+			Pipeline struct {
+				Slug string `json:"slug"`
+			} `json:"pipeline"`
+		}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return ciRun{}, fmt.Errorf("cannot decode Buildkite webhook payload: %w", err)
+		}
+		event := r.Header.Get("X-Buildkite-Event")
+		return ciRun{
+			Event:     event,
+			Pipeline:  p.Pipeline.Slug,
+			Succeeded: event == "build.finished" && p.Build.State == "passed",
+		}, nil
+	},
+}
+
+var circleCIVendor = ciVendor{
+	name: "CircleCI",
+	matches: func(r *http.Request) bool {
+		return r.Header.Get("Circleci-Event-Type") != ""
+	},
+	verify: func(r *http.Request, body []byte, token string) error {
+		// The signature header holds comma-separated versioned signatures,
+		// e.g. v1=<hex HMAC-SHA256 of the body>.
+		mac := hmac.New(sha256.New, []byte(token))
+		mac.Write(body)
+		expected := mac.Sum(nil)
+		for _, sig := range strings.Split(r.Header.Get("Circleci-Signature"), ",") {
+			version, value, ok := strings.Cut(strings.TrimSpace(sig), "=")
+			if !ok || version != "v1" {
+				continue
+			}
+			if actual, err := hex.DecodeString(value); err == nil && hmac.Equal(actual, expected) {
+				return nil
+			}
+		}
+		return errors.New("the Circleci-Signature header is missing or invalid")
+	},
+	parse: func(r *http.Request, body []byte) (ciRun, error) {
+		var p struct {
+			Type    string `json:"type"`
+			Project struct {
+				Slug string `json:"slug"`
+			} `json:"project"`
+			Workflow struct {
+				Status string `json:"status"`
+			} `json:"workflow"`
+			Job struct {
+				Status string `json:"status"`
+			} `json:"job"`
+		}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return ciRun{}, fmt.Errorf("cannot decode CircleCI webhook payload: %w", err)
+		}
+		run := ciRun{Event: p.Type, Pipeline: p.Project.Slug}
+		switch p.Type {
+		case "workflow-completed":
+			run.Succeeded = p.Workflow.Status == "success"
+		case "job-completed":
+			run.Succeeded = p.Job.Status == "success"
+		}
+		return run, nil
+	},
+}
+
+// validateCIWebhook validates the webhook of a ci Receiver with the vendor
+// that sent it, and returns errWebhookIgnored for the runs that didn't
+// succeed or whose event is not in the Receiver events.
+func validateCIWebhook(logger logr.Logger, receiver apiv1.Receiver, r *http.Request, token string) error {
+	var vendor *ciVendor
+	for i := range ciVendors {
+		if ciVendors[i].matches(r) {
+			vendor = &ciVendors[i]
+			break
+		}
+	}
+	if vendor == nil {
+		return errors.New("the request was not sent by a supported CI vendor")
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read %s request body: %w", vendor.name, err)
+	}
+	if err := vendor.verify(r, b, token); err != nil {
+		return err
+	}
+	run, err := vendor.parse(r, b)
+	if err != nil {
+		return err
+	}
+
+	if len(receiver.Spec.Events) > 0 {
+		allowed := false
+		for _, e := range receiver.Spec.Events {
+			if strings.EqualFold(run.Event, e) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: the %s event '%s' is not in the receiver events", errWebhookIgnored, vendor.name, run.Event)
+		}
+	}
+	if !run.Succeeded {
+		return fmt.Errorf("%w: the %s event '%s' for '%s' is not a successful run", errWebhookIgnored, vendor.name, run.Event, run.Pipeline)
+	}
+
+	logger.Info(fmt.Sprintf("handling %s event '%s' for '%s'", vendor.name, run.Event, run.Pipeline))
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func Test_validateCIWebhook(t *testing.T) {
+	circleCISignature := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("token"))
+		mac.Write([]byte(body))
+		return "v1=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	const (
+		buildkitePassed = `{"event":"build.finished","build":{"state":"passed"},"pipeline":{"slug":"webapp"}}`
+		buildkiteFailed = `{"event":"build.finished","build":{"state":"failed"},"pipeline":{"slug":"webapp"}}`
+		circleCISuccess = `{"type":"workflow-completed","workflow":{"status":"success"},"project":{"slug":"gh/org/webapp"}}`
+		circleCIJobFail = `{"type":"job-completed","job":{"status":"failed"},"project":{"slug":"gh/org/webapp"}}`
+	)
+
+	tests := []struct {
+		name        string
+		events      []string
+		headers     map[string]string
+		body        string
+		wantErr     bool
+		wantIgnored bool
+	}{
+		{
+			name:    "Buildkite passed build",
+			headers: map[string]string{"X-Buildkite-Event": "build.finished", "X-Buildkite-Token": "token"},
+			body:    buildkitePassed,
+		},
+		{
+			name:        "Buildkite failed build",
+			headers:     map[string]string{"X-Buildkite-Event": "build.finished", "X-Buildkite-Token": "token"},
+			body:        buildkiteFailed,
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:        "Buildkite started build",
+			headers:     map[string]string{"X-Buildkite-Event": "build.started", "X-Buildkite-Token": "token"},
+			body:        `{"event":"build.started","build":{"state":"running"}}`,
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:    "Buildkite invalid token",
+			headers: map[string]string{"X-Buildkite-Event": "build.finished", "X-Buildkite-Token": "invalid"},
+			body:    buildkitePassed,
+			wantErr: true,
+		},
+		{
+			name:    "CircleCI successful workflow",
+			headers: map[string]string{"Circleci-Event-Type": "workflow-completed", "Circleci-Signature": circleCISignature(circleCISuccess)},
+			body:    circleCISuccess,
+		},
+		{
+			name:   "CircleCI signature among other versions",
+			events: []string{"workflow-completed"},
+			headers: map[string]string{
+				"Circleci-Event-Type": "workflow-completed",
+				"Circleci-Signature":  "v0=abc, " + circleCISignature(circleCISuccess),
+			},
+			body: circleCISuccess,
+		},
+		{
+			name:        "CircleCI failed job",
+			headers:     map[string]string{"Circleci-Event-Type": "job-completed", "Circleci-Signature": circleCISignature(circleCIJobFail)},
+			body:        circleCIJobFail,
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:    "CircleCI invalid signature",
+			headers: map[string]string{"Circleci-Event-Type": "workflow-completed", "Circleci-Signature": circleCISignature("{}")},
+			body:    circleCISuccess,
+			wantErr: true,
+		},
+		{
+			name:        "event not in the receiver events",
+			events:      []string{"job-completed"},
+			headers:     map[string]string{"Circleci-Event-Type": "workflow-completed", "Circleci-Signature": circleCISignature(circleCISuccess)},
+			body:        circleCISuccess,
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:    "unsupported vendor",
+			headers: map[string]string{"X-Semaphore-Event": "pipeline_done"},
+			body:    `{}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			receiver := apiv1.Receiver{
+				Spec: apiv1.ReceiverSpec{
+					Type:   apiv1.CIReceiver,
+					Events: tt.events,
+				},
+			}
+			req := httptest.NewRequest("POST", "/hook/", bytes.NewBufferString(tt.body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			err := validateCIWebhook(logr.Discard(), receiver, req, "token")
+			if !tt.wantErr {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(errors.Is(err, errWebhookIgnored)).To(Equal(tt.wantIgnored))
+		})
+	}
+}
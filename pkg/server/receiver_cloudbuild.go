@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// cloudBuildSuccessStatus is the status of the successful Cloud Build builds.
+const cloudBuildSuccessStatus = "SUCCESS"
+
+// cloudBuildPushMessage is the Pub/Sub push message of the cloud-builds topic.
+type cloudBuildPushMessage struct {
+	Message struct {
+		Attributes map[string]string `json:"attributes"`
+		Data       string            `json:"data"`
+		MessageID  string            `json:"messageId"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// cloudBuild is the Cloud Build resource carried by the Pub/Sub messages.
+type cloudBuild struct {
+	ID      string   `json:"id"`
+	Status  string   `json:"status"`
+	Images  []string `json:"images"`
+	Results struct {
+		Images []struct {
+			Name   string `json:"name"`
+			Digest string `json:"digest"`
+		} `json:"images"`
+	} `json:"results"`
+}
+
+// builtImages returns the names of the images built by the build.
+func (b cloudBuild) builtImages() []string {
+	images := append([]string{}, b.Images...)
+	for _, image := range b.Results.Images {
+		images = append(images, image.Name)
+	}
+	return images
+}
+
+// validateCloudBuildWebhook unwraps the Cloud Build resource of the Pub/Sub
+// push message of a cloudbuild Receiver. It returns errWebhookIgnored for
+// the builds that didn't succeed, and for the builds that didn't build any
+// of the images in the Receiver events, when set.
+func validateCloudBuildWebhook(logger logr.Logger, receiver apiv1.Receiver, r *http.Request) error {
+	var p cloudBuildPushMessage
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		return fmt.Errorf("cannot decode Cloud Build Pub/Sub message: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(p.Message.Data)
+	if err != nil {
+		return fmt.Errorf("cannot decode Cloud Build Pub/Sub message data: %w", err)
+	}
+	var build cloudBuild
+	if err := json.Unmarshal(raw, &build); err != nil {
+		return fmt.Errorf("cannot decode Cloud Build resource: %w", err)
+	}
+
+	if build.Status != cloudBuildSuccessStatus {
+		return fmt.Errorf("%w: Cloud Build build '%s' has status '%s'", errWebhookIgnored, build.ID, build.Status)
+	}
+
+	if len(receiver.Spec.Events) > 0 {
+		matched := ""
+		for _, image := range build.builtImages() {
+			for _, e := range receiver.Spec.Events {
+				if image == e || imageRepository(image) == e {
+					matched = image
+					break
+				}
+			}
+			if matched != "" {
+				break
+			}
+		}
+		if matched == "" {
+			return fmt.Errorf("%w: Cloud Build build '%s' didn't build any of the receiver images", errWebhookIgnored, build.ID)
+		}
+		logger.Info(fmt.Sprintf("handling Cloud Build build '%s' for image %s", build.ID, matched))
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("handling Cloud Build build '%s'", build.ID))
+	return nil
+}
+
+// imageRepository returns the repository of the given image reference,
+// without its tag and digest.
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func Test_validateCloudBuildWebhook(t *testing.T) {
+	message := func(build string) string {
+		b, _ := json.Marshal(map[string]interface{}{
+			"message": map[string]interface{}{
+				"attributes": map[string]string{"buildId": "b1"},
+				"data":       base64.StdEncoding.EncodeToString([]byte(build)),
+				"messageId":  "1",
+			},
+			"subscription": "projects/my-project/subscriptions/flux",
+		})
+		return string(b)
+	}
+	const (
+		success = `{"id":"b1","status":"SUCCESS","images":["us-docker.pkg.dev/my-project/apps/webapp:v1.0.0"],` +
+			`"results":{"images":[{"name":"us-docker.pkg.dev/my-project/apps/webapp:v1.0.0","digest":"sha256:abc"}]}}`
+		failure = `{"id":"b1","status":"FAILURE","images":["us-docker.pkg.dev/my-project/apps/webapp:v1.0.0"]}`
+	)
+
+	tests := []struct {
+		name        string
+		events      []string
+		body        string
+		wantErr     bool
+		wantIgnored bool
+	}{
+		{
+			name: "successful build",
+			body: message(success),
+		},
+		{
+			name:   "successful build of the receiver image repository",
+			events: []string{"us-docker.pkg.dev/my-project/apps/webapp"},
+			body:   message(success),
+		},
+		{
+			name:   "successful build of the receiver image",
+			events: []string{"us-docker.pkg.dev/my-project/apps/webapp:v1.0.0"},
+			body:   message(success),
+		},
+		{
+			name:        "successful build of another image",
+			events:      []string{"us-docker.pkg.dev/my-project/apps/backend"},
+			body:        message(success),
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:        "failed build",
+			body:        message(failure),
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:    "invalid build",
+			body:    message(`{"status":1}`),
+			wantErr: true,
+		},
+		{
+			name:    "invalid message",
+			body:    `{"message":{"data":"!"}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			receiver := apiv1.Receiver{
+				Spec: apiv1.ReceiverSpec{
+					Type:   apiv1.CloudBuildReceiver,
+					Events: tt.events,
+				},
+			}
+			req := httptest.NewRequest("POST", "/hook/", bytes.NewBufferString(tt.body))
+
+			err := validateCloudBuildWebhook(logr.Discard(), receiver, req)
+			if !tt.wantErr {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(errors.Is(err, errWebhookIgnored)).To(Equal(tt.wantIgnored))
+		})
+	}
+}
+
+func Test_imageRepository(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(imageRepository("us-docker.pkg.dev/p/r/webapp:v1")).To(Equal("us-docker.pkg.dev/p/r/webapp"))
+	g.Expect(imageRepository("us-docker.pkg.dev/p/r/webapp@sha256:abc")).To(Equal("us-docker.pkg.dev/p/r/webapp"))
+	g.Expect(imageRepository("localhost:5000/webapp")).To(Equal("localhost:5000/webapp"))
+	g.Expect(imageRepository("localhost:5000/webapp:v1@sha256:abc")).To(Equal("localhost:5000/webapp"))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// WebhookPathsEndpoint is the path of the debug endpoint listing the webhook
+// paths registered with the receiver server.
+const WebhookPathsEndpoint = "/debug/receivers"
+
+// webhookPathInfo describes a Receiver webhook path and whether requests to
+// it are currently accepted.
+type webhookPathInfo struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	Type        string `json:"type"`
+	WebhookPath string `json:"webhookPath"`
+	Ready       bool   `json:"ready"`
+	Suspended   bool   `json:"suspended"`
+}
+
+// WebhookPathsHandler returns an http.Handler that lists the webhook paths of
+// the Receivers of the namespace given in the query, or of all namespaces if
+// no namespace is given, along with their Ready and suspended state. The
+// request must bear the token of a user allowed to list the Receivers of the
+// namespace, as the webhook paths are the only credential of the generic
+// Receivers. The handler is meant to be registered with the metrics server,
+// next to the pprof endpoints.
+func WebhookPathsHandler(kubeClient client.Client, logger logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		namespace := r.URL.Query().Get("namespace")
+		if status, err := authorizeList(r.Context(), kubeClient, r, "receivers", namespace); err != nil {
+			logger.V(1).Info("rejecting webhook paths request", "namespace", namespace, "error", err.Error())
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		var receivers apiv1.ReceiverList
+		if err := kubeClient.List(r.Context(), &receivers, client.InNamespace(namespace)); err != nil {
+			logger.Error(err, "unable to list receivers")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		paths := make([]webhookPathInfo, 0, len(receivers.Items))
+		for i := range receivers.Items {
+			receiver := &receivers.Items[i]
+			if receiver.Status.WebhookPath == "" {
+				continue
+			}
+			paths = append(paths, webhookPathInfo{
+				Name:        receiver.Name,
+				Namespace:   receiver.Namespace,
+				Type:        receiver.Spec.Type,
+				WebhookPath: receiver.Status.WebhookPath,
+				Ready:       conditions.IsReady(receiver),
+				Suspended:   receiver.Spec.Suspend,
+			})
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			if paths[i].Namespace != paths[j].Namespace {
+				return paths[i].Namespace < paths[j].Namespace
+			}
+			return paths[i].Name < paths[j].Name
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(paths); err != nil {
+			logger.Error(err, "unable to write webhook paths")
+		}
+	})
+}
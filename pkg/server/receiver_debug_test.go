@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/fluxcd/pkg/apis/meta"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func TestWebhookPathsHandler(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+
+	kclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&apiv1.Receiver{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "b"},
+			Spec:       apiv1.ReceiverSpec{Type: apiv1.GitHubReceiver},
+			Status: apiv1.ReceiverStatus{
+				WebhookPath: "/hook/ready",
+				Conditions:  []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionTrue}},
+			},
+		},
+		&apiv1.Receiver{
+			ObjectMeta: metav1.ObjectMeta{Name: "suspended", Namespace: "a"},
+			Spec:       apiv1.ReceiverSpec{Type: apiv1.GenericReceiver, Suspend: true},
+			Status: apiv1.ReceiverStatus{
+				WebhookPath: "/hook/suspended",
+				Conditions:  []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionFalse}},
+			},
+		},
+		&apiv1.Receiver{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-path", Namespace: "a"},
+			Spec:       apiv1.ReceiverSpec{Type: apiv1.GenericReceiver},
+		},
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			switch o := obj.(type) {
+			case *authnv1.TokenReview:
+				if o.Spec.Token == "viewer" || o.Spec.Token == "admin" {
+					o.Status.Authenticated = true
+					o.Status.User.Username = o.Spec.Token
+				}
+			case *authzv1.SubjectAccessReview:
+				attrs := o.Spec.ResourceAttributes
+				o.Status.Allowed = attrs.Verb == "list" && attrs.Resource == "receivers" &&
+					(o.Spec.User == "admin" || attrs.Namespace == "b")
+			default:
+				return c.Create(ctx, obj, opts...)
+			}
+			return nil
+		},
+	}).Build()
+
+	handler := WebhookPathsHandler(kclient, log.Log)
+	get := func(token, namespace string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, WebhookPathsEndpoint+"?namespace="+namespace, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("admin", "")
+	g.Expect(rr.Code).To(Equal(http.StatusOK))
+
+	var paths []webhookPathInfo
+	g.Expect(json.Unmarshal(rr.Body.Bytes(), &paths)).To(Succeed())
+	g.Expect(paths).To(Equal([]webhookPathInfo{
+		{Name: "suspended", Namespace: "a", Type: apiv1.GenericReceiver, WebhookPath: "/hook/suspended", Ready: false, Suspended: true},
+		{Name: "ready", Namespace: "b", Type: apiv1.GitHubReceiver, WebhookPath: "/hook/ready", Ready: true, Suspended: false},
+	}))
+
+	rr = get("viewer", "b")
+	g.Expect(rr.Code).To(Equal(http.StatusOK))
+	g.Expect(json.Unmarshal(rr.Body.Bytes(), &paths)).To(Succeed())
+	g.Expect(paths).To(HaveLen(1))
+	g.Expect(paths[0].Name).To(Equal("ready"))
+
+	g.Expect(get("", "").Code).To(Equal(http.StatusUnauthorized))
+	g.Expect(get("unknown", "").Code).To(Equal(http.StatusUnauthorized))
+	g.Expect(get("viewer", "").Code).To(Equal(http.StatusForbidden))
+	g.Expect(get("viewer", "a").Code).To(Equal(http.StatusForbidden))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, WebhookPathsEndpoint, nil))
+	g.Expect(rr.Code).To(Equal(http.StatusMethodNotAllowed))
+}
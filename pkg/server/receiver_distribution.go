@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// distributionPushAction is the action of the registry events sent when a
+// manifest is pushed, the only action handled when the Receiver has no events.
+const distributionPushAction = "push"
+
+// distributionEnvelope is the notification envelope sent by the registries
+// implementing the CNCF Distribution notifications, e.g. registry:2, Zot
+// and Harbor core.
+type distributionEnvelope struct {
+	Events []distributionEvent `json:"events"`
+}
+
+type distributionEvent struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Target struct {
+		MediaType  string `json:"mediaType"`
+		Digest     string `json:"digest"`
+		Repository string `json:"repository"`
+		Tag        string `json:"tag"`
+	} `json:"target"`
+}
+
+// isManifest returns true if the event target is a manifest or an index,
+// as opposed to a blob.
+func (e distributionEvent) isManifest() bool {
+	return e.Target.Tag != "" ||
+		strings.Contains(e.Target.MediaType, "manifest") ||
+		strings.Contains(e.Target.MediaType, "index")
+}
+
+// validateDistributionWebhook validates the notification envelope of a
+// distribution Receiver. The request is authenticated by its Authorization
+// header, which must hold the Receiver token, optionally as a bearer token.
+// It returns errWebhookIgnored when no event of the envelope is about a
+// manifest with one of the Receiver events, push by default.
+func validateDistributionWebhook(logger logr.Logger, receiver apiv1.Receiver, r *http.Request, token string) error {
+	auth := r.Header.Get("Authorization")
+	if subtle.ConstantTimeCompare([]byte(auth), []byte(token)) != 1 &&
+		subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+		return errors.New("the Authorization header value does not match the receiver token")
+	}
+
+	var envelope distributionEnvelope
+	if err := json.NewDecoder(r.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("cannot decode registry notification envelope: %w", err)
+	}
+
+	actions := receiver.Spec.Events
+	if len(actions) == 0 {
+		actions = []string{distributionPushAction}
+	}
+	for _, event := range envelope.Events {
+		if !event.isManifest() {
+			continue
+		}
+		for _, action := range actions {
+			if strings.EqualFold(event.Action, action) {
+				logger.Info(fmt.Sprintf("handling registry %s event for %s:%s@%s",
+					event.Action, event.Target.Repository, event.Target.Tag, event.Target.Digest))
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: no registry event matches the receiver events among %d events",
+		errWebhookIgnored, len(envelope.Events))
+}
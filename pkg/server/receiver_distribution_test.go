@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func Test_validateDistributionWebhook(t *testing.T) {
+	const (
+		manifestPush = `{"events":[
+			{"action":"push","target":{"mediaType":"application/octet-stream","digest":"sha256:b1","repository":"webapp"}},
+			{"action":"push","target":{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:m1","repository":"webapp","tag":"v1.0.0"}}
+		]}`
+		blobPush     = `{"events":[{"action":"push","target":{"mediaType":"application/octet-stream","digest":"sha256:b1","repository":"webapp"}}]}`
+		manifestPull = `{"events":[{"action":"pull","target":{"mediaType":"application/vnd.docker.distribution.manifest.v2+json","digest":"sha256:m1","repository":"webapp","tag":"latest"}}]}`
+	)
+
+	tests := []struct {
+		name        string
+		events      []string
+		auth        string
+		body        string
+		wantErr     bool
+		wantIgnored bool
+	}{
+		{
+			name: "manifest push with token",
+			auth: "token",
+			body: manifestPush,
+		},
+		{
+			name: "manifest push with bearer token",
+			auth: "Bearer token",
+			body: manifestPush,
+		},
+		{
+			name:    "invalid token",
+			auth:    "Bearer invalid",
+			body:    manifestPush,
+			wantErr: true,
+		},
+		{
+			name:        "blob push",
+			auth:        "token",
+			body:        blobPush,
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:        "manifest pull",
+			auth:        "token",
+			body:        manifestPull,
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:   "manifest pull in receiver events",
+			events: []string{"pull"},
+			auth:   "token",
+			body:   manifestPull,
+		},
+		{
+			name:    "invalid envelope",
+			auth:    "token",
+			body:    `{"events":{}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			receiver := apiv1.Receiver{
+				Spec: apiv1.ReceiverSpec{
+					Type:   apiv1.DistributionReceiver,
+					Events: tt.events,
+				},
+			}
+			req := httptest.NewRequest("POST", "/hook/", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/vnd.docker.distribution.events.v1+json")
+			req.Header.Set("Authorization", tt.auth)
+
+			err := validateDistributionWebhook(logr.Discard(), receiver, req, "token")
+			if !tt.wantErr {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(errors.Is(err, errWebhookIgnored)).To(Equal(tt.wantIgnored))
+		})
+	}
+}
@@ -26,15 +26,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v64/github"
 	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/logger"
 
@@ -116,6 +119,69 @@ func Test_handlePayload(t *testing.T) {
 			},
 			expectedResponseCode: http.StatusOK,
 		},
+		{
+			name: "gitlab receiver with custom token key",
+			receiver: &apiv1.Receiver{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "gitlab-receiver",
+				},
+				Spec: apiv1.ReceiverSpec{
+					Type: apiv1.GitLabReceiver,
+					SecretRef: meta.LocalObjectReference{
+						Name: "token",
+					},
+					SecretKey: "webhook-token",
+				},
+				Status: apiv1.ReceiverStatus{
+					WebhookPath: apiv1.ReceiverWebhookPath,
+					Conditions:  []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionTrue}},
+				},
+			},
+			headers: map[string]string{
+				"X-Gitlab-Token": "custom-token",
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "token",
+				},
+				Data: map[string][]byte{
+					"token":         []byte("token"),
+					"webhook-token": []byte("custom-token"),
+				},
+			},
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			name: "receiver with missing custom token key",
+			receiver: &apiv1.Receiver{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "gitlab-receiver",
+				},
+				Spec: apiv1.ReceiverSpec{
+					Type: apiv1.GitLabReceiver,
+					SecretRef: meta.LocalObjectReference{
+						Name: "token",
+					},
+					SecretKey: "webhook-token",
+				},
+				Status: apiv1.ReceiverStatus{
+					WebhookPath: apiv1.ReceiverWebhookPath,
+					Conditions:  []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionTrue}},
+				},
+			},
+			headers: map[string]string{
+				"X-Gitlab-Token": "token",
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "token",
+				},
+				Data: map[string][]byte{
+					"token": []byte("token"),
+				},
+			},
+			expectedResponseCode: http.StatusBadRequest,
+		},
 		{
 			name: "cdevents receiver",
 			receiver: &apiv1.Receiver{
@@ -487,7 +553,7 @@ func Test_handlePayload(t *testing.T) {
 					SecretRef: meta.LocalObjectReference{
 						Name: "token",
 					},
-					Resources: []apiv1.CrossNamespaceObjectReference{
+					Resources: []apiv1.ReceiverObjectReference{
 						{
 							Kind: apiv1.ReceiverKind,
 							MatchLabels: map[string]string{
@@ -522,7 +588,7 @@ func Test_handlePayload(t *testing.T) {
 					SecretRef: meta.LocalObjectReference{
 						Name: "token",
 					},
-					Resources: []apiv1.CrossNamespaceObjectReference{
+					Resources: []apiv1.ReceiverObjectReference{
 						{
 							APIVersion: apiv1.GroupVersion.String(),
 							Kind:       apiv1.ReceiverKind,
@@ -556,7 +622,7 @@ func Test_handlePayload(t *testing.T) {
 					SecretRef: meta.LocalObjectReference{
 						Name: "token",
 					},
-					Resources: []apiv1.CrossNamespaceObjectReference{
+					Resources: []apiv1.ReceiverObjectReference{
 						{
 							APIVersion: apiv1.GroupVersion.String(),
 							Kind:       apiv1.ReceiverKind,
@@ -620,7 +686,7 @@ func Test_handlePayload(t *testing.T) {
 					SecretRef: meta.LocalObjectReference{
 						Name: "token",
 					},
-					Resources: []apiv1.CrossNamespaceObjectReference{
+					Resources: []apiv1.ReceiverObjectReference{
 						{
 							APIVersion: apiv1.GroupVersion.String(),
 							Kind:       apiv1.ReceiverKind,
@@ -679,7 +745,7 @@ func Test_handlePayload(t *testing.T) {
 					SecretRef: meta.LocalObjectReference{
 						Name: "token",
 					},
-					Resources: []apiv1.CrossNamespaceObjectReference{
+					Resources: []apiv1.ReceiverObjectReference{
 						{
 							APIVersion: apiv1.GroupVersion.String(),
 							Kind:       apiv1.ReceiverKind,
@@ -713,7 +779,7 @@ func Test_handlePayload(t *testing.T) {
 					SecretRef: meta.LocalObjectReference{
 						Name: "token",
 					},
-					Resources: []apiv1.CrossNamespaceObjectReference{
+					Resources: []apiv1.ReceiverObjectReference{
 						{
 							APIVersion: apiv1.GroupVersion.String(),
 							Kind:       apiv1.ReceiverKind,
@@ -786,9 +852,10 @@ func Test_handlePayload(t *testing.T) {
 
 			client := builder.Build()
 			s := ReceiverServer{
-				port:       "",
-				logger:     logger.NewLogger(logger.Options{}),
-				kubeClient: client,
+				port:               "",
+				logger:             logger.NewLogger(logger.Options{}),
+				kubeClient:         client,
+				arbitraryResources: true,
 			}
 
 			data, err := json.Marshal(tt.payload)
@@ -827,3 +894,459 @@ func Test_handlePayload(t *testing.T) {
 		})
 	}
 }
+
+func Test_handlePayload_async(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	receiver := &apiv1.Receiver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "async-receiver",
+			Namespace: "default",
+		},
+		Spec: apiv1.ReceiverSpec{
+			Type: apiv1.GenericReceiver,
+			SecretRef: meta.LocalObjectReference{
+				Name: "token",
+			},
+			Resources: []apiv1.ReceiverObjectReference{
+				{
+					APIVersion: apiv1.GroupVersion.String(),
+					Kind:       apiv1.ReceiverKind,
+					Name:       "dummy-resource",
+				},
+			},
+		},
+		Status: apiv1.ReceiverStatus{
+			WebhookPath: apiv1.ReceiverWebhookPath,
+			Conditions:  []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionTrue}},
+		},
+	}
+	resource := &apiv1.Receiver{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       apiv1.ReceiverKind,
+			APIVersion: apiv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dummy-resource",
+			Namespace: "default",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "token",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"token": []byte("token"),
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	kclient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(receiver, resource, secret).
+		WithIndex(&apiv1.Receiver{}, WebhookPathIndexKey, IndexReceiverWebhookPath).
+		Build()
+
+	s := NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{AsyncWorkers: 1, ArbitraryResources: true})
+
+	req := httptest.NewRequest("POST", "/hook/", bytes.NewBuffer(nil))
+	rr := httptest.NewRecorder()
+	s.handlePayload()(rr, req)
+	g.Expect(rr.Result().StatusCode).To(gomega.Equal(http.StatusAccepted))
+
+	// The resource is only annotated once a worker picks up the request.
+	g.Expect(testutil.ToFloat64(receiverQueueDepth.WithLabelValues(receiver.Name, receiver.Namespace))).To(gomega.Equal(float64(1)))
+	obj := &apiv1.Receiver{}
+	g.Expect(kclient.Get(context.TODO(), client.ObjectKeyFromObject(resource), obj)).To(gomega.Succeed())
+	g.Expect(obj.GetAnnotations()).ToNot(gomega.HaveKey(meta.ReconcileRequestAnnotation))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go s.processQueue(stopCh)
+
+	g.Eventually(func() map[string]string {
+		obj := &apiv1.Receiver{}
+		_ = kclient.Get(context.TODO(), client.ObjectKeyFromObject(resource), obj)
+		return obj.GetAnnotations()
+	}, "2s", "0.1s").Should(gomega.HaveKey(meta.ReconcileRequestAnnotation))
+	g.Eventually(func() float64 {
+		return testutil.ToFloat64(receiverQueueProcessed.WithLabelValues(receiver.Name, receiver.Namespace, "success"))
+	}, "2s", "0.1s").Should(gomega.Equal(float64(1)))
+	g.Expect(testutil.ToFloat64(receiverQueueDepth.WithLabelValues(receiver.Name, receiver.Namespace))).To(gomega.Equal(float64(0)))
+}
+
+func Test_processQueue_stop(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	s := NewReceiverServer("", logger.NewLogger(logger.Options{}), fake.NewClientBuilder().Build(), ReceiverServerOptions{AsyncWorkers: 1})
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.processQueue(stopCh)
+		close(done)
+	}()
+
+	close(stopCh)
+	g.Eventually(done, "2s", "0.1s").Should(gomega.BeClosed())
+}
+
+func Test_requestReconciliation_arbitraryResources(t *testing.T) {
+	resource := &apiv1.Receiver{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       apiv1.ReceiverKind,
+			APIVersion: apiv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dummy-resource",
+			Namespace: "default",
+		},
+	}
+	ref := apiv1.ReceiverObjectReference{
+		APIVersion: apiv1.GroupVersion.String(),
+		Kind:       apiv1.ReceiverKind,
+		Name:       resource.Name,
+	}
+
+	tests := []struct {
+		name               string
+		arbitraryResources bool
+		wantErr            string
+	}{
+		{
+			name:               "rejects non-Flux kinds when disabled",
+			arbitraryResources: false,
+			wantErr:            "kind 'Receiver' is not a Flux kind, enable the ReceiverArbitraryResources feature gate to target it",
+		},
+		{
+			name:               "annotates non-Flux kinds when enabled",
+			arbitraryResources: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(apiv1.AddToScheme(scheme)).To(gomega.Succeed())
+			kclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(resource.DeepCopy()).Build()
+
+			s := NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{ArbitraryResources: tt.arbitraryResources})
+			err := s.requestReconciliation(context.TODO(), logger.NewLogger(logger.Options{}), ref, apiv1.Receiver{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+			})
+
+			obj := &apiv1.Receiver{}
+			g.Expect(kclient.Get(context.TODO(), client.ObjectKeyFromObject(resource), obj)).To(gomega.Succeed())
+			if tt.wantErr != "" {
+				g.Expect(err).To(gomega.MatchError(tt.wantErr))
+				g.Expect(obj.GetAnnotations()).ToNot(gomega.HaveKey(meta.ReconcileRequestAnnotation))
+				return
+			}
+			g.Expect(err).ToNot(gomega.HaveOccurred())
+			g.Expect(obj.GetAnnotations()).To(gomega.HaveKey(meta.ReconcileRequestAnnotation))
+		})
+	}
+}
+
+type recordingDispatcher struct {
+	events []*eventv1.Event
+}
+
+func (d *recordingDispatcher) DispatchEvent(_ context.Context, event *eventv1.Event) {
+	d.events = append(d.events, event)
+}
+
+func Test_requestReconciliation_notify(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	resource := &apiv1.Receiver{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       apiv1.ReceiverKind,
+			APIVersion: apiv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dummy-resource",
+			Namespace: "default",
+		},
+	}
+	receiver := apiv1.Receiver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-receiver",
+			Namespace: "default",
+		},
+	}
+	ref := apiv1.ReceiverObjectReference{
+		APIVersion: apiv1.GroupVersion.String(),
+		Kind:       apiv1.ReceiverKind,
+		Name:       resource.Name,
+		Action:     apiv1.NotifyAction,
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).To(gomega.Succeed())
+	kclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(resource.DeepCopy()).Build()
+
+	s := NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{ArbitraryResources: true})
+	err := s.requestReconciliation(context.TODO(), logger.NewLogger(logger.Options{}), ref, receiver)
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("event dispatching is not enabled")))
+
+	dispatcher := &recordingDispatcher{}
+	s = NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{ArbitraryResources: true, Dispatcher: dispatcher})
+	g.Expect(s.requestReconciliation(context.TODO(), logger.NewLogger(logger.Options{}), ref, receiver)).To(gomega.Succeed())
+
+	g.Expect(dispatcher.events).To(gomega.HaveLen(1))
+	event := dispatcher.events[0]
+	g.Expect(event.InvolvedObject.Kind).To(gomega.Equal(apiv1.ReceiverKind))
+	g.Expect(event.InvolvedObject.Name).To(gomega.Equal(resource.Name))
+	g.Expect(event.InvolvedObject.Namespace).To(gomega.Equal(resource.Namespace))
+	g.Expect(event.Reason).To(gomega.Equal(apiv1.WebhookReceivedReason))
+	g.Expect(event.Severity).To(gomega.Equal(eventv1.EventSeverityInfo))
+	g.Expect(event.Metadata).To(gomega.HaveKeyWithValue(eventv1.Group+"/receiver", "default/test-receiver"))
+
+	obj := &apiv1.Receiver{}
+	g.Expect(kclient.Get(context.TODO(), client.ObjectKeyFromObject(resource), obj)).To(gomega.Succeed())
+	g.Expect(obj.GetAnnotations()).ToNot(gomega.HaveKey(meta.ReconcileRequestAnnotation))
+}
+
+func Test_requestReconciliations_lastTriggers(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	resource := &apiv1.Receiver{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       apiv1.ReceiverKind,
+			APIVersion: apiv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dummy-resource",
+			Namespace: "default",
+		},
+	}
+	receiver := &apiv1.Receiver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-receiver",
+			Namespace: "default",
+		},
+		Spec: apiv1.ReceiverSpec{
+			Type: apiv1.GenericReceiver,
+			Resources: []apiv1.ReceiverObjectReference{
+				{
+					APIVersion: apiv1.GroupVersion.String(),
+					Kind:       apiv1.ReceiverKind,
+					Name:       resource.Name,
+				},
+				{
+					APIVersion:  apiv1.GroupVersion.String(),
+					Kind:        apiv1.ReceiverKind,
+					Name:        "*",
+					MatchLabels: map[string]string{"app": "podinfo"},
+				},
+				{
+					APIVersion: apiv1.GroupVersion.String(),
+					Kind:       apiv1.ReceiverKind,
+					Name:       "missing-resource",
+					Namespace:  "other",
+				},
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).To(gomega.Succeed())
+	kclient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(receiver, resource).
+		WithStatusSubresource(&apiv1.Receiver{}).
+		Build()
+
+	s := NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{ArbitraryResources: true})
+	for i := 0; i < 4; i++ {
+		obj := &apiv1.Receiver{}
+		g.Expect(kclient.Get(context.TODO(), client.ObjectKeyFromObject(receiver), obj)).To(gomega.Succeed())
+		g.Expect(s.requestReconciliations(context.TODO(), logger.NewLogger(logger.Options{}), *obj)).To(gomega.BeTrue())
+	}
+
+	obj := &apiv1.Receiver{}
+	g.Expect(kclient.Get(context.TODO(), client.ObjectKeyFromObject(receiver), obj)).To(gomega.Succeed())
+	g.Expect(obj.Status.LastTriggers).To(gomega.HaveLen(apiv1.MaxReceiverTriggers))
+
+	latest := obj.Status.LastTriggers[:3]
+	g.Expect(latest[0].Resource).To(gomega.Equal("Receiver/default/dummy-resource"))
+	g.Expect(latest[0].Result).To(gomega.Equal(apiv1.TriggerAccepted))
+	g.Expect(latest[1].Resource).To(gomega.Equal("Receiver/default/*"))
+	g.Expect(latest[1].Result).To(gomega.Equal(apiv1.TriggerFiltered))
+	g.Expect(latest[2].Resource).To(gomega.Equal("Receiver/other/missing-resource"))
+	g.Expect(latest[2].Result).To(gomega.Equal(apiv1.TriggerFailed))
+	g.Expect(latest[2].Message).To(gomega.ContainSubstring("not found"))
+}
+
+func Test_annotate_patchRateLimit(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	resource := &apiv1.Receiver{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       apiv1.ReceiverKind,
+			APIVersion: apiv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dummy-resource",
+			Namespace: "default",
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).To(gomega.Succeed())
+	kclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(resource.DeepCopy()).Build()
+
+	s := NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{ArbitraryResources: true, PatchQPS: 0.1, PatchBurst: 1})
+
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(apiv1.GroupVersion.WithKind(apiv1.ReceiverKind))
+	g.Expect(kclient.Get(context.TODO(), client.ObjectKeyFromObject(resource), obj)).To(gomega.Succeed())
+
+	// The first patch uses the burst.
+	g.Expect(s.annotate(context.TODO(), obj)).To(gomega.Succeed())
+
+	// The second patch would wait for 10s and exceeds the deadline.
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	err := s.annotate(ctx, obj)
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("rate limit exceeded"))
+}
+
+func Test_IndexReceiverWebhookPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		webhookPath string
+		want        []string
+	}{
+		{
+			name:        "empty webhook path",
+			webhookPath: "",
+			want:        nil,
+		},
+		{
+			name:        "flat webhook path",
+			webhookPath: "/hook/abc",
+			want:        []string{"/hook/abc", "/hook/default/abc"},
+		},
+		{
+			name:        "namespaced webhook path",
+			webhookPath: "/hook/default/abc",
+			want:        []string{"/hook/abc", "/hook/default/abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			receiver := &apiv1.Receiver{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-receiver",
+					Namespace: "default",
+				},
+				Status: apiv1.ReceiverStatus{
+					WebhookPath: tt.webhookPath,
+				},
+			}
+			g.Expect(IndexReceiverWebhookPath(receiver)).To(gomega.Equal(tt.want))
+		})
+	}
+}
+
+func Test_resourcesForEvent(t *testing.T) {
+	repository := apiv1.ReceiverObjectReference{Kind: "GitRepository", Name: "app"}
+	push := apiv1.ReceiverObjectReference{Kind: "GitRepository", Name: "app-push", Events: []string{"push"}}
+	tag := apiv1.ReceiverObjectReference{Kind: "OCIRepository", Name: "app-tag", Events: []string{"Tag Push Hook", "release"}}
+	resources := []apiv1.ReceiverObjectReference{repository, push, tag}
+
+	tests := []struct {
+		name  string
+		event string
+		want  []apiv1.ReceiverObjectReference
+	}{
+		{
+			name:  "push event",
+			event: "push",
+			want:  []apiv1.ReceiverObjectReference{repository, push},
+		},
+		{
+			name:  "case insensitive event",
+			event: "tag push hook",
+			want:  []apiv1.ReceiverObjectReference{repository, tag},
+		},
+		{
+			name:  "no event type",
+			event: "",
+			want:  []apiv1.ReceiverObjectReference{repository},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(resourcesForEvent(resources, tt.event)).To(gomega.Equal(tt.want))
+		})
+	}
+}
+
+// hungTransport is an http.RoundTripper never responding before the
+// context of the request is done.
+type hungTransport struct{}
+
+func (hungTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func Test_authenticateGCRRequest_timeout(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	receiver := &apiv1.Receiver{
+		Spec: apiv1.ReceiverSpec{
+			Timeout: &metav1.Duration{Duration: 100 * time.Millisecond},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), receiver.GetTimeout())
+	defer cancel()
+
+	start := time.Now()
+	err := authenticateGCRRequest(ctx, &http.Client{Transport: hungTransport{}}, "Bearer token", len("Bearer "))
+	g.Expect(err).To(gomega.MatchError(context.DeadlineExceeded))
+	g.Expect(time.Since(start)).To(gomega.BeNumerically("<", 5*time.Second))
+
+	g.Expect((&apiv1.Receiver{}).GetTimeout()).To(gomega.Equal(15 * time.Second))
+}
+
+func Test_requestReconciliation_imageAutomation(t *testing.T) {
+	for _, kind := range []string{"ImageRepository", "ImagePolicy", "ImageUpdateAutomation"} {
+		t.Run(kind, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			obj := newUnstructured(kind, "podinfo", map[string]interface{}{})
+			scheme := runtime.NewScheme()
+			g.Expect(apiv1.AddToScheme(scheme)).To(gomega.Succeed())
+			kclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj).Build()
+
+			s := NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{})
+			err := s.requestReconciliation(context.TODO(), logger.NewLogger(logger.Options{}), apiv1.ReceiverObjectReference{
+				Kind: kind,
+				Name: "podinfo",
+			}, apiv1.Receiver{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+			})
+			g.Expect(err).ToNot(gomega.HaveOccurred())
+
+			g.Expect(kclient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)).To(gomega.Succeed())
+			g.Expect(obj.GetAPIVersion()).To(gomega.Equal("image.toolkit.fluxcd.io/v1beta2"))
+			g.Expect(obj.GetAnnotations()).To(gomega.HaveKey(meta.ReconcileRequestAnnotation))
+		})
+	}
+}
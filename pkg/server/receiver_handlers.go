@@ -33,6 +33,7 @@ import (
 
 	cdevents "github.com/cdevents/sdk-go/pkg/api"
 	cdevents04 "github.com/cdevents/sdk-go/pkg/api/v04"
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/conditions"
 	"github.com/go-logr/logr"
@@ -44,30 +45,34 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+	"github.com/fluxcd/notification-controller/internal/features"
+	"github.com/fluxcd/notification-controller/internal/fips"
+	"github.com/fluxcd/notification-controller/internal/notifier"
 )
 
 var (
 	WebhookPathIndexKey = ".metadata.webhookPath"
 )
 
-// defaultFluxAPIVersions is a map of Flux API kinds to their API versions.
-var defaultFluxAPIVersions = map[string]string{
-	"Bucket":          "source.toolkit.fluxcd.io/v1",
-	"HelmChart":       "source.toolkit.fluxcd.io/v1",
-	"HelmRepository":  "source.toolkit.fluxcd.io/v1",
-	"GitRepository":   "source.toolkit.fluxcd.io/v1",
-	"OCIRepository":   "source.toolkit.fluxcd.io/v1beta2",
-	"ImageRepository": "image.toolkit.fluxcd.io/v1beta2",
-}
+// errNoMatchingResources is returned when no object matches the labels
+// of a Receiver resource.
+var errNoMatchingResources = errors.New("no matching resources")
 
 // IndexReceiverWebhookPath is a client.IndexerFunc that returns the Receiver's
-// webhook path, if present in its status.
+// webhook path, if present in its status. Both the flat and the namespaced
+// forms of the path are returned, so that the webhooks keep working when
+// switching between the two.
 func IndexReceiverWebhookPath(o client.Object) []string {
 	receiver := o.(*apiv1.Receiver)
-	if receiver.Status.WebhookPath != "" {
-		return []string{receiver.Status.WebhookPath}
+	if receiver.Status.WebhookPath == "" {
+		return nil
+	}
+	digest := strings.TrimPrefix(receiver.Status.WebhookPath, apiv1.ReceiverWebhookPath)
+	digest = strings.TrimPrefix(digest, receiver.Namespace+"/")
+	return []string{
+		apiv1.ReceiverWebhookPath + digest,
+		apiv1.ReceiverWebhookPath + receiver.Namespace + "/" + digest,
 	}
-	return nil
 }
 
 func (s *ReceiverServer) handlePayload() func(w http.ResponseWriter, r *http.Request) {
@@ -109,21 +114,39 @@ func (s *ReceiverServer) handlePayload() func(w http.ResponseWriter, r *http.Req
 			return
 		}
 
-		if err := s.validate(ctx, receiver, r); err != nil {
+		// Bound the outbound requests of the validation by the Receiver
+		// timeout, and cancel them if the client goes away.
+		validateCtx, cancel := context.WithTimeout(r.Context(), receiver.GetTimeout())
+		err = s.validate(validateCtx, receiver, r)
+		cancel()
+		if err != nil {
+			if errors.Is(err, errWebhookIgnored) {
+				logger.Info(err.Error())
+				w.WriteHeader(http.StatusOK)
+				return
+			}
 			logger.Error(err, "unable to validate payload")
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
 
-		var withErrors bool
-		for _, resource := range receiver.Spec.Resources {
-			if err := s.requestReconciliation(ctx, logger, resource, receiver.Namespace); err != nil {
-				logger.Error(err, "unable to request reconciliation")
-				withErrors = true
+		event := receiverEventType(receiver.Spec.Type, r)
+		receiver.Spec.Resources = resourcesForEvent(receiver.Spec.Resources, event)
+		if len(receiver.Spec.Resources) == 0 {
+			logger.Info(fmt.Sprintf("no resources to handle for the event '%s'", event))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if s.queue != nil {
+			if s.enqueue(receiver, logger) {
+				w.WriteHeader(http.StatusAccepted)
+				return
 			}
+			logger.Info("receiver queue is full, processing request synchronously")
 		}
 
-		if withErrors {
+		if withErrors := s.requestReconciliations(ctx, logger, receiver); withErrors {
 			w.WriteHeader(http.StatusInternalServerError)
 		} else {
 			w.WriteHeader(http.StatusOK)
@@ -131,6 +154,101 @@ func (s *ReceiverServer) handlePayload() func(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// requestReconciliations requests the reconciliation of all the resources
+// of the given Receiver and returns true if any of the requests failed.
+// The results of the requests are recorded in the Receiver status.
+func (s *ReceiverServer) requestReconciliations(ctx context.Context, logger logr.Logger, receiver apiv1.Receiver) bool {
+	var withErrors bool
+	triggers := make([]apiv1.ReceiverTrigger, 0, len(receiver.Spec.Resources))
+	for _, resource := range receiver.Spec.Resources {
+		trigger := apiv1.ReceiverTrigger{
+			Resource: triggerResource(resource, receiver.Namespace),
+			Time:     metav1.Now(),
+			Result:   apiv1.TriggerAccepted,
+		}
+		if err := s.requestReconciliation(ctx, logger, resource, receiver); err != nil {
+			if errors.Is(err, errNoMatchingResources) {
+				logger.Error(err, "error annotating resources")
+				trigger.Result = apiv1.TriggerFiltered
+			} else {
+				logger.Error(err, "unable to request reconciliation")
+				trigger.Result = apiv1.TriggerFailed
+				trigger.Message = err.Error()
+				withErrors = true
+			}
+		}
+		triggers = append(triggers, trigger)
+	}
+	if err := s.recordTriggers(ctx, receiver, triggers); err != nil {
+		logger.Error(err, "unable to record the triggers in the receiver status")
+	}
+	return withErrors
+}
+
+// recordTriggers prepends the given triggers to the last triggers of the
+// Receiver status, keeping at most apiv1.MaxReceiverTriggers entries.
+func (s *ReceiverServer) recordTriggers(ctx context.Context, receiver apiv1.Receiver, triggers []apiv1.ReceiverTrigger) error {
+	if len(triggers) == 0 {
+		return nil
+	}
+	patch := client.MergeFrom(receiver.DeepCopy())
+	lastTriggers := append(triggers, receiver.Status.LastTriggers...)
+	if len(lastTriggers) > apiv1.MaxReceiverTriggers {
+		lastTriggers = lastTriggers[:apiv1.MaxReceiverTriggers]
+	}
+	receiver.Status.LastTriggers = lastTriggers
+	return s.kubeClient.Status().Patch(ctx, &receiver, patch)
+}
+
+// receiverEventType returns the event type of the given webhook request,
+// for the Receiver types handling several event types, or an empty string.
+func receiverEventType(receiverType string, r *http.Request) string {
+	switch receiverType {
+	case apiv1.GitHubReceiver:
+		return github.WebHookType(r)
+	case apiv1.GitLabReceiver:
+		return r.Header.Get("X-Gitlab-Event")
+	case apiv1.CDEventsReceiver:
+		return r.Header.Get("Ce-Type")
+	case apiv1.BitbucketReceiver:
+		return r.Header.Get("X-Event-Key")
+	case apiv1.RenovateReceiver:
+		return r.Header.Get(renovateEventHeader)
+	default:
+		return ""
+	}
+}
+
+// resourcesForEvent returns the resources of a Receiver handling the given
+// event type, which are the ones not scoped to specific event types and
+// the ones scoped to this event type.
+func resourcesForEvent(resources []apiv1.ReceiverObjectReference, event string) []apiv1.ReceiverObjectReference {
+	var filtered []apiv1.ReceiverObjectReference
+	for _, resource := range resources {
+		if len(resource.Events) == 0 {
+			filtered = append(filtered, resource)
+			continue
+		}
+		for _, e := range resource.Events {
+			if strings.EqualFold(event, e) {
+				filtered = append(filtered, resource)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// triggerResource returns the 'Kind/namespace/name' reference of the given
+// resource, defaulting the namespace to the one of the Receiver.
+func triggerResource(resource apiv1.ReceiverObjectReference, defaultNamespace string) string {
+	namespace := defaultNamespace
+	if resource.Namespace != "" {
+		namespace = resource.Namespace
+	}
+	return fmt.Sprintf("%s/%s/%s", resource.Kind, namespace, resource.Name)
+}
+
 func (s *ReceiverServer) validate(ctx context.Context, receiver apiv1.Receiver, r *http.Request) error {
 	token, err := s.token(ctx, receiver)
 	if err != nil {
@@ -144,19 +262,33 @@ func (s *ReceiverServer) validate(ctx context.Context, receiver apiv1.Receiver,
 
 	switch receiver.Spec.Type {
 	case apiv1.GenericReceiver:
-		return nil
+		if receiver.Spec.PayloadSchemaRef == nil {
+			return nil
+		}
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("unable to read request body: %s", err)
+		}
+		return s.validatePayloadSchema(ctx, receiver, b)
 	case apiv1.GenericHMACReceiver:
 		b, err := io.ReadAll(r.Body)
 		if err != nil {
 			return fmt.Errorf("unable to read request body: %s", err)
 		}
 
-		err = github.ValidateSignature(r.Header.Get("X-Signature"), b, []byte(token))
+		signature := r.Header.Get("X-Signature")
+		if err := checkSignatureAlgorithm(signature); err != nil {
+			return err
+		}
+		err = github.ValidateSignature(signature, b, []byte(token))
 		if err != nil {
 			return fmt.Errorf("unable to validate HMAC signature: %s", err)
 		}
-		return nil
+		return s.validatePayloadSchema(ctx, receiver, b)
 	case apiv1.GitHubReceiver:
+		if err := checkSignatureAlgorithm(hubSignature(r)); err != nil {
+			return err
+		}
 		_, err := github.ValidatePayload(r, []byte(token))
 		if err != nil {
 			return fmt.Errorf("the GitHub signature header is invalid, err: %w", err)
@@ -232,6 +364,9 @@ func (s *ReceiverServer) validate(ctx context.Context, receiver apiv1.Receiver,
 		logger.Info(fmt.Sprintf("handling CDEvent: %s", event))
 		return nil
 	case apiv1.BitbucketReceiver:
+		if err := checkSignatureAlgorithm(hubSignature(r)); err != nil {
+			return err
+		}
 		_, err := github.ValidatePayload(r, []byte(token))
 		if err != nil {
 			return fmt.Errorf("the Bitbucket server signature header is invalid, err: %w", err)
@@ -307,7 +442,7 @@ func (s *ReceiverServer) validate(ctx context.Context, receiver apiv1.Receiver,
 			} `json:"message"`
 		}
 
-		err := authenticateGCRRequest(&http.Client{}, r.Header.Get("Authorization"), tokenIndex)
+		err := authenticateGCRRequest(ctx, &http.Client{}, r.Header.Get("Authorization"), tokenIndex)
 		if err != nil {
 			return fmt.Errorf("cannot authenticate GCR request: %s", err)
 		}
@@ -328,6 +463,10 @@ func (s *ReceiverServer) validate(ctx context.Context, receiver apiv1.Receiver,
 		logger.Info(fmt.Sprintf("handling GCR event from %s for tag %s", d.Digest, d.Tag))
 		return nil
 	case apiv1.NexusReceiver:
+		// The Nexus signatures are SHA-1 HMACs.
+		if err := fips.CheckHMACAlgorithm("sha1"); err != nil {
+			return err
+		}
 		signature := r.Header.Get("X-Nexus-Webhook-Signature")
 		if len(signature) == 0 {
 			return fmt.Errorf("Nexus signature is missing from header")
@@ -370,6 +509,18 @@ func (s *ReceiverServer) validate(ctx context.Context, receiver apiv1.Receiver,
 
 		logger.Info(fmt.Sprintf("handling ACR event from %s for tag %s", p.Target.Repository, p.Target.Tag))
 		return nil
+	case apiv1.CIReceiver:
+		return validateCIWebhook(logger, receiver, r, token)
+	case apiv1.DistributionReceiver:
+		return validateDistributionWebhook(logger, receiver, r, token)
+	case apiv1.CloudBuildReceiver:
+		const tokenIndex = len("Bearer ")
+		if err := authenticateGCRRequest(ctx, &http.Client{}, r.Header.Get("Authorization"), tokenIndex); err != nil {
+			return fmt.Errorf("cannot authenticate Cloud Build request: %s", err)
+		}
+		return validateCloudBuildWebhook(logger, receiver, r)
+	case apiv1.RenovateReceiver:
+		return validateRenovateWebhook(logger, receiver, r, token)
 	}
 
 	return fmt.Errorf("recevier type '%s' not supported", receiver.Spec.Type)
@@ -388,28 +539,36 @@ func (s *ReceiverServer) token(ctx context.Context, receiver apiv1.Receiver) (st
 		return "", fmt.Errorf("unable to read token from secret '%s' error: %w", secretName, err)
 	}
 
-	if val, ok := secret.Data["token"]; ok {
+	key := receiver.GetTokenSecretKey()
+	if val, ok := secret.Data[key]; ok {
 		token = string(val)
 	} else {
-		return "", fmt.Errorf("invalid '%s' secret data: required field 'token'", secretName)
+		return "", fmt.Errorf("invalid '%s' secret data: required field '%s'", secretName, key)
 	}
 
 	return token, nil
 }
 
-// requestReconciliation requests reconciliation of all the resources matching the given CrossNamespaceObjectReference by annotating them accordingly.
-func (s *ReceiverServer) requestReconciliation(ctx context.Context, logger logr.Logger, resource apiv1.CrossNamespaceObjectReference, defaultNamespace string) error {
-	namespace := defaultNamespace
+// requestReconciliation requests reconciliation of all the resources matching
+// the given ReceiverObjectReference by annotating them accordingly, or
+// dispatches an event for them when the reference has the notify action.
+func (s *ReceiverServer) requestReconciliation(ctx context.Context, logger logr.Logger, resource apiv1.ReceiverObjectReference, receiver apiv1.Receiver) error {
+	namespace := receiver.Namespace
 	if resource.Namespace != "" {
 		namespace = resource.Namespace
 	}
 
+	if !apiv1.IsFluxKind(resource.Kind) && !s.arbitraryResources {
+		return fmt.Errorf("kind '%s' is not a Flux kind, enable the %s feature gate to target it",
+			resource.Kind, features.ReceiverArbitraryResources)
+	}
+
 	apiVersion := resource.APIVersion
 	if apiVersion == "" {
-		if defaultFluxAPIVersions[resource.Kind] == "" {
+		apiVersion = s.fluxAPIVersion(resource.Kind)
+		if apiVersion == "" {
 			return fmt.Errorf("apiVersion must be specified for kind '%s'", resource.Kind)
 		}
-		apiVersion = defaultFluxAPIVersions[resource.Kind]
 	}
 
 	group, version := getGroupVersion(apiVersion)
@@ -422,12 +581,13 @@ func (s *ReceiverServer) requestReconciliation(ctx context.Context, logger logr.
 		logger.V(1).Info(fmt.Sprintf("annotate resources by matchLabel for kind '%s' in '%s'",
 			resource.Kind, namespace), "matchLabels", resource.MatchLabels)
 
-		var resources metav1.PartialObjectMetadataList
-		resources.SetGroupVersionKind(schema.GroupVersionKind{
+		gvk := schema.GroupVersionKind{
 			Group:   group,
 			Kind:    resource.Kind,
 			Version: version,
-		})
+		}
+		var resources metav1.PartialObjectMetadataList
+		resources.SetGroupVersionKind(gvk)
 
 		if err := s.kubeClient.List(ctx, &resources,
 			client.InNamespace(namespace),
@@ -437,17 +597,14 @@ func (s *ReceiverServer) requestReconciliation(ctx context.Context, logger logr.
 		}
 
 		if len(resources.Items) == 0 {
-			noObjectsFoundErr := fmt.Errorf("no '%s' resources found with matching labels '%s' in '%s' namespace", resource.Kind, resource.MatchLabels, namespace)
-			logger.Error(noObjectsFoundErr, "error annotating resources")
-			return nil
+			return fmt.Errorf("%w for kind '%s' with labels '%s' in '%s' namespace",
+				errNoMatchingResources, resource.Kind, resource.MatchLabels, namespace)
 		}
 
-		for i, resource := range resources.Items {
-			if err := s.annotate(ctx, &resources.Items[i]); err != nil {
-				return fmt.Errorf("failed to annotate resource: '%s/%s.%s': %w", resource.Kind, resource.Name, namespace, err)
-			} else {
-				logger.Info(fmt.Sprintf("resource '%s/%s.%s' annotated",
-					resource.Kind, resource.Name, namespace))
+		for i := range resources.Items {
+			resources.Items[i].SetGroupVersionKind(gvk)
+			if err := s.trigger(ctx, logger, resource.Action, &resources.Items[i], receiver); err != nil {
+				return err
 			}
 		}
 
@@ -470,14 +627,59 @@ func (s *ReceiverServer) requestReconciliation(ctx context.Context, logger logr.
 		return fmt.Errorf("unable to read %s '%s' error: %w", resource.Kind, objectKey, err)
 	}
 
-	err := s.annotate(ctx, u)
-	if err != nil {
-		return fmt.Errorf("failed to annotate resource: '%s/%s.%s': %w", resource.Kind, resource.Name, namespace, err)
-	} else {
-		logger.Info(fmt.Sprintf("resource '%s/%s.%s' annotated",
-			resource.Kind, resource.Name, namespace))
+	return s.trigger(ctx, logger, resource.Action, u, receiver)
+}
+
+// trigger annotates the given object to request its reconciliation, or
+// dispatches an event for it if the action is apiv1.NotifyAction.
+func (s *ReceiverServer) trigger(ctx context.Context, logger logr.Logger, action string, resource *metav1.PartialObjectMetadata, receiver apiv1.Receiver) error {
+	if action == apiv1.NotifyAction {
+		if err := s.notify(ctx, resource, receiver); err != nil {
+			return fmt.Errorf("failed to notify resource: '%s/%s.%s': %w", resource.Kind, resource.Name, resource.Namespace, err)
+		}
+		logger.Info(fmt.Sprintf("resource '%s/%s.%s' notified",
+			resource.Kind, resource.Name, resource.Namespace))
+		return nil
+	}
+
+	if err := s.annotate(ctx, resource); err != nil {
+		return fmt.Errorf("failed to annotate resource: '%s/%s.%s': %w", resource.Kind, resource.Name, resource.Namespace, err)
+	}
+	logger.Info(fmt.Sprintf("resource '%s/%s.%s' annotated",
+		resource.Kind, resource.Name, resource.Namespace))
+
+	if resource.Kind == helmRepositoryKind {
+		return s.triggerHelmCharts(ctx, logger, resource)
+	}
+	return nil
+}
+
+// notify dispatches an event for the given object to the Alerts, noting that
+// the webhook of the given Receiver was called.
+func (s *ReceiverServer) notify(ctx context.Context, resource *metav1.PartialObjectMetadata, receiver apiv1.Receiver) error {
+	if s.dispatcher == nil {
+		return errors.New("event dispatching is not enabled")
 	}
 
+	event := &eventv1.Event{
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      resource.APIVersion,
+			Kind:            resource.Kind,
+			Name:            resource.Name,
+			Namespace:       resource.Namespace,
+			UID:             resource.UID,
+			ResourceVersion: resource.ResourceVersion,
+		},
+		Severity:            eventv1.EventSeverityInfo,
+		Timestamp:           metav1.Now(),
+		Message:             fmt.Sprintf("Webhook received by Receiver '%s/%s'", receiver.Namespace, receiver.Name),
+		Reason:              apiv1.WebhookReceivedReason,
+		ReportingController: "notification-controller",
+		Metadata: map[string]string{
+			eventv1.Group + "/receiver": fmt.Sprintf("%s/%s", receiver.Namespace, receiver.Name),
+		},
+	}
+	s.dispatcher.DispatchEvent(ctx, event)
 	return nil
 }
 
@@ -492,6 +694,15 @@ func (s *ReceiverServer) annotate(ctx context.Context, resource *metav1.PartialO
 	sourceAnnotations[meta.ReconcileRequestAnnotation] = metav1.Now().String()
 	resource.SetAnnotations(sourceAnnotations)
 
+	if s.patchLimiter != nil {
+		if err := s.patchLimiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit exceeded for annotating %s '%s': %w", resource.Kind, client.ObjectKey{
+				Namespace: resource.Namespace,
+				Name:      resource.Name,
+			}, err)
+		}
+	}
+
 	if err := s.kubeClient.Patch(ctx, resource, patch); err != nil {
 		return fmt.Errorf("unable to annotate %s '%s' error: %w", resource.Kind, client.ObjectKey{
 			Namespace: resource.Namespace,
@@ -502,7 +713,9 @@ func (s *ReceiverServer) annotate(ctx context.Context, resource *metav1.PartialO
 	return nil
 }
 
-func authenticateGCRRequest(c *http.Client, bearer string, tokenIndex int) (err error) {
+// authenticateGCRRequest verifies the Google ID token of the given bearer
+// with the tokeninfo endpoint, within the deadline of the given context.
+func authenticateGCRRequest(ctx context.Context, c *http.Client, bearer string, tokenIndex int) (err error) {
 	type auth struct {
 		Aud string `json:"aud"`
 	}
@@ -514,10 +727,17 @@ func authenticateGCRRequest(c *http.Client, bearer string, tokenIndex int) (err
 	token := bearer[tokenIndex:]
 	url := fmt.Sprintf("https://oauth2.googleapis.com/tokeninfo?id_token=%s", token)
 
-	resp, err := c.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("cannot verify authenticity of payload: %w", err)
+	}
+	req.Header.Set("User-Agent", notifier.UserAgent("receiver/"+apiv1.GCRReceiver))
+
+	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("cannot verify authenticity of payload: %w", err)
 	}
+	defer resp.Body.Close()
 
 	var p auth
 	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
@@ -534,6 +754,23 @@ func verifyHmacSignature(key []byte, signature string, payload []byte) bool {
 	return hmac.Equal([]byte(signature), []byte(expectedMAC))
 }
 
+// hubSignature returns the signature header validated by
+// github.ValidatePayload, which prefers the SHA-256 signature.
+func hubSignature(r *http.Request) string {
+	if signature := r.Header.Get("X-Hub-Signature-256"); signature != "" {
+		return signature
+	}
+	return r.Header.Get("X-Hub-Signature")
+}
+
+// checkSignatureAlgorithm returns an error when the HMAC algorithm of the
+// given signature, in the form <algorithm>=<hex-encoded HMAC>, is not
+// allowed in FIPS mode.
+func checkSignatureAlgorithm(signature string) error {
+	algorithm, _, _ := strings.Cut(signature, "=")
+	return fips.CheckHMACAlgorithm(algorithm)
+}
+
 func getGroupVersion(s string) (string, string) {
 	slice := strings.Split(s, "/")
 	if len(slice) == 0 {
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=source.fluxcd.io,resources=helmcharts,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=source.fluxcd.io,resources=helmcharts/status,verbs=get
+
+const (
+	helmRepositoryKind = "HelmRepository"
+	helmChartKind      = "HelmChart"
+
+	// helmRepositoryOCIType is the type of the HelmRepositories serving
+	// the charts of an OCI registry.
+	helmRepositoryOCIType = "oci"
+)
+
+// triggerHelmCharts requests the reconciliation of the HelmCharts sourced
+// from the given HelmRepository, when it's an OCI HelmRepository. The OCI
+// HelmRepositories have no index to fetch, the charts pushed to the registry
+// are only pulled by the reconciliation of their HelmCharts.
+func (s *ReceiverServer) triggerHelmCharts(ctx context.Context, logger logr.Logger, repository *metav1.PartialObjectMetadata) error {
+	repositoryKey := client.ObjectKeyFromObject(repository)
+	repo := &unstructured.Unstructured{}
+	repo.SetGroupVersionKind(repository.GroupVersionKind())
+	if err := s.kubeClient.Get(ctx, repositoryKey, repo); err != nil {
+		return fmt.Errorf("unable to read %s '%s' error: %w", helmRepositoryKind, repositoryKey, err)
+	}
+	if repoType, _, _ := unstructured.NestedString(repo.Object, "spec", "type"); repoType != helmRepositoryOCIType {
+		return nil
+	}
+
+	group, version := getGroupVersion(s.fluxAPIVersion(helmChartKind))
+	gvk := schema.GroupVersionKind{Group: group, Version: version, Kind: helmChartKind}
+	var charts unstructured.UnstructuredList
+	charts.SetGroupVersionKind(gvk.GroupVersion().WithKind(helmChartKind + "List"))
+	if err := s.kubeClient.List(ctx, &charts, client.InNamespace(repository.Namespace)); err != nil {
+		return fmt.Errorf("failed listing %ss in namespace %q: %w", helmChartKind, repository.Namespace, err)
+	}
+
+	for _, name := range helmChartsForRepository(charts.Items, repository.Name) {
+		chart := &metav1.PartialObjectMetadata{}
+		chart.SetGroupVersionKind(gvk)
+		chartKey := client.ObjectKey{Namespace: repository.Namespace, Name: name}
+		if err := s.kubeClient.Get(ctx, chartKey, chart); err != nil {
+			return fmt.Errorf("unable to read %s '%s' error: %w", helmChartKind, chartKey, err)
+		}
+		if err := s.annotate(ctx, chart); err != nil {
+			return fmt.Errorf("failed to annotate resource: '%s/%s.%s': %w", helmChartKind, chart.Name, chart.Namespace, err)
+		}
+		logger.Info(fmt.Sprintf("resource '%s/%s.%s' annotated for %s '%s'",
+			helmChartKind, chart.Name, chart.Namespace, helmRepositoryKind, repository.Name))
+	}
+	return nil
+}
+
+// helmChartsForRepository returns the names of the given HelmCharts whose
+// source is the HelmRepository of the given name.
+func helmChartsForRepository(charts []unstructured.Unstructured, repository string) []string {
+	var names []string
+	for _, chart := range charts {
+		kind, _, _ := unstructured.NestedString(chart.Object, "spec", "sourceRef", "kind")
+		name, _, _ := unstructured.NestedString(chart.Object, "spec", "sourceRef", "name")
+		if kind == helmRepositoryKind && name == repository {
+			names = append(names, chart.GetName())
+		}
+	}
+	return names
+}
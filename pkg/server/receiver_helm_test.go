@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/logger"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func newUnstructured(kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	u.SetAPIVersion(defaultFluxAPIVersions[kind])
+	u.SetKind(kind)
+	u.SetNamespace("default")
+	u.SetName(name)
+	return u
+}
+
+func Test_requestReconciliation_ociHelmRepository(t *testing.T) {
+	chartSpec := func(repository string) map[string]interface{} {
+		return map[string]interface{}{
+			"chart": "podinfo",
+			"sourceRef": map[string]interface{}{
+				"kind": helmRepositoryKind,
+				"name": repository,
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		repositoryType string
+		wantAnnotated  []string
+	}{
+		{
+			name:           "annotates the charts of OCI repositories",
+			repositoryType: helmRepositoryOCIType,
+			wantAnnotated:  []string{"podinfo"},
+		},
+		{
+			name:           "doesn't annotate the charts of HTTP repositories",
+			repositoryType: "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			objects := []client.Object{
+				newUnstructured(helmRepositoryKind, "charts", map[string]interface{}{
+					"type": tt.repositoryType,
+					"url":  "oci://ghcr.io/stefanprodan/charts",
+				}),
+				newUnstructured(helmChartKind, "podinfo", chartSpec("charts")),
+				newUnstructured(helmChartKind, "other", chartSpec("other")),
+			}
+			scheme := runtime.NewScheme()
+			g.Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+			kclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+			s := NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{})
+			err := s.requestReconciliation(context.TODO(), logger.NewLogger(logger.Options{}), apiv1.ReceiverObjectReference{
+				Kind: helmRepositoryKind,
+				Name: "charts",
+			}, apiv1.Receiver{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+			})
+			g.Expect(err).ToNot(HaveOccurred())
+
+			for _, name := range []string{"podinfo", "other"} {
+				chart := newUnstructured(helmChartKind, name, nil)
+				g.Expect(kclient.Get(context.TODO(), client.ObjectKeyFromObject(chart), chart)).To(Succeed())
+				if slices.Contains(tt.wantAnnotated, name) {
+					g.Expect(chart.GetAnnotations()).To(HaveKey(meta.ReconcileRequestAnnotation), name)
+				} else {
+					g.Expect(chart.GetAnnotations()).ToNot(HaveKey(meta.ReconcileRequestAnnotation), name)
+				}
+			}
+		})
+	}
+}
+
+func Test_helmChartsForRepository(t *testing.T) {
+	g := NewWithT(t)
+
+	charts := []unstructured.Unstructured{
+		*newUnstructured(helmChartKind, "podinfo", map[string]interface{}{
+			"sourceRef": map[string]interface{}{"kind": helmRepositoryKind, "name": "charts"},
+		}),
+		*newUnstructured(helmChartKind, "git", map[string]interface{}{
+			"sourceRef": map[string]interface{}{"kind": "GitRepository", "name": "charts"},
+		}),
+		*newUnstructured(helmChartKind, "empty", nil),
+	}
+	g.Expect(helmChartsForRepository(charts, "charts")).To(Equal([]string{"podinfo"}))
+	g.Expect(helmChartsForRepository(charts, "other")).To(BeEmpty())
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// receiverQueueSize is the number of webhook requests that can be waiting
+// for a worker before the receiver server falls back to processing
+// requests synchronously.
+const receiverQueueSize = 100
+
+// receiverQueueRequestTimeout bounds the annotation of the resources of a
+// queued request, so that a stuck Kubernetes API call doesn't hold a worker.
+const receiverQueueRequestTimeout = 30 * time.Second
+
+var (
+	receiverQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gotk_receiver_queue_depth",
+			Help: "The number of webhook requests waiting to be processed asynchronously, per Receiver.",
+		},
+		[]string{"name", "namespace"},
+	)
+	receiverQueueProcessed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gotk_receiver_queue_processed_total",
+			Help: "The number of asynchronously processed webhook requests, per Receiver and result.",
+		},
+		[]string{"name", "namespace", "result"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(receiverQueueDepth, receiverQueueProcessed)
+}
+
+// receiverRequest is a validated webhook request waiting for the resources
+// of its Receiver to be annotated.
+type receiverRequest struct {
+	receiver apiv1.Receiver
+	logger   logr.Logger
+}
+
+// enqueue adds the given Receiver to the queue of asynchronous requests. It
+// returns false if the queue is full.
+func (s *ReceiverServer) enqueue(receiver apiv1.Receiver, logger logr.Logger) bool {
+	select {
+	case s.queue <- receiverRequest{receiver: receiver, logger: logger}:
+		receiverQueueDepth.WithLabelValues(receiver.Name, receiver.Namespace).Inc()
+		return true
+	default:
+		return false
+	}
+}
+
+// processQueue annotates the resources of the queued requests until the
+// stop channel is closed. The requests in progress are cancelled when the
+// stop channel is closed.
+func (s *ReceiverServer) processQueue(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-s.queue:
+			s.lastDequeue.Store(time.Now().UnixNano())
+			receiverQueueDepth.WithLabelValues(req.receiver.Name, req.receiver.Namespace).Dec()
+			s.processRequest(ctx, req)
+		}
+	}
+}
+
+// processRequest annotates the resources of the given queued request
+// within receiverQueueRequestTimeout.
+func (s *ReceiverServer) processRequest(ctx context.Context, req receiverRequest) {
+	ctx, cancel := context.WithTimeout(ctx, receiverQueueRequestTimeout)
+	defer cancel()
+
+	result := "success"
+	if withErrors := s.requestReconciliations(ctx, req.logger, req.receiver); withErrors {
+		result = "failure"
+	}
+	receiverQueueProcessed.WithLabelValues(req.receiver.Name, req.receiver.Namespace, result).Inc()
+}
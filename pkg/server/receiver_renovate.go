@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+const (
+	// renovateEventHeader is the header holding the event type of the
+	// Renovate webhooks, e.g. 'pr-created' or 'branch-updated'.
+	renovateEventHeader = "X-Renovate-Event"
+	// renovateSignatureHeader is the header holding the HMAC-SHA256 of the
+	// Renovate webhook payload, in the form sha256=<hex-encoded HMAC>.
+	renovateSignatureHeader = "X-Renovate-Signature"
+)
+
+// renovatePayload is the payload of the Renovate webhooks.
+type renovatePayload struct {
+	Repository string `json:"repository"`
+	Branch     string `json:"branch"`
+	Commit     string `json:"commit"`
+	PR         struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		URL    string `json:"url"`
+	} `json:"pr"`
+}
+
+// verifyRenovateRequest returns an error if the request is not authenticated
+// by the Receiver token, either with the HMAC signature of the payload when
+// the request is signed, or with the token as a bearer token.
+func verifyRenovateRequest(r *http.Request, body []byte, token string) error {
+	if signature := r.Header.Get(renovateSignatureHeader); signature != "" {
+		if err := checkSignatureAlgorithm(signature); err != nil {
+			return err
+		}
+		algorithm, value, _ := strings.Cut(signature, "=")
+		if algorithm != "sha256" {
+			return fmt.Errorf("unsupported %s algorithm '%s'", renovateSignatureHeader, algorithm)
+		}
+		mac := hmac.New(sha256.New, []byte(token))
+		mac.Write(body)
+		if actual, err := hex.DecodeString(value); err != nil || !hmac.Equal(actual, mac.Sum(nil)) {
+			return fmt.Errorf("the %s header is invalid", renovateSignatureHeader)
+		}
+		return nil
+	}
+
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+		return errors.New("the request is neither signed nor authorized with the receiver token")
+	}
+	return nil
+}
+
+// validateRenovateWebhook validates the webhook of a renovate Receiver and
+// logs the repository, branch and pull request it is about. It returns
+// errWebhookIgnored for the events that are not in the Receiver events,
+// when set.
+func validateRenovateWebhook(logger logr.Logger, receiver apiv1.Receiver, r *http.Request, token string) error {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read Renovate request body: %w", err)
+	}
+	if err := verifyRenovateRequest(r, b, token); err != nil {
+		return err
+	}
+
+	var p renovatePayload
+	if err := json.Unmarshal(b, &p); err != nil {
+		return fmt.Errorf("cannot decode Renovate webhook payload: %w", err)
+	}
+
+	event := r.Header.Get(renovateEventHeader)
+	if len(receiver.Spec.Events) > 0 {
+		allowed := false
+		for _, e := range receiver.Spec.Events {
+			if strings.EqualFold(event, e) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: the Renovate event '%s' is not in the receiver events", errWebhookIgnored, event)
+		}
+	}
+
+	keysAndValues := []interface{}{"repository", p.Repository, "branch", p.Branch}
+	if p.Commit != "" {
+		keysAndValues = append(keysAndValues, "commit", p.Commit)
+	}
+	if p.PR.Number != 0 {
+		keysAndValues = append(keysAndValues, "pr", p.PR.Number, "prTitle", p.PR.Title)
+	}
+	logger.Info(fmt.Sprintf("handling Renovate event '%s'", event), keysAndValues...)
+	return nil
+}
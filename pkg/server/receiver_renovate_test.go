@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func Test_validateRenovateWebhook(t *testing.T) {
+	const (
+		token = "token"
+		body  = `{"repository":"org/app","branch":"renovate/podinfo-6.x","pr":{"number":42,"title":"Update podinfo to v6.5.0"}}`
+	)
+	sign := func(key, body string) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		events      []string
+		body        string
+		wantErr     bool
+		wantIgnored bool
+	}{
+		{
+			name:    "bearer token",
+			headers: map[string]string{"Authorization": "Bearer " + token, renovateEventHeader: "pr-created"},
+			body:    body,
+		},
+		{
+			name:    "signed payload",
+			headers: map[string]string{renovateSignatureHeader: sign(token, body), renovateEventHeader: "pr-created"},
+			body:    body,
+		},
+		{
+			name:    "event in the receiver events",
+			headers: map[string]string{"Authorization": "Bearer " + token, renovateEventHeader: "Branch-Updated"},
+			events:  []string{"branch-updated"},
+			body:    body,
+		},
+		{
+			name:        "event not in the receiver events",
+			headers:     map[string]string{"Authorization": "Bearer " + token, renovateEventHeader: "pr-created"},
+			events:      []string{"branch-updated"},
+			body:        body,
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:    "invalid bearer token",
+			headers: map[string]string{"Authorization": "Bearer wrong"},
+			body:    body,
+			wantErr: true,
+		},
+		{
+			name:    "invalid signature",
+			headers: map[string]string{renovateSignatureHeader: sign("wrong", body), "Authorization": "Bearer " + token},
+			body:    body,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported signature algorithm",
+			headers: map[string]string{renovateSignatureHeader: "sha512=abc"},
+			body:    body,
+			wantErr: true,
+		},
+		{
+			name:    "unauthenticated",
+			body:    body,
+			wantErr: true,
+		},
+		{
+			name:    "invalid payload",
+			headers: map[string]string{"Authorization": "Bearer " + token},
+			body:    `{"pr":1}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			receiver := apiv1.Receiver{
+				Spec: apiv1.ReceiverSpec{
+					Type:   apiv1.RenovateReceiver,
+					Events: tt.events,
+				},
+			}
+			req := httptest.NewRequest("POST", "/hook/", bytes.NewBufferString(tt.body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			err := validateRenovateWebhook(logr.Discard(), receiver, req, token)
+			if !tt.wantErr {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(errors.Is(err, errWebhookIgnored)).To(Equal(tt.wantIgnored))
+		})
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// Placeholders that can be used in the header values of a WebhookFixture.
+// They are replaced with the token of the Receiver, or with the HMAC of the
+// fixture body computed with the token, before the fixture is replayed.
+const (
+	ReplayTokenPlaceholder      = "$(TOKEN)"
+	ReplayHMACSHA1Placeholder   = "$(HMAC_SHA1)"
+	ReplayHMACSHA256Placeholder = "$(HMAC_SHA256)"
+)
+
+// WebhookFixture is a recorded webhook request.
+type WebhookFixture struct {
+	// Name of the fixture, defaults to the fixture file name.
+	Name string `json:"name,omitempty"`
+
+	// Receiver is the namespace/name of the Receiver the request is sent to.
+	Receiver string `json:"receiver"`
+
+	// Method of the request, defaults to POST.
+	Method string `json:"method,omitempty"`
+
+	// Headers of the request. The values may contain the replay placeholders.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body of the request.
+	Body string `json:"body,omitempty"`
+
+	// ExpectedStatus is the expected response status code, if set.
+	ExpectedStatus int `json:"expectedStatus,omitempty"`
+}
+
+// WebhookReplayResult is the outcome of replaying a WebhookFixture.
+type WebhookReplayResult struct {
+	Fixture    WebhookFixture
+	StatusCode int
+}
+
+// Unexpected returns true if the response status code doesn't match the
+// expected status of the fixture.
+func (r WebhookReplayResult) Unexpected() bool {
+	return r.Fixture.ExpectedStatus != 0 && r.Fixture.ExpectedStatus != r.StatusCode
+}
+
+// LoadWebhookFixtures reads the YAML or JSON webhook fixtures from the given
+// directory, sorted by file name.
+func LoadWebhookFixtures(dir string) ([]WebhookFixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read fixtures directory: %w", err)
+	}
+
+	var fixtures []WebhookFixture
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read fixture '%s': %w", entry.Name(), err)
+		}
+		var fixture WebhookFixture
+		if err := yaml.UnmarshalStrict(b, &fixture); err != nil {
+			return nil, fmt.Errorf("unable to decode fixture '%s': %w", entry.Name(), err)
+		}
+		if fixture.Name == "" {
+			fixture.Name = strings.TrimSuffix(entry.Name(), ext)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Name < fixtures[j].Name })
+	return fixtures, nil
+}
+
+// WebhookReplayer drives recorded webhook requests through the receiver
+// pipeline, from the payload validation to the annotation of the resources.
+type WebhookReplayer struct {
+	server *ReceiverServer
+}
+
+// NewWebhookReplayer returns a WebhookReplayer annotating the resources
+// with the given client. The client must index the Receivers by webhook
+// path, see IndexReceiverWebhookPath. When arbitraryResources is true, the
+// Receivers can target resources of any kind, and not only Flux kinds.
+func NewWebhookReplayer(kubeClient client.Client, logger logr.Logger, arbitraryResources bool) *WebhookReplayer {
+	return &WebhookReplayer{
+		server: &ReceiverServer{
+			logger:             logger.WithName("webhook-replay"),
+			kubeClient:         kubeClient,
+			arbitraryResources: arbitraryResources,
+		},
+	}
+}
+
+// Replay sends the given fixture to the webhook path of its Receiver and
+// returns the response status code.
+func (r *WebhookReplayer) Replay(ctx context.Context, fixture WebhookFixture) (WebhookReplayResult, error) {
+	result := WebhookReplayResult{Fixture: fixture}
+
+	namespace, name, ok := strings.Cut(fixture.Receiver, "/")
+	if !ok {
+		return result, fmt.Errorf("invalid receiver '%s' in fixture '%s', expected namespace/name", fixture.Receiver, fixture.Name)
+	}
+	var receiver apiv1.Receiver
+	if err := r.server.kubeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &receiver); err != nil {
+		return result, fmt.Errorf("unable to read receiver '%s': %w", fixture.Receiver, err)
+	}
+	if receiver.Status.WebhookPath == "" {
+		return result, fmt.Errorf("receiver '%s' has no webhook path", fixture.Receiver)
+	}
+
+	token, err := r.server.token(ctx, receiver)
+	if err != nil {
+		return result, err
+	}
+
+	method := fixture.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	body := []byte(fixture.Body)
+	req := httptest.NewRequest(method, receiver.Status.WebhookPath, bytes.NewReader(body)).WithContext(ctx)
+	for k, v := range fixture.Headers {
+		req.Header.Set(k, expandReplayPlaceholders(v, token, body))
+	}
+
+	rec := httptest.NewRecorder()
+	r.server.handlePayload()(rec, req)
+	result.StatusCode = rec.Code
+	return result, nil
+}
+
+// ReplayDir replays all the fixtures found in the given directory, and
+// returns an error if a fixture can't be replayed or if a response doesn't
+// have the expected status code.
+func (r *WebhookReplayer) ReplayDir(ctx context.Context, dir string) ([]WebhookReplayResult, error) {
+	fixtures, err := LoadWebhookFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []WebhookReplayResult
+	var failed []string
+	for _, fixture := range fixtures {
+		result, err := r.Replay(ctx, fixture)
+		if err != nil {
+			return results, err
+		}
+		r.server.logger.Info("replayed webhook fixture", "fixture", fixture.Name, "status", result.StatusCode)
+		if result.Unexpected() {
+			failed = append(failed, fmt.Sprintf("%s (got %d, expected %d)", fixture.Name, result.StatusCode, fixture.ExpectedStatus))
+		}
+		results = append(results, result)
+	}
+	if len(failed) > 0 {
+		return results, fmt.Errorf("unexpected response status for fixtures: %s", strings.Join(failed, ", "))
+	}
+	return results, nil
+}
+
+func expandReplayPlaceholders(value, token string, body []byte) string {
+	value = strings.ReplaceAll(value, ReplayTokenPlaceholder, token)
+	if strings.Contains(value, ReplayHMACSHA1Placeholder) {
+		value = strings.ReplaceAll(value, ReplayHMACSHA1Placeholder, hmacHex(sha1.New, token, body))
+	}
+	if strings.Contains(value, ReplayHMACSHA256Placeholder) {
+		value = strings.ReplaceAll(value, ReplayHMACSHA256Placeholder, hmacHex(sha256.New, token, body))
+	}
+	return value
+}
+
+func hmacHex(h func() hash.Hash, key string, body []byte) string {
+	mac := hmac.New(h, []byte(key))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/logger"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func newReplayTestClient(g *WithT) client.Client {
+	resource := &apiv1.Receiver{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       apiv1.ReceiverKind,
+			APIVersion: apiv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dummy-resource",
+			Namespace: "default",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "token",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"token": []byte("token"),
+		},
+	}
+
+	objects := []client.Object{resource, secret}
+	for name, receiverType := range map[string]string{
+		"github-receiver":       apiv1.GitHubReceiver,
+		"gitlab-receiver":       apiv1.GitLabReceiver,
+		"harbor-receiver":       apiv1.HarborReceiver,
+		"nexus-receiver":        apiv1.NexusReceiver,
+		"generic-hmac-receiver": apiv1.GenericHMACReceiver,
+	} {
+		receiver := &apiv1.Receiver{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: apiv1.ReceiverSpec{
+				Type:      receiverType,
+				SecretRef: meta.LocalObjectReference{Name: secret.Name},
+				Resources: []apiv1.ReceiverObjectReference{
+					{
+						APIVersion: apiv1.GroupVersion.String(),
+						Kind:       apiv1.ReceiverKind,
+						Name:       resource.Name,
+					},
+				},
+			},
+			Status: apiv1.ReceiverStatus{
+				WebhookPath: apiv1.ReceiverWebhookPath + name,
+				Conditions:  []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionTrue}},
+			},
+		}
+		if receiverType == apiv1.GitHubReceiver {
+			receiver.Spec.Events = []string{"push"}
+		}
+		objects = append(objects, receiver)
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithIndex(&apiv1.Receiver{}, WebhookPathIndexKey, IndexReceiverWebhookPath).
+		Build()
+}
+
+func TestWebhookReplayer_ReplayDir(t *testing.T) {
+	g := NewWithT(t)
+
+	kclient := newReplayTestClient(g)
+	replayer := NewWebhookReplayer(kclient, logger.NewLogger(logger.Options{}), true)
+
+	results, err := replayer.ReplayDir(context.TODO(), "testdata/webhooks")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(HaveLen(7))
+	for _, result := range results {
+		g.Expect(result.Unexpected()).To(BeFalse(), "fixture %s", result.Fixture.Name)
+	}
+
+	obj := &apiv1.Receiver{}
+	g.Expect(kclient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "dummy-resource"}, obj)).To(Succeed())
+	g.Expect(obj.GetAnnotations()).To(HaveKey(meta.ReconcileRequestAnnotation))
+}
+
+func TestWebhookReplayer_ReplayDir_unexpectedStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(dir, "gitlab.yaml"), []byte(`receiver: default/gitlab-receiver
+headers:
+  X-Gitlab-Token: not-the-token
+expectedStatus: 200
+`), 0o644)).To(Succeed())
+
+	replayer := NewWebhookReplayer(newReplayTestClient(g), logger.NewLogger(logger.Options{}), true)
+	_, err := replayer.ReplayDir(context.TODO(), dir)
+	g.Expect(err).To(MatchError("unexpected response status for fixtures: gitlab (got 400, expected 200)"))
+}
+
+func TestLoadWebhookFixtures(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("receiver: default/b\n"), 0o644)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"name": "first", "receiver": "default/a"}`), 0o644)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "README.md"), []byte("# fixtures"), 0o644)).To(Succeed())
+
+	fixtures, err := LoadWebhookFixtures(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(fixtures).To(HaveLen(2))
+	g.Expect(fixtures[0].Name).To(Equal("b"))
+	g.Expect(fixtures[1].Name).To(Equal("first"))
+
+	g.Expect(os.WriteFile(filepath.Join(dir, "c.yaml"), []byte("unknown: field\n"), 0o644)).To(Succeed())
+	_, err = LoadWebhookFixtures(dir)
+	g.Expect(err).To(MatchError(ContainSubstring("unable to decode fixture 'c.yaml'")))
+}
+
+func Test_expandReplayPlaceholders(t *testing.T) {
+	g := NewWithT(t)
+
+	body := []byte(`{"ref": "main"}`)
+	g.Expect(expandReplayPlaceholders("$(TOKEN)", "token", body)).To(Equal("token"))
+	g.Expect(expandReplayPlaceholders("sha256=$(HMAC_SHA256)", "token", body)).To(Equal("sha256=" + hmacHex(sha256.New, "token", body)))
+	g.Expect(verifyHmacSignature([]byte("token"), expandReplayPlaceholders("$(HMAC_SHA1)", "token", body), body)).To(BeTrue())
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// payloadSchemaURL is the location of the payload schemas in the compiler.
+const payloadSchemaURL = "payload-schema.json"
+
+// noRemoteLoader refuses to load the schemas referenced by the payload
+// schemas, so that the controller doesn't read files or fetch URLs on
+// behalf of the Receivers.
+type noRemoteLoader struct{}
+
+func (noRemoteLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("loading the referenced schema '%s' is not allowed", url)
+}
+
+// validatePayloadSchema validates the given payload against the JSON Schema
+// referenced by the Receiver, if any.
+func (s *ReceiverServer) validatePayloadSchema(ctx context.Context, receiver apiv1.Receiver, payload []byte) error {
+	ref := receiver.Spec.PayloadSchemaRef
+	if ref == nil {
+		return nil
+	}
+
+	configMapName := types.NamespacedName{Namespace: receiver.Namespace, Name: ref.Name}
+	var configMap corev1.ConfigMap
+	if err := s.kubeClient.Get(ctx, configMapName, &configMap); err != nil {
+		return fmt.Errorf("unable to read payload schema from ConfigMap '%s' error: %w", configMapName, err)
+	}
+	val, ok := configMap.Data[ref.Key]
+	if !ok {
+		return fmt.Errorf("no '%s' key found in ConfigMap '%s'", ref.Key, configMapName)
+	}
+
+	schema, err := compilePayloadSchema(val)
+	if err != nil {
+		return fmt.Errorf("invalid payload schema in ConfigMap '%s': %w", configMapName, err)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to decode the payload: %w", err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		return fmt.Errorf("the payload doesn't match the schema of ConfigMap '%s': %w", configMapName, err)
+	}
+	return nil
+}
+
+// compilePayloadSchema compiles the given JSON Schema, without loading the
+// schemas it references.
+func compilePayloadSchema(data string) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	c := jsonschema.NewCompiler()
+	c.UseLoader(noRemoteLoader{})
+	if err := c.AddResource(payloadSchemaURL, doc); err != nil {
+		return nil, err
+	}
+	return c.Compile(payloadSchemaURL)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fluxcd/pkg/runtime/logger"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+func Test_validatePayloadSchema(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "schemas",
+			Namespace: "default",
+		},
+		Data: map[string]string{
+			"push.json": `{
+  "type": "object",
+  "required": ["ref"],
+  "properties": {
+    "ref": {"type": "string", "pattern": "^refs/heads/"}
+  }
+}`,
+			"remote.json": `{"$ref": "https://example.com/schema.json"}`,
+			"invalid":     `{"type": `,
+		},
+	}
+
+	tests := []struct {
+		name      string
+		schemaRef *apiv1.ConfigMapKeyReference
+		payload   string
+		wantErr   string
+	}{
+		{
+			name:    "accepts any payload without schema",
+			payload: "not json",
+		},
+		{
+			name:      "accepts a payload matching the schema",
+			schemaRef: &apiv1.ConfigMapKeyReference{Name: "schemas", Key: "push.json"},
+			payload:   `{"ref": "refs/heads/main"}`,
+		},
+		{
+			name:      "rejects a payload not matching the schema",
+			schemaRef: &apiv1.ConfigMapKeyReference{Name: "schemas", Key: "push.json"},
+			payload:   `{"ref": "refs/tags/v1.0.0"}`,
+			wantErr:   "the payload doesn't match the schema",
+		},
+		{
+			name:      "rejects a payload without the required fields",
+			schemaRef: &apiv1.ConfigMapKeyReference{Name: "schemas", Key: "push.json"},
+			payload:   `{}`,
+			wantErr:   "the payload doesn't match the schema",
+		},
+		{
+			name:      "rejects a malformed payload",
+			schemaRef: &apiv1.ConfigMapKeyReference{Name: "schemas", Key: "push.json"},
+			payload:   `{"ref": `,
+			wantErr:   "unable to decode the payload",
+		},
+		{
+			name:      "rejects a missing key",
+			schemaRef: &apiv1.ConfigMapKeyReference{Name: "schemas", Key: "missing.json"},
+			payload:   `{}`,
+			wantErr:   "no 'missing.json' key found",
+		},
+		{
+			name:      "rejects a missing ConfigMap",
+			schemaRef: &apiv1.ConfigMapKeyReference{Name: "missing", Key: "push.json"},
+			payload:   `{}`,
+			wantErr:   "unable to read payload schema",
+		},
+		{
+			name:      "rejects an invalid schema",
+			schemaRef: &apiv1.ConfigMapKeyReference{Name: "schemas", Key: "invalid"},
+			payload:   `{}`,
+			wantErr:   "invalid payload schema",
+		},
+		{
+			name:      "doesn't load the referenced schemas",
+			schemaRef: &apiv1.ConfigMapKeyReference{Name: "schemas", Key: "remote.json"},
+			payload:   `{}`,
+			wantErr:   "invalid payload schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			kclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+			s := NewReceiverServer("", logger.NewLogger(logger.Options{}), kclient, ReceiverServerOptions{})
+
+			receiver := apiv1.Receiver{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+				Spec: apiv1.ReceiverSpec{
+					Type:             apiv1.GenericReceiver,
+					PayloadSchemaRef: tt.schemaRef,
+				},
+			}
+			err := s.validatePayloadSchema(context.TODO(), receiver, []byte(tt.payload))
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/slok/go-http-metrics/middleware"
+	"github.com/slok/go-http-metrics/middleware/std"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+
+	apiv1 "github.com/fluxcd/notification-controller/api/v1"
+)
+
+// EventDispatcher dispatches the events generated by the Receivers to the
+// matching Alerts.
+type EventDispatcher interface {
+	DispatchEvent(ctx context.Context, event *eventv1.Event)
+}
+
+// ReceiverServer handles webhook POST requests
+type ReceiverServer struct {
+	port                  string
+	logger                logr.Logger
+	kubeClient            client.Client
+	exportHTTPPathMetrics bool
+	asyncWorkers          int
+	arbitraryResources    bool
+	queue                 chan receiverRequest
+	dispatcher            EventDispatcher
+	patchLimiter          flowcontrol.RateLimiter
+	apiVersions           map[string]string
+	listenOptions         ListenOptions
+	listening             atomic.Bool
+	lastDequeue           atomic.Int64
+}
+
+// ReceiverServerOptions configures a ReceiverServer. The zero value of the
+// options disables the optional features.
+type ReceiverServerOptions struct {
+	// ExportHTTPPathMetrics labels the HTTP metrics with the webhook paths.
+	ExportHTTPPathMetrics bool
+
+	// AsyncWorkers is the number of workers annotating the resources of the
+	// Receivers after the webhook requests are acknowledged. When zero, the
+	// resources are annotated before the requests are answered.
+	AsyncWorkers int
+
+	// ArbitraryResources allows the Receivers to target resources of any
+	// kind, and not only Flux kinds.
+	ArbitraryResources bool
+
+	// Dispatcher dispatches the events of the resources with the notify
+	// action.
+	Dispatcher EventDispatcher
+
+	// PatchQPS rate limits the annotation patches of the resources when
+	// greater than zero, with bursts of up to PatchBurst requests.
+	PatchQPS   float32
+	PatchBurst int
+
+	// APIVersions maps kinds to the API versions of the resources without
+	// apiVersion, overriding the versions discovered from the cluster.
+	APIVersions map[string]string
+
+	// ListenOptions selects the IP family the server is bound to.
+	ListenOptions ListenOptions
+}
+
+// NewReceiverServer returns an HTTP server that handles webhooks on the
+// given port, configured with the given options.
+func NewReceiverServer(port string, logger logr.Logger, kubeClient client.Client, opts ReceiverServerOptions) *ReceiverServer {
+	s := &ReceiverServer{
+		port:                  port,
+		logger:                logger.WithName("receiver-server"),
+		kubeClient:            kubeClient,
+		exportHTTPPathMetrics: opts.ExportHTTPPathMetrics,
+		asyncWorkers:          opts.AsyncWorkers,
+		arbitraryResources:    opts.ArbitraryResources,
+		dispatcher:            opts.Dispatcher,
+		apiVersions:           opts.APIVersions,
+		listenOptions:         opts.ListenOptions,
+	}
+	if opts.AsyncWorkers > 0 {
+		s.queue = make(chan receiverRequest, receiverQueueSize)
+	}
+	if opts.PatchQPS > 0 {
+		s.patchLimiter = flowcontrol.NewTokenBucketRateLimiter(opts.PatchQPS, max(opts.PatchBurst, 1))
+	}
+	return s
+}
+
+// ListenAndServe starts the HTTP server on the specified port
+func (s *ReceiverServer) ListenAndServe(stopCh <-chan struct{}, mdlw middleware.Middleware) {
+	s.lastDequeue.Store(time.Now().UnixNano())
+	for i := 0; i < s.asyncWorkers; i++ {
+		go s.processQueue(stopCh)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(apiv1.ReceiverWebhookPath, gzipMiddleware(s.logger, http.HandlerFunc(s.handlePayload())))
+	handlerID := apiv1.ReceiverWebhookPath
+	if s.exportHTTPPathMetrics {
+		handlerID = ""
+	}
+	h := std.Handler(handlerID, mdlw, mux)
+	srv := &http.Server{
+		Handler: h,
+	}
+
+	ln, err := s.listenOptions.Listen(s.port)
+	if err != nil {
+		s.logger.Error(err, "Receiver server crashed")
+		os.Exit(1)
+	}
+	s.logger.Info("Receiver server started", "url", listenURL(ln))
+	s.listening.Store(true)
+
+	go func() {
+		if err := srv.Serve(ln); err != http.ErrServerClosed {
+			s.logger.Error(err, "Receiver server crashed")
+			os.Exit(1)
+		}
+	}()
+
+	// wait for SIGTERM or SIGINT
+	<-stopCh
+	s.listening.Store(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		s.logger.Error(err, "Receiver server graceful shutdown failed")
+	} else {
+		s.logger.Info("Receiver server stopped")
+	}
+}
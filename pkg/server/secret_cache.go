@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretCache is a client.Client serving the Secrets and ConfigMaps of the
+// Providers read at dispatch time from a cache, to cut the redundant reads
+// from the Kubernetes API during event storms. The cached objects expire
+// after the TTL, and the other objects are read with the wrapped client.
+type secretCache struct {
+	client.Client
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[secretCacheKey]secretCacheEntry
+}
+
+type secretCacheKey struct {
+	kind string
+	name types.NamespacedName
+}
+
+type secretCacheEntry struct {
+	obj       client.Object
+	expiresAt time.Time
+}
+
+// newSecretCache returns the given client wrapped with a cache of the
+// Secrets and ConfigMaps expiring after the given TTL, or the client as
+// is if the TTL is not greater than zero.
+func newSecretCache(c client.Client, ttl time.Duration) client.Client {
+	if ttl <= 0 {
+		return c
+	}
+	return &secretCache{
+		Client:  c,
+		ttl:     ttl,
+		entries: make(map[secretCacheKey]secretCacheEntry),
+	}
+}
+
+// Get reads the Secrets and ConfigMaps from the cache when they haven't
+// expired yet, and from the wrapped client otherwise. The failed reads,
+// e.g. of the missing objects, are not cached.
+func (c *secretCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	var kind string
+	switch obj.(type) {
+	case *corev1.Secret:
+		kind = "Secret"
+	case *corev1.ConfigMap:
+		kind = "ConfigMap"
+	default:
+		return c.Client.Get(ctx, key, obj, opts...)
+	}
+	k := secretCacheKey{kind: kind, name: key}
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[k]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		switch o := obj.(type) {
+		case *corev1.Secret:
+			entry.obj.(*corev1.Secret).DeepCopyInto(o)
+		case *corev1.ConfigMap:
+			entry.obj.(*corev1.ConfigMap).DeepCopyInto(o)
+		}
+		return nil
+	}
+
+	if err := c.Client.Get(ctx, key, obj, opts...); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for cached, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, cached)
+		}
+	}
+	c.entries[k] = secretCacheEntry{
+		obj:       obj.DeepCopyObject().(client.Object),
+		expiresAt: now.Add(c.ttl),
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestSecretCache(t *testing.T) {
+	g := NewWithT(t)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "token"},
+		Data:       map[string][]byte{"token": []byte("foo")},
+	}
+	reads := 0
+	kubeClient := fakeclient.NewClientBuilder().
+		WithObjects(secret).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				reads++
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).Build()
+
+	g.Expect(newSecretCache(kubeClient, 0)).To(BeIdenticalTo(kubeClient))
+
+	cache := newSecretCache(kubeClient, time.Minute)
+	key := types.NamespacedName{Namespace: "default", Name: "token"}
+	for range 3 {
+		var s corev1.Secret
+		g.Expect(cache.Get(context.TODO(), key, &s)).To(Succeed())
+		g.Expect(s.Data).To(HaveKeyWithValue("token", []byte("foo")))
+	}
+	g.Expect(reads).To(Equal(1))
+
+	// The cached objects are copied to the callers.
+	var s corev1.Secret
+	g.Expect(cache.Get(context.TODO(), key, &s)).To(Succeed())
+	s.Data["token"] = []byte("bar")
+	g.Expect(cache.Get(context.TODO(), key, &s)).To(Succeed())
+	g.Expect(s.Data).To(HaveKeyWithValue("token", []byte("foo")))
+
+	// The missing objects are not cached.
+	missing := types.NamespacedName{Namespace: "default", Name: "missing"}
+	g.Expect(cache.Get(context.TODO(), missing, &corev1.Secret{})).ToNot(Succeed())
+	g.Expect(cache.Get(context.TODO(), missing, &corev1.Secret{})).ToNot(Succeed())
+	g.Expect(reads).To(Equal(3))
+
+	// The expired objects are read again.
+	c := cache.(*secretCache)
+	c.mu.Lock()
+	for k, e := range c.entries {
+		e.expiresAt = time.Now()
+		c.entries[k] = e
+	}
+	c.mu.Unlock()
+	g.Expect(cache.Get(context.TODO(), key, &corev1.Secret{})).To(Succeed())
+	g.Expect(reads).To(Equal(4))
+}